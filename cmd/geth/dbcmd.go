@@ -35,9 +35,11 @@ import (
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/console/prompt"
+	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -70,6 +72,7 @@ Remove blockchain and state databases`,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
+			dbLogIndexCmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -244,6 +247,44 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		},
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbLogIndexCmd = cli.Command{
+		Name:      "logindex",
+		Usage:     "Build or verify the address and topic log index",
+		ArgsUsage: "",
+		Subcommands: []cli.Command{
+			{
+				Action: utils.MigrateFlags(buildLogIndex),
+				Name:   "build",
+				Usage:  "Index all sections of the chain not yet in the log index",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+					utils.MainnetFlag,
+					utils.RopstenFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+				},
+				Description: `This command builds the log index used by eth_getLogs on nodes running with
+--logindex, up to the last complete section of the local chain.`,
+			},
+			{
+				Action: utils.MigrateFlags(verifyLogIndex),
+				Name:   "verify",
+				Usage:  "Check the log index against the logs stored in the database",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+					utils.MainnetFlag,
+					utils.RopstenFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+				},
+				Description: "This command regenerates every indexed section of the log index and compares it with the stored one.",
+			},
+		},
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -528,9 +569,10 @@ func freezerInspect(ctx *cli.Context) error {
 		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
 	}
 	kind := ctx.Args().Get(0)
-	if noSnap, ok := rawdb.FreezerNoSnappy[kind]; !ok {
+	tables := rawdb.AncientTables()
+	if noSnap, ok := tables[kind]; !ok {
 		var options []string
-		for opt := range rawdb.FreezerNoSnappy {
+		for opt := range tables {
 			options = append(options, opt)
 		}
 		sort.Strings(options)
@@ -696,3 +738,81 @@ func exportChaindata(ctx *cli.Context) error {
 	db := utils.MakeChainDatabase(ctx, stack, true)
 	return utils.ExportChaindata(ctx.Args().Get(1), kind, exporter(db), stop)
 }
+
+// openLogIndex opens the log indexer of the chain database, along with the
+// chain config to decode the logs with.
+func openLogIndex(db ethdb.Database) (*core.ChainIndexer, *params.ChainConfig, error) {
+	config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if config == nil {
+		return nil, nil, errors.New("chain config not found")
+	}
+	return core.NewLogIndexer(db, config, params.BloomBitsBlocks, params.BloomConfirms), config, nil
+}
+
+// buildLogIndex indexes the logs of all complete sections of the local chain.
+func buildLogIndex(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	head := rawdb.ReadHeadHeader(db)
+	if head == nil {
+		return errors.New("head header not found")
+	}
+	indexer, _, err := openLogIndex(db)
+	if err != nil {
+		return err
+	}
+	defer indexer.Close()
+
+	var (
+		start    = time.Now()
+		sections = (head.Number.Uint64() + 1) / params.BloomBitsBlocks
+	)
+	stored, _, _ := indexer.Sections()
+	log.Info("Building log index", "indexed", stored, "sections", sections)
+	if err := indexer.ProcessSections(sections); err != nil {
+		return err
+	}
+	log.Info("Built log index", "sections", sections, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// verifyLogIndex checks all indexed sections against the logs in the database.
+func verifyLogIndex(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	indexer, config, err := openLogIndex(db)
+	if err != nil {
+		return err
+	}
+	defer indexer.Close()
+
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		failures int
+	)
+	sections, _, _ := indexer.Sections()
+	for section := uint64(0); section < sections; section++ {
+		if err := core.VerifyLogIndex(db, config, params.BloomBitsBlocks, section); err != nil {
+			log.Error("Invalid log index section", "section", section, "err", err)
+			failures++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying log index", "section", section, "sections", sections, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d log index sections invalid", failures, sections)
+	}
+	log.Info("Verified log index", "sections", sections, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
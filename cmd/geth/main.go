@@ -65,6 +65,7 @@ var (
 		utils.BootnodesFlag,
 		utils.DataDirFlag,
 		utils.AncientFlag,
+		utils.DBEngineFlag,
 		utils.MinFreeDiskSpaceFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
@@ -91,11 +92,13 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolPrivateFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.LogIndexFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -147,6 +150,7 @@ var (
 		utils.GoerliFlag,
 		utils.ScrollAlphaFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMWasmEngineFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
 		utils.FakePoWFlag,
@@ -158,6 +162,17 @@ var (
 		utils.MinerNotifyFullFlag,
 		configFileFlag,
 		utils.CatalystFlag,
+		utils.DerivationEndpointFlag,
+		utils.DerivationPollIntervalFlag,
+		utils.L1SyncEndpointFlag,
+		utils.L1SyncPollIntervalFlag,
+		utils.L1SyncConfirmationsFlag,
+		utils.L1GasOracleEndpointFlag,
+		utils.L1GasOracleAccountFlag,
+		utils.L1GasOracleIntervalFlag,
+		utils.L1GasOracleThresholdFlag,
+		utils.L1GasOracleMaxChangeFlag,
+		utils.L1GasOracleDryRunFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -177,12 +192,22 @@ var (
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
+		utils.AuthEnabledFlag,
+		utils.AuthListenFlag,
+		utils.AuthPortFlag,
+		utils.AuthVirtualHostsFlag,
+		utils.JWTSecretFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCBatchRequestLimitFlag,
+		utils.RPCBatchResponseMaxSizeFlag,
+		utils.RPCMethodConcurrencyFlag,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateBurstFlag,
 		utils.AllowUnprotectedTxs,
 	}
 
@@ -37,6 +37,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			configFileFlag,
 			utils.DataDirFlag,
 			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.MinFreeDiskSpaceFlag,
 			utils.KeyStoreDirFlag,
 			utils.USBFlag,
@@ -52,6 +53,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
+			utils.LogIndexFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
@@ -107,6 +109,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolPrivateFlag,
 		},
 	},
 	{
@@ -153,9 +156,19 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GraphQLEnabledFlag,
 			utils.GraphQLCORSDomainFlag,
 			utils.GraphQLVirtualHostsFlag,
+			utils.AuthEnabledFlag,
+			utils.AuthListenFlag,
+			utils.AuthPortFlag,
+			utils.AuthVirtualHostsFlag,
+			utils.JWTSecretFlag,
 			utils.RPCGlobalGasCapFlag,
 			utils.RPCGlobalEVMTimeoutFlag,
 			utils.RPCGlobalTxFeeCapFlag,
+			utils.RPCBatchRequestLimitFlag,
+			utils.RPCBatchResponseMaxSizeFlag,
+			utils.RPCMethodConcurrencyFlag,
+			utils.RPCRateLimitFlag,
+			utils.RPCRateBurstFlag,
 			utils.AllowUnprotectedTxs,
 			utils.JSpathFlag,
 			utils.ExecFlag,
@@ -202,10 +215,27 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GpoIgnoreGasPriceFlag,
 		},
 	},
+	{
+		Name: "ROLLUP",
+		Flags: []cli.Flag{
+			utils.DerivationEndpointFlag,
+			utils.DerivationPollIntervalFlag,
+			utils.L1SyncEndpointFlag,
+			utils.L1SyncPollIntervalFlag,
+			utils.L1SyncConfirmationsFlag,
+			utils.L1GasOracleEndpointFlag,
+			utils.L1GasOracleAccountFlag,
+			utils.L1GasOracleIntervalFlag,
+			utils.L1GasOracleThresholdFlag,
+			utils.L1GasOracleMaxChangeFlag,
+			utils.L1GasOracleDryRunFlag,
+		},
+	},
 	{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
+			utils.VMWasmEngineFlag,
 		},
 	},
 	{
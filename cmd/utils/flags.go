@@ -70,6 +70,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/p2p/nat"
 	"github.com/scroll-tech/go-ethereum/p2p/netutil"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/derivation"
+	"github.com/scroll-tech/go-ethereum/rollup/gas_oracle"
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
 )
 
 func init() {
@@ -122,6 +125,10 @@ var (
 		Name:  "datadir.minfreedisk",
 		Usage: "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
 	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: "Backing database implementation to use ('leveldb' or 'pebble')",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -236,6 +243,10 @@ var (
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value: ethconfig.Defaults.TxLookupLimit,
 	}
+	LogIndexFlag = cli.BoolFlag{
+		Name:  "logindex",
+		Usage: "Maintain an address and topic index of logs to speed up log filtering",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -388,6 +399,10 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolPrivateFlag = cli.BoolFlag{
+		Name:  "txpool.private",
+		Usage: "Keeps locally submitted transactions off the network and hides pending transactions from unauthenticated APIs",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -498,6 +513,10 @@ var (
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
 	}
+	VMWasmEngineFlag = cli.StringFlag{
+		Name:  "vm.wasmengine",
+		Usage: "WASM engine executing the contracts (" + strings.Join(vm.WasmEngines(), ", ") + ")",
+	}
 	InsecureUnlockAllowedFlag = cli.BoolFlag{
 		Name:  "allow-insecure-unlock",
 		Usage: "Allow insecure account unlocking when account-related RPCs are exposed by http",
@@ -517,6 +536,28 @@ var (
 		Usage: "Sets a cap on transaction fee (in ether) that can be sent via the RPC APIs (0 = no cap)",
 		Value: ethconfig.Defaults.RPCTxFeeCap,
 	}
+	RPCBatchRequestLimitFlag = cli.IntFlag{
+		Name:  "rpc.batch-request-limit",
+		Usage: "Maximum number of requests in an HTTP/WS batch (0 = unlimited)",
+		Value: node.DefaultConfig.BatchRequestLimit,
+	}
+	RPCBatchResponseMaxSizeFlag = cli.IntFlag{
+		Name:  "rpc.batch-response-max-size",
+		Usage: "Maximum number of bytes returned from an HTTP/WS batch (0 = unlimited)",
+		Value: node.DefaultConfig.BatchResponseMaxSize,
+	}
+	RPCMethodConcurrencyFlag = cli.StringFlag{
+		Name:  "rpc.method-concurrency",
+		Usage: "Comma separated method=limit pairs capping concurrent HTTP/WS calls of a method (e.g. eth_call=16)",
+	}
+	RPCRateLimitFlag = cli.Float64Flag{
+		Name:  "rpc.ratelimit",
+		Usage: "Maximum number of requests per second of an HTTP/WS connection (0 = unlimited)",
+	}
+	RPCRateBurstFlag = cli.IntFlag{
+		Name:  "rpc.ratelimit.burst",
+		Usage: "Number of requests an HTTP/WS connection may issue at once (0 = the per second limit)",
+	}
 	// Logging and debug settings
 	EthStatsURLFlag = cli.StringFlag{
 		Name:  "ethstats",
@@ -573,6 +614,29 @@ var (
 		Usage: "HTTP path path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
 		Value: "",
 	}
+	AuthEnabledFlag = cli.BoolFlag{
+		Name:  "authrpc",
+		Usage: "Enable the JWT authenticated HTTP and WebSocket RPC server, serving the engine and admin APIs",
+	}
+	AuthListenFlag = cli.StringFlag{
+		Name:  "authrpc.addr",
+		Usage: "Listening address for the authenticated RPC server",
+		Value: node.DefaultAuthHost,
+	}
+	AuthPortFlag = cli.IntFlag{
+		Name:  "authrpc.port",
+		Usage: "Listening port for the authenticated RPC server",
+		Value: node.DefaultAuthPort,
+	}
+	AuthVirtualHostsFlag = cli.StringFlag{
+		Name:  "authrpc.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.AuthVirtualHosts, ","),
+	}
+	JWTSecretFlag = cli.StringFlag{
+		Name:  "authrpc.jwtsecret",
+		Usage: "Path to a JWT secret to use for the authenticated RPC server (default = inside the datadir)",
+	}
 	GraphQLEnabledFlag = cli.BoolFlag{
 		Name:  "graphql",
 		Usage: "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
@@ -794,6 +858,58 @@ var (
 		Name:  "catalyst",
 		Usage: "Catalyst mode (eth2 integration testing)",
 	}
+
+	// Rollup derivation settings
+	DerivationEndpointFlag = cli.StringFlag{
+		Name:  "rollup.derive",
+		Usage: "RPC endpoint of the L1 batch source to derive the chain from (enables verifier mode)",
+	}
+	DerivationPollIntervalFlag = cli.DurationFlag{
+		Name:  "rollup.derive.interval",
+		Usage: "Interval between polls for newly committed L1 batches",
+		Value: ethconfig.Defaults.Derivation.PollInterval,
+	}
+	L1SyncEndpointFlag = cli.StringFlag{
+		Name:  "rollup.l1sync",
+		Usage: "RPC endpoint of the L1 message source to sync enqueued messages from",
+	}
+	L1SyncPollIntervalFlag = cli.DurationFlag{
+		Name:  "rollup.l1sync.interval",
+		Usage: "Interval between polls for newly enqueued L1 messages",
+		Value: ethconfig.Defaults.L1Sync.PollInterval,
+	}
+	L1SyncConfirmationsFlag = cli.Uint64Flag{
+		Name:  "rollup.l1sync.confirmations",
+		Usage: "Number of L1 blocks an enqueued message must be buried under before syncing",
+		Value: ethconfig.Defaults.L1Sync.Confirmations,
+	}
+	L1GasOracleEndpointFlag = cli.StringFlag{
+		Name:  "rollup.gasoracle",
+		Usage: "RPC endpoint of the L1 node to keep the L1GasPriceOracle base fee in sync with",
+	}
+	L1GasOracleAccountFlag = cli.StringFlag{
+		Name:  "rollup.gasoracle.account",
+		Usage: "Unlocked keystore account signing the L1 base fee updates",
+	}
+	L1GasOracleIntervalFlag = cli.DurationFlag{
+		Name:  "rollup.gasoracle.interval",
+		Usage: "Interval between L1 base fee polls",
+		Value: ethconfig.Defaults.L1GasOracle.PollInterval,
+	}
+	L1GasOracleThresholdFlag = cli.Uint64Flag{
+		Name:  "rollup.gasoracle.threshold",
+		Usage: "Minimum deviation of the L1 base fee from the on-chain value to update it (percent)",
+		Value: ethconfig.Defaults.L1GasOracle.Threshold,
+	}
+	L1GasOracleMaxChangeFlag = cli.Uint64Flag{
+		Name:  "rollup.gasoracle.maxchange",
+		Usage: "Maximum change of the on-chain L1 base fee per update (percent, 0 = unlimited)",
+		Value: ethconfig.Defaults.L1GasOracle.MaxChange,
+	}
+	L1GasOracleDryRunFlag = cli.BoolFlag{
+		Name:  "rollup.gasoracle.dryrun",
+		Usage: "Log the L1 base fee updates instead of submitting them",
+	}
 )
 
 // MakeDataDir retrieves the currently requested data directory, terminating
@@ -980,6 +1096,29 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setAuth creates the authenticated RPC listener interface string from the set
+// command line flags, returning empty if the authenticated endpoint is disabled.
+func setAuth(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(AuthEnabledFlag.Name) && cfg.AuthAddr == "" {
+		cfg.AuthAddr = "127.0.0.1"
+		if ctx.GlobalIsSet(AuthListenFlag.Name) {
+			cfg.AuthAddr = ctx.GlobalString(AuthListenFlag.Name)
+		}
+	}
+
+	if ctx.GlobalIsSet(AuthPortFlag.Name) {
+		cfg.AuthPort = ctx.GlobalInt(AuthPortFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(AuthVirtualHostsFlag.Name) {
+		cfg.AuthVirtualHosts = SplitAndTrim(ctx.GlobalString(AuthVirtualHostsFlag.Name))
+	}
+
+	if ctx.GlobalIsSet(JWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.GlobalString(JWTSecretFlag.Name)
+	}
+}
+
 // setGraphQL creates the GraphQL listener interface string from the set
 // command line flags, returning empty if the GraphQL endpoint is disabled.
 func setGraphQL(ctx *cli.Context, cfg *node.Config) {
@@ -991,6 +1130,36 @@ func setGraphQL(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setRPCLimits applies the request limits of the HTTP and WebSocket endpoints.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCBatchRequestLimitFlag.Name) {
+		cfg.BatchRequestLimit = ctx.GlobalInt(RPCBatchRequestLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCBatchResponseMaxSizeFlag.Name) {
+		cfg.BatchResponseMaxSize = ctx.GlobalInt(RPCBatchResponseMaxSizeFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCMethodConcurrencyFlag.Name) {
+		cfg.RPCMethodConcurrency = make(map[string]int)
+		for _, entry := range SplitAndTrim(ctx.GlobalString(RPCMethodConcurrencyFlag.Name)) {
+			parts := strings.Split(entry, "=")
+			if len(parts) != 2 {
+				Fatalf("Invalid method concurrency entry: %s", entry)
+			}
+			limit, err := strconv.Atoi(parts[1])
+			if err != nil || limit < 0 {
+				Fatalf("Invalid concurrency limit for method %s: %s", parts[0], parts[1])
+			}
+			cfg.RPCMethodConcurrency[parts[0]] = limit
+		}
+	}
+	if ctx.GlobalIsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCRateLimit = ctx.GlobalFloat64(RPCRateLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRateBurstFlag.Name) {
+		cfg.RPCRateBurst = ctx.GlobalInt(RPCRateBurstFlag.Name)
+	}
+}
+
 // setWS creates the WebSocket RPC listener interface string from the set
 // command line flags, returning empty if the HTTP endpoint is disabled.
 func setWS(ctx *cli.Context, cfg *node.Config) {
@@ -1223,10 +1392,21 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
+	setAuth(ctx, cfg)
+	setRPCLimits(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	setDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
 
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		dbEngine := ctx.GlobalString(DBEngineFlag.Name)
+		if dbEngine != "leveldb" && dbEngine != "pebble" {
+			Fatalf("Invalid choice for db.engine '%s', allowed 'leveldb' or 'pebble'", dbEngine)
+		}
+		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
+		cfg.DBEngine = dbEngine
+	}
+
 	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
 	}
@@ -1361,6 +1541,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPrivateFlag.Name) {
+		cfg.Private = ctx.GlobalBool(TxPoolPrivateFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1415,6 +1598,55 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	}
 }
 
+func setDerivation(ctx *cli.Context, cfg *derivation.Config) {
+	if ctx.GlobalIsSet(DerivationEndpointFlag.Name) {
+		cfg.Endpoint = ctx.GlobalString(DerivationEndpointFlag.Name)
+	}
+	if ctx.GlobalIsSet(DerivationPollIntervalFlag.Name) {
+		cfg.PollInterval = ctx.GlobalDuration(DerivationPollIntervalFlag.Name)
+	}
+}
+
+func setL1Sync(ctx *cli.Context, cfg *sync_service.Config) {
+	if ctx.GlobalIsSet(L1SyncEndpointFlag.Name) {
+		cfg.Endpoint = ctx.GlobalString(L1SyncEndpointFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1SyncPollIntervalFlag.Name) {
+		cfg.PollInterval = ctx.GlobalDuration(L1SyncPollIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1SyncConfirmationsFlag.Name) {
+		cfg.Confirmations = ctx.GlobalUint64(L1SyncConfirmationsFlag.Name)
+	}
+}
+
+func setL1GasOracle(ctx *cli.Context, cfg *gas_oracle.Config) {
+	if ctx.GlobalIsSet(L1GasOracleEndpointFlag.Name) {
+		cfg.Endpoint = ctx.GlobalString(L1GasOracleEndpointFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1GasOracleAccountFlag.Name) {
+		account := ctx.GlobalString(L1GasOracleAccountFlag.Name)
+		if !common.IsHexAddress(account) {
+			Fatalf("Invalid gas oracle account %q", account)
+		}
+		cfg.Account = common.HexToAddress(account)
+	}
+	if ctx.GlobalIsSet(L1GasOracleIntervalFlag.Name) {
+		cfg.PollInterval = ctx.GlobalDuration(L1GasOracleIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1GasOracleThresholdFlag.Name) {
+		cfg.Threshold = ctx.GlobalUint64(L1GasOracleThresholdFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1GasOracleMaxChangeFlag.Name) {
+		cfg.MaxChange = ctx.GlobalUint64(L1GasOracleMaxChangeFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1GasOracleDryRunFlag.Name) {
+		cfg.DryRun = ctx.GlobalBool(L1GasOracleDryRunFlag.Name)
+	}
+	if cfg.Endpoint != "" && cfg.Account == (common.Address{}) && !cfg.DryRun {
+		Fatalf("Gas oracle updates require --%s", L1GasOracleAccountFlag.Name)
+	}
+}
+
 func setWhitelist(ctx *cli.Context, cfg *ethconfig.Config) {
 	whitelist := ctx.GlobalString(WhitelistFlag.Name)
 	if whitelist == "" {
@@ -1502,6 +1734,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEthash(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
 	setWhitelist(ctx, cfg)
+	setDerivation(ctx, &cfg.Derivation)
+	setL1Sync(ctx, &cfg.L1Sync)
+	setL1GasOracle(ctx, &cfg.L1GasOracle)
 	setLes(ctx, cfg)
 
 	// Cap the cache allowance and tune the garbage collector
@@ -1556,6 +1791,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.GlobalBool(LogIndexFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
@@ -1587,6 +1825,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMWasmEngineFlag.Name) {
+		cfg.WasmEngine = ctx.GlobalString(VMWasmEngineFlag.Name)
+		if _, err := vm.NewWasmEngine(cfg.WasmEngine); err != nil {
+			Fatalf("Option %q: %v", VMWasmEngineFlag.Name, err)
+		}
+	}
 
 	if ctx.GlobalIsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.GlobalUint64(RPCGlobalGasCapFlag.Name)
@@ -1931,7 +2175,10 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cache.TrieDirtyLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
-	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
+	vmcfg := vm.Config{
+		EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name),
+		WasmEngine:              ctx.GlobalString(VMWasmEngineFlag.Name),
+	}
 
 	// TODO(rjl493456442) disable snapshot generation/wiping if the chain is read only.
 	// Disable transaction indexing/unindexing by default.
@@ -37,4 +37,8 @@ var (
 
 	// ErrInvalidTxCount is returned if a block contains too many transactions.
 	ErrInvalidTxCount = errors.New("invalid transaction count")
+
+	// ErrMissingL1MessageData is returned when validating a block requires an
+	// L1 message that hasn't been synced from L1 yet.
+	ErrMissingL1MessageData = errors.New("unknown L1 message data")
 )
@@ -74,9 +74,89 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 		}
 		return consensus.ErrPrunedAncestor
 	}
+	return v.ValidateL1Messages(block)
+}
+
+// ValidateL1Messages validates the L1 messages included in the given block.
+// They must precede all L2 transactions, consume the message queue in order
+// starting at the first message not consumed by the parent, and match the
+// messages synced from L1. Messages which can never be executed are included
+// nonetheless, they are skipped during execution. Once the first pending
+// message is past its forced inclusion deadline, the block may not contain
+// any L2 transactions.
+//
+// The deadline is evaluated against the L1 block number committed in the header,
+// which may not decrease along the chain. A block referencing an L1 block which
+// hasn't been synced yet can't be validated until it is.
+func (v *BlockValidator) ValidateL1Messages(block *types.Block) error {
+	config := v.config.Scroll.L1Config
+	if config == nil {
+		for _, tx := range block.Transactions() {
+			if tx.IsL1MessageTx() {
+				return ErrL1MessagesDisabled
+			}
+		}
+		return nil
+	}
+	l1BlockNumber, err := v.validateL1BlockNumber(block)
+	if err != nil {
+		return err
+	}
+	var (
+		next     = v.bc.NextL1MessageIndex(block.ParentHash())
+		numL1Txs uint64
+		numL2Txs int
+	)
+	for i, tx := range block.Transactions() {
+		if !tx.IsL1MessageTx() {
+			numL2Txs++
+			continue
+		}
+		if numL2Txs > 0 {
+			return fmt.Errorf("%w: L1 message at position %d follows L2 transactions", ErrInvalidL1MessageOrder, i)
+		}
+		if tx.Nonce() != next {
+			return fmt.Errorf("%w: have queue index %d, want %d", ErrInvalidL1MessageOrder, tx.Nonce(), next)
+		}
+		msg, _ := v.bc.L1Message(next)
+		if msg == nil {
+			return fmt.Errorf("%w: queue index %d", consensus.ErrMissingL1MessageData, next)
+		}
+		if types.NewTx(msg).Hash() != tx.Hash() {
+			return fmt.Errorf("%w: queue index %d", ErrUnknownL1Message, next)
+		}
+		next++
+		numL1Txs++
+	}
+	if config.NumL1MessagesPerBlock > 0 && numL1Txs > config.NumL1MessagesPerBlock {
+		return fmt.Errorf("%w: have %d, max %d", ErrTooManyL1Messages, numL1Txs, config.NumL1MessagesPerBlock)
+	}
+	if numL2Txs > 0 && l1BlockNumber != nil && v.bc.L1MessageOverdue(next, *l1BlockNumber) {
+		return fmt.Errorf("%w: queue index %d", ErrL1MessageOverdue, next)
+	}
 	return nil
 }
 
+// validateL1BlockNumber checks the L1 block number committed in the header of a
+// block subject to the forced inclusion deadline, and returns it. Nil is returned
+// if the deadline doesn't apply.
+func (v *BlockValidator) validateL1BlockNumber(block *types.Block) (*uint64, error) {
+	if !v.config.IsForcedInclusion(block.Number()) {
+		return nil, nil
+	}
+	number := block.Header().L1BlockNumber
+	if number == nil {
+		return nil, fmt.Errorf("%w: missing", ErrInvalidL1BlockNumber)
+	}
+	if parent := v.bc.GetHeader(block.ParentHash(), block.NumberU64()-1); parent != nil && parent.L1BlockNumber != nil && *number < *parent.L1BlockNumber {
+		return nil, fmt.Errorf("%w: have %d, parent %d", ErrInvalidL1BlockNumber, *number, *parent.L1BlockNumber)
+	}
+	if synced, _ := v.bc.SyncedL1BlockNumber(); synced < *number {
+		return nil, fmt.Errorf("%w: L1 block %d, synced %d", consensus.ErrMissingL1MessageData, *number, synced)
+	}
+	return number, nil
+}
+
 // ValidateState validates the various changes that happen after a state
 // transition, such as amount of used gas, the receipt roots and the state root
 // itself. ValidateState returns a database batch if the validation was a success
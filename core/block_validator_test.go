@@ -17,14 +17,19 @@
 package core
 
 import (
+	"errors"
+	"math/big"
 	"runtime"
 	"testing"
 	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus"
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/params"
 )
 
@@ -229,3 +234,96 @@ func TestCalcGasLimit(t *testing.T) {
 		}
 	}
 }
+
+// Tests that L1 messages must be included in queue order, ahead of all L2
+// transactions, and before any L2 transaction once they are overdue.
+func TestValidateL1Messages(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		config = *params.TestNoL1feeChainConfig
+	)
+	config.Scroll.L1Config = &params.L1Config{NumL1MessagesPerBlock: 2, ForcedInclusionDeadline: 10}
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{Config: &config, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		signer = types.LatestSigner(&config)
+	)
+	gspec.MustCommit(db)
+	// Message 1 runs out of intrinsic gas and gets skipped, message 3 is overdue
+	// as of L1 block 90
+	msgs := []*types.L1MessageTx{
+		{QueueIndex: 0, Gas: params.TxGas, To: &common.Address{1}, Value: new(big.Int), Sender: common.Address{0xaa}},
+		{QueueIndex: 1, Gas: 1000, To: &common.Address{1}, Value: new(big.Int), Sender: common.Address{0xaa}},
+		{QueueIndex: 2, Gas: params.TxGas, To: &common.Address{2}, Value: new(big.Int), Sender: common.Address{0xbb}},
+		{QueueIndex: 3, Gas: params.TxGas, To: &common.Address{3}, Value: new(big.Int), Sender: common.Address{0xbb}},
+	}
+	for i, msg := range msgs {
+		enqueued := uint64(95)
+		if i == 3 {
+			enqueued = 80
+		}
+		rawdb.WriteL1Message(db, msg, enqueued)
+	}
+	rawdb.WriteLastL1MessageQueueIndex(db, 3)
+	rawdb.WriteSyncedL1BlockNumber(db, 100)
+
+	chain, err := NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// makeBlock creates a block on top of the current head committing to the given
+	// L1 block, with the given L1 messages, optionally followed by an L2 transfer.
+	nonce := uint64(0)
+	makeBlock := func(l1Block uint64, l1 []*types.L1MessageTx, l2 bool) *types.Block {
+		blocks, _ := GenerateChain(&config, chain.CurrentBlock(), ethash.NewFaker(), db, 1, func(i int, b *BlockGen) {
+			b.SetL1BlockNumber(l1Block)
+			for _, msg := range l1 {
+				b.AddTx(types.NewTx(msg))
+			}
+			if l2 {
+				tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0xff}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+				b.AddTx(tx)
+			}
+		})
+		return blocks[0]
+	}
+	tests := []struct {
+		l1Block uint64
+		l1      []*types.L1MessageTx
+		l2      bool
+		want    error
+	}{
+		{90, []*types.L1MessageTx{msgs[1]}, false, ErrInvalidL1MessageOrder},                                     // queue index gap
+		{90, []*types.L1MessageTx{msgs[0], msgs[1], msgs[2]}, false, ErrTooManyL1Messages},                       // over the per-block limit
+		{90, []*types.L1MessageTx{{QueueIndex: 0, Gas: params.TxGas}}, false, ErrUnknownL1Message},               // not the enqueued message
+		{101, []*types.L1MessageTx{msgs[0]}, false, consensus.ErrMissingL1MessageData},                           // L1 block not synced yet
+		{85, []*types.L1MessageTx{msgs[0], msgs[1]}, true, nil},                                                  // in order, skipped message included
+		{84, []*types.L1MessageTx{msgs[2]}, true, ErrInvalidL1BlockNumber},                                       // L1 block below the parent's
+		{89, []*types.L1MessageTx{msgs[2]}, true, nil},                                                           // message 3 not overdue yet
+		{90, []*types.L1MessageTx{}, true, ErrL1MessageOverdue},                                                  // message 3 is overdue
+		{90, []*types.L1MessageTx{}, false, nil},                                                                 // overdue message omitted without L2 transactions
+		{90, []*types.L1MessageTx{msgs[3]}, true, nil},                                                           // overdue message included
+		{90, []*types.L1MessageTx{{QueueIndex: 4, Gas: params.TxGas}}, false, consensus.ErrMissingL1MessageData}, // not synced yet
+	}
+	for i, tt := range tests {
+		block := makeBlock(tt.l1Block, tt.l1, tt.l2)
+		if _, err := chain.InsertChain(types.Blocks{block}); !errors.Is(err, tt.want) {
+			t.Fatalf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+		if tt.want == nil && tt.l2 {
+			nonce++
+		}
+	}
+	// The skipped message must be recorded with a failed, gasless receipt
+	receipts := chain.GetReceiptsByHash(chain.GetBlockByNumber(1).Hash())
+	if len(receipts) != 3 || !IsSkippedL1Message(receipts[1]) || IsSkippedL1Message(receipts[0]) {
+		t.Fatalf("skipped L1 message receipt mismatch")
+	}
+	if next := chain.NextL1MessageIndex(chain.CurrentBlock().Hash()); next != 4 {
+		t.Fatalf("next queue index mismatch: have %d, want 4", next)
+	}
+}
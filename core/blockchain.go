@@ -54,6 +54,9 @@ var (
 	headHeaderGauge    = metrics.NewRegisteredGauge("chain/head/header", nil)
 	headFastBlockGauge = metrics.NewRegisteredGauge("chain/head/receipt", nil)
 
+	headSafeBlockGauge      = metrics.NewRegisteredGauge("chain/head/safe", nil)
+	headFinalizedBlockGauge = metrics.NewRegisteredGauge("chain/head/finalized", nil)
+
 	accountReadTimer   = metrics.NewRegisteredTimer("chain/account/reads", nil)
 	accountHashTimer   = metrics.NewRegisteredTimer("chain/account/hashes", nil)
 	accountUpdateTimer = metrics.NewRegisteredTimer("chain/account/updates", nil)
@@ -83,6 +86,7 @@ var (
 
 	errInsertionInterrupted = errors.New("insertion is interrupted")
 	errChainStopped         = errors.New("blockchain is stopped")
+	errNonCanonicalMarker   = errors.New("rollup marker is not on the canonical chain")
 )
 
 const (
@@ -178,22 +182,26 @@ type BlockChain struct {
 	//  * nil: disable tx reindexer/deleter, but still index new blocks
 	txLookupLimit uint64
 
-	hc            *HeaderChain
-	rmLogsFeed    event.Feed
-	chainFeed     event.Feed
-	chainSideFeed event.Feed
-	chainHeadFeed event.Feed
-	logsFeed      event.Feed
-	blockProcFeed event.Feed
-	scope         event.SubscriptionScope
-	genesisBlock  *types.Block
+	hc                *HeaderChain
+	rmLogsFeed        event.Feed
+	chainFeed         event.Feed
+	chainSideFeed     event.Feed
+	chainHeadFeed     event.Feed
+	safeHeadFeed      event.Feed
+	finalizedHeadFeed event.Feed
+	logsFeed          event.Feed
+	blockProcFeed     event.Feed
+	scope             event.SubscriptionScope
+	genesisBlock      *types.Block
 
 	// This mutex synchronizes chain write operations.
 	// Readers don't need to take it, they can just read the database.
 	chainmu *syncx.ClosableMutex
 
-	currentBlock     atomic.Value // Current head of the block chain
-	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
+	currentBlock          atomic.Value // Current head of the block chain
+	currentFastBlock      atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
+	currentSafeBlock      atomic.Value // Latest block whose batch is committed to L1 (nil if unknown)
+	currentFinalizedBlock atomic.Value // Latest block whose batch is finalized on L1 (nil if unknown)
 
 	stateCache    state.Database // State database to reuse between imports (contains state cache)
 	bodyCache     *lru.Cache     // Cache for the most recent block bodies
@@ -280,6 +288,8 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	var nilBlock *types.Block
 	bc.currentBlock.Store(nilBlock)
 	bc.currentFastBlock.Store(nilBlock)
+	bc.currentSafeBlock.Store(nilBlock)
+	bc.currentFinalizedBlock.Store(nilBlock)
 
 	// Initialize the chain with ancient data if it isn't empty.
 	var txIndexBlock uint64
@@ -476,6 +486,20 @@ func (bc *BlockChain) loadLastState() error {
 			headFastBlockGauge.Update(int64(block.NumberU64()))
 		}
 	}
+	// Restore the rollup markers, they are optional and only known once the
+	// rollup status source reported them
+	if head := rawdb.ReadHeadSafeBlockHash(bc.db); head != (common.Hash{}) {
+		if block := bc.GetBlockByHash(head); block != nil {
+			bc.currentSafeBlock.Store(block)
+			headSafeBlockGauge.Update(int64(block.NumberU64()))
+		}
+	}
+	if head := rawdb.ReadHeadFinalizedBlockHash(bc.db); head != (common.Hash{}) {
+		if block := bc.GetBlockByHash(head); block != nil {
+			bc.currentFinalizedBlock.Store(block)
+			headFinalizedBlockGauge.Update(int64(block.NumberU64()))
+		}
+	}
 	// Issue a status log for the user
 	currentFastBlock := bc.CurrentFastBlock()
 
@@ -489,6 +513,12 @@ func (bc *BlockChain) loadLastState() error {
 	if pivot := rawdb.ReadLastPivotNumber(bc.db); pivot != nil {
 		log.Info("Loaded last fast-sync pivot marker", "number", *pivot)
 	}
+	if block := bc.CurrentSafeBlock(); block != nil {
+		log.Info("Loaded most recent safe block", "number", block.Number(), "hash", block.Hash())
+	}
+	if block := bc.CurrentFinalizedBlock(); block != nil {
+		log.Info("Loaded most recent finalized block", "number", block.Number(), "hash", block.Hash())
+	}
 	return nil
 }
 
@@ -590,7 +620,21 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 			bc.currentFastBlock.Store(newHeadFastBlock)
 			headFastBlockGauge.Update(int64(newHeadFastBlock.NumberU64()))
 		}
-		head := bc.CurrentBlock().NumberU64()
+		// Rewind the rollup markers if they point beyond the new head. They will
+		// be moved forward again by the rollup status source once re-committed.
+		headBlock := bc.CurrentBlock()
+		if safe := bc.CurrentSafeBlock(); safe != nil && safe.NumberU64() > headBlock.NumberU64() {
+			rawdb.WriteHeadSafeBlockHash(db, headBlock.Hash())
+			bc.currentSafeBlock.Store(headBlock)
+			headSafeBlockGauge.Update(int64(headBlock.NumberU64()))
+		}
+		if finalized := bc.CurrentFinalizedBlock(); finalized != nil && finalized.NumberU64() > headBlock.NumberU64() {
+			log.Warn("Rewinding below finalized block", "finalized", finalized.NumberU64(), "head", headBlock.NumberU64())
+			rawdb.WriteHeadFinalizedBlockHash(db, headBlock.Hash())
+			bc.currentFinalizedBlock.Store(headBlock)
+			headFinalizedBlockGauge.Update(int64(headBlock.NumberU64()))
+		}
+		head := headBlock.NumberU64()
 
 		// If setHead underflown the freezer threshold and the block processing
 		// intent afterwards is full block importing, delete the chain segment
@@ -780,6 +824,64 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	headBlockGauge.Update(int64(block.NumberU64()))
 }
 
+// SetSafe marks the given canonical block as the latest block whose batch has
+// been committed to L1. The safe marker is not allowed to drop below the
+// finalized marker, but may otherwise move backwards (e.g. on an L1 reorg
+// reverting a commitment).
+func (bc *BlockChain) SetSafe(block *types.Block) error {
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	if rawdb.ReadCanonicalHash(bc.db, block.NumberU64()) != block.Hash() {
+		return errNonCanonicalMarker
+	}
+	if finalized := bc.CurrentFinalizedBlock(); finalized != nil && block.NumberU64() < finalized.NumberU64() {
+		return fmt.Errorf("safe block #%d below finalized block #%d", block.NumberU64(), finalized.NumberU64())
+	}
+	bc.writeSafeBlock(block)
+	return nil
+}
+
+// SetFinalized marks the given canonical block as the latest block whose batch
+// has been finalized on L1 by a verified proof. Finality is irreversible, so the
+// marker may only move forward. A finalized block is implicitly safe, the safe
+// marker is advanced along if it lags behind.
+func (bc *BlockChain) SetFinalized(block *types.Block) error {
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	if rawdb.ReadCanonicalHash(bc.db, block.NumberU64()) != block.Hash() {
+		return errNonCanonicalMarker
+	}
+	if finalized := bc.CurrentFinalizedBlock(); finalized != nil && block.NumberU64() < finalized.NumberU64() {
+		return fmt.Errorf("finalized block #%d below current finalized block #%d", block.NumberU64(), finalized.NumberU64())
+	}
+	if safe := bc.CurrentSafeBlock(); safe == nil || safe.NumberU64() < block.NumberU64() {
+		bc.writeSafeBlock(block)
+	}
+	rawdb.WriteHeadFinalizedBlockHash(bc.db, block.Hash())
+	bc.currentFinalizedBlock.Store(block)
+	headFinalizedBlockGauge.Update(int64(block.NumberU64()))
+
+	bc.finalizedHeadFeed.Send(FinalizedHeadEvent{Block: block})
+	return nil
+}
+
+// writeSafeBlock persists and announces a new safe block.
+//
+// Note, this function assumes that the `mu` mutex is held!
+func (bc *BlockChain) writeSafeBlock(block *types.Block) {
+	rawdb.WriteHeadSafeBlockHash(bc.db, block.Hash())
+	bc.currentSafeBlock.Store(block)
+	headSafeBlockGauge.Update(int64(block.NumberU64()))
+
+	bc.safeHeadFeed.Send(SafeHeadEvent{Block: block})
+}
+
 // Stop stops the blockchain service. If any imports are currently in progress
 // it will abort them using the procInterrupt.
 func (bc *BlockChain) Stop() {
@@ -1230,6 +1332,15 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	if bc.chainConfig.Scroll.L1MessagesEnabled() {
+		queueIndex := bc.NextL1MessageIndex(block.ParentHash())
+		for _, tx := range block.Transactions() {
+			if tx.IsL1MessageTx() {
+				queueIndex = tx.Nonce() + 1
+			}
+		}
+		rawdb.WriteFirstQueueIndexNotInL2Block(blockBatch, block.Hash(), queueIndex)
+	}
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -49,6 +49,18 @@ func (bc *BlockChain) CurrentFastBlock() *types.Block {
 	return bc.currentFastBlock.Load().(*types.Block)
 }
 
+// CurrentSafeBlock retrieves the latest block whose batch has been committed to
+// L1, or nil if no rollup status has been reported yet.
+func (bc *BlockChain) CurrentSafeBlock() *types.Block {
+	return bc.currentSafeBlock.Load().(*types.Block)
+}
+
+// CurrentFinalizedBlock retrieves the latest block whose batch has been finalized
+// on L1, or nil if no rollup status has been reported yet.
+func (bc *BlockChain) CurrentFinalizedBlock() *types.Block {
+	return bc.currentFinalizedBlock.Load().(*types.Block)
+}
+
 // HasHeader checks if a block header is present in the database or not, caching
 // it if present.
 func (bc *BlockChain) HasHeader(hash common.Hash, number uint64) bool {
@@ -302,6 +314,64 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.stateCache, bc.snaps)
 }
 
+// NextL1MessageIndex returns the queue index of the first L1 message not
+// consumed by the chain up to and including the given block.
+func (bc *BlockChain) NextL1MessageIndex(hash common.Hash) uint64 {
+	// Blocks predating the L1 message queue have consumed no messages
+	if index := rawdb.ReadFirstQueueIndexNotInL2Block(bc.db, hash); index != nil {
+		return *index
+	}
+	return 0
+}
+
+// L1Message retrieves a synced L1 message by its queue index, along with the
+// number of the L1 block it was enqueued in. Nil is returned if the message
+// hasn't been synced.
+func (bc *BlockChain) L1Message(queueIndex uint64) (*types.L1MessageTx, uint64) {
+	return rawdb.ReadL1Message(bc.db, queueIndex)
+}
+
+// L1MessageLag returns the number of L1 blocks the message with the given queue
+// index has been enqueued for, according to the locally synced L1 head. False
+// is returned if the message or the L1 head is unknown.
+func (bc *BlockChain) L1MessageLag(queueIndex uint64) (uint64, bool) {
+	msg, enqueued := rawdb.ReadL1Message(bc.db, queueIndex)
+	if msg == nil {
+		return 0, false
+	}
+	head := rawdb.ReadSyncedL1BlockNumber(bc.db)
+	if head == nil {
+		return 0, false
+	}
+	if *head < enqueued {
+		return 0, true
+	}
+	return *head - enqueued, true
+}
+
+// SyncedL1BlockNumber returns the number of the last L1 block whose enqueued
+// messages have been synced, or false if nothing has been synced yet.
+func (bc *BlockChain) SyncedL1BlockNumber() (uint64, bool) {
+	if number := rawdb.ReadSyncedL1BlockNumber(bc.db); number != nil {
+		return *number, true
+	}
+	return 0, false
+}
+
+// L1MessageOverdue reports whether the message with the given queue index has
+// been enqueued for longer than the forced inclusion deadline as of the given
+// L1 block, in which case it must be included before any further L2 transaction.
+// Blocks carry the L1 block number they are checked against, so all nodes come
+// to the same conclusion.
+func (bc *BlockChain) L1MessageOverdue(queueIndex uint64, l1BlockNumber uint64) bool {
+	config := bc.chainConfig.Scroll.L1Config
+	if config == nil || config.ForcedInclusionDeadline == 0 {
+		return false
+	}
+	msg, enqueued := rawdb.ReadL1Message(bc.db, queueIndex)
+	return msg != nil && l1BlockNumber >= enqueued && l1BlockNumber-enqueued >= config.ForcedInclusionDeadline
+}
+
 // Config retrieves the chain's fork configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 
@@ -373,6 +443,16 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeSafeHeadEvent registers a subscription of SafeHeadEvent.
+func (bc *BlockChain) SubscribeSafeHeadEvent(ch chan<- SafeHeadEvent) event.Subscription {
+	return bc.scope.Track(bc.safeHeadFeed.Subscribe(ch))
+}
+
+// SubscribeFinalizedHeadEvent registers a subscription of FinalizedHeadEvent.
+func (bc *BlockChain) SubscribeFinalizedHeadEvent(ch chan<- FinalizedHeadEvent) event.Subscription {
+	return bc.scope.Track(bc.finalizedHeadFeed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Subscription {
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
@@ -3238,3 +3238,64 @@ func TestTransactionCountLimit(t *testing.T) {
 		t.Fatalf("error mismatch: have: %v, want: %v", err, consensus.ErrInvalidTxCount)
 	}
 }
+
+// Tests that the rollup markers are persisted, survive a restart and are
+// rewound together with the chain head.
+func TestRollupMarkers(t *testing.T) {
+	db, chain, err := newCanonical(ethash.NewFaker(), 10, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if chain.CurrentSafeBlock() != nil || chain.CurrentFinalizedBlock() != nil {
+		t.Fatalf("rollup markers set on pristine chain")
+	}
+	safeCh := make(chan SafeHeadEvent, 2)
+	safeSub := chain.SubscribeSafeHeadEvent(safeCh)
+	defer safeSub.Unsubscribe()
+
+	if err := chain.SetSafe(chain.GetBlockByNumber(8)); err != nil {
+		t.Fatalf("failed to set safe block: %v", err)
+	}
+	// Finalizing beyond the safe block should drag the safe marker along
+	if err := chain.SetFinalized(chain.GetBlockByNumber(9)); err != nil {
+		t.Fatalf("failed to set finalized block: %v", err)
+	}
+	if have := chain.CurrentSafeBlock().NumberU64(); have != 9 {
+		t.Fatalf("safe block mismatch: have %d, want %d", have, 9)
+	}
+	for _, want := range []uint64{8, 9} {
+		if ev := <-safeCh; ev.Block.NumberU64() != want {
+			t.Fatalf("safe event mismatch: have %d, want %d", ev.Block.NumberU64(), want)
+		}
+	}
+	// Finality must not be reverted and safe must not fall below it
+	if err := chain.SetFinalized(chain.GetBlockByNumber(5)); err == nil {
+		t.Fatalf("reverted finalized block")
+	}
+	if err := chain.SetSafe(chain.GetBlockByNumber(5)); err == nil {
+		t.Fatalf("safe block set below finalized block")
+	}
+	// Rewinding the chain should also rewind the markers
+	if err := chain.SetHead(7); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if have := chain.CurrentSafeBlock().NumberU64(); have != 7 {
+		t.Fatalf("safe block mismatch after rewind: have %d, want %d", have, 7)
+	}
+	if have := chain.CurrentFinalizedBlock().NumberU64(); have != 7 {
+		t.Fatalf("finalized block mismatch after rewind: have %d, want %d", have, 7)
+	}
+	// Reopen the chain and ensure the markers were persisted
+	chain.Stop()
+	chain, _ = NewBlockChain(db, nil, params.AllEthashProtocolChanges, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if block := chain.CurrentSafeBlock(); block == nil || block.NumberU64() != 7 {
+		t.Fatalf("safe block not restored: %v", block)
+	}
+	if block := chain.CurrentFinalizedBlock(); block == nil || block.NumberU64() != 7 {
+		t.Fatalf("finalized block not restored: %v", block)
+	}
+}
@@ -416,6 +416,35 @@ func (c *ChainIndexer) processSection(section uint64, lastHead common.Hash) (com
 	return lastHead, nil
 }
 
+// ProcessSections synchronously indexes the canonical chain stored in the
+// database up to the given number of sections. It is meant for offline tools,
+// the indexer must not be started.
+func (c *ChainIndexer) ProcessSections(sections uint64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.verifyLastHead()
+	for updated := time.Now(); c.storedSections < sections; {
+		section := c.storedSections
+		var oldHead common.Hash
+		if section > 0 {
+			oldHead = c.SectionHead(section - 1)
+		}
+		newHead, err := c.processSection(section, oldHead)
+		if err != nil {
+			return fmt.Errorf("section %d: %v", section, err)
+		}
+		c.setSectionHead(section, newHead)
+		c.setValidSections(section + 1)
+
+		if time.Since(updated) > 8*time.Second {
+			c.log.Info("Indexing chain sections", "section", section, "total", sections)
+			updated = time.Now()
+		}
+	}
+	return nil
+}
+
 // verifyLastHead compares last stored section head with the corresponding block hash in the
 // actual canonical chain and rolls back reorged sections if necessary to ensure that stored
 // sections are all valid
@@ -66,6 +66,12 @@ func (b *BlockGen) SetExtra(data []byte) {
 	b.header.Extra = data
 }
 
+// SetL1BlockNumber sets the L1 block number the generated block commits to. It
+// defaults to the one of the parent on chains with forced inclusion of L1 messages.
+func (b *BlockGen) SetL1BlockNumber(number uint64) {
+	b.header.L1BlockNumber = &number
+}
+
 // SetNonce sets the nonce field of the generated block.
 func (b *BlockGen) SetNonce(nonce types.BlockNonce) {
 	b.header.Nonce = nonce
@@ -282,6 +288,13 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 			header.GasLimit = CalcGasLimit(parentGasLimit, parentGasLimit)
 		}
 	}
+	if chain.Config().IsForcedInclusion(header.Number) {
+		number := uint64(0)
+		if parent.Header().L1BlockNumber != nil {
+			number = *parent.Header().L1BlockNumber
+		}
+		header.L1BlockNumber = &number
+	}
 	return header
 }
 
@@ -35,6 +35,35 @@ var (
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
+// List of L1 message validation errors, returned if a block doesn't consume the
+// L1 message queue according to the sequencing rules.
+var (
+	// ErrL1MessagesDisabled is returned if a block contains L1 messages on a
+	// chain without an L1 message queue.
+	ErrL1MessagesDisabled = errors.New("L1 messages are disabled")
+
+	// ErrInvalidL1MessageOrder is returned if the L1 messages of a block don't
+	// precede its L2 transactions or don't consume the queue in order.
+	ErrInvalidL1MessageOrder = errors.New("invalid L1 message order")
+
+	// ErrUnknownL1Message is returned if an L1 message of a block differs from
+	// the message synced from L1 with the same queue index.
+	ErrUnknownL1Message = errors.New("unknown L1 message")
+
+	// ErrTooManyL1Messages is returned if a block consumes more L1 messages than
+	// allowed per block.
+	ErrTooManyL1Messages = errors.New("too many L1 messages")
+
+	// ErrInvalidL1BlockNumber is returned if a block doesn't carry the L1 block
+	// number the forced inclusion deadline is checked against, or if it's below
+	// the one of its parent.
+	ErrInvalidL1BlockNumber = errors.New("invalid L1 block number")
+
+	// ErrL1MessageOverdue is returned if a block contains L2 transactions while
+	// an L1 message past its forced inclusion deadline is still pending.
+	ErrL1MessageOverdue = errors.New("overdue L1 message not included")
+)
+
 // List of evm-call-message pre-checking errors. All state transition messages will
 // be pre-checked before execution. If any invalidation detected, the corresponding
 // error should be returned which is defined here.
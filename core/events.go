@@ -41,3 +41,9 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// SafeHeadEvent is posted when the latest block committed to L1 advances.
+type SafeHeadEvent struct{ Block *types.Block }
+
+// FinalizedHeadEvent is posted when the latest block finalized on L1 advances.
+type FinalizedHeadEvent struct{ Block *types.Block }
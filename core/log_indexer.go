@@ -0,0 +1,167 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+const (
+	// logIndexThrottling is the time to wait between processing two consecutive
+	// log index sections.
+	logIndexThrottling = 100 * time.Millisecond
+)
+
+// LogIndexer implements a core.ChainIndexerBackend, building up an index of the
+// blocks containing logs of each address and topic for every section of the
+// canonical chain. Unlike the bloom bits, the index is exact, which keeps lookups
+// fast even when most blooms are saturated.
+type LogIndexer struct {
+	db      ethdb.Database      // database instance to read logs from and write index data into
+	config  *params.ChainConfig // chain config to decode legacy receipts with
+	size    uint64              // section size to generate the log index for
+	section uint64              // Section is the section number being processed currently
+	head    common.Hash         // Head is the hash of the last header processed
+	terms   map[string][]uint64 // Blocks of the current section containing each term
+}
+
+// NewLogIndexer returns a chain indexer that generates the log index for the
+// canonical chain.
+func NewLogIndexer(db ethdb.Database, config *params.ChainConfig, size, confirms uint64) *ChainIndexer {
+	backend := &LogIndexer{
+		db:     db,
+		config: config,
+		size:   size,
+	}
+	table := rawdb.NewTable(db, string(rawdb.LogIndexPrefix))
+
+	return NewChainIndexer(db, table, backend, size, confirms, logIndexThrottling, "logindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new log index section.
+func (l *LogIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	l.section, l.head, l.terms = section, common.Hash{}, make(map[string][]uint64)
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the addresses and topics
+// of a block's logs into the index.
+func (l *LogIndexer) Process(ctx context.Context, header *types.Header) error {
+	hash, number := header.Hash(), header.Number.Uint64()
+	if header.Bloom != (types.Bloom{}) {
+		logs := rawdb.ReadLogs(l.db, hash, number, l.config)
+		if logs == nil {
+			return fmt.Errorf("logs of block #%d [%x..] not found", number, hash[:4])
+		}
+		offset := number - l.section*l.size
+		for _, txLogs := range logs {
+			for _, log := range txLogs {
+				l.add(rawdb.LogIndexAddressTerm(log.Address), offset)
+				for i, topic := range log.Topics {
+					l.add(rawdb.LogIndexTopicTerm(i, topic), offset)
+				}
+			}
+		}
+	}
+	l.head = hash
+	return nil
+}
+
+// add records a block containing a term, once per block.
+func (l *LogIndexer) add(term []byte, offset uint64) {
+	blocks := l.terms[string(term)]
+	if n := len(blocks); n > 0 && blocks[n-1] == offset {
+		return
+	}
+	l.terms[string(term)] = append(blocks, offset)
+}
+
+// Commit implements core.ChainIndexerBackend, writing the section's log index
+// out into the database. The section is marked as complete last, so lookups
+// never see part of it.
+func (l *LogIndexer) Commit() error {
+	batch := l.db.NewBatch()
+	for term, blocks := range l.terms {
+		rawdb.WriteLogIndex(batch, l.section, l.head, []byte(term), blocks)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	rawdb.WriteLogIndexSection(batch, l.section, l.head)
+	return batch.Write()
+}
+
+// Prune returns an empty error since we don't support pruning here.
+func (l *LogIndexer) Prune(threshold uint64) error {
+	return nil
+}
+
+// VerifyLogIndex regenerates a section of the log index from the chain and checks
+// it against the stored one.
+func VerifyLogIndex(db ethdb.Database, config *params.ChainConfig, size, section uint64) error {
+	head := rawdb.ReadCanonicalHash(db, (section+1)*size-1)
+	if head == (common.Hash{}) {
+		return fmt.Errorf("section %d head not found", section)
+	}
+	if !rawdb.HasLogIndexSection(db, section, head) {
+		return fmt.Errorf("section %d not indexed for head %x", section, head)
+	}
+	stored, err := rawdb.ReadLogIndexSection(db, section, head)
+	if err != nil {
+		return fmt.Errorf("section %d: %v", section, err)
+	}
+	indexer := &LogIndexer{db: db, config: config, size: size}
+	indexer.Reset(context.Background(), section, common.Hash{})
+	for number := section * size; number < (section+1)*size; number++ {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number)
+		if header == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		if err := indexer.Process(context.Background(), header); err != nil {
+			return err
+		}
+	}
+	if len(stored) != len(indexer.terms) {
+		return fmt.Errorf("section %d: term count mismatch: have %d, want %d", section, len(stored), len(indexer.terms))
+	}
+	for term, want := range indexer.terms {
+		have, ok := stored[term]
+		if !ok {
+			return fmt.Errorf("section %d: term %x missing", section, term)
+		}
+		if len(have) != len(want) {
+			return fmt.Errorf("section %d: term %x block count mismatch: have %d, want %d", section, term, len(have), len(want))
+		}
+		for i := range want {
+			if have[i] != want[i] {
+				return fmt.Errorf("section %d: term %x block mismatch: have %d, want %d", section, term, section*size+have[i], section*size+want[i])
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestLogIndexer(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		addrA   = common.Address{0x0a}
+		addrB   = common.Address{0x0b}
+		topic1  = common.Hash{0x01}
+		topic2  = common.Hash{0x02}
+		genesis = GenesisBlockForTesting(db, addrA, big.NewInt(1000000))
+	)
+	emits := map[int]*types.Log{
+		2:  {Address: addrA, Topics: []common.Hash{topic1}},
+		14: {Address: addrB, Topics: []common.Hash{topic1, topic2}},
+		16: {Address: addrA, Topics: []common.Hash{topic2}},
+	}
+	blocks, receipts := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 29, func(i int, gen *BlockGen) {
+		if log, ok := emits[i]; ok {
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = []*types.Log{log}
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 1, gen.BaseFee(), nil))
+		}
+	})
+	rawdb.WriteCanonicalHash(db, genesis.Hash(), 0)
+	for i, block := range blocks {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	indexer := NewLogIndexer(db, params.TestChainConfig, 10, 0)
+	defer indexer.Close()
+
+	if err := indexer.ProcessSections(3); err != nil {
+		t.Fatalf("failed to index sections: %v", err)
+	}
+	if sections, _, _ := indexer.Sections(); sections != 3 {
+		t.Fatalf("section count mismatch: have %d, want %d", sections, 3)
+	}
+	// Blocks are one ahead of the generator indices, as the genesis is block 0
+	tests := []struct {
+		section uint64
+		term    []byte
+		blocks  []uint64
+	}{
+		{0, rawdb.LogIndexAddressTerm(addrA), []uint64{3}},
+		{0, rawdb.LogIndexTopicTerm(0, topic1), []uint64{3}},
+		{0, rawdb.LogIndexAddressTerm(addrB), nil},
+		{1, rawdb.LogIndexAddressTerm(addrA), []uint64{7}},
+		{1, rawdb.LogIndexAddressTerm(addrB), []uint64{5}},
+		{1, rawdb.LogIndexTopicTerm(0, topic2), []uint64{7}},
+		{1, rawdb.LogIndexTopicTerm(1, topic2), []uint64{5}},
+		{2, rawdb.LogIndexAddressTerm(addrA), nil},
+	}
+	for i, test := range tests {
+		head := indexer.SectionHead(test.section)
+		blocks, err := rawdb.ReadLogIndex(db, test.section, head, test.term)
+		if err != nil {
+			t.Fatalf("test %d: failed to read log index: %v", i, err)
+		}
+		if !reflect.DeepEqual(blocks, test.blocks) {
+			t.Errorf("test %d: blocks mismatch: have %v, want %v", i, blocks, test.blocks)
+		}
+	}
+	for section := uint64(0); section < 3; section++ {
+		if err := VerifyLogIndex(db, params.TestChainConfig, 10, section); err != nil {
+			t.Fatalf("section %d: failed to verify: %v", section, err)
+		}
+	}
+	// Corrupt a posting list and ensure verification catches it
+	rawdb.WriteLogIndex(db, 1, indexer.SectionHead(1), rawdb.LogIndexAddressTerm(addrB), []uint64{6})
+	if err := VerifyLogIndex(db, params.TestChainConfig, 10, 1); err == nil {
+		t.Fatal("corrupted section verified")
+	}
+}
@@ -216,6 +216,38 @@ func WriteHeadFastBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
 	}
 }
 
+// ReadHeadSafeBlockHash retrieves the hash of the latest block committed to L1.
+func ReadHeadSafeBlockHash(db ethdb.KeyValueReader) common.Hash {
+	data, _ := db.Get(headSafeBlockKey)
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteHeadSafeBlockHash stores the hash of the latest block committed to L1.
+func WriteHeadSafeBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(headSafeBlockKey, hash.Bytes()); err != nil {
+		log.Crit("Failed to store last safe block's hash", "err", err)
+	}
+}
+
+// ReadHeadFinalizedBlockHash retrieves the hash of the latest block finalized on L1.
+func ReadHeadFinalizedBlockHash(db ethdb.KeyValueReader) common.Hash {
+	data, _ := db.Get(headFinalizedBlockKey)
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteHeadFinalizedBlockHash stores the hash of the latest block finalized on L1.
+func WriteHeadFinalizedBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(headFinalizedBlockKey, hash.Bytes()); err != nil {
+		log.Crit("Failed to store last finalized block's hash", "err", err)
+	}
+}
+
 // ReadLastPivotNumber retrieves the number of the last pivot block. If the node
 // full synced, the last pivot will always be nil.
 func ReadLastPivotNumber(db ethdb.KeyValueReader) *uint64 {
@@ -784,6 +816,11 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 	if err := op.Append(freezerDifficultyTable, num, td); err != nil {
 		return fmt.Errorf("can't append block %d total difficulty: %v", num, err)
 	}
+	for _, table := range userAncientTables() {
+		if err := op.AppendRaw(table.Name, num, nil); err != nil {
+			return fmt.Errorf("can't append block %d %s: %v", num, table.Name, err)
+		}
+	}
 	return nil
 }
 
@@ -313,6 +313,8 @@ func TestHeadStorage(t *testing.T) {
 	blockHead := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block header")})
 	blockFull := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block full")})
 	blockFast := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block fast")})
+	blockSafe := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block safe")})
+	blockFinal := types.NewBlockWithHeader(&types.Header{Extra: []byte("test block finalized")})
 
 	// Check that no head entries are in a pristine database
 	if entry := ReadHeadHeaderHash(db); entry != (common.Hash{}) {
@@ -324,10 +326,18 @@ func TestHeadStorage(t *testing.T) {
 	if entry := ReadHeadFastBlockHash(db); entry != (common.Hash{}) {
 		t.Fatalf("Non fast head block entry returned: %v", entry)
 	}
+	if entry := ReadHeadSafeBlockHash(db); entry != (common.Hash{}) {
+		t.Fatalf("Non safe head block entry returned: %v", entry)
+	}
+	if entry := ReadHeadFinalizedBlockHash(db); entry != (common.Hash{}) {
+		t.Fatalf("Non finalized head block entry returned: %v", entry)
+	}
 	// Assign separate entries for the head header and block
 	WriteHeadHeaderHash(db, blockHead.Hash())
 	WriteHeadBlockHash(db, blockFull.Hash())
 	WriteHeadFastBlockHash(db, blockFast.Hash())
+	WriteHeadSafeBlockHash(db, blockSafe.Hash())
+	WriteHeadFinalizedBlockHash(db, blockFinal.Hash())
 
 	// Check that both heads are present, and different (i.e. two heads maintained)
 	if entry := ReadHeadHeaderHash(db); entry != blockHead.Hash() {
@@ -339,6 +349,12 @@ func TestHeadStorage(t *testing.T) {
 	if entry := ReadHeadFastBlockHash(db); entry != blockFast.Hash() {
 		t.Fatalf("Fast head block hash mismatch: have %v, want %v", entry, blockFast.Hash())
 	}
+	if entry := ReadHeadSafeBlockHash(db); entry != blockSafe.Hash() {
+		t.Fatalf("Safe head block hash mismatch: have %v, want %v", entry, blockSafe.Hash())
+	}
+	if entry := ReadHeadFinalizedBlockHash(db); entry != blockFinal.Hash() {
+		t.Fatalf("Finalized head block hash mismatch: have %v, want %v", entry, blockFinal.Hash())
+	}
 }
 
 // Tests that receipts associated with a single block can be stored and retrieved.
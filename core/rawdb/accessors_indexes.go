@@ -18,6 +18,8 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"math/big"
 
 	"github.com/scroll-tech/go-ethereum/common"
@@ -175,3 +177,114 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+const (
+	// logIndexAddressTermLength is the length of a log index term of an address.
+	logIndexAddressTermLength = common.AddressLength
+
+	// logIndexTopicTermLength is the length of a log index term of a topic, made
+	// up of the topic position and the topic itself.
+	logIndexTopicTermLength = 1 + common.HashLength
+)
+
+// errInvalidLogIndex is returned if a stored log index posting list is corrupt.
+var errInvalidLogIndex = errors.New("invalid log index entry")
+
+// LogIndexAddressTerm returns the log index term of logs emitted by an address.
+func LogIndexAddressTerm(address common.Address) []byte {
+	return address.Bytes()
+}
+
+// LogIndexTopicTerm returns the log index term of logs with a topic at the given
+// position.
+func LogIndexTopicTerm(position int, topic common.Hash) []byte {
+	return append([]byte{byte(position)}, topic.Bytes()...)
+}
+
+// HasLogIndexSection checks if the log index section ending with the given head
+// was written out completely. A section indexed for another head, before a reorg
+// rolled it back, is missing.
+func HasLogIndexSection(db ethdb.KeyValueReader, section uint64, head common.Hash) bool {
+	ok, _ := db.Has(logIndexKey(section, head, nil))
+	return ok
+}
+
+// WriteLogIndexSection marks the log index section ending with the given head as
+// written out completely.
+func WriteLogIndexSection(db ethdb.KeyValueWriter, section uint64, head common.Hash) {
+	if err := db.Put(logIndexKey(section, head, nil), []byte{}); err != nil {
+		log.Crit("Failed to store log index section", "err", err)
+	}
+}
+
+// ReadLogIndex retrieves the blocks of a log index section containing logs that
+// match the given term, as ascending offsets from the start of the section. A
+// term without any matches in the section yields an empty list, so the section
+// must be checked with HasLogIndexSection first.
+func ReadLogIndex(db ethdb.KeyValueReader, section uint64, head common.Hash, term []byte) ([]uint64, error) {
+	data, _ := db.Get(logIndexKey(section, head, term))
+	return decodeLogIndex(data)
+}
+
+// WriteLogIndex stores the blocks of a log index section containing logs that
+// match the given term, as ascending offsets from the start of the section.
+func WriteLogIndex(db ethdb.KeyValueWriter, section uint64, head common.Hash, term []byte, blocks []uint64) {
+	if err := db.Put(logIndexKey(section, head, term), encodeLogIndex(blocks)); err != nil {
+		log.Crit("Failed to store log index", "err", err)
+	}
+}
+
+// ReadLogIndexSection retrieves all the terms of a log index section, along with
+// the blocks containing them.
+func ReadLogIndexSection(db ethdb.Iteratee, section uint64, head common.Hash) (map[string][]uint64, error) {
+	prefix := logIndexKey(section, head, nil)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	terms := make(map[string][]uint64)
+	for it.Next() {
+		term := it.Key()[len(prefix):]
+		if len(term) != logIndexAddressTermLength && len(term) != logIndexTopicTermLength {
+			continue
+		}
+		blocks, err := decodeLogIndex(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		terms[string(term)] = blocks
+	}
+	return terms, it.Error()
+}
+
+// encodeLogIndex encodes an ascending list of block offsets as varint deltas.
+func encodeLogIndex(blocks []uint64) []byte {
+	var (
+		enc  = make([]byte, 0, len(blocks)*2)
+		buf  [binary.MaxVarintLen64]byte
+		prev uint64
+	)
+	for _, block := range blocks {
+		n := binary.PutUvarint(buf[:], block-prev)
+		enc = append(enc, buf[:n]...)
+		prev = block
+	}
+	return enc
+}
+
+// decodeLogIndex decodes a list of block offsets encoded by encodeLogIndex.
+func decodeLogIndex(data []byte) ([]uint64, error) {
+	var (
+		blocks []uint64
+		prev   uint64
+	)
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errInvalidLogIndex
+		}
+		prev += delta
+		blocks = append(blocks, prev)
+		data = data[n:]
+	}
+	return blocks, nil
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"hash"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"golang.org/x/crypto/sha3"
@@ -178,3 +179,43 @@ func TestDeleteBloomBits(t *testing.T) {
 	check(1, 1, params.MainnetGenesisHash, true)
 	check(1, 1, params.RinkebyGenesisHash, true)
 }
+
+func TestLogIndexStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		head    = common.Hash{0x01}
+		address = LogIndexAddressTerm(common.Address{0x0a})
+		topic   = LogIndexTopicTerm(1, common.Hash{0x0b})
+		blocks  = []uint64{0, 1, 127, 128, 300, 70000}
+	)
+	WriteLogIndex(db, 1, head, address, blocks)
+	WriteLogIndex(db, 1, head, topic, blocks[:2])
+	WriteLogIndex(db, 2, head, topic, blocks[2:])
+
+	if have, err := ReadLogIndex(db, 1, head, address); err != nil || !reflect.DeepEqual(have, blocks) {
+		t.Fatalf("posting list mismatch: have %v (%v), want %v", have, err, blocks)
+	}
+	if have, err := ReadLogIndex(db, 1, common.Hash{0x02}, address); err != nil || len(have) != 0 {
+		t.Fatalf("unexpected posting list for other head: %v (%v)", have, err)
+	}
+	if HasLogIndexSection(db, 1, head) {
+		t.Fatal("section marked complete before being written out")
+	}
+	WriteLogIndexSection(db, 1, head)
+	if !HasLogIndexSection(db, 1, head) || HasLogIndexSection(db, 1, common.Hash{0x02}) {
+		t.Fatal("section marker mismatch")
+	}
+	terms, err := ReadLogIndexSection(db, 1, head)
+	if err != nil {
+		t.Fatalf("failed to read section: %v", err)
+	}
+	want := map[string][]uint64{string(address): blocks, string(topic): blocks[:2]}
+	if !reflect.DeepEqual(terms, want) {
+		t.Fatalf("section mismatch: have %v, want %v", terms, want)
+	}
+	db.Put(logIndexKey(3, head, address), []byte{0x80})
+	if _, err := ReadLogIndex(db, 3, head, address); err != errInvalidLogIndex {
+		t.Fatalf("error mismatch: have %v, want %v", err, errInvalidLogIndex)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadLastDerivedBatchIndex retrieves the index of the last L1 batch whose
+// blocks have been derived and imported by a verifier node.
+func ReadLastDerivedBatchIndex(db ethdb.KeyValueReader) *uint64 {
+	return readUint64(db, lastDerivedBatchKey, "derived batch index")
+}
+
+// WriteLastDerivedBatchIndex stores the index of the last derived L1 batch.
+func WriteLastDerivedBatchIndex(db ethdb.KeyValueWriter, index uint64) {
+	writeUint64(db, lastDerivedBatchKey, index, "derived batch index")
+}
+
+// l1MessageEntry is the database representation of an L1 message, along with
+// the number of the L1 block it was enqueued in.
+type l1MessageEntry struct {
+	Message       *types.L1MessageTx
+	L1BlockNumber uint64
+}
+
+// ReadL1Message retrieves the L1 message with the given queue index and the
+// number of the L1 block it was enqueued in. Nil is returned if the message
+// hasn't been synced.
+func ReadL1Message(db ethdb.KeyValueReader, queueIndex uint64) (*types.L1MessageTx, uint64) {
+	data, _ := db.Get(l1MessageKey(queueIndex))
+	if len(data) == 0 {
+		return nil, 0
+	}
+	var entry l1MessageEntry
+	if err := rlp.DecodeBytes(data, &entry); err != nil {
+		log.Error("Invalid L1 message RLP", "index", queueIndex, "err", err)
+		return nil, 0
+	}
+	return entry.Message, entry.L1BlockNumber
+}
+
+// WriteL1Message stores an L1 message enqueued in the given L1 block.
+func WriteL1Message(db ethdb.KeyValueWriter, msg *types.L1MessageTx, l1BlockNumber uint64) {
+	data, err := rlp.EncodeToBytes(&l1MessageEntry{Message: msg, L1BlockNumber: l1BlockNumber})
+	if err != nil {
+		log.Crit("Failed to RLP encode L1 message", "err", err)
+	}
+	if err := db.Put(l1MessageKey(msg.QueueIndex), data); err != nil {
+		log.Crit("Failed to store L1 message", "err", err)
+	}
+}
+
+// ReadLastL1MessageQueueIndex retrieves the queue index of the last synced
+// L1 message.
+func ReadLastL1MessageQueueIndex(db ethdb.KeyValueReader) *uint64 {
+	return readUint64(db, lastL1MessageKey, "L1 message queue index")
+}
+
+// WriteLastL1MessageQueueIndex stores the queue index of the last synced L1
+// message.
+func WriteLastL1MessageQueueIndex(db ethdb.KeyValueWriter, queueIndex uint64) {
+	writeUint64(db, lastL1MessageKey, queueIndex, "L1 message queue index")
+}
+
+// ReadSyncedL1BlockNumber retrieves the number of the last L1 block whose
+// enqueued messages have been synced.
+func ReadSyncedL1BlockNumber(db ethdb.KeyValueReader) *uint64 {
+	return readUint64(db, syncedL1BlockKey, "synced L1 block number")
+}
+
+// WriteSyncedL1BlockNumber stores the number of the last synced L1 block.
+func WriteSyncedL1BlockNumber(db ethdb.KeyValueWriter, number uint64) {
+	writeUint64(db, syncedL1BlockKey, number, "synced L1 block number")
+}
+
+// ReadFirstQueueIndexNotInL2Block retrieves the queue index of the first L1
+// message not consumed by the chain up to and including the given block.
+func ReadFirstQueueIndexNotInL2Block(db ethdb.KeyValueReader, hash common.Hash) *uint64 {
+	return readUint64(db, l1MessageQueueIndexKey(hash), "L1 message queue index")
+}
+
+// WriteFirstQueueIndexNotInL2Block stores the queue index of the first L1
+// message not consumed by the chain up to and including the given block.
+func WriteFirstQueueIndexNotInL2Block(db ethdb.KeyValueWriter, hash common.Hash, queueIndex uint64) {
+	writeUint64(db, l1MessageQueueIndexKey(hash), queueIndex, "L1 message queue index")
+}
+
+// readUint64 retrieves an RLP encoded integer stored under the given key.
+func readUint64(db ethdb.KeyValueReader, key []byte, name string) *uint64 {
+	data, _ := db.Get(key)
+	if len(data) == 0 {
+		return nil
+	}
+	var value uint64
+	if err := rlp.DecodeBytes(data, &value); err != nil {
+		log.Error("Invalid "+name+" in database", "err", err)
+		return nil
+	}
+	return &value
+}
+
+// writeUint64 stores an integer RLP encoded under the given key.
+func writeUint64(db ethdb.KeyValueWriter, key []byte, value uint64, name string) {
+	enc, err := rlp.EncodeToBytes(value)
+	if err != nil {
+		log.Crit("Failed to encode "+name, "err", err)
+	}
+	if err := db.Put(key, enc); err != nil {
+		log.Crit("Failed to store "+name, "err", err)
+	}
+}
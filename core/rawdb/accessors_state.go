@@ -17,7 +17,10 @@
 package rawdb
 
 import (
+	"time"
+
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/codehash"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
 )
@@ -61,15 +64,131 @@ func ReadCodeWithPrefix(db ethdb.KeyValueReader, hash common.Hash) []byte {
 	return data
 }
 
-// WriteCode writes the provided contract code database.
+// WriteCode writes the provided contract code database, indexing it by its
+// poseidon code hash.
 func WriteCode(db ethdb.KeyValueWriter, hash common.Hash, code []byte) {
 	if err := db.Put(codeKey(hash), code); err != nil {
 		log.Crit("Failed to store contract code", "err", err)
 	}
+	WritePoseidonCodeHash(db, codehash.PoseidonCodeHash(code), hash)
+}
+
+// ReadKeccakCodeHash retrieves the keccak code hash of the code with the
+// provided poseidon code hash, or the zero hash if the code isn't indexed.
+func ReadKeccakCodeHash(db ethdb.KeyValueReader, poseidonHash common.Hash) common.Hash {
+	data, _ := db.Get(poseidonCodeHashKey(poseidonHash))
+	return common.BytesToHash(data)
+}
+
+// WritePoseidonCodeHash maps the poseidon code hash of a code to its keccak
+// code hash.
+func WritePoseidonCodeHash(db ethdb.KeyValueWriter, poseidonHash, keccakHash common.Hash) {
+	if err := db.Put(poseidonCodeHashKey(poseidonHash), keccakHash.Bytes()); err != nil {
+		log.Crit("Failed to store poseidon code hash", "err", err)
+	}
+}
+
+// ReadCodeByPoseidonHash retrieves the contract code of the provided poseidon
+// code hash.
+func ReadCodeByPoseidonHash(db ethdb.KeyValueReader, poseidonHash common.Hash) []byte {
+	keccakHash := ReadKeccakCodeHash(db, poseidonHash)
+	if keccakHash == (common.Hash{}) {
+		return nil
+	}
+	return ReadCode(db, keccakHash)
+}
+
+// readPoseidonCodeIndexProgress retrieves the keccak code hash of the last code
+// indexed by IndexPoseidonCodeHashes, and whether all the codes are indexed.
+func readPoseidonCodeIndexProgress(db ethdb.KeyValueReader) (common.Hash, bool) {
+	data, _ := db.Get(poseidonCodeIndexKey)
+	if len(data) == common.HashLength {
+		return common.BytesToHash(data), false
+	}
+	return common.Hash{}, len(data) != 0
+}
+
+// writePoseidonCodeIndexProgress stores the keccak code hash of the last code
+// indexed by IndexPoseidonCodeHashes.
+func writePoseidonCodeIndexProgress(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(poseidonCodeIndexKey, hash.Bytes()); err != nil {
+		log.Crit("Failed to store poseidon code index progress", "err", err)
+	}
 }
 
-// DeleteCode deletes the specified contract code from the database.
-func DeleteCode(db ethdb.KeyValueWriter, hash common.Hash) {
+// IndexPoseidonCodeHashes indexes by poseidon code hash the codes written
+// before the index existed, only the codes stored with the current scheme
+// being indexed. The progress is stored with every batch, so an interrupted
+// indexing resumes from the last indexed code, and nothing is done once all
+// the codes are indexed.
+func IndexPoseidonCodeHashes(db ethdb.Database, interrupt chan struct{}) {
+	progress, done := readPoseidonCodeIndexProgress(db)
+	if done {
+		return
+	}
+	var (
+		it     = db.NewIterator(CodePrefix, progress.Bytes())
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+		count  int
+	)
+	defer it.Release()
+	if progress != (common.Hash{}) {
+		log.Info("Resuming poseidon code hash indexing", "progress", progress)
+	}
+	for it.Next() {
+		select {
+		case <-interrupt:
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to index poseidon code hashes", "err", err)
+			}
+			log.Debug("Poseidon code hash indexing interrupted", "codes", count, "progress", progress, "elapsed", common.PrettyDuration(time.Since(start)))
+			return
+		default:
+		}
+		ok, hash := IsCodeKey(it.Key())
+		if !ok {
+			continue
+		}
+		progress = common.BytesToHash(hash)
+		WritePoseidonCodeHash(batch, codehash.PoseidonCodeHash(it.Value()), progress)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			writePoseidonCodeIndexProgress(batch, progress)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to index poseidon code hashes", "err", err)
+			}
+			batch.Reset()
+		}
+		count++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing poseidon code hashes", "codes", count, "progress", progress, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		log.Crit("Failed to iterate contract codes", "err", err)
+	}
+	if err := batch.Put(poseidonCodeIndexKey, []byte{1}); err != nil {
+		log.Crit("Failed to store poseidon code index marker", "err", err)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to index poseidon code hashes", "err", err)
+	}
+	log.Info("Indexed poseidon code hashes", "codes", count, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// DeleteCode deletes the specified contract code from the database, along with
+// its poseidon code hash index entry.
+func DeleteCode(db ethdb.KeyValueStore, hash common.Hash) {
+	if code := ReadCodeWithPrefix(db, hash); len(code) != 0 {
+		poseidonHash := codehash.PoseidonCodeHash(code)
+		if ReadKeccakCodeHash(db, poseidonHash) == hash {
+			if err := db.Delete(poseidonCodeHashKey(poseidonHash)); err != nil {
+				log.Crit("Failed to delete poseidon code hash", "err", err)
+			}
+		}
+	}
 	if err := db.Delete(codeKey(hash)); err != nil {
 		log.Crit("Failed to delete contract code", "err", err)
 	}
@@ -0,0 +1,105 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/codehash"
+)
+
+func TestPoseidonCodeHashIndex(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	// code stored before the index existed
+	legacy := []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	if err := db.Put(codeKey(crypto.Keccak256Hash(legacy)), legacy); err != nil {
+		t.Fatal(err)
+	}
+	if code := ReadCodeByPoseidonHash(db, codehash.PoseidonCodeHash(legacy)); code != nil {
+		t.Fatalf("unindexed code found: %x", code)
+	}
+	IndexPoseidonCodeHashes(db, nil)
+	if code := ReadCodeByPoseidonHash(db, codehash.PoseidonCodeHash(legacy)); !bytes.Equal(code, legacy) {
+		t.Fatalf("wrong backfilled code: have %x, want %x", code, legacy)
+	}
+
+	// code stored with the index
+	code := []byte{0x00, 0x61, 0x73, 0x6d}
+	WriteCode(db, crypto.Keccak256Hash(code), code)
+	if have := ReadKeccakCodeHash(db, codehash.PoseidonCodeHash(code)); have != crypto.Keccak256Hash(code) {
+		t.Fatalf("wrong keccak code hash: have %x, want %x", have, crypto.Keccak256Hash(code))
+	}
+	if have := ReadKeccakCodeHash(db, common.Hash{1}); have != (common.Hash{}) {
+		t.Fatalf("unknown poseidon code hash resolved to %x", have)
+	}
+
+	// the index entry is deleted with the code
+	DeleteCode(db, crypto.Keccak256Hash(code))
+	if have := ReadKeccakCodeHash(db, codehash.PoseidonCodeHash(code)); have != (common.Hash{}) {
+		t.Fatalf("deleted code still indexed: %x", have)
+	}
+}
+
+func TestPoseidonCodeHashIndexResume(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	// codes stored before the index existed, in the order of their hashes
+	codes := [][]byte{{0x60, 0x01}, {0x60, 0x02}, {0x60, 0x03}}
+	sort.Slice(codes, func(i, j int) bool {
+		return bytes.Compare(crypto.Keccak256(codes[i]), crypto.Keccak256(codes[j])) < 0
+	})
+	for _, code := range codes {
+		if err := db.Put(codeKey(crypto.Keccak256Hash(code)), code); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexed := func(code []byte) bool {
+		return ReadCodeByPoseidonHash(db, codehash.PoseidonCodeHash(code)) != nil
+	}
+
+	// an interrupted indexing isn't complete
+	interrupt := make(chan struct{})
+	close(interrupt)
+	IndexPoseidonCodeHashes(db, interrupt)
+	if _, done := readPoseidonCodeIndexProgress(db); done {
+		t.Fatal("interrupted indexing marked as complete")
+	}
+
+	// the indexing resumes from the last indexed code
+	writePoseidonCodeIndexProgress(db, crypto.Keccak256Hash(codes[1]))
+	IndexPoseidonCodeHashes(db, nil)
+	if indexed(codes[0]) || !indexed(codes[1]) || !indexed(codes[2]) {
+		t.Fatalf("wrong codes indexed: %v %v %v", indexed(codes[0]), indexed(codes[1]), indexed(codes[2]))
+	}
+	if _, done := readPoseidonCodeIndexProgress(db); !done {
+		t.Fatal("indexing not marked as complete")
+	}
+
+	// nothing is done once complete
+	if err := db.Delete(poseidonCodeHashKey(codehash.PoseidonCodeHash(codes[2]))); err != nil {
+		t.Fatal(err)
+	}
+	IndexPoseidonCodeHashes(db, nil)
+	if indexed(codes[2]) {
+		t.Fatal("indexing repeated once complete")
+	}
+}
@@ -15,3 +15,99 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 package rawdb
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+)
+
+func TestOpenDetectsEngine(t *testing.T) {
+	for _, engine := range []string{dbLeveldb, dbPebble} {
+		if engine == dbPebble && !PebbleEnabled {
+			continue
+		}
+		dir := t.TempDir()
+		if have := PreexistingDatabase(dir); have != "" {
+			t.Fatalf("%s: found %q database in an empty directory", engine, have)
+		}
+		db, err := Open(OpenOptions{Type: engine, Directory: dir})
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		if err := db.Put([]byte("key"), []byte("value")); err != nil {
+			t.Fatalf("%s: failed to write: %v", engine, err)
+		}
+		db.Close()
+
+		if have := PreexistingDatabase(dir); have != engine {
+			t.Fatalf("detected engine mismatch: have %q, want %q", have, engine)
+		}
+		// the engine of the existing database is used by default
+		db, err = Open(OpenOptions{Directory: dir})
+		if err != nil {
+			t.Fatalf("%s: failed to reopen database: %v", engine, err)
+		}
+		if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+			t.Fatalf("%s: value mismatch: have %q, %v", engine, value, err)
+		}
+		db.Close()
+
+		other := dbPebble
+		if engine == dbPebble {
+			other = dbLeveldb
+		}
+		if _, err := Open(OpenOptions{Type: other, Directory: dir}); err == nil {
+			t.Fatalf("%s: opened as %s", engine, other)
+		}
+	}
+}
+
+// This test checks that a database can be reopened after the tail truncation
+// of its freezer dropped the genesis.
+func TestReopenAfterTailTruncation(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		db     = memorydb.New()
+		tables = AncientTables()
+	)
+	frdb, err := newFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatalf("failed to create freezer: %v", err)
+	}
+	_, err = frdb.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := 0; i < 100; i++ {
+			for table := range tables {
+				if err := op.AppendRaw(table, uint64(i), getChunk(256, i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to freeze items: %v", err)
+	}
+	WriteCanonicalHash(db, common.Hash{0x01}, 0)
+	WriteCanonicalHash(db, common.Hash{0x02}, 100)
+
+	if err := frdb.TruncateTail(50); err != nil {
+		t.Fatalf("failed to truncate tail: %v", err)
+	}
+	if ok, _ := frdb.HasAncient(freezerHashTable, 0); ok {
+		t.Fatalf("genesis retained after tail truncation")
+	}
+	frdb.Close()
+
+	frdb, err = newFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatalf("failed to reopen freezer: %v", err)
+	}
+	chaindb, err := newFreezerDatabase(db, frdb)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	chaindb.Close()
+}
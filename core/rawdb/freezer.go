@@ -132,8 +132,12 @@ func newFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		quit:         make(chan struct{}),
 	}
 
-	// Create the tables.
+	// Create the tables, tracking the ones that didn't exist yet.
+	var created []string
 	for name, disableSnappy := range tables {
+		if !freezerTableExists(datadir, name, disableSnappy) {
+			created = append(created, name)
+		}
 		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy)
 		if err != nil {
 			for _, table := range freezer.tables {
@@ -145,7 +149,15 @@ func newFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		freezer.tables[name] = table
 	}
 
-	// Truncate all tables to common length.
+	// Line up tables added to an existing freezer with the others, then truncate
+	// all tables to common length.
+	if err := freezer.initTables(created); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		lock.Release()
+		return nil, err
+	}
 	if err := freezer.repair(); err != nil {
 		for _, table := range freezer.tables {
 			table.Close()
@@ -220,6 +232,22 @@ func (f *freezer) Ancients() (uint64, error) {
 	return atomic.LoadUint64(&f.frozen), nil
 }
 
+// Tail returns the number of the first frozen item still stored. No table holds
+// items below it, though tables may start above it since they are pruned by data
+// file and tables added to an existing freezer start out empty.
+func (f *freezer) Tail() (uint64, error) {
+	tail := uint64(math.MaxUint64)
+	for _, table := range f.tables {
+		if t := table.tail(); t < tail {
+			tail = t
+		}
+	}
+	if tail == math.MaxUint64 {
+		return 0, nil
+	}
+	return tail, nil
+}
+
 // AncientSize returns the ancient size of the specified category.
 func (f *freezer) AncientSize(kind string) (uint64, error) {
 	// This needs the write lock to avoid data races on table fields.
@@ -295,6 +323,26 @@ func (f *freezer) TruncateAncients(items uint64) error {
 	return nil
 }
 
+// TruncateTail discards the frozen items below the provided number. Data is deleted
+// by file, so items sharing a data file with the new tail are retained.
+func (f *freezer) TruncateTail(tail uint64) error {
+	if f.readonly {
+		return errReadOnly
+	}
+	f.writeLock.Lock()
+	defer f.writeLock.Unlock()
+
+	if frozen := atomic.LoadUint64(&f.frozen); tail > frozen {
+		tail = frozen
+	}
+	for _, table := range f.tables {
+		if err := table.truncateTail(tail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Sync flushes all data tables to disk.
 func (f *freezer) Sync() error {
 	var errs []error
@@ -309,6 +357,34 @@ func (f *freezer) Sync() error {
 	return nil
 }
 
+// initTables moves the tail of tables created in an existing freezer to the end
+// of the others, so they hold the items frozen from now on.
+func (f *freezer) initTables(created []string) error {
+	if len(created) == 0 || len(created) == len(f.tables) {
+		return nil
+	}
+	isNew := make(map[string]bool)
+	for _, name := range created {
+		isNew[name] = true
+	}
+	min := uint64(math.MaxUint64)
+	for name, table := range f.tables {
+		if items := atomic.LoadUint64(&table.items); !isNew[name] && items < min {
+			min = items
+		}
+	}
+	if min == 0 {
+		return nil
+	}
+	for _, name := range created {
+		log.Info("Adding freezer table", "table", name, "tail", min)
+		if err := f.tables[name].initTail(min); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // repair truncates all data tables to the same length.
 func (f *freezer) repair() error {
 	min := uint64(math.MaxUint64)
@@ -327,6 +403,16 @@ func (f *freezer) repair() error {
 	return nil
 }
 
+// freezerTableExists reports whether the index file of a table exists.
+func freezerTableExists(path, name string, noCompression bool) bool {
+	idxName := fmt.Sprintf("%s.cidx", name)
+	if noCompression {
+		idxName = fmt.Sprintf("%s.ridx", name)
+	}
+	_, err := os.Stat(filepath.Join(path, idxName))
+	return err == nil
+}
+
 // freeze is a background thread that periodically checks the blockchain for any
 // import progress and moves ancient data from the fast database into the freezer.
 //
@@ -403,7 +489,8 @@ func (f *freezer) freeze(db ethdb.KeyValueStore) {
 		if limit-first > freezerBatchLimit {
 			limit = first + freezerBatchLimit
 		}
-		ancients, err := f.freezeRange(nfdb, first, limit)
+		userTables := f.userTables()
+		ancients, err := f.freezeRange(nfdb, userTables, first, limit)
 		if err != nil {
 			log.Error("Error in block freeze operation", "err", err)
 			backoff = true
@@ -422,6 +509,11 @@ func (f *freezer) freeze(db ethdb.KeyValueStore) {
 			if first+uint64(i) != 0 {
 				DeleteBlockWithoutNumber(batch, ancients[i], first+uint64(i))
 				DeleteCanonicalHash(batch, first+uint64(i))
+				for _, table := range userTables {
+					if table.Delete != nil {
+						table.Delete(batch, first+uint64(i), ancients[i])
+					}
+				}
 			}
 		}
 		if err := batch.Write(); err != nil {
@@ -496,7 +588,18 @@ func (f *freezer) freeze(db ethdb.KeyValueStore) {
 	}
 }
 
-func (f *freezer) freezeRange(nfdb *nofreezedb, number, limit uint64) (hashes []common.Hash, err error) {
+// userTables returns the registered user-defined tables held by the freezer.
+func (f *freezer) userTables() []AncientTable {
+	var tables []AncientTable
+	for _, table := range userAncientTables() {
+		if _, ok := f.tables[table.Name]; ok {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+func (f *freezer) freezeRange(nfdb *nofreezedb, userTables []AncientTable, number, limit uint64) (hashes []common.Hash, err error) {
 	hashes = make([]common.Hash, 0, limit-number)
 
 	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
@@ -539,6 +642,15 @@ func (f *freezer) freezeRange(nfdb *nofreezedb, number, limit uint64) (hashes []
 			if err := op.AppendRaw(freezerDifficultyTable, number, td); err != nil {
 				return fmt.Errorf("can't write td to freezer: %v", err)
 			}
+			for _, table := range userTables {
+				item, err := table.Freeze(nfdb, number, hash)
+				if err != nil {
+					return fmt.Errorf("can't read %s of block %d: %v", table.Name, number, err)
+				}
+				if err := op.AppendRaw(table.Name, number, item); err != nil {
+					return fmt.Errorf("can't write %s to freezer: %v", table.Name, err)
+				}
+			}
 
 			hashes = append(hashes, hash)
 		}
@@ -45,11 +45,17 @@ func newFreezerBatch(f *freezer) *freezerBatch {
 
 // Append adds an RLP-encoded item of the given kind.
 func (batch *freezerBatch) Append(kind string, num uint64, item interface{}) error {
+	if batch.tables[kind] == nil {
+		return errUnknownTable
+	}
 	return batch.tables[kind].Append(num, item)
 }
 
 // AppendRaw adds an item of the given kind.
 func (batch *freezerBatch) AppendRaw(kind string, num uint64, item []byte) error {
+	if batch.tables[kind] == nil {
+		return errUnknownTable
+	}
 	return batch.tables[kind].AppendRaw(num, item)
 }
 
@@ -22,8 +22,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"sync/atomic"
 
@@ -45,6 +47,10 @@ var (
 
 	// errNotSupported is returned if the database doesn't support the required operation.
 	errNotSupported = errors.New("this operation is not supported")
+
+	// errBelowTail is returned if the table is truncated to fewer items than were
+	// already deleted from its tail.
+	errBelowTail = errors.New("truncation below the tail")
 )
 
 // indexEntry contains the number/id of the file that the data resides in, aswell as the
@@ -262,8 +268,12 @@ func (t *freezerTable) repair() error {
 	}
 	contentSize = stat.Size()
 
-	// Keep truncating both files until they come in sync
+	// Keep truncating both files until they come in sync. A lone first entry
+	// carries the tail instead of a data offset, the head file is empty then.
 	contentExp = int64(lastIndex.offset)
+	if offsetsSize == indexEntrySize {
+		contentExp = 0
+	}
 
 	for contentExp != contentSize {
 		// Truncate the head file to the last offset pointer
@@ -300,6 +310,9 @@ func (t *freezerTable) repair() error {
 			}
 			lastIndex = newLastIndex
 			contentExp = int64(lastIndex.offset)
+			if offsetsSize == indexEntrySize {
+				contentExp = 0
+			}
 		}
 	}
 	// Ensure all reparation changes have been written to disk
@@ -350,6 +363,10 @@ func (t *freezerTable) truncate(items uint64) error {
 	if existing <= items {
 		return nil
 	}
+	itemOffset := uint64(t.itemOffset)
+	if items < itemOffset {
+		return fmt.Errorf("%w: truncating to %d, tail is %d", errBelowTail, items, itemOffset)
+	}
 	// We need to truncate, save the old size for metrics tracking
 	oldSize, err := t.sizeNolock()
 	if err != nil {
@@ -361,17 +378,22 @@ func (t *freezerTable) truncate(items uint64) error {
 		log = t.logger.Warn // Only loud warn if we delete multiple items
 	}
 	log("Truncating freezer table", "items", existing, "limit", items)
-	if err := truncateFreezerFile(t.index, int64(items+1)*indexEntrySize); err != nil {
+	if err := truncateFreezerFile(t.index, int64(items-itemOffset+1)*indexEntrySize); err != nil {
 		return err
 	}
 	// Calculate the new expected size of the data file and truncate it
 	buffer := make([]byte, indexEntrySize)
-	if _, err := t.index.ReadAt(buffer, int64(items*indexEntrySize)); err != nil {
+	if _, err := t.index.ReadAt(buffer, int64((items-itemOffset)*indexEntrySize)); err != nil {
 		return err
 	}
 	var expected indexEntry
 	expected.unmarshalBinary(buffer)
 
+	// The first index entry carries the tail, the tail file is emptied entirely.
+	if items == itemOffset {
+		expected = indexEntry{filenum: t.tailId}
+	}
+
 	// We might need to truncate back to older files
 	if expected.filenum != t.headId {
 		// If already open for reading, force-reopen for writing
@@ -404,6 +426,146 @@ func (t *freezerTable) truncate(items uint64) error {
 	return nil
 }
 
+// initTail moves the tail of an empty table to the given item, so that a table
+// created in an existing freezer lines up with the other tables.
+func (t *freezerTable) initTail(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if atomic.LoadUint64(&t.items) != 0 || t.headBytes != 0 {
+		return fmt.Errorf("table %s is not empty", t.name)
+	}
+	if items > math.MaxUint32 {
+		return fmt.Errorf("tail %d of table %s out of range", items, t.name)
+	}
+	first := indexEntry{filenum: t.tailId, offset: uint32(items)}
+	if _, err := t.index.WriteAt(first.append(nil), 0); err != nil {
+		return err
+	}
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	atomic.StoreUint32(&t.itemOffset, uint32(items))
+	atomic.StoreUint64(&t.items, items)
+	return nil
+}
+
+// tail returns the number of the first item stored in the table.
+func (t *freezerTable) tail() uint64 {
+	return uint64(atomic.LoadUint32(&t.itemOffset))
+}
+
+// truncateTail discards the items below the given number. Data is deleted in whole
+// files, so the items sharing a data file with the new tail are retained and the
+// tail of the table may end up below the requested one.
+func (t *freezerTable) truncateTail(tail uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var (
+		items      = atomic.LoadUint64(&t.items)
+		itemOffset = uint64(t.itemOffset)
+	)
+	if tail > items {
+		tail = items
+	}
+	if tail <= itemOffset {
+		return nil
+	}
+	// Find the data file holding the new tail, the head if everything goes.
+	buffer := make([]byte, indexEntrySize)
+	readEntry := func(i uint64) (indexEntry, error) {
+		var entry indexEntry
+		if _, err := t.index.ReadAt(buffer, int64(i*indexEntrySize)); err != nil {
+			return entry, err
+		}
+		entry.unmarshalBinary(buffer)
+		return entry, nil
+	}
+	tailId := t.headId
+	if tail < items {
+		// The entry after an item's position marks its end, and thus its file.
+		entry, err := readEntry(tail - itemOffset + 1)
+		if err != nil {
+			return err
+		}
+		tailId = entry.filenum
+	}
+	if tailId == t.tailId {
+		return nil
+	}
+	// Find the first item stored in that file.
+	var (
+		n       = int(tail - itemOffset)
+		readErr error
+	)
+	first := sort.Search(n, func(i int) bool {
+		entry, err := readEntry(uint64(i) + 1)
+		if err != nil {
+			readErr = err
+			return true
+		}
+		return entry.filenum >= tailId
+	})
+	if readErr != nil {
+		return readErr
+	}
+	oldSize, err := t.sizeNolock()
+	if err != nil {
+		return err
+	}
+	// Rewrite the index without the deleted items, then swap it in.
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	name := t.index.Name()
+	index, err := openFreezerFileTruncated(name + ".tmp")
+	if err != nil {
+		return err
+	}
+	head := indexEntry{filenum: tailId, offset: uint32(itemOffset + uint64(first))}
+	if _, err := index.Write(head.append(nil)); err != nil {
+		index.Close()
+		return err
+	}
+	start := int64(first+1) * indexEntrySize
+	if _, err := io.Copy(index, io.NewSectionReader(t.index, start, stat.Size()-start)); err != nil {
+		index.Close()
+		return err
+	}
+	if err := index.Sync(); err != nil {
+		index.Close()
+		return err
+	}
+	index.Close()
+	t.index.Close()
+	if err := os.Rename(name+".tmp", name); err != nil {
+		return err
+	}
+	if t.index, err = openFreezerFileForAppend(name); err != nil {
+		return err
+	}
+	// Delete the data files below the new tail.
+	for num := t.tailId; num < tailId; num++ {
+		if f, ok := t.files[num]; ok {
+			delete(t.files, num)
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}
+	t.logger.Info("Truncated freezer table tail", "tail", head.offset, "requested", tail, "files", tailId-t.tailId)
+	t.tailId = tailId
+	atomic.StoreUint32(&t.itemOffset, head.offset)
+
+	newSize, err := t.sizeNolock()
+	if err != nil {
+		return err
+	}
+	t.sizeGauge.Dec(int64(oldSize - newSize))
+	return nil
+}
+
 // Close closes all opened files.
 func (t *freezerTable) Close() error {
 	t.lock.Lock()
@@ -652,7 +814,7 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 // has returns an indicator whether the specified number data
 // exists in the freezer table.
 func (t *freezerTable) has(number uint64) bool {
-	return atomic.LoadUint64(&t.items) > number
+	return atomic.LoadUint64(&t.items) > number && t.tail() <= number
 }
 
 // size returns the total data size in the freezer table.
@@ -18,6 +18,7 @@ package rawdb
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -399,6 +400,122 @@ func TestFreezerTruncate(t *testing.T) {
 	}
 }
 
+// TestFreezerTruncateTail tests deleting items from the tail of a table.
+func TestFreezerTruncateTail(t *testing.T) {
+	t.Parallel()
+	rm, wm, sg := metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge()
+	fname := fmt.Sprintf("truncate-tail-%d", rand.Uint64())
+
+	// Fill table, 3 items per file
+	{
+		f, err := newTable(os.TempDir(), fname, rm, wm, sg, 50, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeChunks(t, f, 30, 15)
+
+		// Item 10 is stored in the fourth file, along with items 9 and 11
+		require.NoError(t, f.truncateTail(10))
+		if tail := f.tail(); tail != 9 {
+			t.Fatalf("expected tail %d, got %d", 9, tail)
+		}
+		if _, err := f.Retrieve(8); err != errOutOfBounds {
+			t.Fatalf("expected %v, got %v", errOutOfBounds, err)
+		}
+		if f.has(8) || !f.has(9) {
+			t.Fatal("wrong items reported after tail truncation")
+		}
+		for i := 0; i < 3; i++ {
+			p := filepath.Join(os.TempDir(), fmt.Sprintf("%v.%04d.rdat", fname, i))
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				t.Fatalf("data file %d not deleted", i)
+			}
+		}
+		// Truncating to a lower tail is a noop
+		require.NoError(t, f.truncateTail(5))
+		f.Close()
+	}
+	// Reopen, check the retained items
+	{
+		f, err := newTable(os.TempDir(), fname, rm, wm, sg, 50, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if f.tail() != 9 || f.items != 30 {
+			t.Fatalf("expected tail %d and %d items, got %d and %d", 9, 30, f.tail(), f.items)
+		}
+		for y := 9; y < 30; y++ {
+			got, err := f.Retrieve(uint64(y))
+			if err != nil {
+				t.Fatalf("reading item %d: %v", y, err)
+			}
+			if !bytes.Equal(got, getChunk(15, y)) {
+				t.Fatalf("wrong item %d: %x", y, got)
+			}
+		}
+		// Head truncation is bounded by the tail
+		if err := f.truncate(8); !errors.Is(err, errBelowTail) {
+			t.Fatalf("expected %v, got %v", errBelowTail, err)
+		}
+		require.NoError(t, f.truncate(9))
+		if f.items != 9 || f.headBytes != 0 {
+			t.Fatalf("expected %d items and empty head, got %d and %d bytes", 9, f.items, f.headBytes)
+		}
+		// Appending continues behind the tail
+		batch := f.newBatch()
+		require.NoError(t, batch.AppendRaw(9, getChunk(15, 0x41)))
+		require.NoError(t, batch.commit())
+		got, err := f.Retrieve(9)
+		require.NoError(t, err)
+		require.Equal(t, getChunk(15, 0x41), got)
+	}
+}
+
+// TestFreezerInitTail tests starting a new table at an item offset.
+func TestFreezerInitTail(t *testing.T) {
+	t.Parallel()
+	rm, wm, sg := metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge()
+	fname := fmt.Sprintf("init-tail-%d", rand.Uint64())
+
+	{
+		f, err := newTable(os.TempDir(), fname, rm, wm, sg, 50, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.NoError(t, f.initTail(100))
+		batch := f.newBatch()
+		for i := 100; i < 110; i++ {
+			require.NoError(t, batch.AppendRaw(uint64(i), getChunk(15, i)))
+		}
+		require.NoError(t, batch.commit())
+		f.Close()
+	}
+	f, err := newTable(os.TempDir(), fname, rm, wm, sg, 50, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if f.tail() != 100 || f.items != 110 {
+		t.Fatalf("expected tail %d and %d items, got %d and %d", 100, 110, f.tail(), f.items)
+	}
+	if _, err := f.Retrieve(99); err != errOutOfBounds {
+		t.Fatalf("expected %v, got %v", errOutOfBounds, err)
+	}
+	for y := 100; y < 110; y++ {
+		got, err := f.Retrieve(uint64(y))
+		if err != nil {
+			t.Fatalf("reading item %d: %v", y, err)
+		}
+		if !bytes.Equal(got, getChunk(15, y)) {
+			t.Fatalf("wrong item %d: %x", y, got)
+		}
+	}
+	if err := f.initTail(200); err == nil {
+		t.Fatal("expected error initializing the tail of a non-empty table")
+	}
+}
+
 // TestFreezerRepairFirstFile tests a head file with the very first item only half-written.
 // That will rewind the index, and _should_ truncate the head file
 func TestFreezerRepairFirstFile(t *testing.T) {
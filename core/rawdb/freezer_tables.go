@@ -0,0 +1,112 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+)
+
+// AncientTable defines a table of the chain freezer, holding one item per
+// frozen block.
+type AncientTable struct {
+	Name     string // Name of the table, also used for its files
+	NoSnappy bool   // Disables compression for data that doesn't compress well
+
+	// Freeze retrieves the item of a canonical block that is moved into the
+	// freezer. Nil results are stored as empty items, as are the items of blocks
+	// written into the freezer directly during sync.
+	Freeze func(db ethdb.KeyValueReader, number uint64, hash common.Hash) ([]byte, error)
+
+	// Delete optionally removes the data of a frozen block from the key-value
+	// store once its item was written.
+	Delete func(db ethdb.KeyValueWriter, number uint64, hash common.Hash)
+}
+
+var (
+	errTableExists  = errors.New("ancient table already registered")
+	errInvalidTable = errors.New("invalid ancient table")
+)
+
+var (
+	ancientTablesLock sync.RWMutex
+
+	// ancientTables are the tables of the chain freezer. The built-in chain data
+	// tables are filled by the freezer itself and have no Freeze function.
+	// Hashes and difficulties don't compress well.
+	ancientTables = []AncientTable{
+		{Name: freezerHeaderTable},
+		{Name: freezerHashTable, NoSnappy: true},
+		{Name: freezerBodiesTable},
+		{Name: freezerReceiptTable},
+		{Name: freezerDifficultyTable, NoSnappy: true},
+	}
+)
+
+// RegisterAncientTable adds a user-defined table to the chain freezer. Tables
+// must be registered before the database is opened. A table added to an existing
+// freezer starts at the first block frozen after it was added.
+func RegisterAncientTable(table AncientTable) error {
+	if table.Name == "" || strings.ContainsAny(table.Name, `/\.`) {
+		return fmt.Errorf("%w: bad name %q", errInvalidTable, table.Name)
+	}
+	if table.Freeze == nil {
+		return fmt.Errorf("%w: %s has no freeze function", errInvalidTable, table.Name)
+	}
+	ancientTablesLock.Lock()
+	defer ancientTablesLock.Unlock()
+
+	for _, t := range ancientTables {
+		if t.Name == table.Name {
+			return fmt.Errorf("%w: %s", errTableExists, table.Name)
+		}
+	}
+	ancientTables = append(ancientTables, table)
+	return nil
+}
+
+// AncientTables returns the names of the chain freezer tables, mapped to whether
+// compression is disabled for them.
+func AncientTables() map[string]bool {
+	ancientTablesLock.RLock()
+	defer ancientTablesLock.RUnlock()
+
+	tables := make(map[string]bool, len(ancientTables))
+	for _, table := range ancientTables {
+		tables[table.Name] = table.NoSnappy
+	}
+	return tables
+}
+
+// userAncientTables returns the registered user-defined tables.
+func userAncientTables() []AncientTable {
+	ancientTablesLock.RLock()
+	defer ancientTablesLock.RUnlock()
+
+	var tables []AncientTable
+	for _, table := range ancientTables {
+		if table.Freeze != nil {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
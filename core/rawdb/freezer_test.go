@@ -29,6 +29,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/rlp"
 )
@@ -254,6 +255,110 @@ func TestFreezerConcurrentModifyTruncate(t *testing.T) {
 	}
 }
 
+func TestFreezerTailTruncation(t *testing.T) {
+	t.Parallel()
+
+	f, dir := newFreezerForTesting(t, map[string]bool{"a": true, "b": true})
+	defer os.RemoveAll(dir)
+	defer f.Close()
+
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := 0; i < 100; i++ {
+			if err := op.AppendRaw("a", uint64(i), getChunk(256, i)); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("b", uint64(i), getChunk(256, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.TruncateTail(50))
+	tail, err := f.Tail()
+	require.NoError(t, err)
+	if tail > 50 {
+		t.Fatalf("tail %d above the requested one", tail)
+	}
+	for kind := range f.tables {
+		if ok, _ := f.HasAncient(kind, tail-1); ok {
+			t.Errorf("HasAncient(%q, %d) returned true after tail truncation", kind, tail-1)
+		}
+		checkAncientCount(t, f, kind, 100)
+	}
+	// History is retained up to the tail, head truncation stops at it.
+	for i := uint64(50); i < 100; i++ {
+		v, err := f.Ancient("a", i)
+		require.NoError(t, err)
+		require.Equal(t, getChunk(256, int(i)), v)
+	}
+	if err := f.TruncateAncients(tail - 1); !errors.Is(err, errBelowTail) {
+		t.Fatalf("expected %v, got %v", errBelowTail, err)
+	}
+}
+
+func TestFreezerAddTable(t *testing.T) {
+	t.Parallel()
+
+	f, dir := newFreezerForTesting(t, map[string]bool{"a": true})
+	defer os.RemoveAll(dir)
+
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := 0; i < 10; i++ {
+			if err := op.AppendRaw("a", uint64(i), getChunk(32, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	f.Close()
+
+	// Reopen with another table, existing data must be kept.
+	f, err = newFreezer(dir, "", false, 2049, map[string]bool{"a": true, "b": false})
+	require.NoError(t, err)
+	defer f.Close()
+
+	checkAncientCount(t, f, "a", 10)
+	if ok, _ := f.HasAncient("b", 9); ok {
+		t.Fatal("new table reports frozen item")
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		if err := op.AppendRaw("a", 10, getChunk(32, 10)); err != nil {
+			return err
+		}
+		return op.AppendRaw("b", 10, getChunk(32, 10))
+	})
+	require.NoError(t, err)
+	checkAncientCount(t, f, "b", 11)
+
+	// Tables absent from the freezer are rejected.
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		return op.AppendRaw("c", 11, nil)
+	})
+	if err != errUnknownTable {
+		t.Fatalf("expected %v, got %v", errUnknownTable, err)
+	}
+}
+
+func TestRegisterAncientTable(t *testing.T) {
+	freeze := func(ethdb.KeyValueReader, uint64, common.Hash) ([]byte, error) { return nil, nil }
+
+	if err := RegisterAncientTable(AncientTable{Name: freezerHeaderTable, Freeze: freeze}); !errors.Is(err, errTableExists) {
+		t.Fatalf("expected %v, got %v", errTableExists, err)
+	}
+	if err := RegisterAncientTable(AncientTable{Name: "../traces", Freeze: freeze}); !errors.Is(err, errInvalidTable) {
+		t.Fatalf("expected %v, got %v", errInvalidTable, err)
+	}
+	if err := RegisterAncientTable(AncientTable{Name: "traces"}); !errors.Is(err, errInvalidTable) {
+		t.Fatalf("expected %v, got %v", errInvalidTable, err)
+	}
+	if len(userAncientTables()) != 0 {
+		t.Fatal("rejected table was registered")
+	}
+}
+
 func newFreezerForTesting(t *testing.T, tables map[string]bool) (*freezer, string) {
 	t.Helper()
 
@@ -39,6 +39,21 @@ var (
 	// headFastBlockKey tracks the latest known incomplete block's hash during fast sync.
 	headFastBlockKey = []byte("LastFast")
 
+	// headSafeBlockKey tracks the latest block whose batch has been committed to L1.
+	headSafeBlockKey = []byte("LastSafe")
+
+	// headFinalizedBlockKey tracks the latest block whose batch has been finalized on L1.
+	headFinalizedBlockKey = []byte("LastFinalized")
+
+	// lastDerivedBatchKey tracks the last L1 batch derived by a verifier node.
+	lastDerivedBatchKey = []byte("LastDerivedBatch")
+
+	// syncedL1BlockKey tracks the last L1 block whose enqueued messages have been synced.
+	syncedL1BlockKey = []byte("LastSyncedL1Block")
+
+	// lastL1MessageKey tracks the queue index of the last synced L1 message.
+	lastL1MessageKey = []byte("LastL1Message")
+
 	// lastPivotKey tracks the last pivot block used by fast sync (to reenable on sethead).
 	lastPivotKey = []byte("LastPivot")
 
@@ -69,6 +84,10 @@ var (
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
+	// poseidonCodeIndexKey tracks the progress of the poseidon code hash index
+	// backfill for the codes written before it existed.
+	poseidonCodeIndexKey = []byte("PoseidonCodeIndex")
+
 	// badBlockKey tracks the list of bad blocks seen by local
 	badBlockKey = []byte("InvalidBlock")
 
@@ -84,17 +103,23 @@ var (
 	blockBodyPrefix     = []byte("b") // blockBodyPrefix + num (uint64 big endian) + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
-	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
-	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
-	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
-	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
-	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
+	txLookupPrefix         = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	bloomBitsPrefix        = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
+	logIndexPrefix         = []byte("x") // logIndexPrefix + section (uint64 big endian) + hash + term -> blocks containing the term
+	SnapshotAccountPrefix  = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
+	SnapshotStoragePrefix  = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
+	CodePrefix             = []byte("c") // CodePrefix + code hash -> account code
+	poseidonCodeHashPrefix = []byte("C") // poseidonCodeHashPrefix + poseidon code hash -> keccak code hash
+
+	l1MessagePrefix           = []byte("q") // l1MessagePrefix + queue index (uint64 big endian) -> L1 message
+	l1MessageQueueIndexPrefix = []byte("Q") // l1MessageQueueIndexPrefix + hash -> first queue index not included up to the block
 
 	PreimagePrefix = []byte("secure-key-")      // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	LogIndexPrefix       = []byte("iL") // LogIndexPrefix is the data table of the log indexer to track its progress
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
@@ -117,16 +142,6 @@ const (
 	freezerDifficultyTable = "diffs"
 )
 
-// FreezerNoSnappy configures whether compression is disabled for the ancient-tables.
-// Hashes and difficulties don't compress well.
-var FreezerNoSnappy = map[string]bool{
-	freezerHeaderTable:     false,
-	freezerHashTable:       true,
-	freezerBodiesTable:     false,
-	freezerReceiptTable:    false,
-	freezerDifficultyTable: true,
-}
-
 // LegacyTxLookupEntry is the legacy TxLookupEntry definition with some unnecessary
 // fields.
 type LegacyTxLookupEntry struct {
@@ -182,6 +197,16 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// l1MessageKey = l1MessagePrefix + queue index (uint64 big endian)
+func l1MessageKey(queueIndex uint64) []byte {
+	return append(l1MessagePrefix, encodeBlockNumber(queueIndex)...)
+}
+
+// l1MessageQueueIndexKey = l1MessageQueueIndexPrefix + hash
+func l1MessageQueueIndexKey(hash common.Hash) []byte {
+	return append(l1MessageQueueIndexPrefix, hash.Bytes()...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
@@ -207,6 +232,16 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// logIndexKey = logIndexPrefix + section (uint64 big endian) + hash + term
+func logIndexKey(section uint64, hash common.Hash, term []byte) []byte {
+	key := make([]byte, len(logIndexPrefix)+8+common.HashLength+len(term))
+	copy(key, logIndexPrefix)
+	binary.BigEndian.PutUint64(key[len(logIndexPrefix):], section)
+	copy(key[len(logIndexPrefix)+8:], hash.Bytes())
+	copy(key[len(logIndexPrefix)+8+common.HashLength:], term)
+	return key
+}
+
 // preimageKey = PreimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(PreimagePrefix, hash.Bytes()...)
@@ -217,6 +252,11 @@ func codeKey(hash common.Hash) []byte {
 	return append(CodePrefix, hash.Bytes()...)
 }
 
+// poseidonCodeHashKey = poseidonCodeHashPrefix + hash
+func poseidonCodeHashKey(hash common.Hash) []byte {
+	return append(poseidonCodeHashPrefix, hash.Bytes()...)
+}
+
 // IsCodeKey reports whether the given byte slice is the key of contract code,
 // if so return the raw code hash as well.
 func IsCodeKey(key []byte) (bool, []byte) {
@@ -61,6 +61,10 @@ func TestNodeIteratorCoverage(t *testing.T) {
 		if bytes.HasPrefix(key, []byte("secure-key-")) {
 			continue
 		}
+		// skip the poseidon code hash index
+		if bytes.HasPrefix(key, []byte("C")) && len(key) == 1+common.HashLength {
+			continue
+		}
 		if _, ok := hashes[common.BytesToHash(key)]; !ok {
 			t.Errorf("state entry not reported %x", key)
 		}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
 )
 
@@ -98,9 +100,26 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	evm.Reset(txContext, statedb)
 
 	// Apply the transaction to the current state (included in the env).
+	var (
+		snap  int
+		gas   uint64
+		nonce = tx.Nonce()
+	)
+	if msg.IsL1MessageTx() {
+		// L1 messages are not ordered by the sender nonce
+		snap, gas, nonce = statedb.Snapshot(), gp.Gas(), statedb.GetNonce(msg.From())
+	}
 	result, err := ApplyMessage(evm, msg, gp)
 	if err != nil {
-		return nil, err
+		if !msg.IsL1MessageTx() || !skippableL1Message(msg, evm, err) {
+			return nil, err
+		}
+		// The L1 message can never be executed, skip it. It's still included to
+		// consume its queue index, but leaves the state untouched.
+		log.Debug("Skipping failing L1 message", "hash", tx.Hash(), "index", tx.Nonce(), "err", err)
+		statedb.RevertToSnapshot(snap)
+		*gp = GasPool(gas)
+		result = &ExecutionResult{L1Fee: new(big.Int), Err: err}
 	}
 
 	// Update the state with pending changes.
@@ -129,8 +148,8 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	receipt.GasUsed = result.UsedGas
 
 	// If the transaction created a contract, store the creation address in the receipt.
-	if msg.To() == nil {
-		receipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, tx.Nonce())
+	if msg.To() == nil && result.UsedGas > 0 {
+		receipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, nonce)
 	}
 
 	// Set the receipt logs and create the bloom filter.
@@ -140,7 +159,23 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
 	receipt.L1Fee = result.L1Fee
-	return receipt, err
+	return receipt, nil
+}
+
+// skippableL1Message returns whether an L1 message failing with the given error
+// can never be executed and must be skipped to unblock the message queue. A
+// message merely not fitting into the remaining gas of the block is not.
+func skippableL1Message(msg Message, evm *vm.EVM, err error) bool {
+	if errors.Is(err, ErrGasLimitReached) {
+		return msg.Gas() > evm.Context.GasLimit
+	}
+	return true
+}
+
+// IsSkippedL1Message returns whether the receipt belongs to an L1 message which
+// was skipped rather than executed. Executed messages always consume gas.
+func IsSkippedL1Message(receipt *types.Receipt) bool {
+	return receipt.Type == types.L1MessageTxType && receipt.GasUsed == 0
 }
 
 // ApplyTransaction attempts to apply a transaction to the given state database
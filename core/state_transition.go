@@ -80,6 +80,7 @@ type Message interface {
 
 	Nonce() uint64
 	IsFake() bool
+	IsL1MessageTx() bool
 	Data() []byte
 	AccessList() types.AccessList
 }
@@ -164,7 +165,8 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 // NewStateTransition initialises and returns a new state transition object.
 func NewStateTransition(evm *vm.EVM, msg Message, gp *GasPool) *StateTransition {
 	l1Fee := new(big.Int)
-	if evm.ChainConfig().Scroll.FeeVaultEnabled() {
+	// L1 messages are paid for on L1
+	if evm.ChainConfig().Scroll.FeeVaultEnabled() && !msg.IsL1MessageTx() {
 		l1Fee, _ = fees.CalculateL1MsgFee(msg, evm.StateDB)
 	}
 
@@ -235,8 +237,9 @@ func (st *StateTransition) buyGas() error {
 }
 
 func (st *StateTransition) preCheck() error {
-	// Only check transactions that are not fake
-	if !st.msg.IsFake() {
+	// Only check transactions that are not fake. L1 messages are ordered by
+	// their queue index and their sender is authenticated on L1.
+	if !st.msg.IsFake() && !st.msg.IsL1MessageTx() {
 		// Make sure this transaction's nonce is correct.
 		stNonce := st.state.GetNonce(st.msg.From())
 		if msgNonce := st.msg.Nonce(); stNonce < msgNonce {
@@ -255,8 +258,9 @@ func (st *StateTransition) preCheck() error {
 				st.msg.From().Hex(), codeHash)
 		}
 	}
-	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
-	if st.evm.ChainConfig().IsLondon(st.evm.Context.BlockNumber) {
+	// Make sure that transaction gasFeeCap is greater than the baseFee (post london),
+	// L1 messages don't pay for L2 gas
+	if st.evm.ChainConfig().IsLondon(st.evm.Context.BlockNumber) && !st.msg.IsL1MessageTx() {
 		// Skip the checks if gas fields are zero and baseFee was explicitly disabled (eth_call)
 		if !st.evm.Config.NoBaseFee || st.gasFeeCap.BitLen() > 0 || st.gasTipCap.BitLen() > 0 {
 			if l := st.gasFeeCap.BitLen(); l > 256 {
@@ -310,6 +314,13 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// 5. there is no overflow when calculating intrinsic gas
 	// 6. caller has enough balance to cover asset transfer for **topmost** call
 
+	if tracer, ok := st.evm.Config.Tracer.(vm.EVMTxLogger); st.evm.Config.Debug && ok {
+		tracer.CaptureTxStart(st.evm, st.msg.From(), st.msg.To())
+		defer func() {
+			tracer.CaptureTxEnd(st.gas)
+		}()
+	}
+
 	// Check clauses 1-3, buy gas if everything is correct
 	if err := st.preCheck(); err != nil {
 		return nil, err
@@ -360,7 +371,10 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		st.refundGas(params.RefundQuotientEIP3529)
 	}
 	effectiveTip := st.gasPrice
-	if london {
+	if st.msg.IsL1MessageTx() {
+		// L1 messages are paid for on L1, the sequencer earns no tip
+		effectiveTip = new(big.Int)
+	} else if london {
 		if st.evm.Context.BaseFee != nil {
 			effectiveTip = cmath.BigMin(st.gasTipCap, new(big.Int).Sub(st.gasFeeCap, st.evm.Context.BaseFee))
 		} else {
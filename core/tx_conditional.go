@@ -0,0 +1,99 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// MaxConditionalCost is the maximum number of state lookups the preconditions of
+// a single transaction may require.
+const MaxConditionalCost = 1000
+
+var (
+	// ErrConditionalInvalid is returned if the preconditions of a transaction are
+	// malformed or too expensive to check.
+	ErrConditionalInvalid = errors.New("invalid transaction conditions")
+
+	// ErrConditionalNotReached is returned if the preconditions of a transaction
+	// can only be met by a later block.
+	ErrConditionalNotReached = errors.New("transaction conditions not yet met")
+
+	// ErrConditionalFailed is returned if the preconditions of a transaction can
+	// no longer be met.
+	ErrConditionalFailed = errors.New("transaction conditions not met")
+)
+
+// ValidateConditional checks the preconditions of a transaction for consistency,
+// independently of the chain.
+func ValidateConditional(cond *types.TransactionConditional) error {
+	if cost := cond.Cost(); cost > MaxConditionalCost {
+		return fmt.Errorf("%w: cost %d exceeds limit %d", ErrConditionalInvalid, cost, MaxConditionalCost)
+	}
+	if cond.BlockNumberMin != nil && cond.BlockNumberMax != nil && cond.BlockNumberMin.ToInt().Cmp(cond.BlockNumberMax.ToInt()) > 0 {
+		return fmt.Errorf("%w: block number range is empty", ErrConditionalInvalid)
+	}
+	if cond.TimestampMin != nil && cond.TimestampMax != nil && *cond.TimestampMin > *cond.TimestampMax {
+		return fmt.Errorf("%w: timestamp range is empty", ErrConditionalInvalid)
+	}
+	return nil
+}
+
+// CheckConditional checks the preconditions of a transaction against the header
+// of the block it is to be included in and the state it executes on. It returns
+// ErrConditionalNotReached if only the lower block number or timestamp bounds
+// are unmet, and ErrConditionalFailed if the conditions can no longer be met.
+func CheckConditional(cond *types.TransactionConditional, header *types.Header, statedb *state.StateDB) error {
+	if cond.BlockNumberMax != nil && header.Number.Cmp(cond.BlockNumberMax.ToInt()) > 0 {
+		return fmt.Errorf("%w: block number %v above maximum %v", ErrConditionalFailed, header.Number, cond.BlockNumberMax.ToInt())
+	}
+	if cond.TimestampMax != nil && header.Time > uint64(*cond.TimestampMax) {
+		return fmt.Errorf("%w: timestamp %d above maximum %d", ErrConditionalFailed, header.Time, uint64(*cond.TimestampMax))
+	}
+	for addr, account := range cond.KnownAccounts {
+		if account.Nonce != nil {
+			if nonce := statedb.GetNonce(addr); nonce != *account.Nonce {
+				return fmt.Errorf("%w: account %s nonce %d, want %d", ErrConditionalFailed, addr.Hex(), nonce, *account.Nonce)
+			}
+		}
+		if account.StorageRoot != nil {
+			root := statedb.Database().TrieDB().EmptyRoot()
+			if trie := statedb.StorageTrie(addr); trie != nil {
+				root = trie.Hash()
+			}
+			if root != *account.StorageRoot {
+				return fmt.Errorf("%w: account %s storage root %s, want %s", ErrConditionalFailed, addr.Hex(), root.Hex(), account.StorageRoot.Hex())
+			}
+		}
+		for slot, want := range account.StorageSlots {
+			if have := statedb.GetState(addr, slot); have != want {
+				return fmt.Errorf("%w: account %s slot %s is %s, want %s", ErrConditionalFailed, addr.Hex(), slot.Hex(), have.Hex(), want.Hex())
+			}
+		}
+	}
+	if cond.BlockNumberMin != nil && header.Number.Cmp(cond.BlockNumberMin.ToInt()) < 0 {
+		return fmt.Errorf("%w: block number %v below minimum %v", ErrConditionalNotReached, header.Number, cond.BlockNumberMin.ToInt())
+	}
+	if cond.TimestampMin != nil && header.Time < uint64(*cond.TimestampMin) {
+		return fmt.Errorf("%w: timestamp %d below minimum %d", ErrConditionalNotReached, header.Time, uint64(*cond.TimestampMin))
+	}
+	return nil
+}
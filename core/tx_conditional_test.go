@@ -0,0 +1,158 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+func TestCheckConditional(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	contract := common.Address{0x0a}
+	statedb.SetState(contract, common.Hash{0x01}, common.Hash{0x02})
+	statedb.SetNonce(contract, 3)
+
+	var (
+		nonce    = uint64(3)
+		badNonce = uint64(4)
+		header   = &types.Header{Number: big.NewInt(10), Time: 100}
+		number   = func(n int64) *hexutil.Big { return (*hexutil.Big)(big.NewInt(n)) }
+		stamp    = func(n uint64) *hexutil.Uint64 { return (*hexutil.Uint64)(&n) }
+	)
+	tests := []struct {
+		cond types.TransactionConditional
+		err  error
+	}{
+		{types.TransactionConditional{}, nil},
+		{types.TransactionConditional{BlockNumberMin: number(10), BlockNumberMax: number(10)}, nil},
+		{types.TransactionConditional{BlockNumberMin: number(11)}, ErrConditionalNotReached},
+		{types.TransactionConditional{BlockNumberMax: number(9)}, ErrConditionalFailed},
+		{types.TransactionConditional{TimestampMin: stamp(100), TimestampMax: stamp(100)}, nil},
+		{types.TransactionConditional{TimestampMin: stamp(101)}, ErrConditionalNotReached},
+		{types.TransactionConditional{TimestampMax: stamp(99)}, ErrConditionalFailed},
+		{types.TransactionConditional{KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {StorageSlots: map[common.Hash]common.Hash{{0x01}: {0x02}}, Nonce: &nonce},
+		}}, nil},
+		{types.TransactionConditional{KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {StorageSlots: map[common.Hash]common.Hash{{0x01}: {0x03}}},
+		}}, ErrConditionalFailed},
+		{types.TransactionConditional{KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {Nonce: &badNonce},
+		}}, ErrConditionalFailed},
+		// Unmet state conditions take precedence over future block bounds
+		{types.TransactionConditional{BlockNumberMin: number(11), KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {Nonce: &badNonce},
+		}}, ErrConditionalFailed},
+	}
+	for i, test := range tests {
+		if err := CheckConditional(&test.cond, header, statedb); !errors.Is(err, test.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+}
+
+func TestCheckConditionalStorageRoot(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	contract := common.Address{0x0a}
+	statedb.SetState(contract, common.Hash{0x01}, common.Hash{0x02})
+
+	root := statedb.StorageTrie(contract).Hash()
+	empty := statedb.Database().TrieDB().EmptyRoot()
+	header := &types.Header{Number: big.NewInt(1)}
+
+	cond := &types.TransactionConditional{KnownAccounts: map[common.Address]types.KnownAccount{
+		contract:          {StorageRoot: &root},
+		common.Address{1}: {StorageRoot: &empty},
+	}}
+	if err := CheckConditional(cond, header, statedb); err != nil {
+		t.Fatalf("failed to check storage roots: %v", err)
+	}
+	cond.KnownAccounts[contract] = types.KnownAccount{StorageRoot: &empty}
+	if err := CheckConditional(cond, header, statedb); !errors.Is(err, ErrConditionalFailed) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrConditionalFailed)
+	}
+}
+
+func TestValidateConditional(t *testing.T) {
+	slots := make(map[common.Hash]common.Hash)
+	for i := 0; i <= MaxConditionalCost; i++ {
+		slots[common.BigToHash(big.NewInt(int64(i)))] = common.Hash{}
+	}
+	var (
+		min, max = uint64(2), uint64(1)
+		tests    = []types.TransactionConditional{
+			{KnownAccounts: map[common.Address]types.KnownAccount{{}: {StorageSlots: slots}}},
+			{BlockNumberMin: (*hexutil.Big)(big.NewInt(2)), BlockNumberMax: (*hexutil.Big)(big.NewInt(1))},
+			{TimestampMin: (*hexutil.Uint64)(&min), TimestampMax: (*hexutil.Uint64)(&max)},
+		}
+	)
+	for i, cond := range tests {
+		if err := ValidateConditional(&cond); !errors.Is(err, ErrConditionalInvalid) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, ErrConditionalInvalid)
+		}
+	}
+}
+
+func TestTransactionConditionalAdmission(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	conditional := func(nonce uint64, cond *types.TransactionConditional) *types.Transaction {
+		tx := transaction(nonce, 100000, key)
+		tx.SetConditional(cond)
+		return tx
+	}
+	// The pending block is the first one, a lower bound above it is admitted
+	if err := pool.AddLocal(conditional(0, &types.TransactionConditional{BlockNumberMin: (*hexutil.Big)(big.NewInt(5))})); err != nil {
+		t.Fatalf("failed to add future conditional transaction: %v", err)
+	}
+	if err := pool.AddLocal(conditional(1, &types.TransactionConditional{BlockNumberMax: (*hexutil.Big)(big.NewInt(0))})); !errors.Is(err, ErrConditionalFailed) {
+		t.Fatalf("expired conditional: error mismatch: have %v, want %v", err, ErrConditionalFailed)
+	}
+	nonce := uint64(1)
+	cond := &types.TransactionConditional{KnownAccounts: map[common.Address]types.KnownAccount{{0x0a}: {Nonce: &nonce}}}
+	if err := pool.AddLocal(conditional(1, cond)); !errors.Is(err, ErrConditionalFailed) {
+		t.Fatalf("state conditional: error mismatch: have %v, want %v", err, ErrConditionalFailed)
+	}
+	// Conditional transactions must not be written to the journal
+	if locals := pool.local(); len(locals) != 0 {
+		t.Fatalf("conditional transactions journaled: %v", locals)
+	}
+	tx := conditional(1, &types.TransactionConditional{})
+	if err := pool.AddLocal(tx); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	pool.Drop(tx.Hash())
+	if pool.Has(tx.Hash()) {
+		t.Fatal("dropped transaction still in the pool")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
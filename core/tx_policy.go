@@ -0,0 +1,228 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/simplelru"
+	"golang.org/x/time/rate"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// ErrPolicyRejected is returned if a transaction is refused by one of the
+// admission policies of the pool.
+var ErrPolicyRejected = errors.New("transaction rejected by policy")
+
+// senderLimiterCount is the number of senders tracked by the rate limit policy.
+const senderLimiterCount = 4096
+
+// AdmissionPolicy decides which transactions are accepted into the pool. Policies
+// are called with the pool lock held and must not call back into the pool.
+type AdmissionPolicy interface {
+	// Name identifies the policy in rejection errors and metrics.
+	Name() string
+
+	// Admit checks a transaction entering the pool. Returning an error rejects
+	// the transaction.
+	Admit(tx *types.Transaction, from common.Address, local bool) error
+
+	// Promote checks a queued transaction before it becomes executable. Returning
+	// an error drops the transaction from the pool.
+	Promote(tx *types.Transaction, from common.Address) error
+}
+
+// AdmissionConfig configures the built-in admission policies of the pool.
+type AdmissionConfig struct {
+	Allow []common.Address // Senders admitted exclusively, all of them if empty
+	Deny  []common.Address // Senders and recipients whose transactions are rejected
+
+	DenySelectors []hexutil.Bytes // Function selectors of rejected contract calls
+
+	SenderRate  float64 // Remote transactions admitted per second and sender (0 = unlimited)
+	SenderBurst int     // Remote transactions a sender may submit at once
+
+	// Priority lists the senders of system transactions. They bypass all policies
+	// and are treated as local, which exempts them from pricing and eviction and
+	// places them ahead of other transactions in blocks.
+	Priority []common.Address
+}
+
+// policies creates the built-in admission policies enabled in the config.
+func (config *AdmissionConfig) policies() []AdmissionPolicy {
+	var policies []AdmissionPolicy
+	if len(config.Allow) > 0 || len(config.Deny) > 0 {
+		policies = append(policies, newAddressPolicy(config.Allow, config.Deny))
+	}
+	if len(config.DenySelectors) > 0 {
+		policies = append(policies, newSelectorPolicy(config.DenySelectors))
+	}
+	if config.SenderRate > 0 {
+		policies = append(policies, newSenderRatePolicy(config.SenderRate, config.SenderBurst))
+	}
+	return policies
+}
+
+// txPolicy is an admission policy installed into the pool, along with its metrics.
+type txPolicy struct {
+	AdmissionPolicy
+	rejectMeter metrics.Meter // Transactions refused on admission
+	dropMeter   metrics.Meter // Queued transactions dropped on promotion
+}
+
+func newTxPolicy(policy AdmissionPolicy) *txPolicy {
+	prefix := "txpool/policy/" + policy.Name()
+	return &txPolicy{
+		AdmissionPolicy: policy,
+		rejectMeter:     metrics.GetOrRegisterMeter(prefix+"/rejected", nil),
+		dropMeter:       metrics.GetOrRegisterMeter(prefix+"/dropped", nil),
+	}
+}
+
+// addressPolicy filters transactions by sender and recipient.
+type addressPolicy struct {
+	allow map[common.Address]struct{}
+	deny  map[common.Address]struct{}
+}
+
+func newAddressPolicy(allow, deny []common.Address) *addressPolicy {
+	policy := &addressPolicy{
+		allow: make(map[common.Address]struct{}),
+		deny:  make(map[common.Address]struct{}),
+	}
+	for _, addr := range allow {
+		policy.allow[addr] = struct{}{}
+	}
+	for _, addr := range deny {
+		policy.deny[addr] = struct{}{}
+	}
+	return policy
+}
+
+func (p *addressPolicy) Name() string { return "addresses" }
+
+func (p *addressPolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	return p.Promote(tx, from)
+}
+
+func (p *addressPolicy) Promote(tx *types.Transaction, from common.Address) error {
+	if _, ok := p.deny[from]; ok {
+		return fmt.Errorf("sender %s is denied", from.Hex())
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := p.deny[*to]; ok {
+			return fmt.Errorf("recipient %s is denied", to.Hex())
+		}
+	}
+	if len(p.allow) > 0 {
+		if _, ok := p.allow[from]; !ok {
+			return fmt.Errorf("sender %s is not allowed", from.Hex())
+		}
+	}
+	return nil
+}
+
+// selectorPolicy rejects contract calls to the configured functions.
+type selectorPolicy struct {
+	deny map[[4]byte]struct{}
+}
+
+func newSelectorPolicy(selectors []hexutil.Bytes) *selectorPolicy {
+	policy := &selectorPolicy{deny: make(map[[4]byte]struct{})}
+	for _, selector := range selectors {
+		if len(selector) != 4 {
+			log.Warn("Ignoring invalid txpool function selector", "selector", selector)
+			continue
+		}
+		var sel [4]byte
+		copy(sel[:], selector)
+		policy.deny[sel] = struct{}{}
+	}
+	return policy
+}
+
+func (p *selectorPolicy) Name() string { return "selectors" }
+
+func (p *selectorPolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	return p.Promote(tx, from)
+}
+
+func (p *selectorPolicy) Promote(tx *types.Transaction, from common.Address) error {
+	data := tx.Data()
+	if tx.To() == nil || len(data) < 4 {
+		return nil
+	}
+	var sel [4]byte
+	copy(sel[:], data)
+	if _, ok := p.deny[sel]; ok {
+		return fmt.Errorf("function %s is denied", hexutil.Encode(sel[:]))
+	}
+	return nil
+}
+
+// senderRatePolicy limits the rate of remote transactions per sender.
+type senderRatePolicy struct {
+	limit rate.Limit
+	burst int
+
+	lock     sync.Mutex
+	limiters *lru.LRU // Rate limiters of recently seen senders
+}
+
+func newSenderRatePolicy(limit float64, burst int) *senderRatePolicy {
+	if burst < 1 {
+		burst = int(math.Ceil(limit))
+	}
+	limiters, _ := lru.NewLRU(senderLimiterCount, nil)
+	return &senderRatePolicy{
+		limit:    rate.Limit(limit),
+		burst:    burst,
+		limiters: limiters,
+	}
+}
+
+func (p *senderRatePolicy) Name() string { return "ratelimit" }
+
+func (p *senderRatePolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if local {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	limiter, ok := p.limiters.Get(from)
+	if !ok {
+		limiter = rate.NewLimiter(p.limit, p.burst)
+		p.limiters.Add(from, limiter)
+	}
+	if !limiter.(*rate.Limiter).Allow() {
+		return fmt.Errorf("sender %s exceeds %v transactions per second", from.Hex(), float64(p.limit))
+	}
+	return nil
+}
+
+func (p *senderRatePolicy) Promote(tx *types.Transaction, from common.Address) error {
+	return nil
+}
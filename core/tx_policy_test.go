@@ -0,0 +1,192 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/event"
+)
+
+// setupTxPoolWithAdmission creates a pool enforcing the given admission config,
+// along with a funded key.
+func setupTxPoolWithAdmission(admission AdmissionConfig) (*TxPool, *ecdsa.PrivateKey) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{10000000, statedb, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Admission = admission
+	pool := NewTxPool(config, noL1feeConfig, blockchain)
+	<-pool.initDoneCh
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	return pool, key
+}
+
+func TestTransactionAddressPolicy(t *testing.T) {
+	t.Parallel()
+
+	denied, _ := crypto.GenerateKey()
+	recipient := common.Address{0x01}
+	pool, key := setupTxPoolWithAdmission(AdmissionConfig{
+		Deny: []common.Address{crypto.PubkeyToAddress(denied.PublicKey), recipient},
+	})
+	defer pool.Stop()
+	testAddBalance(pool, crypto.PubkeyToAddress(denied.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.AddRemote(transaction(0, 100000, denied)); !errors.Is(err, ErrPolicyRejected) {
+		t.Fatalf("denied sender: expected %v, got %v", ErrPolicyRejected, err)
+	}
+	tx, _ := types.SignTx(types.NewTransaction(0, recipient, big.NewInt(1), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err := pool.AddRemote(tx); !errors.Is(err, ErrPolicyRejected) {
+		t.Fatalf("denied recipient: expected %v, got %v", ErrPolicyRejected, err)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+}
+
+func TestTransactionAllowPolicy(t *testing.T) {
+	t.Parallel()
+
+	allowed, _ := crypto.GenerateKey()
+	pool, key := setupTxPoolWithAdmission(AdmissionConfig{
+		Allow: []common.Address{crypto.PubkeyToAddress(allowed.PublicKey)},
+	})
+	defer pool.Stop()
+	testAddBalance(pool, crypto.PubkeyToAddress(allowed.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.AddRemote(transaction(0, 100000, key)); !errors.Is(err, ErrPolicyRejected) {
+		t.Fatalf("expected %v, got %v", ErrPolicyRejected, err)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, allowed)); err != nil {
+		t.Fatalf("failed to add allowed transaction: %v", err)
+	}
+}
+
+func TestTransactionSelectorPolicy(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithAdmission(AdmissionConfig{
+		DenySelectors: []hexutil.Bytes{{0xa9, 0x05, 0x9c, 0xbb}},
+	})
+	defer pool.Stop()
+
+	contract := common.Address{0x02}
+	call := func(nonce uint64, data []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, contract, big.NewInt(0), 100000, big.NewInt(1), data), types.HomesteadSigner{}, key)
+		return tx
+	}
+	if err := pool.AddRemote(call(0, []byte{0xa9, 0x05, 0x9c, 0xbb, 0x00})); !errors.Is(err, ErrPolicyRejected) {
+		t.Fatalf("expected %v, got %v", ErrPolicyRejected, err)
+	}
+	if err := pool.AddRemote(call(0, []byte{0x09, 0x5e, 0xa7, 0xb3, 0x00})); err != nil {
+		t.Fatalf("failed to add allowed call: %v", err)
+	}
+}
+
+func TestTransactionSenderRatePolicy(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithAdmission(AdmissionConfig{SenderRate: 0.001, SenderBurst: 2})
+	defer pool.Stop()
+
+	for i := uint64(0); i < 2; i++ {
+		if err := pool.AddRemote(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	if err := pool.AddRemote(transaction(2, 100000, key)); !errors.Is(err, ErrPolicyRejected) {
+		t.Fatalf("expected %v, got %v", ErrPolicyRejected, err)
+	}
+	// Local transactions are not rate limited
+	if err := pool.AddLocal(transaction(2, 100000, key)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+}
+
+func TestTransactionPriorityPolicy(t *testing.T) {
+	t.Parallel()
+
+	system, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(system.PublicKey)
+	pool, _ := setupTxPoolWithAdmission(AdmissionConfig{
+		Deny:     []common.Address{addr},
+		Priority: []common.Address{addr},
+	})
+	defer pool.Stop()
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	// Priority senders bypass the policies and the price limit
+	pool.gasPrice = big.NewInt(1000)
+	if err := pool.AddRemote(transaction(0, 100000, system)); err != nil {
+		t.Fatalf("failed to add priority transaction: %v", err)
+	}
+	if !pool.locals.contains(addr) {
+		t.Fatal("priority sender not treated as local")
+	}
+}
+
+// nonceLimitPolicy refuses to promote transactions above a nonce.
+type nonceLimitPolicy struct{ limit uint64 }
+
+func (p *nonceLimitPolicy) Name() string { return "noncelimit" }
+
+func (p *nonceLimitPolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	return nil
+}
+
+func (p *nonceLimitPolicy) Promote(tx *types.Transaction, from common.Address) error {
+	if tx.Nonce() > p.limit {
+		return errors.New("nonce too high")
+	}
+	return nil
+}
+
+func TestTransactionPromotionPolicy(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithAdmission(AdmissionConfig{})
+	defer pool.Stop()
+	pool.AddPolicy(&nonceLimitPolicy{limit: 1})
+
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)}
+	for i, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pending/queued mismatch: have %d/%d, want %d/%d", pending, queued, 2, 0)
+	}
+	if pool.Get(txs[2].Hash()) != nil {
+		t.Fatal("rejected transaction still in the pool")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
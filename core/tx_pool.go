@@ -18,6 +18,7 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"sort"
@@ -179,6 +180,10 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	Admission AdmissionConfig // Built-in policies restricting which transactions are accepted
+
+	Private bool // Keeps local transactions off the network and hides pending transactions from public APIs
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -234,6 +239,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.Private && conf.NoLocals {
+		log.Warn("Sanitizing txpool local handling, required by the private pool", "provided", conf.NoLocals, "updated", false)
+		conf.NoLocals = false
+	}
 	return conf
 }
 
@@ -258,6 +267,7 @@ type TxPool struct {
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
 
+	currentHead   *types.Header  // Current head of the blockchain
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
@@ -265,6 +275,9 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	policies []*txPolicy                 // Admission policies consulted for every transaction
+	priority map[common.Address]struct{} // Senders of system transactions bypassing the policies
+
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
@@ -314,6 +327,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		reorgShutdownCh: make(chan struct{}),
 		initDoneCh:      make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
+		priority:        make(map[common.Address]struct{}),
 		spammers:        prque.New(nil),
 	}
 	pool.locals = newAccountSet(pool.signer)
@@ -321,6 +335,14 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	for _, addr := range config.Admission.Priority {
+		log.Info("Setting new priority account", "address", addr)
+		pool.locals.add(addr)
+		pool.priority[addr] = struct{}{}
+	}
+	for _, policy := range config.Admission.policies() {
+		pool.policies = append(pool.policies, newTxPolicy(policy))
+	}
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -348,6 +370,16 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	return pool
 }
 
+// AddPolicy installs an additional admission policy, which is consulted for every
+// transaction added to the pool from now on.
+func (pool *TxPool) AddPolicy(policy AdmissionPolicy) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	log.Info("Adding transaction admission policy", "policy", policy.Name())
+	pool.policies = append(pool.policies, newTxPolicy(policy))
+}
+
 // loop is the transaction pool's main event loop, waiting for and reacting to
 // outside blockchain events as well as for various reporting and transaction
 // eviction events.
@@ -582,6 +614,15 @@ func (pool *TxPool) Locals() []common.Address {
 	return pool.locals.flatten()
 }
 
+// IsLocal reports whether a transaction is treated as local by the pool, having
+// been submitted locally or sent by a local account.
+func (pool *TxPool) IsLocal(tx *types.Transaction) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.locals.containsTx(tx)
+}
+
 // local retrieves all currently known local transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -594,6 +635,18 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 		if queued := pool.queue[addr]; queued != nil {
 			txs[addr] = append(txs[addr], queued.Flatten()...)
 		}
+		// Conditions are not journaled, so neither are conditional transactions
+		unconditional := txs[addr][:0]
+		for _, tx := range txs[addr] {
+			if tx.Conditional() == nil {
+				unconditional = append(unconditional, tx)
+			}
+		}
+		if len(unconditional) == 0 {
+			delete(txs, addr)
+		} else {
+			txs[addr] = unconditional
+		}
 	}
 	return txs
 }
@@ -609,6 +662,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !pool.eip1559 && tx.Type() == types.DynamicFeeTxType {
 		return ErrTxTypeNotSupported
 	}
+	// L1 messages are only included by the sequencer from the L1 message queue
+	if tx.IsL1MessageTx() {
+		return ErrTxTypeNotSupported
+	}
 	// Reject transactions over defined size to prevent DOS attacks
 	if uint64(tx.Size()) > txMaxSize {
 		return ErrOversizedData
@@ -660,9 +717,63 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if tx.Gas() < intrGas {
 		return ErrIntrinsicGas
 	}
+	// Ensure the preconditions of conditional transactions can still be met
+	if cond := tx.Conditional(); cond != nil {
+		if err := pool.validateConditional(cond); err != nil {
+			return err
+		}
+	}
+	// Ensure the transaction passes the admission policies
+	return pool.admit(tx, from, local)
+}
+
+// validateConditional checks the preconditions of a transaction against the
+// pending block. Lower block number and timestamp bounds may be met later, so
+// only conditions that can no longer be met are rejected.
+func (pool *TxPool) validateConditional(cond *types.TransactionConditional) error {
+	if err := ValidateConditional(cond); err != nil {
+		return err
+	}
+	header := &types.Header{
+		Number: new(big.Int).Add(pool.currentHead.Number, common.Big1),
+		Time:   uint64(time.Now().Unix()),
+	}
+	if err := CheckConditional(cond, header, pool.currentState); err != nil && !errors.Is(err, ErrConditionalNotReached) {
+		return err
+	}
+	return nil
+}
+
+// admit checks a transaction entering the pool against the admission policies.
+func (pool *TxPool) admit(tx *types.Transaction, from common.Address, local bool) error {
+	if _, ok := pool.priority[from]; ok {
+		return nil
+	}
+	for _, policy := range pool.policies {
+		if err := policy.Admit(tx, from, local); err != nil {
+			policy.rejectMeter.Mark(1)
+			return fmt.Errorf("%w: %s: %v", ErrPolicyRejected, policy.Name(), err)
+		}
+	}
 	return nil
 }
 
+// rejectPromotion reports whether any admission policy refuses to promote a
+// queued transaction.
+func (pool *TxPool) rejectPromotion(tx *types.Transaction, from common.Address) bool {
+	if _, ok := pool.priority[from]; ok {
+		return false
+	}
+	for _, policy := range pool.policies {
+		if err := policy.Promote(tx, from); err != nil {
+			log.Trace("Dropping policy-rejected transaction", "hash", tx.Hash(), "policy", policy.Name(), "err", err)
+			policy.dropMeter.Mark(1)
+			return true
+		}
+	}
+	return false
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -825,6 +936,10 @@ func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
 	if pool.journal == nil || !pool.locals.contains(from) {
 		return
 	}
+	// Conditions are lost on restart, don't resurrect the transaction without them
+	if tx.Conditional() != nil {
+		return
+	}
 	if err := pool.journal.insert(tx); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
@@ -1012,6 +1127,15 @@ func (pool *TxPool) Has(hash common.Hash) bool {
 	return pool.all.Get(hash) != nil
 }
 
+// Drop removes a single transaction from the pool, moving all subsequent
+// transactions of the sender back to the future queue.
+func (pool *TxPool) Drop(hash common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.removeTx(hash, true)
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
@@ -1317,6 +1441,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset txpool state", "err", err)
 		return
 	}
+	pool.currentHead = newHead
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = newHead.GasLimit
@@ -1363,6 +1488,18 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
 
+		// Drop all transactions refused by the admission policies
+		var rejects types.Transactions
+		if len(pool.policies) > 0 {
+			rejects = list.txs.Filter(func(tx *types.Transaction) bool {
+				return pool.rejectPromotion(tx, addr)
+			})
+			for _, tx := range rejects {
+				pool.all.Remove(tx.Hash())
+			}
+			log.Trace("Removed policy-rejected queued transactions", "count", len(rejects))
+		}
+
 		// Gather all executable transactions and promote them
 		readies := list.Ready(pool.pendingNonces.get(addr))
 		for _, tx := range readies {
@@ -1386,10 +1523,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			queuedRateLimitMeter.Mark(int64(len(caps)))
 		}
 		// Mark all the items dropped as removed
-		pool.priced.Removed(len(forwards) + len(drops) + len(caps))
-		queuedGauge.Dec(int64(len(forwards) + len(drops) + len(caps)))
+		pool.priced.Removed(len(forwards) + len(drops) + len(rejects) + len(caps))
+		queuedGauge.Dec(int64(len(forwards) + len(drops) + len(rejects) + len(caps)))
 		if pool.locals.contains(addr) {
-			localGauge.Dec(int64(len(forwards) + len(drops) + len(caps)))
+			localGauge.Dec(int64(len(forwards) + len(drops) + len(rejects) + len(caps)))
 		}
 		// Delete the entire queue entry if it became empty.
 		if list.Empty() {
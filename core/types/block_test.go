@@ -195,6 +195,36 @@ func TestEIP2718BlockEncoding(t *testing.T) {
 	}
 }
 
+func TestL1BlockNumberHeaderEncoding(t *testing.T) {
+	number := uint64(100)
+	header := &Header{
+		Difficulty:    big.NewInt(1),
+		Number:        big.NewInt(1),
+		BaseFee:       big.NewInt(params.InitialBaseFee),
+		L1BlockNumber: &number,
+	}
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	var dec Header
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if dec.L1BlockNumber == nil || *dec.L1BlockNumber != number {
+		t.Fatalf("L1 block number mismatch: have %v, want %d", dec.L1BlockNumber, number)
+	}
+	if dec.Hash() != header.Hash() {
+		t.Fatalf("hash mismatch: have %x, want %x", dec.Hash(), header.Hash())
+	}
+	// Headers without the L1 block number keep their legacy encoding
+	header.L1BlockNumber = nil
+	var legacy Header
+	if enc, _ := rlp.EncodeToBytes(header); rlp.DecodeBytes(enc, &legacy) != nil || legacy.L1BlockNumber != nil {
+		t.Fatalf("L1 block number decoded from a legacy header")
+	}
+}
+
 func TestUncleHash(t *testing.T) {
 	uncles := make([]*Header, 0)
 	h := CalcUncleHash(uncles)
@@ -0,0 +1,72 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// L1MessageTx is a transaction enqueued on L1 and relayed to L2 by the
+// sequencer. It carries no signature: the sender is authenticated by the L1
+// message queue contract, and the gas is paid for on L1.
+type L1MessageTx struct {
+	QueueIndex uint64
+	Gas        uint64          // gas limit
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int
+	Data       []byte
+	Sender     common.Address
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *L1MessageTx) copy() TxData {
+	cpy := &L1MessageTx{
+		QueueIndex: tx.QueueIndex,
+		Gas:        tx.Gas,
+		To:         copyAddressPtr(tx.To),
+		Value:      new(big.Int),
+		Data:       common.CopyBytes(tx.Data),
+		Sender:     tx.Sender,
+	}
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *L1MessageTx) txType() byte           { return L1MessageTxType }
+func (tx *L1MessageTx) chainID() *big.Int      { return new(big.Int) }
+func (tx *L1MessageTx) accessList() AccessList { return nil }
+func (tx *L1MessageTx) data() []byte           { return tx.Data }
+func (tx *L1MessageTx) gas() uint64            { return tx.Gas }
+func (tx *L1MessageTx) gasFeeCap() *big.Int    { return common.Big0 }
+func (tx *L1MessageTx) gasTipCap() *big.Int    { return common.Big0 }
+func (tx *L1MessageTx) gasPrice() *big.Int     { return common.Big0 }
+func (tx *L1MessageTx) value() *big.Int        { return tx.Value }
+func (tx *L1MessageTx) nonce() uint64          { return tx.QueueIndex }
+func (tx *L1MessageTx) to() *common.Address    { return tx.To }
+
+func (tx *L1MessageTx) rawSignatureValues() (v, r, s *big.Int) {
+	return common.Big0, common.Big0, common.Big0
+}
+
+func (tx *L1MessageTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	// L1 messages are not signed
+}
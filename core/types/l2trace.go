@@ -65,6 +65,33 @@ type ExecutionResult struct {
 	// If it is a contract call, the contract code is returned.
 	ByteCode   string          `json:"byteCode,omitempty"`
 	StructLogs []*StructLogRes `json:"structLogs"`
+	// Estimated circuit rows consumed by the tx, only reported if requested
+	RowUsage *RowUsage `json:"rowUsage,omitempty"`
+}
+
+// RowUsage is the estimated number of rows a transaction occupies in each of
+// the zkEVM sub-circuits. A block can only be proven as long as none of the
+// sub-circuits overflows, so the most used one bounds the proving capacity a
+// transaction consumes.
+type RowUsage struct {
+	EVMSteps  uint64 `json:"evmSteps"`
+	Keccak    uint64 `json:"keccak"`
+	Poseidon  uint64 `json:"poseidon"`
+	Storage   uint64 `json:"storage"`
+	Memory    uint64 `json:"memory"`
+	Copy      uint64 `json:"copy"`
+	WasmSteps uint64 `json:"wasmSteps"`
+}
+
+// Max returns the row count of the most used sub-circuit.
+func (r *RowUsage) Max() uint64 {
+	max := r.EVMSteps
+	for _, rows := range []uint64{r.Keccak, r.Poseidon, r.Storage, r.Memory, r.Copy, r.WasmSteps} {
+		if rows > max {
+			max = rows
+		}
+	}
+	return max
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
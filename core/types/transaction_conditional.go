@@ -0,0 +1,112 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+// KnownAccount is a precondition on the state of an account. It either fixes the
+// root of the account storage, or the values of individual storage slots, and
+// optionally the account nonce.
+//
+// In JSON, a storage root is given as a plain hash and storage slots as an object
+// mapping slots to values. Nonces require the full object form, with the fields
+// "storageRoot", "storageSlots" and "nonce".
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+	Nonce        *uint64
+}
+
+// knownAccountJSON is the full object form of a KnownAccount.
+type knownAccountJSON struct {
+	StorageRoot  *common.Hash                `json:"storageRoot,omitempty"`
+	StorageSlots map[common.Hash]common.Hash `json:"storageSlots,omitempty"`
+	Nonce        *hexutil.Uint64             `json:"nonce,omitempty"`
+}
+
+// MarshalJSON encodes the account precondition in its shortest form.
+func (a KnownAccount) MarshalJSON() ([]byte, error) {
+	switch {
+	case a.Nonce == nil && a.StorageRoot != nil && a.StorageSlots == nil:
+		return json.Marshal(a.StorageRoot)
+	case a.Nonce == nil && a.StorageRoot == nil:
+		return json.Marshal(a.StorageSlots)
+	}
+	return json.Marshal(knownAccountJSON{
+		StorageRoot:  a.StorageRoot,
+		StorageSlots: a.StorageSlots,
+		Nonce:        (*hexutil.Uint64)(a.Nonce),
+	})
+}
+
+// UnmarshalJSON decodes the account precondition from any of its forms.
+func (a *KnownAccount) UnmarshalJSON(input []byte) error {
+	*a = KnownAccount{}
+
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		a.StorageRoot = &root
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return err
+	}
+	for key := range fields {
+		if !strings.HasPrefix(key, "0x") {
+			var dec knownAccountJSON
+			if err := json.Unmarshal(input, &dec); err != nil {
+				return err
+			}
+			a.StorageRoot, a.StorageSlots, a.Nonce = dec.StorageRoot, dec.StorageSlots, (*uint64)(dec.Nonce)
+			return nil
+		}
+	}
+	return json.Unmarshal(input, &a.StorageSlots)
+}
+
+// TransactionConditional holds the preconditions a transaction is only valid
+// under. They are enforced by the local pool and miner, and are not part of the
+// transaction itself.
+type TransactionConditional struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts"`
+	BlockNumberMin *hexutil.Big                    `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Big                    `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                 `json:"timestampMax,omitempty"`
+}
+
+// Cost returns the number of state lookups needed to check the preconditions.
+func (c *TransactionConditional) Cost() int {
+	cost := 0
+	for _, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			cost++
+		}
+		if account.Nonce != nil {
+			cost++
+		}
+		cost += len(account.StorageSlots)
+	}
+	return cost
+}
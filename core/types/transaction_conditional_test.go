@@ -0,0 +1,92 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestKnownAccountJSON(t *testing.T) {
+	root := common.HexToHash("0x01")
+	nonce := uint64(5)
+	slots := map[common.Hash]common.Hash{common.HexToHash("0x02"): common.HexToHash("0x03")}
+
+	tests := []struct {
+		account KnownAccount
+		json    string
+	}{
+		{
+			KnownAccount{StorageRoot: &root},
+			`"0x0000000000000000000000000000000000000000000000000000000000000001"`,
+		},
+		{
+			KnownAccount{StorageSlots: slots},
+			`{"0x0000000000000000000000000000000000000000000000000000000000000002":"0x0000000000000000000000000000000000000000000000000000000000000003"}`,
+		},
+		{
+			KnownAccount{StorageRoot: &root, Nonce: &nonce},
+			`{"storageRoot":"0x0000000000000000000000000000000000000000000000000000000000000001","nonce":"0x5"}`,
+		},
+	}
+	for i, test := range tests {
+		enc, err := json.Marshal(test.account)
+		if err != nil {
+			t.Fatalf("test %d: failed to encode: %v", i, err)
+		}
+		if string(enc) != test.json {
+			t.Errorf("test %d: encoding mismatch: have %s, want %s", i, enc, test.json)
+		}
+		var dec KnownAccount
+		if err := json.Unmarshal(enc, &dec); err != nil {
+			t.Fatalf("test %d: failed to decode: %v", i, err)
+		}
+		if !reflect.DeepEqual(dec, test.account) {
+			t.Errorf("test %d: decoding mismatch: have %+v, want %+v", i, dec, test.account)
+		}
+	}
+}
+
+func TestTransactionConditionalCost(t *testing.T) {
+	var cond TransactionConditional
+	input := `{
+		"knownAccounts": {
+			"0x000000000000000000000000000000000000000a": "0x0000000000000000000000000000000000000000000000000000000000000001",
+			"0x000000000000000000000000000000000000000b": {
+				"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002",
+				"0x0000000000000000000000000000000000000000000000000000000000000003": "0x0000000000000000000000000000000000000000000000000000000000000004"
+			}
+		},
+		"blockNumberMax": "0x10",
+		"timestampMin": "0x20"
+	}`
+	if err := json.Unmarshal([]byte(input), &cond); err != nil {
+		t.Fatalf("failed to decode conditional: %v", err)
+	}
+	if cost := cond.Cost(); cost != 3 {
+		t.Errorf("cost mismatch: have %d, want %d", cost, 3)
+	}
+	if cond.BlockNumberMax.ToInt().Uint64() != 16 || cond.BlockNumberMin != nil {
+		t.Errorf("block number range mismatch: have %v-%v", cond.BlockNumberMin, cond.BlockNumberMax)
+	}
+	if uint64(*cond.TimestampMin) != 32 || cond.TimestampMax != nil {
+		t.Errorf("timestamp range mismatch: have %v-%v", cond.TimestampMin, cond.TimestampMax)
+	}
+}
@@ -46,6 +46,10 @@ type txJSON struct {
 	ChainID    *hexutil.Big `json:"chainId,omitempty"`
 	AccessList *AccessList  `json:"accessList,omitempty"`
 
+	// L1 message transaction fields:
+	QueueIndex *hexutil.Uint64 `json:"queueIndex,omitempty"`
+	Sender     *common.Address `json:"sender,omitempty"`
+
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
 }
@@ -94,6 +98,13 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 		enc.V = (*hexutil.Big)(tx.V)
 		enc.R = (*hexutil.Big)(tx.R)
 		enc.S = (*hexutil.Big)(tx.S)
+	case *L1MessageTx:
+		enc.QueueIndex = (*hexutil.Uint64)(&tx.QueueIndex)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = t.To()
+		enc.Sender = &tx.Sender
 	}
 	return json.Marshal(&enc)
 }
@@ -263,6 +274,33 @@ func (t *Transaction) UnmarshalJSON(input []byte) error {
 			}
 		}
 
+	case L1MessageTxType:
+		var itx L1MessageTx
+		inner = &itx
+		if dec.QueueIndex == nil {
+			return errors.New("missing required field 'queueIndex' in transaction")
+		}
+		itx.QueueIndex = uint64(*dec.QueueIndex)
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' in transaction")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.To != nil {
+			itx.To = dec.To
+		}
+		if dec.Value == nil {
+			return errors.New("missing required field 'value' in transaction")
+		}
+		itx.Value = (*big.Int)(dec.Value)
+		if dec.Data == nil {
+			return errors.New("missing required field 'input' in transaction")
+		}
+		itx.Data = *dec.Data
+		if dec.Sender == nil {
+			return errors.New("missing required field 'sender' in transaction")
+		}
+		itx.Sender = *dec.Sender
+
 	default:
 		return ErrTxTypeNotSupported
 	}
@@ -488,6 +488,41 @@ func TestTransactionCoding(t *testing.T) {
 	}
 }
 
+// TestL1MessageTxCoding tests serializing/de-serializing L1 messages, which
+// carry their sender instead of a signature.
+func TestL1MessageTxCoding(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	for _, to := range []*common.Address{&testAddr, nil} {
+		tx := NewTx(&L1MessageTx{
+			QueueIndex: 7,
+			Gas:        100000,
+			To:         to,
+			Value:      big.NewInt(10),
+			Data:       []byte("abcdef"),
+			Sender:     sender,
+		})
+		if !tx.IsL1MessageTx() || tx.Nonce() != 7 {
+			t.Fatalf("unexpected L1 message: type %d, nonce %d", tx.Type(), tx.Nonce())
+		}
+		from, err := Sender(LatestSignerForChainID(big.NewInt(1)), tx)
+		if err != nil || from != sender {
+			t.Fatalf("sender mismatch: have %v (%v), want %v", from, err, sender)
+		}
+		for _, coder := range []func(*Transaction) (*Transaction, error){encodeDecodeBinary, encodeDecodeJSON} {
+			parsedTx, err := coder(tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := assertEqual(parsedTx, tx); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(parsedTx.AsL1MessageTx(), tx.AsL1MessageTx()) {
+				t.Fatalf("L1 message mismatch: have %+v, want %+v", parsedTx.AsL1MessageTx(), tx.AsL1MessageTx())
+			}
+		}
+	}
+}
+
 func encodeDecodeJSON(tx *Transaction) (*Transaction, error) {
 	data, err := json.Marshal(tx)
 	if err != nil {
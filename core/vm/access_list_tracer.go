@@ -161,6 +161,12 @@ func (a *AccessListTracer) CaptureState(pc uint64, op OpCode, gas, cost uint64,
 	}
 }
 
+// CaptureHostCall captures the host functions of WASM contracts that touch
+// storage or addresses and adds them to the accesslist.
+func (a *AccessListTracer) CaptureHostCall(op OpCode, scope *ScopeContext, depth int) {
+	a.CaptureState(0, op, 0, 0, scope, nil, depth, nil)
+}
+
 // CaptureStateAfter for special needs, tracks SSTORE ops and records the storage change.
 func (*AccessListTracer) CaptureStateAfter(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
 }
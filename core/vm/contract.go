@@ -61,6 +61,8 @@ type Contract struct {
 
 	Gas   uint64
 	value *big.Int
+
+	eof bool // Validated EOF code, whose jumps are checked with the container
 }
 
 // NewContract returns a new contract environment for the execution of EVM.
@@ -94,6 +96,11 @@ func (c *Contract) validJumpdest(dest *uint256.Int) bool {
 	if OpCode(c.Code[udest]) != JUMPDEST {
 		return false
 	}
+	// The jumps of EOF code are checked against the code section with the
+	// container, so there's no need for the JUMPDEST analysis.
+	if c.eof {
+		return true
+	}
 	return c.isCode(udest)
 }
 
@@ -28,7 +28,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/crypto/blake2b"
 	"github.com/scroll-tech/go-ethereum/crypto/bls12381"
 	"github.com/scroll-tech/go-ethereum/crypto/bn256"
+	"github.com/scroll-tech/go-ethereum/crypto/poseidon"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/trie"
 
 	//lint:ignore SA1019 Needed for precompile
 	"golang.org/x/crypto/ripemd160"
@@ -103,6 +105,20 @@ var PrecompiledContractsArchimedes = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{8}): &bn256PairingIstanbul{},
 }
 
+// PrecompiledContractsPoseidon contains the pre-compiled contracts added by the
+// Poseidon release, on top of the set of the Ethereum or Scroll release which
+// is active.
+var PrecompiledContractsPoseidon = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{0x20}): &poseidonHash{},
+}
+
+// PrecompiledContractsZktrieProof contains the pre-compiled contracts added by
+// the zktrie proof release, on top of the set of the Ethereum or Scroll release
+// which is active.
+var PrecompiledContractsZktrieProof = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{0x21}): &zktrieProof{},
+}
+
 // PrecompiledContractsBLS contains the set of pre-compiled Ethereum
 // contracts specified in EIP-2537. These are exported for testing purposes.
 var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
@@ -118,11 +134,13 @@ var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
 }
 
 var (
-	PrecompiledAddressesArchimedes []common.Address
-	PrecompiledAddressesBerlin     []common.Address
-	PrecompiledAddressesIstanbul   []common.Address
-	PrecompiledAddressesByzantium  []common.Address
-	PrecompiledAddressesHomestead  []common.Address
+	PrecompiledAddressesZktrieProof []common.Address
+	PrecompiledAddressesPoseidon    []common.Address
+	PrecompiledAddressesArchimedes  []common.Address
+	PrecompiledAddressesBerlin      []common.Address
+	PrecompiledAddressesIstanbul    []common.Address
+	PrecompiledAddressesByzantium   []common.Address
+	PrecompiledAddressesHomestead   []common.Address
 )
 
 func init() {
@@ -141,10 +159,29 @@ func init() {
 	for k := range PrecompiledContractsArchimedes {
 		PrecompiledAddressesArchimedes = append(PrecompiledAddressesArchimedes, k)
 	}
+	for k := range PrecompiledContractsPoseidon {
+		PrecompiledAddressesPoseidon = append(PrecompiledAddressesPoseidon, k)
+	}
+	for k := range PrecompiledContractsZktrieProof {
+		PrecompiledAddressesZktrieProof = append(PrecompiledAddressesZktrieProof, k)
+	}
 }
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	addrs := activeReleasePrecompiles(rules)
+	if rules.IsPoseidon {
+		addrs = append(addrs[:len(addrs):len(addrs)], PrecompiledAddressesPoseidon...)
+	}
+	if rules.IsZktrieProof {
+		addrs = append(addrs[:len(addrs):len(addrs)], PrecompiledAddressesZktrieProof...)
+	}
+	return addrs
+}
+
+// activeReleasePrecompiles returns the precompiles of the active release,
+// without the ones added by the optional forks.
+func activeReleasePrecompiles(rules params.Rules) []common.Address {
 	switch {
 	case rules.IsArchimedes:
 		return PrecompiledAddressesArchimedes
@@ -1060,3 +1097,172 @@ func (c *bls12381MapG2) Run(input []byte) ([]byte, error) {
 	// Encode the G2 point to 256 bytes
 	return g.EncodePoint(r), nil
 }
+
+var (
+	errPoseidonInvalidInputLength = errors.New("invalid input length")
+	errPoseidonInvalidWidth       = errors.New("invalid width")
+	errPoseidonInvalidCapacity    = errors.New("invalid capacity")
+)
+
+// poseidonHash implements the poseidon hash of the zktrie as a native contract.
+type poseidonHash struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *poseidonHash) RequiredGas(input []byte) uint64 {
+	if len(input) < 64 {
+		return params.PoseidonBaseGas
+	}
+	width, _, err := decodePoseidonHeader(input[:64])
+	if err != nil {
+		return params.PoseidonBaseGas
+	}
+	return poseidonGas(width, uint64(len(input)-64)/32)
+}
+
+// Run hashes the input, encoded as
+//
+//	width (32 bytes) | capacity (32 bytes) | inputs (32 bytes each)
+//
+// A width of zero selects HashFixed over the inputs, with a width of one more
+// than their number and a zero capacity. Any other width selects HashWithCap.
+// Every input must be a big-endian field element. The output is the 32 byte hash.
+func (c *poseidonHash) Run(input []byte) ([]byte, error) {
+	if len(input) < 64 || len(input)%32 != 0 {
+		return nil, errPoseidonInvalidInputLength
+	}
+	width, nBytes, err := decodePoseidonHeader(input[:64])
+	if err != nil {
+		return nil, err
+	}
+	return runPoseidon(width, nBytes, input[64:])
+}
+
+// decodePoseidonHeader decodes the width and capacity words of the poseidon
+// precompile input.
+func decodePoseidonHeader(header []byte) (uint64, uint64, error) {
+	if !allZero(header[:31]) || int(header[31]) > len(poseidon.NROUNDSP)+1 || header[31] == 1 {
+		return 0, 0, errPoseidonInvalidWidth
+	}
+	if !allZero(header[32:56]) {
+		return 0, 0, errPoseidonInvalidCapacity
+	}
+	return uint64(header[31]), binary.BigEndian.Uint64(header[56:64]), nil
+}
+
+// runPoseidon hashes the 32 byte inputs with the given width and capacity,
+// where a zero width selects the fixed-size hash.
+func runPoseidon(width, nBytes uint64, data []byte) ([]byte, error) {
+	if len(data)%32 != 0 {
+		return nil, errPoseidonInvalidInputLength
+	}
+	if width == 0 && nBytes != 0 {
+		return nil, errPoseidonInvalidCapacity
+	}
+	inputs := make([][32]byte, len(data)/32)
+	for i := range inputs {
+		copy(inputs[i][:], data[i*32:])
+	}
+	var (
+		hash [32]byte
+		err  error
+	)
+	if width == 0 {
+		hash, err = poseidon.HashFixed32(inputs)
+	} else {
+		hash, err = poseidon.HashWithCap32(inputs, int(width), nBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hash[:], nil
+}
+
+// poseidonGas returns the gas needed to hash n inputs with the given width,
+// where a zero width selects the fixed-size hash. Every permutation is priced
+// by the width of its state.
+func poseidonGas(width, n uint64) uint64 {
+	permutations := poseidonPermutations(width, n)
+	if width == 0 {
+		width = n + 1
+	}
+	gas, overflow := math.SafeMul(permutations*width, params.PoseidonPerElementGas)
+	if overflow {
+		return math.MaxUint64
+	}
+	if gas, overflow = math.SafeAdd(gas, params.PoseidonBaseGas); overflow {
+		return math.MaxUint64
+	}
+	return gas
+}
+
+// poseidonPermutations returns the number of permutations hashing n inputs
+// with the given width takes, the fixed-size hash taking a single one.
+func poseidonPermutations(width, n uint64) uint64 {
+	if width > 1 && n > width-1 {
+		return (n + width - 2) / (width - 1)
+	}
+	return 1
+}
+
+var (
+	errZktrieProofInvalidInputLength = errors.New("invalid input length")
+	errZktrieProofTooManyNodes       = errors.New("too many proof nodes")
+)
+
+// zktrieProofMaxNodes bounds the number of nodes of a proof: a full path of the
+// 256 bit key, the leaf and the magic bytes.
+const zktrieProofMaxNodes = 256 + 2
+
+// zktrieProof implements zktrie merkle proof verification as a native contract.
+type zktrieProof struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *zktrieProof) RequiredGas(input []byte) uint64 {
+	nodes, err := splitZktrieProof(input)
+	if err != nil {
+		return params.ZktrieProofBaseGas
+	}
+	return params.ZktrieProofBaseGas + uint64(len(nodes))*params.ZktrieProofPerNodeGas
+}
+
+// Run verifies a zktrie proof, encoded as
+//
+//	root (32 bytes) | key (32 bytes) | [node length (2 bytes) | node]...
+//
+// where the nodes are those written by ZkTrie.Prove and the key is a storage
+// slot, or an account address padded with zeros on the right. A present key
+// returns its value, an absent one returns no data and an invalid proof fails.
+func (c *zktrieProof) Run(input []byte) ([]byte, error) {
+	nodes, err := splitZktrieProof(input)
+	if err != nil {
+		return nil, err
+	}
+	value, err := trie.VerifyProofSMTNodes(common.BytesToHash(input[:32]), input[32:64], nodes)
+	if err != nil {
+		return nil, err
+	}
+	return common.CopyBytes(value), nil
+}
+
+// splitZktrieProof returns the length prefixed proof nodes of the input.
+func splitZktrieProof(input []byte) ([][]byte, error) {
+	if len(input) < 64 {
+		return nil, errZktrieProofInvalidInputLength
+	}
+	var nodes [][]byte
+	for data := input[64:]; len(data) > 0; {
+		if len(data) < 2 {
+			return nil, errZktrieProofInvalidInputLength
+		}
+		size := int(binary.BigEndian.Uint16(data))
+		if len(data) < 2+size {
+			return nil, errZktrieProofInvalidInputLength
+		}
+		if len(nodes) == zktrieProofMaxNodes {
+			return nil, errZktrieProofTooManyNodes
+		}
+		nodes = append(nodes, data[2:2+size])
+		data = data[2+size:]
+	}
+	return nodes, nil
+}
@@ -21,10 +21,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -65,6 +67,8 @@ var allPrecompiles = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{16}):   &bls12381Pairing{},
 	common.BytesToAddress([]byte{17}):   &bls12381MapG1{},
 	common.BytesToAddress([]byte{18}):   &bls12381MapG2{},
+	common.BytesToAddress([]byte{0x20}): &poseidonHash{},
+	common.BytesToAddress([]byte{0x21}): &zktrieProof{},
 }
 
 // EIP-152 test vectors
@@ -272,6 +276,14 @@ func TestPrecompileBlake2FMalformedInput(t *testing.T) {
 
 func TestPrecompiledEcrecover(t *testing.T) { testJson("ecRecover", "01", t) }
 
+func TestPrecompiledPoseidon(t *testing.T)      { testJson("poseidon", "20", t) }
+func TestPrecompiledPoseidonFail(t *testing.T)  { testJsonFail("poseidon", "20", t) }
+func BenchmarkPrecompiledPoseidon(b *testing.B) { benchJson("poseidon", "20", b) }
+
+func TestPrecompiledZktrieProof(t *testing.T)      { testJson("zktrieProof", "21", t) }
+func TestPrecompiledZktrieProofFail(t *testing.T)  { testJsonFail("zktrieProof", "21", t) }
+func BenchmarkPrecompiledZktrieProof(b *testing.B) { benchJson("zktrieProof", "21", b) }
+
 func testJson(name, addr string, t *testing.T) {
 	tests, err := loadJson(name)
 	if err != nil {
@@ -391,3 +403,76 @@ func BenchmarkPrecompiledBLS12381G2MultiExpWorstCase(b *testing.B) {
 	}
 	benchmarkPrecompiled("0f", testcase, b)
 }
+
+// Tests that the scroll precompiles are only active from their own fork on.
+func TestPrecompileForks(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ArchimedesBlock = big.NewInt(0)
+	config.PoseidonBlock = big.NewInt(10)
+	config.ZktrieProofBlock = big.NewInt(20)
+
+	tests := []struct {
+		number int64
+		addr   byte
+		active bool
+	}{
+		{9, 0x20, false},
+		{10, 0x20, true},
+		{10, 0x09, false}, // blake2f is disabled since Archimedes
+		{10, 0x21, false},
+		{20, 0x20, true},
+		{20, 0x21, true},
+	}
+	for i, test := range tests {
+		var (
+			addr  = common.BytesToAddress([]byte{test.addr})
+			rules = config.Rules(big.NewInt(test.number))
+		)
+		if _, ok := (&EVM{chainRules: rules}).precompile(addr); ok != test.active {
+			t.Errorf("test %d: precompile %x at block %d: have active %v, want %v", i, addr, test.number, ok, test.active)
+		}
+		active := false
+		for _, precompile := range ActivePrecompiles(rules) {
+			active = active || precompile == addr
+		}
+		if active != test.active {
+			t.Errorf("test %d: active precompiles at block %d: have %x active %v, want %v", i, test.number, addr, active, test.active)
+		}
+	}
+}
+
+// Tests that the poseidon fork adds its precompile to the set of the active
+// release, even if it isn't Archimedes.
+func TestPrecompilePoseidonWithoutArchimedes(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ArchimedesBlock = nil
+	config.PoseidonBlock = big.NewInt(10)
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("unexpected fork ordering error: %v", err)
+	}
+	rules := config.Rules(big.NewInt(10))
+	for _, addr := range []byte{0x02, 0x03, 0x09, 0x20} {
+		if _, ok := (&EVM{chainRules: rules}).precompile(common.BytesToAddress([]byte{addr})); !ok {
+			t.Errorf("precompile %#x not active", addr)
+		}
+	}
+	if have, want := len(ActivePrecompiles(rules)), len(PrecompiledAddressesBerlin)+1; have != want {
+		t.Errorf("active precompiles mismatch: have %d, want %d", have, want)
+	}
+}
+
+// Tests that the zktrie proof precompile doesn't enable the poseidon one, even
+// with rules the fork ordering check rejects.
+func TestPrecompileZktrieProofWithoutPoseidon(t *testing.T) {
+	rules := params.Rules{IsBerlin: true, IsArchimedes: true, IsZktrieProof: true}
+	evm := &EVM{chainRules: rules}
+	if _, ok := evm.precompile(common.BytesToAddress([]byte{0x21})); !ok {
+		t.Error("zktrie proof precompile not active")
+	}
+	if _, ok := evm.precompile(common.BytesToAddress([]byte{0x20})); ok {
+		t.Error("poseidon precompile active without its fork")
+	}
+	if have, want := len(ActivePrecompiles(rules)), len(PrecompiledAddressesArchimedes)+1; have != want {
+		t.Errorf("active precompiles mismatch: have %d, want %d", have, want)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// The EVM Object Format container, version 1:
+//
+//	magic (0xEF00) || version (0x01) ||
+//	kind_code (0x01) || code_size (u16) || [kind_data (0x02) || data_size (u16)] || terminator (0x00) ||
+//	code || [data]
+//
+// The code is validated at deploy time: all its instructions are defined, it
+// ends with a terminating instruction, every JUMP and JUMPI is immediately
+// preceded by a PUSH of a JUMPDEST of the code section, and the stack height
+// of every instruction is the same on all the paths reaching it. Offsets, such
+// as the jump destinations and the PC, are relative to the container.
+const (
+	eofVersion1 byte = 0x01
+
+	eofKindTerminator byte = 0x00
+	eofKindCode       byte = 0x01
+	eofKindData       byte = 0x02
+)
+
+var eofMagic = []byte{0xEF, 0x00}
+
+var (
+	errEOFTruncatedHeader    = fmt.Errorf("%w: truncated header", ErrInvalidEOF)
+	errEOFUnsupportedVersion = fmt.Errorf("%w: unsupported version", ErrInvalidEOF)
+	errEOFInvalidSection     = fmt.Errorf("%w: invalid section headers", ErrInvalidEOF)
+	errEOFEmptySection       = fmt.Errorf("%w: empty section", ErrInvalidEOF)
+	errEOFSizeMismatch       = fmt.Errorf("%w: container size mismatch", ErrInvalidEOF)
+	errEOFUndefinedOpcode    = fmt.Errorf("%w: undefined instruction", ErrInvalidEOF)
+	errEOFTruncatedPush      = fmt.Errorf("%w: truncated push", ErrInvalidEOF)
+	errEOFMissingTerminator  = fmt.Errorf("%w: code must end with a terminating instruction", ErrInvalidEOF)
+	errEOFDynamicJump        = fmt.Errorf("%w: jump destination must be pushed right before the jump", ErrInvalidEOF)
+	errEOFInvalidJump        = fmt.Errorf("%w: invalid jump destination", ErrInvalidEOF)
+	errEOFStackUnderflow     = fmt.Errorf("%w: stack underflow", ErrInvalidEOF)
+	errEOFStackOverflow      = fmt.Errorf("%w: stack overflow", ErrInvalidEOF)
+	errEOFStackMismatch      = fmt.Errorf("%w: inconsistent stack height", ErrInvalidEOF)
+)
+
+// hasEOFMagic returns whether the code starts with the EOF magic.
+func hasEOFMagic(code []byte) bool {
+	return bytes.HasPrefix(code, eofMagic)
+}
+
+// eofContainer locates the sections of an EOF container.
+type eofContainer struct {
+	codeOffset, codeSize int
+	dataOffset, dataSize int
+}
+
+// parseEOF decodes the header of an EOF container, checking the sections fill
+// the container exactly.
+func parseEOF(code []byte) (*eofContainer, error) {
+	if !hasEOFMagic(code) {
+		return nil, ErrInvalidEOF
+	}
+	if len(code) < 3 {
+		return nil, errEOFTruncatedHeader
+	}
+	if code[2] != eofVersion1 {
+		return nil, errEOFUnsupportedVersion
+	}
+	var (
+		c     eofContainer
+		pos   = 3
+		sizes []int
+	)
+	for {
+		if pos >= len(code) {
+			return nil, errEOFTruncatedHeader
+		}
+		kind := code[pos]
+		pos++
+		if kind == eofKindTerminator {
+			break
+		}
+		// the code section comes first, optionally followed by the data section
+		if int(kind) != len(sizes)+1 || kind > eofKindData {
+			return nil, errEOFInvalidSection
+		}
+		if pos+2 > len(code) {
+			return nil, errEOFTruncatedHeader
+		}
+		size := int(binary.BigEndian.Uint16(code[pos:]))
+		if size == 0 {
+			return nil, errEOFEmptySection
+		}
+		sizes = append(sizes, size)
+		pos += 2
+	}
+	if len(sizes) == 0 {
+		return nil, errEOFInvalidSection
+	}
+	c.codeOffset, c.codeSize = pos, sizes[0]
+	c.dataOffset = c.codeOffset + c.codeSize
+	if len(sizes) > 1 {
+		c.dataSize = sizes[1]
+	}
+	if c.dataOffset+c.dataSize != len(code) {
+		return nil, errEOFSizeMismatch
+	}
+	return &c, nil
+}
+
+// isTerminating returns whether the instruction ends the execution.
+func isTerminating(op OpCode) bool {
+	switch op {
+	case STOP, RETURN, REVERT, INVALID, SELFDESTRUCT:
+		return true
+	}
+	return false
+}
+
+// validateEOF validates the EOF container against the instructions of the jump
+// table.
+func validateEOF(code []byte, jt *JumpTable) error {
+	c, err := parseEOF(code)
+	if err != nil {
+		return err
+	}
+	var (
+		start = c.codeOffset
+		end   = c.codeOffset + c.codeSize
+		// jump destinations of the code section, and the jumps to check
+		jumpdests = make(map[int]bool)
+		targets   = make(map[int]int) // jump -> destination
+		last      OpCode
+	)
+	// check the instructions, and collect the static jumps
+	for pos, prev := start, -1; pos < end; {
+		op := OpCode(code[pos])
+		if jt[op] == nil && op != INVALID {
+			return fmt.Errorf("%w %#x at %d", errEOFUndefinedOpcode, byte(op), pos)
+		}
+		size := 1
+		if op.IsPush() {
+			size += int(op - PUSH1 + 1)
+			if pos+size > end {
+				return errEOFTruncatedPush
+			}
+		}
+		switch op {
+		case JUMPDEST:
+			jumpdests[pos] = true
+		case JUMP, JUMPI:
+			if prev < 0 || !OpCode(code[prev]).IsPush() {
+				return fmt.Errorf("%w at %d", errEOFDynamicJump, pos)
+			}
+			// the container size is a u16, so are the destinations within it
+			dest := bytes.TrimLeft(code[prev+1:pos], "\x00")
+			if len(dest) > 2 {
+				return fmt.Errorf("%w at %d", errEOFInvalidJump, pos)
+			}
+			target := 0
+			for _, b := range dest {
+				target = target<<8 | int(b)
+			}
+			targets[pos] = target
+		}
+		last, prev = op, pos
+		pos += size
+	}
+	if !isTerminating(last) {
+		return errEOFMissingTerminator
+	}
+	for pos, dest := range targets {
+		if !jumpdests[dest] {
+			return fmt.Errorf("%w at %d", errEOFInvalidJump, pos)
+		}
+	}
+	return validateEOFStack(code[:end], start, targets, jt)
+}
+
+// validateEOFStack follows all the paths of the code section, checking the
+// stack height of every instruction is within bounds and the same on all the
+// paths reaching it.
+func validateEOFStack(code []byte, start int, targets map[int]int, jt *JumpTable) error {
+	var (
+		heights = map[int]int{start: 0}
+		work    = []int{start}
+	)
+	visit := func(pos, height int) error {
+		if h, ok := heights[pos]; ok {
+			if h != height {
+				return fmt.Errorf("%w at %d: %d and %d", errEOFStackMismatch, pos, h, height)
+			}
+			return nil
+		}
+		heights[pos] = height
+		work = append(work, pos)
+		return nil
+	}
+	for len(work) > 0 {
+		pos := work[len(work)-1]
+		work = work[:len(work)-1]
+		height := heights[pos]
+
+		op := OpCode(code[pos])
+		operation := jt[op]
+		if operation == nil {
+			// INVALID, the only undefined instruction allowed
+			continue
+		}
+		if height < operation.minStack {
+			return fmt.Errorf("%w at %d", errEOFStackUnderflow, pos)
+		}
+		if height > operation.maxStack {
+			return fmt.Errorf("%w at %d", errEOFStackOverflow, pos)
+		}
+		if isTerminating(op) {
+			continue
+		}
+		// maxStack is the stack limit, less the items pushed net of the pops
+		height += int(params.StackLimit) - operation.maxStack
+
+		next := pos + 1
+		if op.IsPush() {
+			next += int(op - PUSH1 + 1)
+		}
+		if op == JUMP || op == JUMPI {
+			if err := visit(targets[pos], height); err != nil {
+				return err
+			}
+			if op == JUMP {
+				continue
+			}
+		}
+		// the code ends with a terminating instruction, so next is in the code
+		if err := visit(next, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eofCodeOffset returns the offset of the code section of the contract, if it's
+// a valid EOF container. The code isn't trusted to be validated at deploy time,
+// as the genesis allocations and the state overrides aren't deployed by a
+// creation, so it's validated once per code hash.
+func (in *EVMInterpreter) eofCodeOffset(contract *Contract) (uint64, bool) {
+	valid, ok := in.eofValid[contract.CodeHash]
+	if !ok || contract.CodeHash == (common.Hash{}) {
+		valid = validateEOF(contract.Code, (*JumpTable)(&in.cfg.JumpTable)) == nil
+		if contract.CodeHash != (common.Hash{}) {
+			if in.eofValid == nil {
+				in.eofValid = make(map[common.Hash]bool)
+			}
+			in.eofValid[contract.CodeHash] = valid
+		}
+	}
+	if !valid {
+		return 0, false
+	}
+	c, _ := parseEOF(contract.Code)
+	return uint64(c.codeOffset), true
+}
+
+// validateEOF validates the EOF container for the EVM interpreter.
+func (evm *EVM) validateEOF(code []byte) error {
+	in, ok := evm.interpreter.(*EVMInterpreter)
+	if !ok {
+		return ErrInvalidEOF
+	}
+	return validateEOF(code, (*JumpTable)(&in.cfg.JumpTable))
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func TestValidateEOF(t *testing.T) {
+	jt := newLondonInstructionSet()
+	for i, tt := range []struct {
+		code string
+		err  error
+	}{
+		{"0xef000101000500600a565b00", nil},                     // PUSH1 10 JUMP JUMPDEST STOP
+		{"0xef00010100010200020000aabb", nil},                   // STOP, with data
+		{"0xef000101000300600000", nil},                         // PUSH1 0 STOP
+		{"0xef0002010001000000", errEOFUnsupportedVersion},      // version 2
+		{"0xef000102000100aa", errEOFInvalidSection},            // data section first
+		{"0xef0001010000000000", errEOFEmptySection},            // empty code section
+		{"0xef00010100", errEOFTruncatedHeader},                 // truncated code size
+		{"0xef0001010006006000600a565b00", errEOFSizeMismatch},  // code size too large
+		{"0xef0001010002000c00", errEOFUndefinedOpcode},         // 0x0c
+		{"0xef000101000100ef", errEOFUndefinedOpcode},           // 0xef
+		{"0xef00010100010061", errEOFTruncatedPush},             // PUSH2 without data
+		{"0xef0001010001005b", errEOFMissingTerminator},         // JUMPDEST
+		{"0xef0001010003005b5600", errEOFDynamicJump},           // JUMPDEST JUMP STOP
+		{"0xef00010100050060095600fe", errEOFInvalidJump},       // PUSH1 9 JUMP STOP INVALID
+		{"0xef0001010005006009565b00", errEOFInvalidJump},       // jump to the JUMP
+		{"0xef00010100060061ffff565b00", errEOFInvalidJump},     // jump out of the code
+		{"0xef0001010002000100", errEOFStackUnderflow},          // ADD
+		{"0xef000101000100f3", errEOFStackUnderflow},            // RETURN
+		{"0xef0001010007005b600160075600", errEOFStackMismatch}, // loop pushing an item
+	} {
+		err := validateEOF(hexutil.MustDecode(tt.code), &jt)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidEOF) {
+			t.Errorf("test %d: error %v doesn't wrap ErrInvalidEOF", i, err)
+		}
+	}
+}
+
+func TestEOFCreateInvalidInitCode(t *testing.T) {
+	eof := *params.AllEthashProtocolChanges
+	eof.EOFBlock = big.NewInt(0)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	vmenv := NewEVM(vmctx, TxContext{}, statedb, &eof, Config{})
+
+	// RETURN without its operands
+	initcode := hexutil.MustDecode("0xef000101000100f3")
+	_, address, gas, err := vmenv.Create(AccountRef(common.Address{}), initcode, 100000, new(big.Int))
+	if !errors.Is(err, errEOFStackUnderflow) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errEOFStackUnderflow)
+	}
+	if gas != 0 {
+		t.Errorf("gas left mismatch: have %d, want 0", gas)
+	}
+	if statedb.Exist(address) {
+		t.Errorf("account created for invalid initcode")
+	}
+}
+
+func TestEOFUnvalidatedCode(t *testing.T) {
+	eof := *params.AllEthashProtocolChanges
+	eof.EOFBlock = big.NewInt(0)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	vmenv := NewEVM(vmctx, TxContext{}, statedb, &eof, Config{})
+
+	// code stored without a creation, as genesis allocations are, is validated
+	// before it runs as an EOF container
+	for i, tt := range []struct {
+		code  string
+		halts bool
+	}{
+		{"0xef000101000500600a565b00", false},    // PUSH1 10 JUMP JUMPDEST STOP
+		{"0xef000101000700600b56615b0000", true}, // PUSH1 11 JUMP PUSH2 0x5b00 STOP, jumping into the push data
+	} {
+		addr := common.Address{byte(i + 1)}
+		statedb.SetCode(addr, hexutil.MustDecode(tt.code))
+		// the validity is cached for the second call
+		for j := 0; j < 2; j++ {
+			_, _, err := vmenv.Call(AccountRef(common.Address{}), addr, nil, 100000, new(big.Int))
+			// invalid containers run as legacy code, halting on the magic
+			var opErr *ErrInvalidOpCode
+			if halted := errors.As(err, &opErr); halted != tt.halts {
+				t.Errorf("test %d: halted on the magic: have %v, want %v (%v)", i, halted, tt.halts, err)
+			}
+		}
+	}
+}
@@ -36,11 +36,13 @@ var (
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrExecutionReverted        = errors.New("execution reverted")
 	ErrMaxCodeSizeExceeded      = errors.New("max code size exceeded")
+	ErrMaxInitCodeSizeExceeded  = errors.New("max initcode size exceeded")
 	ErrInvalidJump              = errors.New("invalid jump destination")
 	ErrWriteProtection          = errors.New("write protection")
 	ErrReturnDataOutOfBounds    = errors.New("return data out of bounds")
 	ErrGasUintOverflow          = errors.New("gas uint64 overflow")
 	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
+	ErrInvalidEOF               = errors.New("invalid EOF container")
 	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
 	ErrBadInputParams           = errors.New("bad input params")
 	ErrBadWasmBinary            = errors.New("bas wasm binary")
@@ -60,11 +62,13 @@ var evmUnwrapableErrors = map[error]bool{
 	ErrContractAddressCollision: true,
 	ErrExecutionReverted:        true,
 	ErrMaxCodeSizeExceeded:      true,
+	ErrMaxInitCodeSizeExceeded:  true,
 	ErrInvalidJump:              true,
 	ErrWriteProtection:          true,
 	ErrReturnDataOutOfBounds:    true,
 	ErrGasUintOverflow:          true,
 	ErrInvalidCode:              true,
+	ErrInvalidEOF:               true,
 	ErrNonceUintOverflow:        true,
 	ErrBadInputParams:           true,
 	ErrBadWasmBinary:            true,
@@ -57,8 +57,20 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	default:
 		precompiles = PrecompiledContractsHomestead
 	}
-	p, ok := precompiles[addr]
-	return p, ok
+	if p, ok := precompiles[addr]; ok {
+		return p, true
+	}
+	if evm.chainRules.IsPoseidon {
+		if p, ok := PrecompiledContractsPoseidon[addr]; ok {
+			return p, true
+		}
+	}
+	if evm.chainRules.IsZktrieProof {
+		if p, ok := PrecompiledContractsZktrieProof[addr]; ok {
+			return p, true
+		}
+	}
+	return nil, false
 }
 
 // BlockContext provides the EVM with auxiliary information. Once provided
@@ -434,6 +446,9 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
+	if evm.chainRules.IsWebAssembly && len(codeAndHash.code) > evm.chainConfig.Scroll.WasmInitCodeSizeLimit() {
+		return nil, common.Address{}, gas, ErrMaxInitCodeSizeExceeded
+	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	if nonce+1 < nonce {
 		return nil, common.Address{}, gas, ErrNonceUintOverflow
@@ -476,15 +491,40 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 
 	start := time.Now()
 
-	ret, err := evm.interpreter.Run(contract, nil, false)
+	// EOF initcode is validated before it runs, and may only deploy EOF code.
+	var (
+		ret []byte
+		err error
+	)
+	eofInitCode := evm.chainRules.IsEOF && hasEOFMagic(codeAndHash.code)
+	if eofInitCode {
+		err = evm.validateEOF(codeAndHash.code)
+	}
+	if err == nil && evm.chainRules.IsWebAssembly {
+		err = evm.prepareWasmInitCode(contract)
+	}
+	if err == nil {
+		ret, err = evm.interpreter.Run(contract, nil, false)
+	}
+	if err == nil && evm.chainRules.IsEOF && (eofInitCode || hasEOFMagic(ret)) {
+		err = evm.validateEOF(ret)
+	}
+
+	// WASM code is stored with the gas computation injected, has its own size
+	// limit and is priced by the complexity of the module.
+	var wasmDeployGas uint64
+	if err == nil && evm.chainRules.IsWebAssembly {
+		ret, wasmDeployGas, err = evm.prepareWasmCode(ret)
+	}
 
 	// Check whether the max code size has been exceeded, assign err if the case.
-	if err == nil && evm.chainRules.IsEIP158 && len(ret) > params.MaxCodeSize {
+	if err == nil && evm.chainRules.IsEIP158 && !evm.chainRules.IsWebAssembly && len(ret) > params.MaxCodeSize {
 		err = ErrMaxCodeSizeExceeded
 	}
 
-	// Reject code starting with 0xEF if EIP-3541 is enabled.
-	if err == nil && len(ret) >= 1 && ret[0] == 0xEF && evm.chainRules.IsLondon {
+	// Reject code starting with 0xEF if EIP-3541 is enabled, unless it's a valid
+	// EOF container.
+	if err == nil && len(ret) >= 1 && ret[0] == 0xEF && evm.chainRules.IsLondon && !(evm.chainRules.IsEOF && hasEOFMagic(ret)) {
 		err = ErrInvalidCode
 	}
 
@@ -493,7 +533,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	// be stored due to not enough gas set an error and let it be handled
 	// by the error checking condition below.
 	if err == nil {
-		createDataGas := uint64(len(ret)) * params.CreateDataGas
+		createDataGas := uint64(len(ret))*params.CreateDataGas + wasmDeployGas
 		if contract.UseGas(createDataGas) {
 			evm.StateDB.SetCode(address, ret)
 		} else {
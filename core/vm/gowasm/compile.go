@@ -0,0 +1,515 @@
+package gowasm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// branch describes a jump, with the operands to keep on top of the stack and
+// the ones to drop below them.
+type branch struct {
+	target uint32 // index of the instruction to continue with
+	drop   uint32
+	keep   uint32
+}
+
+// instr is a decoded instruction. The operand stack height is known statically
+// at every instruction, so branches are resolved to plain jumps at compile time.
+type instr struct {
+	op  uint16
+	pc  uint32 // offset of the opcode in the binary
+	imm uint64 // index, constant, memory offset or branch table of the instruction
+	br  branch
+}
+
+// hasParams reports whether the immediate of the instruction is reported in
+// the execution trace.
+func (in *instr) hasParams() bool {
+	switch in.op {
+	case opBr, opBrIf, opBrTable, opCall, opCallIndirect,
+		opLocalGet, opLocalSet, opLocalTee, opGlobalGet, opGlobalSet,
+		opI32Const, opI64Const:
+		return true
+	}
+	return in.op >= opI32Load && in.op <= opI64Store32
+}
+
+// ctrlFrame is a block being compiled.
+type ctrlFrame struct {
+	op          uint16
+	params      []byte
+	results     []byte
+	height      int   // type stack height at the start of the block, without the params
+	start       int   // index of the first instruction of the block
+	fixups      []int // instructions branching to the end of the block
+	tableFixups [][2]int
+	unreachable bool
+}
+
+// labelTypes returns the types of the operands passed by a branch to the frame.
+func (f *ctrlFrame) labelTypes() []byte {
+	if f.op == opLoop {
+		return f.params
+	}
+	return f.results
+}
+
+// compiler validates a function body and translates it to instructions.
+type compiler struct {
+	m      *module
+	fn     *function
+	r      *reader
+	locals []byte
+	vals   []byte // types of the operands, zero if unknown in unreachable code
+	ctrls  []ctrlFrame
+	code   []instr
+	tables [][]branch
+	height int
+}
+
+// compileFunctions compiles all function bodies of the module.
+func (m *module) compileFunctions() error {
+	var base uint32
+	for i, fn := range m.funcs {
+		fn.codeBase = base
+		if err := m.compile(fn); err != nil {
+			return fmt.Errorf("function %d: %w", len(m.imports)+i, err)
+		}
+		base += uint32(len(fn.code))
+	}
+	return nil
+}
+
+func (m *module) compile(fn *function) error {
+	typ := &m.types[fn.typeIdx]
+	c := &compiler{
+		m:      m,
+		fn:     fn,
+		r:      &reader{buf: fn.body},
+		locals: append([]byte{}, typ.params...),
+	}
+	for n := c.r.u32(); n > 0 && c.r.err == nil; n-- {
+		count, t := c.r.u32(), c.r.valueType()
+		if uint64(len(c.locals))+uint64(count) > maxLocals {
+			return errors.New("too many locals")
+		}
+		for i := uint32(0); i < count; i++ {
+			c.locals = append(c.locals, t)
+		}
+	}
+	c.ctrls = []ctrlFrame{{op: opBlock, results: typ.results}}
+	for len(c.ctrls) > 0 && c.r.err == nil {
+		c.step()
+	}
+	if c.r.err != nil {
+		return c.r.err
+	}
+	if c.r.pos != len(c.r.buf) {
+		return errors.New("instructions after the function end")
+	}
+	if len(c.ctrls) > 0 {
+		return errors.New("function body must end")
+	}
+	fn.locals = c.locals[len(typ.params):]
+	fn.code, fn.tables, fn.maxHeight = c.code, c.tables, c.height
+	return nil
+}
+
+func (c *compiler) fail(format string, args ...interface{}) {
+	c.r.fail(fmt.Errorf(format, args...))
+}
+
+func (c *compiler) push(t byte) {
+	c.vals = append(c.vals, t)
+	if len(c.vals) > c.height {
+		c.height = len(c.vals)
+	}
+}
+
+func (c *compiler) pushAll(types []byte) {
+	for _, t := range types {
+		c.push(t)
+	}
+}
+
+// pop removes an operand of the wanted type, or of any type if want is zero.
+func (c *compiler) pop(want byte) byte {
+	f := &c.ctrls[len(c.ctrls)-1]
+	if len(c.vals) == f.height {
+		if !f.unreachable {
+			c.fail("type mismatch: operand stack underflow")
+		}
+		return want
+	}
+	t := c.vals[len(c.vals)-1]
+	c.vals = c.vals[:len(c.vals)-1]
+	if want != 0 && t != 0 && t != want {
+		c.fail("type mismatch: expected 0x%x, got 0x%x", want, t)
+	}
+	if t == 0 {
+		return want
+	}
+	return t
+}
+
+func (c *compiler) popAll(types []byte) {
+	for i := len(types) - 1; i >= 0; i-- {
+		c.pop(types[i])
+	}
+}
+
+// unreachable marks the rest of the block as dead code.
+func (c *compiler) unreachable() {
+	f := &c.ctrls[len(c.ctrls)-1]
+	c.vals = c.vals[:f.height]
+	f.unreachable = true
+}
+
+func (c *compiler) emit(in instr) int {
+	c.code = append(c.code, in)
+	return len(c.code) - 1
+}
+
+// label returns the frame targeted by a branch of the given depth.
+func (c *compiler) label(depth uint32) *ctrlFrame {
+	if depth >= uint32(len(c.ctrls)) {
+		c.fail("unknown label %d", depth)
+		return nil
+	}
+	return &c.ctrls[len(c.ctrls)-1-int(depth)]
+}
+
+// branchTo computes the branch to the frame from the current stack height.
+// Forward targets are patched once the end of the frame is reached.
+func (c *compiler) branchTo(f *ctrlFrame) branch {
+	keep := len(f.labelTypes())
+	drop := len(c.vals) - keep - f.height
+	if drop < 0 {
+		// only possible in dead code
+		drop = 0
+	}
+	b := branch{drop: uint32(drop), keep: uint32(keep)}
+	if f.op == opLoop {
+		b.target = uint32(f.start)
+	}
+	return b
+}
+
+// blockType decodes the type of a block.
+func (c *compiler) blockType() (params, results []byte) {
+	if c.r.pos >= len(c.r.buf) {
+		c.r.fail(errUnexpectedEnd)
+		return nil, nil
+	}
+	switch t := c.r.buf[c.r.pos]; t {
+	case 0x40:
+		c.r.pos++
+		return nil, nil
+	case valueTypeI32, valueTypeI64, valueTypeF32, valueTypeF64:
+		return nil, []byte{c.r.valueType()}
+	}
+	idx := c.r.signed(33)
+	if idx < 0 || idx >= int64(len(c.m.types)) {
+		c.fail("unknown type %d", idx)
+		return nil, nil
+	}
+	return c.m.types[idx].params, c.m.types[idx].results
+}
+
+// memarg decodes the immediates of a memory access.
+func (c *compiler) memarg(op uint16) uint64 {
+	align, offset := c.r.u32(), c.r.u32()
+	if align >= 32 || 1<<align > memAccessSize(op) {
+		c.fail("alignment must not be larger than natural")
+	}
+	c.needMemory()
+	return uint64(offset)
+}
+
+func (c *compiler) needMemory() {
+	if c.m.memory == nil {
+		c.fail("unknown memory 0")
+	}
+}
+
+// step compiles a single instruction.
+func (c *compiler) step() {
+	var (
+		r   = c.r
+		pc  = c.fn.bodyPc + uint32(r.pos)
+		op  = uint16(r.byte())
+		top = &c.ctrls[len(c.ctrls)-1]
+	)
+	if op == opPrefixFC {
+		sub := r.u32()
+		if sub > 0xff {
+			c.fail("unknown opcode 0xfc %d", sub)
+			return
+		}
+		op = opPrefixFC<<8 | uint16(sub)
+	}
+	in := instr{op: op, pc: pc}
+	switch op {
+	case opBlock, opLoop, opIf:
+		params, results := c.blockType()
+		if op == opIf {
+			c.pop(valueTypeI32)
+		}
+		c.popAll(params)
+		idx := c.emit(in)
+		f := ctrlFrame{op: op, params: params, results: results, height: len(c.vals), start: idx + 1}
+		if op == opIf {
+			f.fixups = []int{idx}
+		}
+		c.ctrls = append(c.ctrls, f)
+		c.pushAll(params)
+
+	case opElse:
+		if top.op != opIf {
+			c.fail("else without if")
+			return
+		}
+		c.popAll(top.results)
+		if len(c.vals) != top.height {
+			c.fail("type mismatch: values remaining on stack at the end of block")
+			return
+		}
+		idx := c.emit(in)
+		// a false condition of the if continues after the else
+		c.code[top.fixups[0]].br.target = uint32(idx + 1)
+		top.fixups[0] = idx
+		top.op, top.unreachable = opElse, false
+		c.pushAll(top.params)
+
+	case opEnd:
+		c.popAll(top.results)
+		if len(c.vals) != top.height {
+			c.fail("type mismatch: values remaining on stack at the end of block")
+			return
+		}
+		if top.op == opIf && len(top.params) != len(top.results) {
+			c.fail("type mismatch: if without else must not change the stack")
+			return
+		}
+		idx := c.emit(in)
+		// branches to the function frame return through its end
+		target := uint32(idx + 1)
+		if len(c.ctrls) == 1 {
+			target = uint32(idx)
+		}
+		for _, i := range top.fixups {
+			c.code[i].br.target = target
+		}
+		for _, f := range top.tableFixups {
+			c.tables[f[0]][f[1]].target = target
+		}
+		results := top.results
+		c.ctrls = c.ctrls[:len(c.ctrls)-1]
+		c.pushAll(results)
+
+	case opBr:
+		depth := r.u32()
+		f := c.label(depth)
+		if f == nil {
+			return
+		}
+		in.imm, in.br = uint64(depth), c.branchTo(f)
+		c.addFixup(f, c.emit(in))
+		c.popAll(f.labelTypes())
+		c.unreachable()
+
+	case opBrIf:
+		depth := r.u32()
+		c.pop(valueTypeI32)
+		f := c.label(depth)
+		if f == nil {
+			return
+		}
+		in.imm, in.br = uint64(depth), c.branchTo(f)
+		c.addFixup(f, c.emit(in))
+		c.popAll(f.labelTypes())
+		c.pushAll(f.labelTypes())
+
+	case opBrTable:
+		n := r.count()
+		depths := make([]uint32, n+1)
+		for i := range depths {
+			depths[i] = r.u32()
+		}
+		c.pop(valueTypeI32)
+		def := c.label(depths[n])
+		if def == nil {
+			return
+		}
+		table := make([]branch, len(depths))
+		in.imm = uint64(len(c.tables))
+		c.tables = append(c.tables, table)
+		for i, depth := range depths {
+			f := c.label(depth)
+			if f == nil {
+				return
+			}
+			if len(f.labelTypes()) != len(def.labelTypes()) {
+				c.fail("type mismatch: br_table targets with different arities")
+				return
+			}
+			table[i] = c.branchTo(f)
+			if f.op != opLoop {
+				f.tableFixups = append(f.tableFixups, [2]int{int(in.imm), i})
+			}
+		}
+		c.emit(in)
+		c.popAll(def.labelTypes())
+		c.unreachable()
+
+	case opReturn:
+		f := &c.ctrls[0]
+		in.br = c.branchTo(f)
+		c.addFixup(f, c.emit(in))
+		c.popAll(f.results)
+		c.unreachable()
+
+	case opUnreachable:
+		c.emit(in)
+		c.unreachable()
+
+	case opCall:
+		idx := r.u32()
+		if idx >= uint32(len(c.m.imports)+len(c.m.funcs)) {
+			c.fail("unknown function %d", idx)
+			return
+		}
+		t := c.m.funcType(idx)
+		c.popAll(t.params)
+		c.pushAll(t.results)
+		in.imm = uint64(idx)
+		c.emit(in)
+
+	case opCallIndirect:
+		idx := r.u32()
+		if table := r.byte(); table != 0 || c.m.table == nil {
+			c.fail("unknown table %d", table)
+			return
+		}
+		if idx >= uint32(len(c.m.types)) {
+			c.fail("unknown type %d", idx)
+			return
+		}
+		t := &c.m.types[idx]
+		c.pop(valueTypeI32)
+		c.popAll(t.params)
+		c.pushAll(t.results)
+		in.imm = uint64(idx)
+		c.emit(in)
+
+	case opDrop:
+		c.pop(0)
+		c.emit(in)
+
+	case opSelect, opTypedSelect:
+		var want byte
+		if op == opTypedSelect {
+			if n := r.u32(); n != 1 {
+				c.fail("invalid result arity %d", n)
+				return
+			}
+			want = r.valueType()
+		}
+		c.pop(valueTypeI32)
+		t := c.pop(want)
+		t = c.pop(t)
+		c.push(t)
+		c.emit(in)
+
+	case opLocalGet, opLocalSet, opLocalTee:
+		idx := r.u32()
+		if idx >= uint32(len(c.locals)) {
+			c.fail("unknown local %d", idx)
+			return
+		}
+		t := c.locals[idx]
+		if op != opLocalGet {
+			c.pop(t)
+		}
+		if op != opLocalSet {
+			c.push(t)
+		}
+		in.imm = uint64(idx)
+		c.emit(in)
+
+	case opGlobalGet, opGlobalSet:
+		idx := r.u32()
+		if idx >= uint32(len(c.m.globals)) {
+			c.fail("unknown global %d", idx)
+			return
+		}
+		g := &c.m.globals[idx]
+		if op == opGlobalGet {
+			c.push(g.typ)
+		} else {
+			if !g.mutable {
+				c.fail("global %d is immutable", idx)
+				return
+			}
+			c.pop(g.typ)
+		}
+		in.imm = uint64(idx)
+		c.emit(in)
+
+	case opMemorySize, opMemoryGrow:
+		if b := r.byte(); b != 0 {
+			c.fail("zero byte expected")
+		}
+		c.needMemory()
+		c.simple(in)
+
+	case opMemoryCopy, opMemoryFill:
+		if b := r.byte(); b != 0 {
+			c.fail("zero byte expected")
+		}
+		if op == opMemoryCopy {
+			if b := r.byte(); b != 0 {
+				c.fail("zero byte expected")
+			}
+		}
+		c.needMemory()
+		c.simple(in)
+
+	case opI32Const:
+		in.imm = uint64(uint32(r.s32()))
+		c.simple(in)
+
+	case opI64Const:
+		in.imm = uint64(r.s64())
+		c.simple(in)
+
+	default:
+		if op >= opI32Load && op <= opI64Store32 && !isFloatOp(op) {
+			in.imm = c.memarg(op)
+		}
+		c.simple(in)
+	}
+}
+
+// simple compiles an instruction whose operand types only depend on the opcode.
+func (c *compiler) simple(in instr) {
+	params, results, ok := opSignature(in.op)
+	if !ok {
+		if isFloatOp(in.op) {
+			c.r.fail(errFloat)
+		} else {
+			c.fail("unsupported opcode 0x%x", in.op)
+		}
+		return
+	}
+	c.popAll(params)
+	c.pushAll(results)
+	c.emit(in)
+}
+
+// addFixup records a forward branch, resolved at the end of the frame.
+func (c *compiler) addFixup(f *ctrlFrame, idx int) {
+	if f.op != opLoop {
+		f.fixups = append(f.fixups, idx)
+	}
+}
@@ -0,0 +1,126 @@
+//go:build cgo
+
+package gowasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	zkwasm_wasmi "github.com/wasm0/zkwasm-wasmi"
+)
+
+// diffEngine is the API shared by both engines.
+type diffEngine interface {
+	SetWasmBinary(binary []byte)
+	ComputeResult() (int32, error)
+	MemoryData() ([]byte, error)
+}
+
+// diffRun executes the binary, with host functions recording their calls.
+func diffRun(m *module, bin []byte, engine diffEngine, register func(name string, params int, i64 bool, record func(args []int64))) (int32, []string, []byte, error) {
+	var calls []string
+	for _, imp := range m.imports {
+		var (
+			name   = imp.name
+			params = m.types[imp.typeIdx].params
+		)
+		register(name, len(params), len(params) > 0 && params[0] == valueTypeI64, func(args []int64) {
+			calls = append(calls, fmt.Sprintf("%s%v", name, args))
+		})
+	}
+	engine.SetWasmBinary(bin)
+	code, err := engine.ComputeResult()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	mem, _ := engine.MemoryData()
+	return code, calls, mem, nil
+}
+
+// legacyBinaries export a main function taking params, following an older ABI.
+// Neither engine executes them, and as wasmi aborts the process on them they
+// are only checked to be rejected by the pure-Go engine.
+var legacyBinaries = map[string]bool{
+	"hello.wasm":          true,
+	"hello_injected.wasm": true,
+}
+
+// TestDifferential runs the test binaries through both the pure-Go engine and
+// the wasmi binding, comparing the results, host calls and final memory.
+func TestDifferential(t *testing.T) {
+	files, err := filepath.Glob("../testdata/wasm/*.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			bin, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := decodeModule(bin)
+			if err != nil {
+				t.Fatalf("unsupported binary: %v", err)
+			}
+			goEngine := NewEngine()
+			goCode, goCalls, goMem, err := diffRun(m, bin, goEngine, func(name string, params int, i64 bool, record func(args []int64)) {
+				if i64 {
+					goEngine.RegisterHostFnI64(name, params, func(args []int64) int32 {
+						record(args)
+						return 0
+					})
+					return
+				}
+				goEngine.RegisterHostFnI32(name, params, func(args []int32) int32 {
+					record(widen(args))
+					return 0
+				})
+			})
+			if legacyBinaries[filepath.Base(file)] {
+				if !errors.Is(err, errMainType) {
+					t.Fatalf("legacy binary not rejected: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("not executable: %v", err)
+			}
+			wasmi := zkwasm_wasmi.NewWasmEngine()
+			code, calls, mem, _ := diffRun(m, bin, wasmi, func(name string, params int, i64 bool, record func(args []int64)) {
+				if i64 {
+					wasmi.RegisterHostFnI64(name, params, func(args []int64) int32 {
+						record(args)
+						return 0
+					})
+					return
+				}
+				wasmi.RegisterHostFnI32(name, params, func(args []int32) int32 {
+					record(widen(args))
+					return 0
+				})
+			})
+			if goCode != code {
+				t.Errorf("result mismatch: go %d, wasmi %d", goCode, code)
+			}
+			if !reflect.DeepEqual(goCalls, calls) {
+				t.Errorf("host calls mismatch: go %v, wasmi %v", goCalls, calls)
+			}
+			if !bytes.Equal(goMem, mem) {
+				t.Errorf("memory mismatch")
+			}
+		})
+	}
+}
+
+func widen(args []int32) []int64 {
+	res := make([]int64, len(args))
+	for i, arg := range args {
+		res[i] = int64(arg)
+	}
+	return res
+}
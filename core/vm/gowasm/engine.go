@@ -0,0 +1,417 @@
+// Package gowasm implements a deterministic WASM interpreter in pure Go. It
+// supports the integer subset of WASM used by the contracts, and exposes the
+// same API as the wasmi binding so both can back the WASM interpreter of the
+// EVM.
+package gowasm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	errNoBinary = errors.New("no wasm binary set")
+	errNoMain   = errors.New("main function not found")
+	errMainType = errors.New("main function must not have params or results")
+)
+
+type hostFunc struct {
+	params int
+	fn32   func(params []int32) int32
+	fn64   func(params []int64) int32
+}
+
+type traceMemory struct {
+	Offset uint32 `json:"offset"`
+	Len    uint32 `json:"len"`
+	Data   string `json:"data"`
+}
+
+type traceLog struct {
+	Pc            uint32        `json:"pc"`
+	SourcePc      uint32        `json:"source_pc"`
+	Name          string        `json:"name"`
+	Opcode        uint16        `json:"opcode"`
+	StackDrop     *uint32       `json:"stack_drop,omitempty"`
+	StackKeep     *uint32       `json:"stack_keep,omitempty"`
+	Params        []uint64      `json:"params,omitempty"`
+	MemoryChanges []traceMemory `json:"memory_changes,omitempty"`
+	Stack         []uint64      `json:"stack,omitempty"`
+}
+
+type functionMeta struct {
+	FnIndex        uint32 `json:"fn_index"`
+	MaxStackHeight uint32 `json:"max_stack_height"`
+	NumLocals      uint32 `json:"num_locals"`
+	FnName         string `json:"fn_name"`
+}
+
+type globalVariable struct {
+	Index uint32 `json:"index"`
+	Value uint64 `json:"value"`
+}
+
+type trace struct {
+	GlobalMemory    []traceMemory    `json:"global_memory"`
+	Logs            []traceLog       `json:"logs"`
+	GlobalVariables []globalVariable `json:"global_variables"`
+	FnMetas         []functionMeta   `json:"fn_metas"`
+}
+
+// Engine executes WASM binaries. The host functions are registered once, and
+// are linked to the imports of every binary: by name for the "env" module, and
+// by the qualified "module.name" for the other modules.
+//
+// Executions may be nested, a host function calling back into the engine to
+// run another binary. The memory accessors operate on the innermost execution.
+type Engine struct {
+	hosts map[string]*hostFunc
+	onLog func(jsonTrace string)
+
+	pending *instance // instance set up by SetWasmBinary
+	err     error     // error raised by SetWasmBinary
+	running []*instance
+	last    *instance // last instance done executing
+	logs    []traceLog
+}
+
+// NewEngine creates an engine without any host function.
+func NewEngine() *Engine {
+	return &Engine{hosts: make(map[string]*hostFunc)}
+}
+
+// HostName returns the name a host function imported from the module must be
+// registered with.
+func HostName(module, name string) string {
+	if module == "env" {
+		return name
+	}
+	return module + "." + name
+}
+
+func (e *Engine) register(name string, host *hostFunc) bool {
+	if _, ok := e.hosts[name]; ok {
+		return false
+	}
+	e.hosts[name] = host
+	return true
+}
+
+// RegisterHostFnI32 registers a host function taking i32 params. A nonzero
+// result halts the execution, and is returned by ComputeResult.
+func (e *Engine) RegisterHostFnI32(name string, paramsCount int, cb func(params []int32) int32) bool {
+	return e.register(name, &hostFunc{params: paramsCount, fn32: cb})
+}
+
+// RegisterHostFnI64 registers a host function taking i64 params. A nonzero
+// result halts the execution, and is returned by ComputeResult.
+func (e *Engine) RegisterHostFnI64(name string, paramsCount int, cb func(params []int64) int32) bool {
+	return e.register(name, &hostFunc{params: paramsCount, fn64: cb})
+}
+
+// RegisterCallbackOnAfterItemAddedToLogs enables the execution trace, calling
+// back with the JSON encoded state before every instruction.
+func (e *Engine) RegisterCallbackOnAfterItemAddedToLogs(cb func(jsonTrace string)) {
+	e.onLog = cb
+}
+
+// Module is a decoded and compiled binary. It isn't modified by the executions,
+// so it may be shared by any number of engines.
+type Module struct {
+	m *module
+}
+
+// Compile decodes, validates and compiles a binary.
+func Compile(binary []byte) (*Module, error) {
+	m, err := decodeModule(binary)
+	if err != nil {
+		return nil, err
+	}
+	if m.memory != nil && m.memory.min > maxMemoryPages {
+		return nil, fmt.Errorf("memory of %d pages exceeds the limit of %d", m.memory.min, maxMemoryPages)
+	}
+	if m.table != nil && m.table.min > maxStackSize {
+		return nil, fmt.Errorf("table of %d elements exceeds the limit", m.table.min)
+	}
+	if err := m.compileFunctions(); err != nil {
+		return nil, err
+	}
+	return &Module{m: m}, nil
+}
+
+// Allocation returns the number of memory pages and of table elements
+// allocated by every instantiation of the module.
+func (m *Module) Allocation() (memoryPages, tableSize uint64) {
+	if m.m.memory != nil {
+		memoryPages = uint64(m.m.memory.min)
+	}
+	if m.m.table != nil {
+		tableSize = uint64(m.m.table.min)
+	}
+	return memoryPages, tableSize
+}
+
+// SetWasmBinary decodes, validates and instantiates the binary to execute.
+// Errors are reported by ComputeResult.
+func (e *Engine) SetWasmBinary(binary []byte) {
+	m, err := Compile(binary)
+	if err != nil {
+		e.reset()
+		e.pending, e.err = nil, err
+		return
+	}
+	e.SetWasmModule(m)
+}
+
+// SetWasmModule instantiates the compiled module to execute. Errors are
+// reported by ComputeResult.
+func (e *Engine) SetWasmModule(m *Module) {
+	e.reset()
+	e.pending, e.err = e.instantiate(m.m)
+}
+
+func (e *Engine) reset() {
+	if len(e.running) == 0 {
+		e.logs = nil
+	}
+	e.last = nil
+}
+
+func (e *Engine) instantiate(m *module) (*instance, error) {
+	in := &instance{engine: e, module: m}
+	for _, imp := range m.imports {
+		host := e.hosts[HostName(imp.module, imp.name)]
+		if host == nil {
+			return nil, fmt.Errorf("unknown import %s.%s", imp.module, imp.name)
+		}
+		want := valueTypeI32
+		if host.fn64 != nil {
+			want = valueTypeI64
+		}
+		typ := &m.types[imp.typeIdx]
+		if len(typ.params) != host.params || len(typ.results) != 0 {
+			return nil, fmt.Errorf("incompatible import type for %s.%s", imp.module, imp.name)
+		}
+		for _, t := range typ.params {
+			if t != want {
+				return nil, fmt.Errorf("incompatible import type for %s.%s", imp.module, imp.name)
+			}
+		}
+		in.hosts = append(in.hosts, host)
+	}
+	if m.memory != nil {
+		in.memory = make([]byte, m.memory.min*pageSize)
+		in.maxPages = maxMemoryPages
+		if m.memory.hasMax && m.memory.max < in.maxPages {
+			in.maxPages = m.memory.max
+		}
+	}
+	for _, seg := range m.datas {
+		if uint64(seg.offset)+uint64(len(seg.data)) > uint64(len(in.memory)) {
+			return nil, errors.New("data segment does not fit")
+		}
+		copy(in.memory[seg.offset:], seg.data)
+	}
+	if m.table != nil {
+		in.table = make([]int64, m.table.min)
+		for i := range in.table {
+			in.table[i] = -1
+		}
+	}
+	for _, seg := range m.elems {
+		if uint64(seg.offset)+uint64(len(seg.funcs)) > uint64(len(in.table)) {
+			return nil, errors.New("elements segment does not fit")
+		}
+		for i, idx := range seg.funcs {
+			in.table[int(seg.offset)+i] = int64(idx)
+		}
+	}
+	in.globals = make([]uint64, len(m.globals))
+	for i, g := range m.globals {
+		in.globals[i] = g.init
+	}
+	return in, nil
+}
+
+// ComputeResult executes the start function, if any, and the exported main
+// function of the binary. It returns the result of the host function which
+// halted the execution, or zero. Traps are returned as errors, while panics
+// raised by host functions are propagated.
+func (e *Engine) ComputeResult() (code int32, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	in := e.pending
+	if in == nil {
+		return 0, errNoBinary
+	}
+	exp, ok := in.module.exports["main"]
+	if !ok || exp.kind != externalFunc {
+		return 0, errNoMain
+	}
+	if t := in.module.funcType(exp.index); len(t.params) != 0 || len(t.results) != 0 {
+		return 0, errMainType
+	}
+	in.depth, in.inHost = 0, 0
+	e.running = append(e.running, in)
+	defer func() {
+		e.running = e.running[:len(e.running)-1]
+		e.last = in
+		if r := recover(); r != nil {
+			switch v := r.(type) {
+			case exit:
+				code, err = v.code, nil
+			case trap:
+				err = v
+			default:
+				if in.inHost > 0 {
+					panic(r)
+				}
+				err = fmt.Errorf("wasm execution failed: %v", r)
+			}
+		}
+	}()
+	if in.module.start != nil {
+		in.call(*in.module.start, 0)
+	}
+	in.call(exp.index, 0)
+	return 0, nil
+}
+
+// current returns the instance the memory accessors operate on: the innermost
+// execution, else the last one done, else the instance not executed yet.
+func (e *Engine) current() *instance {
+	if len(e.running) > 0 {
+		return e.running[len(e.running)-1]
+	}
+	if e.last != nil {
+		return e.last
+	}
+	return e.pending
+}
+
+// GetLastPc returns the offset in the binary of the last executed instruction.
+func (e *Engine) GetLastPc() (int32, error) {
+	in := e.current()
+	if in == nil {
+		return 0, errNoBinary
+	}
+	return int32(in.lastPc), nil
+}
+
+// MemoryData returns a copy of the memory.
+func (e *Engine) MemoryData() ([]byte, error) {
+	in := e.current()
+	if in == nil {
+		return nil, errNoBinary
+	}
+	return append([]byte{}, in.memory...), nil
+}
+
+// MemorySize returns the size of the memory in bytes.
+func (e *Engine) MemorySize() (uint32, error) {
+	in := e.current()
+	if in == nil {
+		return 0, errNoBinary
+	}
+	return uint32(len(in.memory)), nil
+}
+
+// ReadMemory returns a copy of size bytes of the memory at offset.
+func (e *Engine) ReadMemory(offset, size uint32) ([]byte, error) {
+	in := e.current()
+	if in == nil {
+		return nil, errNoBinary
+	}
+	if uint64(offset)+uint64(size) > uint64(len(in.memory)) {
+		return nil, trapMemoryAccess
+	}
+	return append([]byte{}, in.memory[offset:offset+size]...), nil
+}
+
+// WriteMemory writes data to the memory at offset. Unlike the wasmi binding,
+// which only records the change in its trace, the memory is actually modified.
+func (e *Engine) WriteMemory(offset uint32, data []byte) error {
+	in := e.current()
+	if in == nil {
+		return errNoBinary
+	}
+	if uint64(offset)+uint64(len(data)) > uint64(len(in.memory)) {
+		return trapMemoryAccess
+	}
+	in.store(uint64(offset), 0, data)
+	return nil
+}
+
+// DumpTrace returns the JSON encoded trace of the last execution: the data
+// segments, the executed instructions if the trace is enabled, the initial
+// values of the globals and the functions.
+func (e *Engine) DumpTrace() ([]byte, error) {
+	in := e.current()
+	if in == nil {
+		return nil, errNoBinary
+	}
+	t := &trace{
+		GlobalMemory:    []traceMemory{},
+		Logs:            e.logs,
+		GlobalVariables: []globalVariable{},
+		FnMetas:         []functionMeta{},
+	}
+	if t.Logs == nil {
+		t.Logs = []traceLog{}
+	}
+	m := in.module
+	for _, seg := range m.datas {
+		t.GlobalMemory = append(t.GlobalMemory, traceMemory{seg.offset, uint32(len(seg.data)), hex.EncodeToString(seg.data)})
+	}
+	for i, g := range m.globals {
+		t.GlobalVariables = append(t.GlobalVariables, globalVariable{uint32(i), g.init})
+	}
+	names := make(map[uint32]string)
+	for name, exp := range m.exports {
+		if exp.kind == externalFunc {
+			names[exp.index] = name
+		}
+	}
+	for i, fn := range m.funcs {
+		idx := uint32(len(m.imports) + i)
+		t.FnMetas = append(t.FnMetas, functionMeta{
+			FnIndex:        idx,
+			MaxStackHeight: uint32(fn.maxHeight),
+			NumLocals:      uint32(len(m.types[fn.typeIdx].params) + len(fn.locals)),
+			FnName:         names[idx],
+		})
+	}
+	return json.Marshal(t)
+}
+
+// trace reports the state before the execution of an instruction.
+func (in *instance) trace(fn *function, ins *instr, idx uint32, stack []uint64) {
+	l := traceLog{
+		Pc:       fn.codeBase + idx,
+		SourcePc: ins.pc,
+		Name:     opNames[ins.op],
+		Opcode:   ins.op,
+		Stack:    append([]uint64{}, stack...),
+	}
+	switch ins.op {
+	case opBr, opBrIf, opReturn:
+		l.StackDrop, l.StackKeep = &ins.br.drop, &ins.br.keep
+	}
+	if ins.hasParams() {
+		l.Params = []uint64{ins.imm}
+	}
+	for _, c := range in.changes {
+		l.MemoryChanges = append(l.MemoryChanges, traceMemory{c.offset, uint32(len(c.data)), hex.EncodeToString(c.data)})
+	}
+	in.changes = nil
+
+	e := in.engine
+	e.logs = append(e.logs, l)
+	if e.onLog != nil {
+		data, _ := json.Marshal(&l)
+		e.onLog(string(data))
+	}
+}
@@ -0,0 +1,393 @@
+package gowasm
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func uleb(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+func section(id byte, payload ...byte) []byte {
+	return append(append([]byte{id}, uleb(len(payload))...), payload...)
+}
+
+// buildModule assembles a module exporting the body as main, with a page of
+// memory and the `_evm_result(i64)` host function imported as function 0.
+func buildModule(body ...byte) []byte {
+	bin := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	bin = append(bin, section(1, 0x02, 0x60, 0x00, 0x00, 0x60, 0x01, 0x7e, 0x00)...)
+	bin = append(bin, section(2, append(append([]byte{0x01, 0x03}, "env"...), append(append([]byte{0x0b}, "_evm_result"...), 0x00, 0x01)...)...)...)
+	bin = append(bin, section(3, 0x01, 0x00)...)
+	bin = append(bin, section(5, 0x01, 0x00, 0x01)...)
+	bin = append(bin, section(7, append(append([]byte{0x01, 0x04}, "main"...), 0x00, 0x01)...)...)
+	code := append(uleb(len(body)), body...)
+	return append(bin, section(10, append([]byte{0x01}, code...)...)...)
+}
+
+// run executes the binary, returning the values passed to `_evm_result`.
+func run(t *testing.T, bin []byte) ([]int64, int32, error) {
+	t.Helper()
+	var results []int64
+	e := NewEngine()
+	e.RegisterHostFnI64("_evm_result", 1, func(params []int64) int32 {
+		results = append(results, params[0])
+		return 0
+	})
+	e.SetWasmBinary(bin)
+	code, err := e.ComputeResult()
+	return results, code, err
+}
+
+func TestLoop(t *testing.T) {
+	// sums 1 to 10 in a loop
+	results, code, err := run(t, buildModule(
+		0x01, 0x02, 0x7e, // 2 i64 locals
+		0x02, 0x40, 0x03, 0x40, // block, loop
+		0x20, 0x00, 0x42, 0x0a, 0x5a, 0x0d, 0x01, // exit if i >= 10
+		0x20, 0x00, 0x42, 0x01, 0x7c, 0x22, 0x00, // i += 1
+		0x20, 0x01, 0x7c, 0x21, 0x01, // acc += i
+		0x0c, 0x00, 0x0b, 0x0b, // br loop, end, end
+		0x20, 0x01, 0x10, 0x00, 0x0b, // _evm_result(acc)
+	))
+	if err != nil || code != 0 {
+		t.Fatalf("execution failed: code %d, err %v", code, err)
+	}
+	if want := []int64{55}; !reflect.DeepEqual(results, want) {
+		t.Errorf("wrong results: have %v, want %v", results, want)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	for selector, want := range []int64{100, 9, 9} {
+		results, code, err := run(t, buildModule(
+			0x00,
+			0x02, 0x7e, 0x02, 0x40, 0x02, 0x40, // block (result i64), block, block
+			0x41, byte(selector), 0x0e, 0x01, 0x00, 0x01, 0x0b, // br_table [0] 1, end
+			0x42, 0xe4, 0x00, 0x0c, 0x01, 0x0b, // br 1 with 100, end
+			0x42, 0x07, 0x41, 0x00, 0x04, 0x7e, 0x42, 0x01, 0x05, 0x42, 0x02, 0x0b, 0x7c, // 7 + (0 ? 1 : 2)
+			0x0b, 0x10, 0x00, 0x0b, // end, _evm_result
+		))
+		if err != nil || code != 0 {
+			t.Fatalf("selector %d: execution failed: code %d, err %v", selector, code, err)
+		}
+		if !reflect.DeepEqual(results, []int64{want}) {
+			t.Errorf("selector %d: wrong results: have %v, want %d", selector, results, want)
+		}
+	}
+}
+
+func TestTraps(t *testing.T) {
+	tests := []struct {
+		body []byte
+		err  error
+	}{
+		{[]byte{0x00, 0x41, 0x01, 0x41, 0x00, 0x6e, 0x1a, 0x0b}, trapDivideByZero},
+		{[]byte{0x00, 0x00, 0x0b}, trapUnreachable},
+		{[]byte{0x00, 0x41, 0x80, 0x80, 0x04, 0x28, 0x02, 0x00, 0x1a, 0x0b}, trapMemoryAccess},
+		{[]byte{0x00, 0x10, 0x01, 0x0b}, trapCallStack},
+	}
+	for i, tt := range tests {
+		if _, _, err := run(t, buildModule(tt.body...)); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: wrong error: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	tests := [][]byte{
+		{0x00, 0x43, 0x00, 0x00, 0x00, 0x00, 0x1a, 0x0b}, // f32.const
+		{0x00, 0x42, 0x01, 0x45, 0x1a, 0x0b},             // i32.eqz on an i64
+		{0x00, 0x1a, 0x0b},                               // drop on an empty stack
+		{0x00, 0x0c, 0x01, 0x0b},                         // unknown label
+		{0x00, 0x41, 0x00},                               // missing end
+	}
+	for i, body := range tests {
+		if _, _, err := run(t, buildModule(body...)); err == nil {
+			t.Errorf("test %d: invalid code executed", i)
+		}
+	}
+}
+
+func TestGreeting(t *testing.T) {
+	bin, err := os.ReadFile("../testdata/wasm/greeting.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ret []byte
+	e := NewEngine()
+	e.RegisterHostFnI32("_evm_return", 2, func(params []int32) int32 {
+		mem, _ := e.MemoryData()
+		ret = mem[params[0] : params[0]+params[1]]
+		return 3
+	})
+	e.SetWasmBinary(bin)
+	if code, err := e.ComputeResult(); code != 3 || err != nil {
+		t.Fatalf("wrong result: code %d, err %v", code, err)
+	}
+	if string(ret) != "Hello, World" {
+		t.Errorf("wrong return data: %q", ret)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	bin, err := os.ReadFile("../testdata/wasm/simple.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	e := NewEngine()
+	e.RegisterCallbackOnAfterItemAddedToLogs(func(jsonTrace string) {
+		var l traceLog
+		if err := json.Unmarshal([]byte(jsonTrace), &l); err != nil {
+			t.Fatalf("invalid trace: %v", err)
+		}
+		names = append(names, l.Name)
+	})
+	e.SetWasmBinary(bin)
+	if code, err := e.ComputeResult(); code != 0 || err != nil {
+		t.Fatalf("wrong result: code %d, err %v", code, err)
+	}
+	want := []string{"i32_const", "i32_const", "i32_const", "i32_add", "i32_add", "drop", "end"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("wrong trace: have %v, want %v", names, want)
+	}
+	dump, err := e.DumpTrace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tr trace
+	if err := json.Unmarshal(dump, &tr); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Logs) != len(want) || len(tr.FnMetas) != 1 || tr.FnMetas[0].FnName != "main" || tr.FnMetas[0].MaxStackHeight != 3 {
+		t.Errorf("wrong trace dump: %s", dump)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	body := []byte{0x00, 0x42, 0x01, 0x10, 0x00, 0x0b}
+	funcs, codeSize, err := Measure(buildModule(body...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the code section holds the function count and the sized body
+	if funcs != 1 || codeSize != uint64(2+len(body)) {
+		t.Errorf("wrong measure: %d functions, code size %d", funcs, codeSize)
+	}
+	if _, _, err := Measure([]byte{0x00}); err == nil {
+		t.Error("invalid binary measured")
+	}
+}
+
+// runInjected executes the binary with the gas injected, returning the values
+// passed to `_evm_result` and the gas charged.
+func runInjected(t *testing.T, bin []byte) ([]int64, uint64, error) {
+	t.Helper()
+	injected, err := InjectGas(bin, "env", "gas", GasCosts{Instruction: 2})
+	if err != nil {
+		t.Fatalf("injection failed: %v", err)
+	}
+	var (
+		results []int64
+		gas     uint64
+	)
+	e := NewEngine()
+	e.RegisterHostFnI64("_evm_result", 1, func(params []int64) int32 {
+		results = append(results, params[0])
+		return 0
+	})
+	e.RegisterHostFnI64("gas", 1, func(params []int64) int32 {
+		gas += uint64(params[0])
+		return 0
+	})
+	e.SetWasmBinary(injected)
+	_, err = e.ComputeResult()
+	return results, gas, err
+}
+
+// countInstructions returns the number of instructions executed by the binary.
+func countInstructions(t *testing.T, bin []byte) uint64 {
+	t.Helper()
+	var count uint64
+	e := NewEngine()
+	e.RegisterHostFnI64("_evm_result", 1, func(params []int64) int32 { return 0 })
+	e.RegisterCallbackOnAfterItemAddedToLogs(func(string) { count++ })
+	e.SetWasmBinary(bin)
+	if _, err := e.ComputeResult(); err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	return count
+}
+
+func TestInjectGas(t *testing.T) {
+	bodies := [][]byte{
+		// the loop of TestLoop
+		{
+			0x01, 0x02, 0x7e,
+			0x02, 0x40, 0x03, 0x40,
+			0x20, 0x00, 0x42, 0x0a, 0x5a, 0x0d, 0x01,
+			0x20, 0x00, 0x42, 0x01, 0x7c, 0x22, 0x00,
+			0x20, 0x01, 0x7c, 0x21, 0x01,
+			0x0c, 0x00, 0x0b, 0x0b,
+			0x20, 0x01, 0x10, 0x00, 0x0b,
+		},
+	}
+	// the branches of TestBranches
+	for selector := 0; selector < 3; selector++ {
+		bodies = append(bodies, []byte{
+			0x00,
+			0x02, 0x7e, 0x02, 0x40, 0x02, 0x40,
+			0x41, byte(selector), 0x0e, 0x01, 0x00, 0x01, 0x0b,
+			0x42, 0xe4, 0x00, 0x0c, 0x01, 0x0b,
+			0x42, 0x07, 0x41, 0x00, 0x04, 0x7e, 0x42, 0x01, 0x05, 0x42, 0x02, 0x0b, 0x7c,
+			0x0b, 0x10, 0x00, 0x0b,
+		})
+	}
+	for i, body := range bodies {
+		bin := buildModule(body...)
+		want, _, err := run(t, bin)
+		if err != nil {
+			t.Fatalf("test %d: execution failed: %v", i, err)
+		}
+		results, gas, err := runInjected(t, bin)
+		if err != nil {
+			t.Fatalf("test %d: injected execution failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(results, want) {
+			t.Errorf("test %d: wrong results: have %v, want %v", i, results, want)
+		}
+		// every executed instruction is charged once
+		if count := countInstructions(t, bin); gas != 2*count {
+			t.Errorf("test %d: wrong gas: have %d, want %d", i, gas, 2*count)
+		}
+	}
+}
+
+func TestInjectGasIndices(t *testing.T) {
+	// main calls itself by its index, which is moved by the injected import
+	if _, gas, err := runInjected(t, buildModule(0x00, 0x10, 0x01, 0x0b)); !errors.Is(err, trapCallStack) || gas == 0 {
+		t.Errorf("wrong execution: gas %d, err %v", gas, err)
+	}
+	bin, err := os.ReadFile("../testdata/wasm/greeting.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	injected, err := InjectGas(bin, "env", "gas", GasCosts{Instruction: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ret []byte
+	e := NewEngine()
+	e.RegisterHostFnI32("_evm_return", 2, func(params []int32) int32 {
+		mem, _ := e.MemoryData()
+		ret = mem[params[0] : params[0]+params[1]]
+		return 3
+	})
+	e.RegisterHostFnI64("gas", 1, func(params []int64) int32 { return 0 })
+	e.SetWasmBinary(injected)
+	if code, err := e.ComputeResult(); code != 3 || err != nil {
+		t.Fatalf("wrong result: code %d, err %v", code, err)
+	}
+	if string(ret) != "Hello, World" {
+		t.Errorf("wrong return data: %q", ret)
+	}
+	if _, err := InjectGas(buildModule(0x00, 0x43, 0x00, 0x00, 0x00, 0x00, 0x1a, 0x0b), "env", "gas", GasCosts{Instruction: 1}); err == nil {
+		t.Error("invalid code injected")
+	}
+}
+
+// runMetered executes the binary with the gas injected, halting it once more
+// than limit gas is charged. It returns the gas charged, the exit code and the
+// memory left by the execution.
+func runMetered(t *testing.T, bin []byte, costs GasCosts, limit uint64) (uint64, int32, []byte) {
+	t.Helper()
+	injected, err := InjectGas(bin, "env", "gas", costs)
+	if err != nil {
+		t.Fatalf("injection failed: %v", err)
+	}
+	var gas uint64
+	e := NewEngine()
+	e.RegisterHostFnI64("_evm_result", 1, func(params []int64) int32 { return 0 })
+	e.RegisterHostFnI64("gas", 1, func(params []int64) int32 {
+		if gas += uint64(params[0]); gas > limit {
+			return 1
+		}
+		return 0
+	})
+	e.SetWasmBinary(injected)
+	code, err := e.ComputeResult()
+	if err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	mem, _ := e.MemoryData()
+	return gas, code, mem
+}
+
+func TestInjectGasMemory(t *testing.T) {
+	i32 := func(n int64) []byte { return append([]byte{0x41}, appendSLEB(nil, n)...) }
+	grow := func(pages int64) []byte {
+		return append(append([]byte{0x00}, i32(pages)...), 0x40, 0x00, 0x1a, 0x0b)
+	}
+	fill := func(size int64) []byte {
+		body := append(append([]byte{0x00}, i32(0)...), i32(1)...)
+		return append(append(body, i32(size)...), 0xfc, 0x0b, 0x00, 0x0b)
+	}
+	cpy := func(size int64) []byte {
+		body := append(append([]byte{0x00}, i32(0)...), i32(pageSize/2)...)
+		return append(append(body, i32(size)...), 0xfc, 0x0a, 0x00, 0x00, 0x0b)
+	}
+	costs := GasCosts{Instruction: 1, MemoryPage: 1000, MemoryWord: 3}
+	tests := []struct {
+		body    []byte
+		limit   uint64
+		dynamic uint64 // gas charged on top of the instructions
+		halted  bool
+		memSize int
+	}{
+		{grow(2), 1_000_000, 2 * 1000, false, 3 * pageSize},
+		{grow(0xffff), 1_000_000, 0xffff * 1000, true, pageSize},
+		{fill(pageSize), 1_000_000, pageSize / 32 * 3, false, pageSize},
+		{fill(pageSize), 1000, pageSize / 32 * 3, true, pageSize},
+		{fill(100), 1_000_000, 4 * 3, false, pageSize},
+		{cpy(pageSize / 2), 1_000_000, pageSize / 64 * 3, false, pageSize},
+		{cpy(0x7fffffff), 1_000_000, 0x4000000 * 3, true, pageSize},
+	}
+	for i, tt := range tests {
+		bin := buildModule(tt.body...)
+		gas, code, mem := runMetered(t, bin, costs, tt.limit)
+		if code != 0 != tt.halted {
+			t.Errorf("test %d: wrong exit code %d", i, code)
+		}
+		if tt.halted {
+			if gas < tt.dynamic {
+				t.Errorf("test %d: wrong gas: have %d, want at least %d", i, gas, tt.dynamic)
+			}
+		} else if instrGas, _, _ := runMetered(t, bin, GasCosts{Instruction: costs.Instruction}, math.MaxUint64); gas != instrGas+tt.dynamic {
+			t.Errorf("test %d: wrong gas: have %d, want %d", i, gas, instrGas+tt.dynamic)
+		}
+		// the memory is only written once its gas is charged
+		if len(mem) != tt.memSize {
+			t.Errorf("test %d: wrong memory size: have %d, want %d", i, len(mem), tt.memSize)
+		}
+		if tt.halted && mem[0] != 0 {
+			t.Errorf("test %d: memory written out of gas", i)
+		}
+	}
+}
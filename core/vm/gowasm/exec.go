@@ -0,0 +1,559 @@
+package gowasm
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+const (
+	pageSize = 65536
+	maxPages = 65536
+
+	// maxMemoryPages caps the growth of the memory to 64 MiB, growing it any
+	// further fails as if the allocation was refused.
+	maxMemoryPages = 1024
+
+	maxCallDepth = 1024
+	maxStackSize = 1 << 20
+)
+
+// trap is an error aborting the execution of the WASM code.
+type trap string
+
+func (t trap) Error() string { return "wasm trap: " + string(t) }
+
+const (
+	trapUnreachable      = trap("unreachable")
+	trapMemoryAccess     = trap("out of bounds memory access")
+	trapDivideByZero     = trap("integer divide by zero")
+	trapIntegerOverflow  = trap("integer overflow")
+	trapUndefinedElement = trap("undefined element")
+	trapUninitialized    = trap("uninitialized element")
+	trapIndirectCallType = trap("indirect call type mismatch")
+	trapCallStack        = trap("call stack exhausted")
+	trapStackOverflow    = trap("stack overflow")
+)
+
+// exit halts the execution with the nonzero result of a host function.
+type exit struct {
+	code int32
+}
+
+type memoryChange struct {
+	offset uint32
+	data   []byte
+}
+
+// instance is an instantiated module with its execution state.
+type instance struct {
+	engine   *Engine
+	module   *module
+	hosts    []*hostFunc
+	memory   []byte
+	maxPages uint32
+	globals  []uint64
+	table    []int64 // function indices, -1 if uninitialized
+
+	stack  []uint64
+	depth  int
+	inHost int // number of host functions being executed
+
+	lastPc  uint32
+	changes []memoryChange // memory changes since the last traced instruction
+}
+
+// grow makes room on the stack for n more values.
+func (in *instance) grow(sp, n int) {
+	if sp+n <= len(in.stack) {
+		return
+	}
+	if sp+n > maxStackSize {
+		panic(trapStackOverflow)
+	}
+	size := 2 * len(in.stack)
+	if size < sp+n {
+		size = sp + n
+	}
+	if size > maxStackSize {
+		size = maxStackSize
+	}
+	stack := make([]uint64, size)
+	copy(stack, in.stack[:sp])
+	in.stack = stack
+}
+
+// access returns the memory accessed at the operand address and offset.
+func (in *instance) access(addr uint64, offset uint64, size uint32) []byte {
+	start := uint64(uint32(addr)) + offset
+	if start+uint64(size) > uint64(len(in.memory)) {
+		panic(trapMemoryAccess)
+	}
+	return in.memory[start : start+uint64(size)]
+}
+
+// store writes data in memory, recording the change for the execution trace.
+func (in *instance) store(addr uint64, offset uint64, data []byte) {
+	mem := in.access(addr, offset, uint32(len(data)))
+	copy(mem, data)
+	if in.engine.onLog != nil {
+		in.changes = append(in.changes, memoryChange{uint32(uint64(uint32(addr)) + offset), append([]byte{}, data...)})
+	}
+}
+
+// call executes the function whose params are on top of the stack, leaving
+// its results in place of them. It returns the new stack pointer.
+func (in *instance) call(idx uint32, sp int) int {
+	if idx < uint32(len(in.hosts)) {
+		return in.callHost(idx, sp)
+	}
+	if in.depth >= maxCallDepth {
+		panic(trapCallStack)
+	}
+	in.depth++
+	defer func() { in.depth-- }()
+
+	var (
+		fn   = in.module.funcs[idx-uint32(len(in.hosts))]
+		typ  = &in.module.types[fn.typeIdx]
+		base = sp - len(typ.params)
+	)
+	in.grow(sp, len(fn.locals)+fn.maxHeight)
+	s := in.stack
+	for i := range fn.locals {
+		s[sp+i] = 0
+	}
+	sp += len(fn.locals)
+
+	var (
+		code = fn.code
+		ip   = 0
+	)
+	for {
+		ins := &code[ip]
+		ip++
+		in.lastPc = ins.pc
+		if in.engine.onLog != nil {
+			in.trace(fn, ins, uint32(ip-1), s[base:sp])
+		}
+		switch ins.op {
+		case opNop, opBlock, opLoop:
+
+		case opUnreachable:
+			panic(trapUnreachable)
+
+		case opIf:
+			sp--
+			if uint32(s[sp]) == 0 {
+				ip = int(ins.br.target)
+			}
+
+		case opElse:
+			ip = int(ins.br.target)
+
+		case opEnd:
+			if ip == len(code) {
+				return in.ret(base, sp, len(typ.results))
+			}
+
+		case opBr:
+			sp, ip = branchTo(s, sp, ins.br)
+
+		case opBrIf:
+			sp--
+			if uint32(s[sp]) != 0 {
+				sp, ip = branchTo(s, sp, ins.br)
+			}
+
+		case opBrTable:
+			sp--
+			table := fn.tables[ins.imm]
+			i := uint64(uint32(s[sp]))
+			if i >= uint64(len(table)) {
+				i = uint64(len(table) - 1)
+			}
+			sp, ip = branchTo(s, sp, table[i])
+
+		case opReturn:
+			return in.ret(base, sp, len(typ.results))
+
+		case opCall:
+			sp = in.call(uint32(ins.imm), sp)
+			s = in.stack
+
+		case opCallIndirect:
+			sp--
+			i := uint64(uint32(s[sp]))
+			if i >= uint64(len(in.table)) {
+				panic(trapUndefinedElement)
+			}
+			f := in.table[i]
+			if f < 0 {
+				panic(trapUninitialized)
+			}
+			if !in.module.funcType(uint32(f)).equal(&in.module.types[ins.imm]) {
+				panic(trapIndirectCallType)
+			}
+			sp = in.call(uint32(f), sp)
+			s = in.stack
+
+		case opDrop:
+			sp--
+
+		case opSelect, opTypedSelect:
+			sp -= 2
+			if uint32(s[sp+1]) == 0 {
+				s[sp-1] = s[sp]
+			}
+
+		case opLocalGet:
+			s[sp] = s[base+int(ins.imm)]
+			sp++
+		case opLocalSet:
+			sp--
+			s[base+int(ins.imm)] = s[sp]
+		case opLocalTee:
+			s[base+int(ins.imm)] = s[sp-1]
+		case opGlobalGet:
+			s[sp] = in.globals[ins.imm]
+			sp++
+		case opGlobalSet:
+			sp--
+			in.globals[ins.imm] = s[sp]
+
+		case opI32Load:
+			s[sp-1] = uint64(binary.LittleEndian.Uint32(in.access(s[sp-1], ins.imm, 4)))
+		case opI64Load:
+			s[sp-1] = binary.LittleEndian.Uint64(in.access(s[sp-1], ins.imm, 8))
+		case opI32Load8S:
+			s[sp-1] = uint64(uint32(int8(in.access(s[sp-1], ins.imm, 1)[0])))
+		case opI32Load8U, opI64Load8U:
+			s[sp-1] = uint64(in.access(s[sp-1], ins.imm, 1)[0])
+		case opI32Load16S:
+			s[sp-1] = uint64(uint32(int16(binary.LittleEndian.Uint16(in.access(s[sp-1], ins.imm, 2)))))
+		case opI32Load16U, opI64Load16U:
+			s[sp-1] = uint64(binary.LittleEndian.Uint16(in.access(s[sp-1], ins.imm, 2)))
+		case opI64Load8S:
+			s[sp-1] = uint64(int8(in.access(s[sp-1], ins.imm, 1)[0]))
+		case opI64Load16S:
+			s[sp-1] = uint64(int16(binary.LittleEndian.Uint16(in.access(s[sp-1], ins.imm, 2))))
+		case opI64Load32S:
+			s[sp-1] = uint64(int32(binary.LittleEndian.Uint32(in.access(s[sp-1], ins.imm, 4))))
+		case opI64Load32U:
+			s[sp-1] = uint64(binary.LittleEndian.Uint32(in.access(s[sp-1], ins.imm, 4)))
+
+		case opI32Store, opI64Store, opI32Store8, opI32Store16, opI64Store8, opI64Store16, opI64Store32:
+			sp -= 2
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], s[sp+1])
+			in.store(s[sp], ins.imm, buf[:memAccessSize(ins.op)])
+
+		case opMemorySize:
+			s[sp] = uint64(len(in.memory) / pageSize)
+			sp++
+
+		case opMemoryGrow:
+			var (
+				pages = uint64(len(in.memory) / pageSize)
+				delta = uint64(uint32(s[sp-1]))
+			)
+			if pages+delta > uint64(in.maxPages) {
+				s[sp-1] = uint64(math.MaxUint32)
+			} else {
+				in.memory = append(in.memory, make([]byte, delta*pageSize)...)
+				s[sp-1] = pages
+			}
+
+		case opMemoryCopy:
+			sp -= 3
+			n := uint32(s[sp+2])
+			in.store(s[sp], 0, append([]byte{}, in.access(s[sp+1], 0, n)...))
+
+		case opMemoryFill:
+			sp -= 3
+			data := make([]byte, len(in.access(s[sp], 0, uint32(s[sp+2]))))
+			for i := range data {
+				data[i] = byte(s[sp+1])
+			}
+			in.store(s[sp], 0, data)
+
+		case opI32Const, opI64Const:
+			s[sp] = ins.imm
+			sp++
+
+		default:
+			sp = numeric(ins.op, s, sp)
+		}
+	}
+}
+
+// branchTo drops the operands skipped by the branch, and returns the stack
+// pointer and instruction index to continue with.
+func branchTo(s []uint64, sp int, br branch) (int, int) {
+	if br.drop > 0 {
+		keep := int(br.keep)
+		copy(s[sp-keep-int(br.drop):], s[sp-keep:sp])
+		sp -= int(br.drop)
+	}
+	return sp, int(br.target)
+}
+
+// ret moves the results of the function in place of its params and locals.
+func (in *instance) ret(base, sp, results int) int {
+	copy(in.stack[base:], in.stack[sp-results:sp])
+	return base + results
+}
+
+// callHost executes an imported function.
+func (in *instance) callHost(idx uint32, sp int) int {
+	var (
+		host = in.hosts[idx]
+		n    = len(in.module.funcType(idx).params)
+		res  int32
+	)
+	sp -= n
+	params := in.stack[sp : sp+n]
+	in.inHost++
+	if host.fn64 != nil {
+		args := make([]int64, n)
+		for i, p := range params {
+			args[i] = int64(p)
+		}
+		res = host.fn64(args)
+	} else {
+		args := make([]int32, n)
+		for i, p := range params {
+			args[i] = int32(p)
+		}
+		res = host.fn32(args)
+	}
+	in.inHost--
+	if res != 0 {
+		panic(exit{res})
+	}
+	return sp
+}
+
+// numeric executes the arithmetic, comparison and conversion instructions.
+func numeric(op uint16, s []uint64, sp int) int {
+	// unary operations
+	a := s[sp-1]
+	switch op {
+	case opI32Eqz:
+		s[sp-1] = b2u(uint32(a) == 0)
+		return sp
+	case opI64Eqz:
+		s[sp-1] = b2u(a == 0)
+		return sp
+	case opI32Clz:
+		s[sp-1] = uint64(bits.LeadingZeros32(uint32(a)))
+		return sp
+	case opI32Ctz:
+		s[sp-1] = uint64(bits.TrailingZeros32(uint32(a)))
+		return sp
+	case opI32Popcnt:
+		s[sp-1] = uint64(bits.OnesCount32(uint32(a)))
+		return sp
+	case opI64Clz:
+		s[sp-1] = uint64(bits.LeadingZeros64(a))
+		return sp
+	case opI64Ctz:
+		s[sp-1] = uint64(bits.TrailingZeros64(a))
+		return sp
+	case opI64Popcnt:
+		s[sp-1] = uint64(bits.OnesCount64(a))
+		return sp
+	case opI32WrapI64:
+		s[sp-1] = uint64(uint32(a))
+		return sp
+	case opI64ExtendI32S:
+		s[sp-1] = uint64(int32(a))
+		return sp
+	case opI64ExtendI32U:
+		s[sp-1] = uint64(uint32(a))
+		return sp
+	case opI32Extend8S:
+		s[sp-1] = uint64(uint32(int8(a)))
+		return sp
+	case opI32Extend16S:
+		s[sp-1] = uint64(uint32(int16(a)))
+		return sp
+	case opI64Extend8S:
+		s[sp-1] = uint64(int8(a))
+		return sp
+	case opI64Extend16S:
+		s[sp-1] = uint64(int16(a))
+		return sp
+	case opI64Extend32S:
+		s[sp-1] = uint64(int32(a))
+		return sp
+	}
+	// binary operations
+	sp--
+	x, y := s[sp-1], s[sp]
+	var res uint64
+	if op <= opI64GeU || op >= opI32Clz && op <= opI32Rotr {
+		res = i32op(op, uint32(x), uint32(y), x, y)
+	} else {
+		res = i64op(op, x, y)
+	}
+	s[sp-1] = res
+	return sp
+}
+
+// i32op executes a binary operation on i32 values, or a comparison.
+func i32op(op uint16, x, y uint32, x64, y64 uint64) uint64 {
+	switch op {
+	case opI32Eq:
+		return b2u(x == y)
+	case opI32Ne:
+		return b2u(x != y)
+	case opI32LtS:
+		return b2u(int32(x) < int32(y))
+	case opI32LtU:
+		return b2u(x < y)
+	case opI32GtS:
+		return b2u(int32(x) > int32(y))
+	case opI32GtU:
+		return b2u(x > y)
+	case opI32LeS:
+		return b2u(int32(x) <= int32(y))
+	case opI32LeU:
+		return b2u(x <= y)
+	case opI32GeS:
+		return b2u(int32(x) >= int32(y))
+	case opI32GeU:
+		return b2u(x >= y)
+	case opI64Eq:
+		return b2u(x64 == y64)
+	case opI64Ne:
+		return b2u(x64 != y64)
+	case opI64LtS:
+		return b2u(int64(x64) < int64(y64))
+	case opI64LtU:
+		return b2u(x64 < y64)
+	case opI64GtS:
+		return b2u(int64(x64) > int64(y64))
+	case opI64GtU:
+		return b2u(x64 > y64)
+	case opI64LeS:
+		return b2u(int64(x64) <= int64(y64))
+	case opI64LeU:
+		return b2u(x64 <= y64)
+	case opI64GeS:
+		return b2u(int64(x64) >= int64(y64))
+	case opI64GeU:
+		return b2u(x64 >= y64)
+	case opI32Add:
+		return uint64(x + y)
+	case opI32Sub:
+		return uint64(x - y)
+	case opI32Mul:
+		return uint64(x * y)
+	case opI32DivS:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		if int32(x) == math.MinInt32 && int32(y) == -1 {
+			panic(trapIntegerOverflow)
+		}
+		return uint64(uint32(int32(x) / int32(y)))
+	case opI32DivU:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		return uint64(x / y)
+	case opI32RemS:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		if int32(y) == -1 {
+			return 0
+		}
+		return uint64(uint32(int32(x) % int32(y)))
+	case opI32RemU:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		return uint64(x % y)
+	case opI32And:
+		return uint64(x & y)
+	case opI32Or:
+		return uint64(x | y)
+	case opI32Xor:
+		return uint64(x ^ y)
+	case opI32Shl:
+		return uint64(x << (y & 31))
+	case opI32ShrS:
+		return uint64(uint32(int32(x) >> (y & 31)))
+	case opI32ShrU:
+		return uint64(x >> (y & 31))
+	case opI32Rotl:
+		return uint64(bits.RotateLeft32(x, int(y&31)))
+	case opI32Rotr:
+		return uint64(bits.RotateLeft32(x, -int(y&31)))
+	}
+	panic(trap("unsupported instruction"))
+}
+
+// i64op executes a binary operation on i64 values.
+func i64op(op uint16, x, y uint64) uint64 {
+	switch op {
+	case opI64Add:
+		return x + y
+	case opI64Sub:
+		return x - y
+	case opI64Mul:
+		return x * y
+	case opI64DivS:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		if int64(x) == math.MinInt64 && int64(y) == -1 {
+			panic(trapIntegerOverflow)
+		}
+		return uint64(int64(x) / int64(y))
+	case opI64DivU:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		return x / y
+	case opI64RemS:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		if int64(y) == -1 {
+			return 0
+		}
+		return uint64(int64(x) % int64(y))
+	case opI64RemU:
+		if y == 0 {
+			panic(trapDivideByZero)
+		}
+		return x % y
+	case opI64And:
+		return x & y
+	case opI64Or:
+		return x | y
+	case opI64Xor:
+		return x ^ y
+	case opI64Shl:
+		return x << (y & 63)
+	case opI64ShrS:
+		return uint64(int64(x) >> (y & 63))
+	case opI64ShrU:
+		return x >> (y & 63)
+	case opI64Rotl:
+		return bits.RotateLeft64(x, int(y&63))
+	case opI64Rotr:
+		return bits.RotateLeft64(x, -int(y&63))
+	}
+	panic(trap("unsupported instruction"))
+}
+
+func b2u(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,333 @@
+package gowasm
+
+// GasCosts prices the execution of a binary instrumented by InjectGas. The
+// bulk memory instructions are priced by their operands on top of the price
+// of the instruction, an instruction with a zero price being left as is.
+type GasCosts struct {
+	Instruction uint64 // Price of an executed instruction
+	MemoryPage  uint64 // Price of a page requested by memory.grow
+	MemoryWord  uint64 // Price of a 32 byte word written by memory.copy and memory.fill
+}
+
+// InjectGas instruments a WASM binary to pay for its execution. The binary
+// imports the host function module.name, taking the gas to charge as an i64,
+// and calls it at the start of every sequence of instructions executed
+// together with the price of each instruction of the sequence. Sequences end
+// at the control instructions, so a loop pays for every iteration and the
+// instructions skipped by a branch aren't charged.
+//
+// The bulk memory instructions are replaced by calls to functions appended to
+// the module, which charge the gas of the pages or bytes requested before
+// executing the instruction.
+//
+// The binary is validated first, and the functions defined by it are moved one
+// index up to make room for the import. The name section is dropped, as it
+// refers to the functions by index.
+func InjectGas(binary []byte, module, name string, costs GasCosts) ([]byte, error) {
+	m, err := decodeModule(binary)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.compileFunctions(); err != nil {
+		return nil, err
+	}
+	if len(m.funcs) == 0 {
+		return binary, nil
+	}
+	// reuse the types the module already defines
+	var newTypes []funcType
+	typeIndex := func(t funcType) uint32 {
+		for i := range m.types {
+			if m.types[i].equal(&t) {
+				return uint32(i)
+			}
+		}
+		for i := range newTypes {
+			if newTypes[i].equal(&t) {
+				return uint32(len(m.types) + i)
+			}
+		}
+		newTypes = append(newTypes, t)
+		return uint32(len(m.types) + len(newTypes) - 1)
+	}
+	typeIdx := typeIndex(funcType{params: []byte{valueTypeI64}})
+	gasFunc := uint32(len(m.imports))
+	helpers := m.memoryHelpers(gasFunc, costs, typeIndex)
+	var (
+		hasImport = false
+		res       = append([]byte{}, binary[:8]...)
+		r         = &reader{buf: binary, pos: 8}
+	)
+	shift := func(idx uint32) uint32 {
+		if idx >= gasFunc {
+			return idx + 1
+		}
+		return idx
+	}
+	// addImport appends the import section, adding the import of the gas
+	// function to the ones of the module, if any
+	addImport := func(payload []byte) {
+		var entries []byte
+		s := &reader{buf: payload}
+		n := s.u32()
+		entries = appendULEB(entries, uint64(n)+1)
+		entries = append(entries, payload[s.pos:]...)
+		entries = appendULEB(entries, uint64(len(module)))
+		entries = append(entries, module...)
+		entries = appendULEB(entries, uint64(len(name)))
+		entries = append(entries, name...)
+		entries = append(entries, externalFunc)
+		entries = appendULEB(entries, uint64(typeIdx))
+		res = appendSection(res, sectionImport, entries)
+		hasImport = true
+	}
+	for !r.done() {
+		id := r.byte()
+		payload := r.bytes(r.u32())
+		if r.err != nil {
+			break
+		}
+		if !hasImport && id != sectionCustom && id > sectionImport {
+			addImport(appendULEB(nil, 0))
+		}
+		s := &reader{buf: payload}
+		switch id {
+		case sectionCustom:
+			if s.name() == "name" {
+				continue
+			}
+			res = appendSection(res, id, payload)
+
+		case sectionType:
+			if len(newTypes) == 0 {
+				res = appendSection(res, id, payload)
+				continue
+			}
+			n := s.u32()
+			out := appendULEB(nil, uint64(n)+uint64(len(newTypes)))
+			out = append(out, payload[s.pos:]...)
+			for _, t := range newTypes {
+				out = append(out, 0x60)
+				out = appendULEB(out, uint64(len(t.params)))
+				out = append(out, t.params...)
+				out = appendULEB(out, uint64(len(t.results)))
+				out = append(out, t.results...)
+			}
+			res = appendSection(res, id, out)
+
+		case sectionFunction:
+			n := s.u32()
+			out := appendULEB(nil, uint64(n)+uint64(len(helpers.funcs)))
+			out = append(out, payload[s.pos:]...)
+			for _, helper := range helpers.funcs {
+				out = appendULEB(out, uint64(helper.typeIdx))
+			}
+			res = appendSection(res, id, out)
+
+		case sectionImport:
+			addImport(payload)
+
+		case sectionExport:
+			n := s.u32()
+			out := appendULEB(nil, uint64(n))
+			for i := uint32(0); i < n; i++ {
+				start := s.pos
+				s.name()
+				kind := s.byte()
+				out = append(out, payload[start:s.pos]...)
+				idx := s.u32()
+				if kind == externalFunc {
+					idx = shift(idx)
+				}
+				out = appendULEB(out, uint64(idx))
+			}
+			res = appendSection(res, id, out)
+
+		case sectionStart:
+			res = appendSection(res, id, appendULEB(nil, uint64(shift(s.u32()))))
+
+		case sectionElement:
+			n := s.u32()
+			out := appendULEB(nil, uint64(n))
+			for i := uint32(0); i < n; i++ {
+				start := s.pos
+				s.u32()
+				s.constExpr(m.globals)
+				out = append(out, payload[start:s.pos]...)
+				count := s.u32()
+				out = appendULEB(out, uint64(count))
+				for j := uint32(0); j < count; j++ {
+					out = appendULEB(out, uint64(shift(s.u32())))
+				}
+			}
+			res = appendSection(res, id, out)
+
+		case sectionCode:
+			out := appendULEB(nil, uint64(len(m.funcs)+len(helpers.funcs)))
+			for _, fn := range m.funcs {
+				body := injectBody(fn, gasFunc, costs.Instruction, helpers.index)
+				out = appendULEB(out, uint64(len(body)))
+				out = append(out, body...)
+			}
+			for _, helper := range helpers.funcs {
+				out = appendULEB(out, uint64(len(helper.body)))
+				out = append(out, helper.body...)
+			}
+			res = appendSection(res, id, out)
+
+		default:
+			res = appendSection(res, id, payload)
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return res, nil
+}
+
+// memoryHelpers are the functions charging the gas of the bulk memory
+// instructions, indexed by the instruction they replace.
+type memoryHelpers struct {
+	funcs []*function
+	index map[uint16]uint32
+}
+
+// memoryHelpers returns the helper functions of the bulk memory instructions
+// the module executes, to be appended after its functions.
+func (m *module) memoryHelpers(gasFunc uint32, costs GasCosts, typeIndex func(funcType) uint32) *memoryHelpers {
+	helpers := &memoryHelpers{index: make(map[uint16]uint32)}
+	add := func(op uint16, t funcType, body []byte) {
+		if _, ok := helpers.index[op]; ok {
+			return
+		}
+		// the injected import moves the functions of the module one index up
+		helpers.index[op] = gasFunc + 1 + uint32(len(m.funcs)+len(helpers.funcs))
+		helpers.funcs = append(helpers.funcs, &function{typeIdx: typeIndex(t), body: body})
+	}
+	for _, fn := range m.funcs {
+		for _, ins := range fn.code {
+			switch {
+			case ins.op == opMemoryGrow && costs.MemoryPage > 0:
+				add(ins.op, funcType{params: []byte{valueTypeI32}, results: []byte{valueTypeI32}},
+					memoryHelperBody(gasFunc, 1, false, costs.MemoryPage, 0x40, 0x00))
+			case ins.op == opMemoryCopy && costs.MemoryWord > 0:
+				add(ins.op, funcType{params: []byte{valueTypeI32, valueTypeI32, valueTypeI32}},
+					memoryHelperBody(gasFunc, 3, true, costs.MemoryWord, 0xfc, 0x0a, 0x00, 0x00))
+			case ins.op == opMemoryFill && costs.MemoryWord > 0:
+				add(ins.op, funcType{params: []byte{valueTypeI32, valueTypeI32, valueTypeI32}},
+					memoryHelperBody(gasFunc, 3, true, costs.MemoryWord, 0xfc, 0x0b, 0x00))
+			}
+		}
+	}
+	return helpers
+}
+
+// memoryHelperBody returns the body of a helper function, which charges the
+// gas of its last param, the size in pages or bytes rounded up to words, then
+// executes the instruction on its params.
+func memoryHelperBody(gasFunc uint32, params int, words bool, gas uint64, instr ...byte) []byte {
+	body := []byte{0x00, byte(opLocalGet)}
+	body = appendULEB(body, uint64(params-1))
+	body = append(body, byte(opI64ExtendI32U))
+	if words {
+		body = append(body, byte(opI64Const), 31, byte(opI64Add), byte(opI64Const), 5, byte(opI64ShrU))
+	}
+	body = append(body, byte(opI64Const))
+	body = appendSLEB(body, int64(gas))
+	body = append(body, byte(opI64Mul), byte(opCall))
+	body = appendULEB(body, uint64(gasFunc))
+	for i := 0; i < params; i++ {
+		body = append(body, byte(opLocalGet))
+		body = appendULEB(body, uint64(i))
+	}
+	body = append(body, instr...)
+	return append(body, byte(opEnd))
+}
+
+// injectBody returns the body of the function, charging the gas at the start
+// of every sequence of instructions, calling the functions by their new index
+// and replacing the bulk memory instructions by their helper.
+func injectBody(fn *function, gasFunc uint32, instrGas uint64, helpers map[uint16]uint32) []byte {
+	var (
+		code  = fn.code
+		start = func(i int) uint32 { return code[i].pc - fn.bodyPc }
+		body  = append([]byte{}, fn.body[:start(0)]...)
+	)
+	for i := 0; i < len(code); {
+		// a sequence ends with the first control instruction
+		j := i
+		for j < len(code) && !endsSequence(code[j].op) {
+			j++
+		}
+		if j < len(code) {
+			j++
+		}
+		body = append(body, byte(opI64Const))
+		body = appendSLEB(body, int64(uint64(j-i)*instrGas))
+		body = append(body, byte(opCall))
+		body = appendULEB(body, uint64(gasFunc))
+		for ; i < j; i++ {
+			end := uint32(len(fn.body))
+			if i+1 < len(code) {
+				end = start(i + 1)
+			}
+			if code[i].op == opCall && uint32(code[i].imm) >= gasFunc {
+				body = append(body, byte(opCall))
+				body = appendULEB(body, code[i].imm+1)
+				continue
+			}
+			if helper, ok := helpers[code[i].op]; ok {
+				body = append(body, byte(opCall))
+				body = appendULEB(body, uint64(helper))
+				continue
+			}
+			body = append(body, fn.body[start(i):end]...)
+		}
+	}
+	return body
+}
+
+// endsSequence reports whether the instruction is the last one of the
+// sequence, the next instruction being a branch target or not always executed
+// after it.
+func endsSequence(op uint16) bool {
+	switch op {
+	case opBlock, opLoop, opIf, opElse, opEnd, opBr, opBrIf, opBrTable, opReturn, opUnreachable:
+		return true
+	}
+	return false
+}
+
+func appendSection(bin []byte, id byte, payload []byte) []byte {
+	bin = append(bin, id)
+	bin = appendULEB(bin, uint64(len(payload)))
+	return append(bin, payload...)
+}
+
+func appendULEB(bin []byte, n uint64) []byte {
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			b |= 0x80
+		}
+		bin = append(bin, b)
+		if n == 0 {
+			return bin
+		}
+	}
+}
+
+func appendSLEB(bin []byte, n int64) []byte {
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if (n == 0 && b&0x40 == 0) || (n == -1 && b&0x40 != 0) {
+			return append(bin, b)
+		}
+		bin = append(bin, b|0x80)
+	}
+}
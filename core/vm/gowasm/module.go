@@ -0,0 +1,562 @@
+package gowasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	valueTypeI32 byte = 0x7f
+	valueTypeI64 byte = 0x7e
+	valueTypeF32 byte = 0x7d
+	valueTypeF64 byte = 0x7c
+
+	externalFunc   byte = 0x00
+	externalTable  byte = 0x01
+	externalMemory byte = 0x02
+	externalGlobal byte = 0x03
+
+	sectionCustom    = 0
+	sectionType      = 1
+	sectionImport    = 2
+	sectionFunction  = 3
+	sectionTable     = 4
+	sectionMemory    = 5
+	sectionGlobal    = 6
+	sectionExport    = 7
+	sectionStart     = 8
+	sectionElement   = 9
+	sectionCode      = 10
+	sectionData      = 11
+	sectionDataCount = 12
+
+	// maxLocals limits the number of locals of a function, as they are
+	// allocated on every call
+	maxLocals = 50000
+)
+
+var (
+	wasmMagic   = []byte{0x00, 0x61, 0x73, 0x6d}
+	wasmVersion = []byte{0x01, 0x00, 0x00, 0x00}
+
+	errUnexpectedEnd = errors.New("unexpected end of binary")
+	errFloat         = errors.New("floating point values are not supported")
+)
+
+type funcType struct {
+	params  []byte
+	results []byte
+}
+
+func (t *funcType) equal(other *funcType) bool {
+	return bytes.Equal(t.params, other.params) && bytes.Equal(t.results, other.results)
+}
+
+type importFunc struct {
+	module  string
+	name    string
+	typeIdx uint32
+}
+
+type limits struct {
+	min    uint32
+	max    uint32
+	hasMax bool
+}
+
+type global struct {
+	typ     byte
+	mutable bool
+	init    uint64
+}
+
+type export struct {
+	kind  byte
+	index uint32
+}
+
+type elemSegment struct {
+	offset uint32
+	funcs  []uint32
+}
+
+type dataSegment struct {
+	offset uint32
+	data   []byte
+}
+
+// function is a function defined by the module, compiled before execution.
+type function struct {
+	typeIdx   uint32
+	body      []byte
+	bodyPc    uint32  // offset of the body in the binary
+	locals    []byte  // types of the locals declared by the body
+	code      []instr // compiled body
+	tables    [][]branch
+	maxHeight int    // maximum height of the operand stack
+	codeBase  uint32 // index of the first instruction among all functions
+}
+
+// module is a decoded WASM module.
+type module struct {
+	types   []funcType
+	imports []importFunc
+	funcs   []*function
+	table   *limits
+	memory  *limits
+	globals []global
+	exports map[string]export
+	start   *uint32
+	elems   []elemSegment
+	datas   []dataSegment
+}
+
+// reader decodes the binary format, keeping the first error.
+type reader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *reader) fail(err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("offset %d: %w", r.pos, err)
+	}
+}
+
+func (r *reader) done() bool {
+	return r.err != nil || r.pos >= len(r.buf)
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.buf) {
+		r.fail(errUnexpectedEnd)
+		return 0
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) bytes(n uint32) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(r.pos)+uint64(n) > uint64(len(r.buf)) {
+		r.fail(errUnexpectedEnd)
+		return nil
+	}
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b
+}
+
+func (r *reader) u32() uint32 {
+	var (
+		res   uint64
+		shift uint
+	)
+	for i := 0; i < 5; i++ {
+		b := r.byte()
+		res |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if res > 0xffffffff {
+				r.fail(errors.New("integer too large"))
+			}
+			return uint32(res)
+		}
+		shift += 7
+	}
+	r.fail(errors.New("integer representation too long"))
+	return 0
+}
+
+// count decodes the length of a vector, each element taking at least a byte.
+func (r *reader) count() uint32 {
+	n := r.u32()
+	if uint64(n) > uint64(len(r.buf)-r.pos) {
+		r.fail(errUnexpectedEnd)
+		return 0
+	}
+	return n
+}
+
+func (r *reader) signed(size uint) int64 {
+	var (
+		res   int64
+		shift uint
+		b     byte
+	)
+	for {
+		b = r.byte()
+		res |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+		if shift >= size+7 {
+			r.fail(errors.New("integer representation too long"))
+			return 0
+		}
+	}
+	if shift < 64 && b&0x40 != 0 {
+		res |= -1 << shift
+	}
+	return res
+}
+
+func (r *reader) s32() int32 {
+	return int32(r.signed(32))
+}
+
+func (r *reader) s64() int64 {
+	return r.signed(64)
+}
+
+func (r *reader) name() string {
+	b := r.bytes(r.u32())
+	if !utf8.Valid(b) {
+		r.fail(errors.New("invalid UTF-8 name"))
+	}
+	return string(b)
+}
+
+func (r *reader) valueType() byte {
+	switch t := r.byte(); t {
+	case valueTypeI32, valueTypeI64:
+		return t
+	case valueTypeF32, valueTypeF64:
+		r.fail(errFloat)
+	default:
+		r.fail(fmt.Errorf("invalid value type 0x%x", t))
+	}
+	return 0
+}
+
+func (r *reader) limits() *limits {
+	l := new(limits)
+	switch flag := r.byte(); flag {
+	case 0x00:
+		l.min = r.u32()
+	case 0x01:
+		l.min, l.max, l.hasMax = r.u32(), r.u32(), true
+		if l.max < l.min {
+			r.fail(errors.New("size minimum must not be greater than maximum"))
+		}
+	default:
+		r.fail(fmt.Errorf("invalid limits flag 0x%x", flag))
+	}
+	return l
+}
+
+// constExpr decodes a constant expression, which can only refer to the
+// globals defined before.
+func (r *reader) constExpr(globals []global) uint64 {
+	var res uint64
+	switch op := uint16(r.byte()); op {
+	case opI32Const:
+		res = uint64(uint32(r.s32()))
+	case opI64Const:
+		res = uint64(r.s64())
+	case opGlobalGet:
+		idx := r.u32()
+		if idx >= uint32(len(globals)) {
+			r.fail(fmt.Errorf("unknown global %d", idx))
+			return 0
+		}
+		res = globals[idx].init
+	default:
+		if isFloatOp(op) {
+			r.fail(errFloat)
+		} else {
+			r.fail(fmt.Errorf("invalid constant expression opcode 0x%x", op))
+		}
+	}
+	if end := r.byte(); end != byte(opEnd) {
+		r.fail(errors.New("constant expression must end"))
+	}
+	return res
+}
+
+// HasMagic reports whether a binary starts with the WASM magic number, which is
+// how deployed WASM code is told apart from EVM bytecode.
+func HasMagic(binary []byte) bool {
+	return bytes.HasPrefix(binary, wasmMagic)
+}
+
+// Measure returns the number of functions defined by a WASM binary and the size
+// of its code section, without validating the code. It's used to price the
+// deployment of modules, which may use features the interpreter doesn't
+// support.
+func Measure(binary []byte) (funcs, codeSize uint64, err error) {
+	if len(binary) < 8 || !bytes.Equal(binary[:4], wasmMagic) {
+		return 0, 0, errors.New("invalid magic number")
+	}
+	r := &reader{buf: binary, pos: 8}
+	for !r.done() {
+		id := r.byte()
+		payload := r.bytes(r.u32())
+		if r.err != nil {
+			break
+		}
+		switch id {
+		case sectionFunction:
+			s := &reader{buf: payload}
+			funcs = uint64(s.u32())
+			if s.err != nil {
+				return 0, 0, s.err
+			}
+		case sectionCode:
+			codeSize = uint64(len(payload))
+		}
+	}
+	return funcs, codeSize, r.err
+}
+
+// decodeModule decodes a WASM binary. The function bodies are compiled
+// separately.
+func decodeModule(binary []byte) (*module, error) {
+	if len(binary) < 8 || !bytes.Equal(binary[:4], wasmMagic) {
+		return nil, errors.New("invalid magic number")
+	}
+	if !bytes.Equal(binary[4:8], wasmVersion) {
+		return nil, fmt.Errorf("unsupported version %x", binary[4:8])
+	}
+	var (
+		r       = &reader{buf: binary, pos: 8}
+		m       = &module{exports: make(map[string]export)}
+		funcs   []uint32
+		last    int
+		hasCode bool
+	)
+	for !r.done() {
+		id := r.byte()
+		size := r.u32()
+		start := r.pos
+		payload := r.bytes(size)
+		if r.err != nil {
+			break
+		}
+		if id != sectionCustom {
+			// the data count section goes between the element and code sections
+			order := 2 * int(id)
+			if id == sectionDataCount {
+				order = 2*sectionElement + 1
+			}
+			if order <= last {
+				return nil, fmt.Errorf("unexpected section %d", id)
+			}
+			last = order
+		}
+		s := &reader{buf: binary[:start+len(payload)], pos: start}
+		switch id {
+		case sectionCustom:
+			// custom sections, such as the names, don't affect the execution
+			if s.name(); s.err == nil {
+				s.pos = start + len(payload)
+			}
+		case sectionDataCount:
+			s.u32()
+		case sectionType:
+			m.types = make([]funcType, s.count())
+			for i := range m.types {
+				if form := s.byte(); form != 0x60 {
+					s.fail(fmt.Errorf("invalid function type form 0x%x", form))
+				}
+				params := make([]byte, s.count())
+				for j := range params {
+					params[j] = s.valueType()
+				}
+				results := make([]byte, s.count())
+				for j := range results {
+					results[j] = s.valueType()
+				}
+				m.types[i] = funcType{params, results}
+				if s.err != nil {
+					break
+				}
+			}
+		case sectionImport:
+			for n := s.u32(); n > 0 && s.err == nil; n-- {
+				mod, name := s.name(), s.name()
+				if kind := s.byte(); kind != externalFunc {
+					s.fail(fmt.Errorf("unsupported import %s.%s of kind %d", mod, name, kind))
+					break
+				}
+				m.imports = append(m.imports, importFunc{mod, name, s.u32()})
+			}
+		case sectionFunction:
+			funcs = make([]uint32, s.count())
+			for i := range funcs {
+				funcs[i] = s.u32()
+			}
+		case sectionTable:
+			for n := s.u32(); n > 0 && s.err == nil; n-- {
+				if m.table != nil {
+					s.fail(errors.New("multiple tables"))
+				}
+				if typ := s.byte(); typ != 0x70 {
+					s.fail(fmt.Errorf("unsupported table type 0x%x", typ))
+				}
+				m.table = s.limits()
+			}
+		case sectionMemory:
+			for n := s.u32(); n > 0 && s.err == nil; n-- {
+				if m.memory != nil {
+					s.fail(errors.New("multiple memories"))
+				}
+				m.memory = s.limits()
+				if m.memory.min > maxPages {
+					s.fail(errors.New("memory size must be at most 65536 pages"))
+				}
+			}
+		case sectionGlobal:
+			for n := s.u32(); n > 0 && s.err == nil; n-- {
+				g := global{typ: s.valueType()}
+				switch mut := s.byte(); mut {
+				case 0x00:
+				case 0x01:
+					g.mutable = true
+				default:
+					s.fail(fmt.Errorf("invalid mutability 0x%x", mut))
+				}
+				g.init = s.constExpr(m.globals)
+				m.globals = append(m.globals, g)
+			}
+		case sectionExport:
+			for n := s.u32(); n > 0 && s.err == nil; n-- {
+				name := s.name()
+				if _, ok := m.exports[name]; ok {
+					s.fail(fmt.Errorf("duplicate export %s", name))
+				}
+				m.exports[name] = export{s.byte(), s.u32()}
+			}
+		case sectionStart:
+			idx := s.u32()
+			m.start = &idx
+		case sectionElement:
+			for n := s.u32(); n > 0 && s.err == nil; n-- {
+				if flag := s.u32(); flag != 0 {
+					s.fail(fmt.Errorf("unsupported element segment kind %d", flag))
+					break
+				}
+				seg := elemSegment{offset: uint32(s.constExpr(m.globals))}
+				seg.funcs = make([]uint32, s.count())
+				for i := range seg.funcs {
+					seg.funcs[i] = s.u32()
+				}
+				m.elems = append(m.elems, seg)
+			}
+		case sectionCode:
+			hasCode = true
+			if n := s.u32(); n != uint32(len(funcs)) {
+				s.fail(errors.New("function and code section have inconsistent lengths"))
+				break
+			}
+			m.funcs = make([]*function, len(funcs))
+			for i := range funcs {
+				body := s.bytes(s.u32())
+				if s.err != nil {
+					break
+				}
+				m.funcs[i] = &function{typeIdx: funcs[i], body: body, bodyPc: uint32(s.pos - len(body))}
+			}
+		case sectionData:
+			for n := s.u32(); n > 0 && s.err == nil; n-- {
+				if flag := s.u32(); flag != 0 {
+					s.fail(fmt.Errorf("unsupported data segment kind %d", flag))
+					break
+				}
+				offset := uint32(s.constExpr(m.globals))
+				m.datas = append(m.datas, dataSegment{offset, s.bytes(s.u32())})
+			}
+		default:
+			return nil, fmt.Errorf("unknown section %d", id)
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+		if s.pos != start+len(payload) {
+			return nil, fmt.Errorf("section %d size mismatch", id)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(funcs) > 0 && !hasCode {
+		return nil, errors.New("function and code section have inconsistent lengths")
+	}
+	return m, m.check()
+}
+
+// check validates the indices referred to outside of the function bodies.
+func (m *module) check() error {
+	for _, imp := range m.imports {
+		if imp.typeIdx >= uint32(len(m.types)) {
+			return fmt.Errorf("unknown type %d", imp.typeIdx)
+		}
+	}
+	for _, fn := range m.funcs {
+		if fn.typeIdx >= uint32(len(m.types)) {
+			return fmt.Errorf("unknown type %d", fn.typeIdx)
+		}
+	}
+	numFuncs := uint32(len(m.imports) + len(m.funcs))
+	for name, exp := range m.exports {
+		switch exp.kind {
+		case externalFunc:
+			if exp.index >= numFuncs {
+				return fmt.Errorf("export %s: unknown function %d", name, exp.index)
+			}
+		case externalTable:
+			if m.table == nil || exp.index != 0 {
+				return fmt.Errorf("export %s: unknown table %d", name, exp.index)
+			}
+		case externalMemory:
+			if m.memory == nil || exp.index != 0 {
+				return fmt.Errorf("export %s: unknown memory %d", name, exp.index)
+			}
+		case externalGlobal:
+			if exp.index >= uint32(len(m.globals)) {
+				return fmt.Errorf("export %s: unknown global %d", name, exp.index)
+			}
+		default:
+			return fmt.Errorf("export %s: invalid kind %d", name, exp.kind)
+		}
+	}
+	if m.start != nil {
+		if *m.start >= numFuncs {
+			return fmt.Errorf("unknown start function %d", *m.start)
+		}
+		if t := m.funcType(*m.start); len(t.params) != 0 || len(t.results) != 0 {
+			return errors.New("start function must not have params or results")
+		}
+	}
+	for _, seg := range m.elems {
+		if m.table == nil {
+			return errors.New("element segment without table")
+		}
+		for _, idx := range seg.funcs {
+			if idx >= numFuncs {
+				return fmt.Errorf("element segment: unknown function %d", idx)
+			}
+		}
+	}
+	if len(m.datas) > 0 && m.memory == nil {
+		return errors.New("data segment without memory")
+	}
+	return nil
+}
+
+// funcType returns the type of the function, imported or defined.
+func (m *module) funcType(idx uint32) *funcType {
+	if idx < uint32(len(m.imports)) {
+		return &m.types[m.imports[idx].typeIdx]
+	}
+	return &m.types[m.funcs[idx-uint32(len(m.imports))].typeIdx]
+}
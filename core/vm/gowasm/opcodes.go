@@ -0,0 +1,321 @@
+package gowasm
+
+// WASM opcodes, as encoded in the binary. Prefixed opcodes are stored with
+// their prefix in the high byte.
+const (
+	opUnreachable  uint16 = 0x00
+	opNop          uint16 = 0x01
+	opBlock        uint16 = 0x02
+	opLoop         uint16 = 0x03
+	opIf           uint16 = 0x04
+	opElse         uint16 = 0x05
+	opEnd          uint16 = 0x0b
+	opBr           uint16 = 0x0c
+	opBrIf         uint16 = 0x0d
+	opBrTable      uint16 = 0x0e
+	opReturn       uint16 = 0x0f
+	opCall         uint16 = 0x10
+	opCallIndirect uint16 = 0x11
+
+	opDrop        uint16 = 0x1a
+	opSelect      uint16 = 0x1b
+	opTypedSelect uint16 = 0x1c
+
+	opLocalGet  uint16 = 0x20
+	opLocalSet  uint16 = 0x21
+	opLocalTee  uint16 = 0x22
+	opGlobalGet uint16 = 0x23
+	opGlobalSet uint16 = 0x24
+
+	opI32Load    uint16 = 0x28
+	opI64Load    uint16 = 0x29
+	opI32Load8S  uint16 = 0x2c
+	opI32Load8U  uint16 = 0x2d
+	opI32Load16S uint16 = 0x2e
+	opI32Load16U uint16 = 0x2f
+	opI64Load8S  uint16 = 0x30
+	opI64Load8U  uint16 = 0x31
+	opI64Load16S uint16 = 0x32
+	opI64Load16U uint16 = 0x33
+	opI64Load32S uint16 = 0x34
+	opI64Load32U uint16 = 0x35
+	opI32Store   uint16 = 0x36
+	opI64Store   uint16 = 0x37
+	opI32Store8  uint16 = 0x3a
+	opI32Store16 uint16 = 0x3b
+	opI64Store8  uint16 = 0x3c
+	opI64Store16 uint16 = 0x3d
+	opI64Store32 uint16 = 0x3e
+	opMemorySize uint16 = 0x3f
+	opMemoryGrow uint16 = 0x40
+
+	opI32Const uint16 = 0x41
+	opI64Const uint16 = 0x42
+
+	opI32Eqz uint16 = 0x45
+	opI32Eq  uint16 = 0x46
+	opI32Ne  uint16 = 0x47
+	opI32LtS uint16 = 0x48
+	opI32LtU uint16 = 0x49
+	opI32GtS uint16 = 0x4a
+	opI32GtU uint16 = 0x4b
+	opI32LeS uint16 = 0x4c
+	opI32LeU uint16 = 0x4d
+	opI32GeS uint16 = 0x4e
+	opI32GeU uint16 = 0x4f
+	opI64Eqz uint16 = 0x50
+	opI64Eq  uint16 = 0x51
+	opI64Ne  uint16 = 0x52
+	opI64LtS uint16 = 0x53
+	opI64LtU uint16 = 0x54
+	opI64GtS uint16 = 0x55
+	opI64GtU uint16 = 0x56
+	opI64LeS uint16 = 0x57
+	opI64LeU uint16 = 0x58
+	opI64GeS uint16 = 0x59
+	opI64GeU uint16 = 0x5a
+
+	opI32Clz    uint16 = 0x67
+	opI32Ctz    uint16 = 0x68
+	opI32Popcnt uint16 = 0x69
+	opI32Add    uint16 = 0x6a
+	opI32Sub    uint16 = 0x6b
+	opI32Mul    uint16 = 0x6c
+	opI32DivS   uint16 = 0x6d
+	opI32DivU   uint16 = 0x6e
+	opI32RemS   uint16 = 0x6f
+	opI32RemU   uint16 = 0x70
+	opI32And    uint16 = 0x71
+	opI32Or     uint16 = 0x72
+	opI32Xor    uint16 = 0x73
+	opI32Shl    uint16 = 0x74
+	opI32ShrS   uint16 = 0x75
+	opI32ShrU   uint16 = 0x76
+	opI32Rotl   uint16 = 0x77
+	opI32Rotr   uint16 = 0x78
+	opI64Clz    uint16 = 0x79
+	opI64Ctz    uint16 = 0x7a
+	opI64Popcnt uint16 = 0x7b
+	opI64Add    uint16 = 0x7c
+	opI64Sub    uint16 = 0x7d
+	opI64Mul    uint16 = 0x7e
+	opI64DivS   uint16 = 0x7f
+	opI64DivU   uint16 = 0x80
+	opI64RemS   uint16 = 0x81
+	opI64RemU   uint16 = 0x82
+	opI64And    uint16 = 0x83
+	opI64Or     uint16 = 0x84
+	opI64Xor    uint16 = 0x85
+	opI64Shl    uint16 = 0x86
+	opI64ShrS   uint16 = 0x87
+	opI64ShrU   uint16 = 0x88
+	opI64Rotl   uint16 = 0x89
+	opI64Rotr   uint16 = 0x8a
+
+	opI32WrapI64    uint16 = 0xa7
+	opI64ExtendI32S uint16 = 0xac
+	opI64ExtendI32U uint16 = 0xad
+	opI32Extend8S   uint16 = 0xc0
+	opI32Extend16S  uint16 = 0xc1
+	opI64Extend8S   uint16 = 0xc2
+	opI64Extend16S  uint16 = 0xc3
+	opI64Extend32S  uint16 = 0xc4
+
+	opPrefixFC   uint16 = 0xfc
+	opMemoryCopy uint16 = 0xfc0a
+	opMemoryFill uint16 = 0xfc0b
+)
+
+// isFloatOp reports whether the opcode operates on floating point values, which
+// aren't supported to keep the execution deterministic.
+func isFloatOp(op uint16) bool {
+	switch {
+	case op == 0x2a || op == 0x2b: // f32.load, f64.load
+		return true
+	case op == 0x38 || op == 0x39: // f32.store, f64.store
+		return true
+	case op == 0x43 || op == 0x44: // f32.const, f64.const
+		return true
+	case op >= 0x5b && op <= 0x66: // comparisons
+		return true
+	case op >= 0x8b && op <= 0xa6: // arithmetic
+		return true
+	case op >= 0xa8 && op <= 0xab, op >= 0xae && op <= 0xbf: // conversions
+		return true
+	case op >= 0xfc00 && op <= 0xfc07: // saturating truncations
+		return true
+	}
+	return false
+}
+
+var opNames = map[uint16]string{
+	opUnreachable:   "unreachable",
+	opNop:           "nop",
+	opBlock:         "block",
+	opLoop:          "loop",
+	opIf:            "if",
+	opElse:          "else",
+	opEnd:           "end",
+	opBr:            "br",
+	opBrIf:          "br_if",
+	opBrTable:       "br_table",
+	opReturn:        "return",
+	opCall:          "call",
+	opCallIndirect:  "call_indirect",
+	opDrop:          "drop",
+	opSelect:        "select",
+	opTypedSelect:   "typed_select",
+	opLocalGet:      "local_get",
+	opLocalSet:      "local_set",
+	opLocalTee:      "local_tee",
+	opGlobalGet:     "global_get",
+	opGlobalSet:     "global_set",
+	opI32Load:       "i32_load",
+	opI64Load:       "i64_load",
+	opI32Load8S:     "i32_load8_s",
+	opI32Load8U:     "i32_load8_u",
+	opI32Load16S:    "i32_load16_s",
+	opI32Load16U:    "i32_load16_u",
+	opI64Load8S:     "i64_load8_s",
+	opI64Load8U:     "i64_load8_u",
+	opI64Load16S:    "i64_load16_s",
+	opI64Load16U:    "i64_load16_u",
+	opI64Load32S:    "i64_load32_s",
+	opI64Load32U:    "i64_load32_u",
+	opI32Store:      "i32_store",
+	opI64Store:      "i64_store",
+	opI32Store8:     "i32_store8",
+	opI32Store16:    "i32_store16",
+	opI64Store8:     "i64_store8",
+	opI64Store16:    "i64_store16",
+	opI64Store32:    "i64_store32",
+	opMemorySize:    "memory_size",
+	opMemoryGrow:    "memory_grow",
+	opI32Const:      "i32_const",
+	opI64Const:      "i64_const",
+	opI32Eqz:        "i32_eqz",
+	opI32Eq:         "i32_eq",
+	opI32Ne:         "i32_ne",
+	opI32LtS:        "i32_lt_s",
+	opI32LtU:        "i32_lt_u",
+	opI32GtS:        "i32_gt_s",
+	opI32GtU:        "i32_gt_u",
+	opI32LeS:        "i32_le_s",
+	opI32LeU:        "i32_le_u",
+	opI32GeS:        "i32_ge_s",
+	opI32GeU:        "i32_ge_u",
+	opI64Eqz:        "i64_eqz",
+	opI64Eq:         "i64_eq",
+	opI64Ne:         "i64_ne",
+	opI64LtS:        "i64_lt_s",
+	opI64LtU:        "i64_lt_u",
+	opI64GtS:        "i64_gt_s",
+	opI64GtU:        "i64_gt_u",
+	opI64LeS:        "i64_le_s",
+	opI64LeU:        "i64_le_u",
+	opI64GeS:        "i64_ge_s",
+	opI64GeU:        "i64_ge_u",
+	opI32Clz:        "i32_clz",
+	opI32Ctz:        "i32_ctz",
+	opI32Popcnt:     "i32_popcnt",
+	opI32Add:        "i32_add",
+	opI32Sub:        "i32_sub",
+	opI32Mul:        "i32_mul",
+	opI32DivS:       "i32_div_s",
+	opI32DivU:       "i32_div_u",
+	opI32RemS:       "i32_rem_s",
+	opI32RemU:       "i32_rem_u",
+	opI32And:        "i32_and",
+	opI32Or:         "i32_or",
+	opI32Xor:        "i32_xor",
+	opI32Shl:        "i32_shl",
+	opI32ShrS:       "i32_shr_s",
+	opI32ShrU:       "i32_shr_u",
+	opI32Rotl:       "i32_rotl",
+	opI32Rotr:       "i32_rotr",
+	opI64Clz:        "i64_clz",
+	opI64Ctz:        "i64_ctz",
+	opI64Popcnt:     "i64_popcnt",
+	opI64Add:        "i64_add",
+	opI64Sub:        "i64_sub",
+	opI64Mul:        "i64_mul",
+	opI64DivS:       "i64_div_s",
+	opI64DivU:       "i64_div_u",
+	opI64RemS:       "i64_rem_s",
+	opI64RemU:       "i64_rem_u",
+	opI64And:        "i64_and",
+	opI64Or:         "i64_or",
+	opI64Xor:        "i64_xor",
+	opI64Shl:        "i64_shl",
+	opI64ShrS:       "i64_shr_s",
+	opI64ShrU:       "i64_shr_u",
+	opI64Rotl:       "i64_rotl",
+	opI64Rotr:       "i64_rotr",
+	opI32WrapI64:    "i32_wrap_i64",
+	opI64ExtendI32S: "i64_extend_i32_s",
+	opI64ExtendI32U: "i64_extend_i32_u",
+	opI32Extend8S:   "i32_extend8_s",
+	opI32Extend16S:  "i32_extend16_s",
+	opI64Extend8S:   "i64_extend8_s",
+	opI64Extend16S:  "i64_extend16_s",
+	opI64Extend32S:  "i64_extend32_s",
+	opMemoryCopy:    "memory_copy",
+	opMemoryFill:    "memory_fill",
+}
+
+// opSignature returns the operand and result types of the opcodes which don't
+// depend on their immediates.
+func opSignature(op uint16) (params, results []byte, ok bool) {
+	var (
+		i32 = valueTypeI32
+		i64 = valueTypeI64
+	)
+	switch {
+	case op == opNop || op == opUnreachable:
+		return nil, nil, true
+	case op == opI32Load || op >= opI32Load8S && op <= opI32Load16U:
+		return []byte{i32}, []byte{i32}, true
+	case op == opI64Load || op >= opI64Load8S && op <= opI64Load32U:
+		return []byte{i32}, []byte{i64}, true
+	case op == opI32Store || op == opI32Store8 || op == opI32Store16:
+		return []byte{i32, i32}, nil, true
+	case op == opI64Store || op >= opI64Store8 && op <= opI64Store32:
+		return []byte{i32, i64}, nil, true
+	case op == opMemorySize || op == opI32Const:
+		return nil, []byte{i32}, true
+	case op == opI64Const:
+		return nil, []byte{i64}, true
+	case op == opMemoryGrow:
+		return []byte{i32}, []byte{i32}, true
+	case op == opMemoryCopy || op == opMemoryFill:
+		return []byte{i32, i32, i32}, nil, true
+	case op == opI32Eqz, op >= opI32Clz && op <= opI32Popcnt, op == opI32Extend8S || op == opI32Extend16S:
+		return []byte{i32}, []byte{i32}, true
+	case op >= opI32Eq && op <= opI32GeU, op >= opI32Add && op <= opI32Rotr:
+		return []byte{i32, i32}, []byte{i32}, true
+	case op == opI64Eqz, op == opI32WrapI64:
+		return []byte{i64}, []byte{i32}, true
+	case op >= opI64Eq && op <= opI64GeU:
+		return []byte{i64, i64}, []byte{i32}, true
+	case op >= opI64Clz && op <= opI64Popcnt, op >= opI64Extend8S && op <= opI64Extend32S:
+		return []byte{i64}, []byte{i64}, true
+	case op >= opI64Add && op <= opI64Rotr:
+		return []byte{i64, i64}, []byte{i64}, true
+	case op == opI64ExtendI32S || op == opI64ExtendI32U:
+		return []byte{i32}, []byte{i64}, true
+	}
+	return nil, nil, false
+}
+
+// memAccessSize returns the number of bytes accessed by a load or store.
+func memAccessSize(op uint16) uint32 {
+	switch op {
+	case opI32Load8S, opI32Load8U, opI64Load8S, opI64Load8U, opI32Store8, opI64Store8:
+		return 1
+	case opI32Load16S, opI32Load16U, opI64Load16S, opI64Load16U, opI32Store16, opI64Store16:
+		return 2
+	case opI32Load, opI64Load32S, opI64Load32U, opI32Store, opI64Store32:
+		return 4
+	}
+	return 8
+}
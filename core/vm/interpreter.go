@@ -36,6 +36,8 @@ type Config struct {
 	JumpTable [256]*operation // EVM instruction table, automatically populated if unset
 
 	ExtraEips []int // Additional EIPS that are to be enabled
+
+	WasmEngine string // WASM engine executing the contracts, the default one if empty
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -64,6 +66,8 @@ type EVMInterpreter struct {
 
 	readOnly   bool   // Whether to throw on stateful modifications
 	returnData []byte // Last CALL's return data for subsequent reuse
+
+	eofValid map[common.Hash]bool // Validity of the EOF containers run, by code hash
 }
 
 // NewEVMInterpreter returns a new instance of the Interpreter.
@@ -165,6 +169,14 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	}()
 	contract.Input = input
 
+	// EOF code starts at its code section, with the jumps validated with the
+	// container. Invalid containers run as legacy code, halting on the magic.
+	if in.evm.chainRules.IsEOF && hasEOFMagic(contract.Code) {
+		if offset, ok := in.eofCodeOffset(contract); ok {
+			pc, contract.eof = offset, true
+		}
+	}
+
 	if in.cfg.Debug {
 		defer func() {
 			if err != nil {
@@ -269,8 +281,9 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 			in.cfg.Tracer.CaptureStateAfter(pc, op, gasCopy, cost, callContext, in.returnData, in.evm.depth, err)
 		}
 
+		// The stop token of halting operations is handled by their flags below.
 		switch {
-		case err != nil:
+		case err != nil && err != errStopToken:
 			return nil, err
 		case operation.reverts:
 			return res, ErrExecutionReverted
@@ -0,0 +1,68 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+var haltTests = []struct {
+	code    string
+	ret     []byte
+	failure error
+}{
+	{"0x00", nil, nil},   // STOP
+	{"0x6001", nil, nil}, // end of code
+	{"0x602a60005260206000f3", common.LeftPadBytes([]byte{0x2a}, 32), nil},                  // RETURN
+	{"0x602a60005260206000fd", common.LeftPadBytes([]byte{0x2a}, 32), ErrExecutionReverted}, // REVERT
+	{"0xfe", nil, &ErrInvalidOpCode{opcode: INVALID}},
+}
+
+// This test checks that halting operations end the execution with their return
+// data, without leaking the internal stop token as an error.
+func TestInterpreterHalts(t *testing.T) {
+	for i, tt := range haltTests {
+		address := common.BytesToAddress([]byte("contract"))
+
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, hexutil.MustDecode(tt.code))
+
+		vmctx := BlockContext{
+			BlockNumber: new(big.Int),
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, params.AllEthashProtocolChanges, Config{})
+
+		ret, _, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 100000, new(big.Int))
+		if (err == nil) != (tt.failure == nil) || (err != nil && err.Error() != tt.failure.Error()) {
+			t.Errorf("test %d: failure mismatch: have %v, want %v", i, err, tt.failure)
+		}
+		if !bytes.Equal(ret, tt.ret) {
+			t.Errorf("test %d: return data mismatch: have %x, want %x", i, ret, tt.ret)
+		}
+	}
+}
@@ -55,6 +55,7 @@ type LogConfig struct {
 	EnableReturnData bool // enable return data capture
 	Debug            bool // print output during capture end
 	Limit            int  // maximum length of output, but zero means unlimited
+	EnableRowUsage   bool // enable circuit row usage estimation
 	// Chain overrides, can be used to execute a trace using future fork rules
 	Overrides *params.ChainConfig `json:"overrides,omitempty"`
 }
@@ -161,6 +162,8 @@ type StructLogger struct {
 	logs            []*StructLog
 	output          []byte
 	err             error
+
+	rows *RowCounter // nil unless row usage estimation is enabled
 }
 
 // NewStructLogger returns a new logger
@@ -172,7 +175,9 @@ func NewStructLogger(cfg *LogConfig) *StructLogger {
 	if cfg != nil {
 		logger.cfg = *cfg
 	}
-
+	if logger.cfg.EnableRowUsage {
+		logger.rows = NewRowCounter()
+	}
 	return logger
 }
 
@@ -185,6 +190,9 @@ func (l *StructLogger) Reset() {
 	l.callStackLogInd = nil
 	l.err = nil
 	l.createdAccount = nil
+	if l.rows != nil {
+		l.rows.Reset()
+	}
 }
 
 // CaptureStart implements the EVMLogger interface to initialize the tracing operation.
@@ -203,12 +211,41 @@ func (l *StructLogger) CaptureStart(env *EVM, from common.Address, to common.Add
 
 	l.statesAffected[from] = struct{}{}
 	l.statesAffected[to] = struct{}{}
+
+	if l.rows != nil {
+		l.rows.CaptureStart(env, from, to, isCreate, input, gas, value)
+	}
 }
 
 // CaptureState logs a new structured log message and pushes it out to the environment
 //
 // CaptureState also tracks SLOAD/SSTORE ops to track storage change.
 func (l *StructLogger) CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, opErr error) {
+	// rows are counted for all ops, even if they exceed the log limit
+	if l.rows != nil {
+		l.rows.CaptureState(pc, op, gas, cost, scope, rData, depth, opErr)
+	}
+	l.captureLog(pc, op, gas, cost, scope, rData, depth, opErr)
+}
+
+// CaptureHostCall implements the WASMHostLogger interface, logging the EVM
+// operation a WASM contract performs through a host function like the opcode,
+// before its execution.
+func (l *StructLogger) CaptureHostCall(op OpCode, scope *ScopeContext, depth int) {
+	if l.rows != nil {
+		l.rows.CaptureHostCall(op, scope, depth)
+	}
+	l.captureLog(math.MaxUint64, op, scope.Contract.Gas, 0, scope, nil, depth, nil)
+}
+
+// CapturePoseidon implements the WASMPoseidonLogger interface.
+func (l *StructLogger) CapturePoseidon(width, n uint64, depth int) {
+	if l.rows != nil {
+		l.rows.CapturePoseidon(width, n, depth)
+	}
+}
+
+func (l *StructLogger) captureLog(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, opErr error) {
 	memory := scope.Memory
 	stack := scope.Stack
 	contract := scope.Contract
@@ -279,6 +316,23 @@ func (l *StructLogger) CaptureState(pc uint64, op OpCode, gas, cost uint64, scop
 	l.logs = append(l.logs, structLog)
 }
 
+// CaptureWasmState implements the WASMLogger interface. The WASM instructions
+// aren't logged, but their rows are counted.
+func (l *StructLogger) CaptureWasmState(pc uint64, op OpCodeInfo, memory []MemoryChangeInfo, scope *ScopeContext, depth int, drop, keep uint32) {
+	if l.rows != nil {
+		l.rows.CaptureWasmState(pc, op, memory, scope, depth, drop, keep)
+	}
+}
+
+func (l *StructLogger) CaptureGlobalVariable(index uint64, op OpCodeInfo, value uint64) {}
+
+func (l *StructLogger) CaptureGlobalMemoryState(globalMemory map[uint32][]byte) {}
+
+func (l *StructLogger) CaptureGasState(gasCost uint64, scope *ScopeContext, depth int, err error) {}
+
+func (l *StructLogger) CaptureWasmFunctionCall(fnIndex, maxStackHeight, numLocals uint32, fnName string) {
+}
+
 func (l *StructLogger) CaptureStateAfter(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
 }
 
@@ -381,6 +435,15 @@ func (l *StructLogger) Error() error { return l.err }
 // Output returns the VM return value captured by the trace.
 func (l *StructLogger) Output() []byte { return l.output }
 
+// RowUsage returns the circuit rows estimated by the trace, or nil if row usage
+// estimation isn't enabled.
+func (l *StructLogger) RowUsage() *types.RowUsage {
+	if l.rows == nil {
+		return nil
+	}
+	return l.rows.RowUsage()
+}
+
 // WriteTrace writes a formatted trace to the given writer
 func WriteTrace(writer io.Writer, logs []*StructLog) {
 	for _, log := range logs {
@@ -534,3 +597,34 @@ type WASMLogger interface {
 	CaptureGasState(gasCost uint64, scope *ScopeContext, depth int, err error)
 	CaptureWasmFunctionCall(fnIndex, maxStackHeight, numLocals uint32, fnName string)
 }
+
+// WASMHostLogger is an optional extension of EVMLogger for tracers interested
+// in the EVM operations WASM contracts perform through host functions. The
+// call is captured before execution with the operands on the stack, in the same
+// layout as the EVM opcode. Tracers implementing it receive host calls only
+// through CaptureHostCall, instead of the CaptureState after execution.
+type WASMHostLogger interface {
+	CaptureHostCall(op OpCode, scope *ScopeContext, depth int)
+}
+
+// WASMFluentLogger is an optional extension of EVMLogger for tracers interested
+// in the fluent_v1 host functions. The call is captured once its gas is
+// charged, with a non-nil error if the contract can't pay for it.
+type WASMFluentLogger interface {
+	CaptureFluentCall(name string, gas uint64, scope *ScopeContext, depth int, err error)
+}
+
+// WASMPoseidonLogger is an optional extension of EVMLogger for tracers
+// interested in the poseidon hashes WASM contracts compute through host
+// functions. The hash of n elements is captured once its gas is charged.
+type WASMPoseidonLogger interface {
+	CapturePoseidon(width, n uint64, depth int)
+}
+
+// EVMTxLogger is an optional extension of EVMLogger for tracers interested in
+// the whole transaction, including the nonce update, gas purchase and refund
+// which happen outside of the EVM execution.
+type EVMTxLogger interface {
+	CaptureTxStart(env *EVM, from common.Address, to *common.Address)
+	CaptureTxEnd(restGas uint64)
+}
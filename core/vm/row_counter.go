@@ -0,0 +1,201 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// Row cost estimates of the operations the sub-circuits prove. They mirror the
+// circuit layouts closely enough to compare transactions, but aren't exact.
+const (
+	keccakBlockSize        = 136 // Rate of the keccak sponge in bytes
+	keccakRowsPerBlock     = 300 // Rows of a single keccak-f permutation
+	poseidonRowsPerHash    = 1   // Rows of a single poseidon permutation
+	poseidonHashesPerTrie  = 32  // Expected zktrie path length of a state access
+	poseidonHashesPerChunk = 1   // Poseidon hashes per 31 byte chunk of code
+	copyBytesPerRow        = 1   // Bytes moved per copy circuit row
+)
+
+// RowCounter is an EVM and WASM logger estimating the number of rows a
+// transaction occupies in each of the zkEVM sub-circuits.
+type RowCounter struct {
+	usage types.RowUsage
+}
+
+// NewRowCounter creates a new row counting tracer.
+func NewRowCounter() *RowCounter {
+	return new(RowCounter)
+}
+
+// RowUsage returns the rows consumed so far.
+func (r *RowCounter) RowUsage() *types.RowUsage {
+	usage := r.usage
+	return &usage
+}
+
+// Reset clears the counted rows.
+func (r *RowCounter) Reset() {
+	r.usage = types.RowUsage{}
+}
+
+// CaptureStart accounts for the transaction level work: hashing the
+// transaction, updating the sender and recipient and copying the input.
+func (r *RowCounter) CaptureStart(env *EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	r.keccak(uint64(len(input)))
+	r.stateAccess(2)
+	r.copy(uint64(len(input)))
+	if create {
+		r.codeHash(uint64(len(input)))
+	}
+}
+
+// CaptureState counts the rows of the opcode about to be executed.
+func (r *RowCounter) CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
+	r.usage.EVMSteps++
+	r.operation(op, scope.Stack)
+}
+
+// CaptureHostCall counts the rows of the EVM operation a WASM contract
+// performs through a host function, like the opcode would take.
+func (r *RowCounter) CaptureHostCall(op OpCode, scope *ScopeContext, depth int) {
+	r.operation(op, scope.Stack)
+}
+
+// CapturePoseidon counts the rows of the poseidon hash of n elements a WASM
+// contract computes through a host function.
+func (r *RowCounter) CapturePoseidon(width, n uint64, depth int) {
+	r.usage.Poseidon += poseidonPermutations(width, n) * poseidonRowsPerHash
+}
+
+// operation counts the rows of the opcode, with its operands on the stack, on
+// top of the execution step.
+func (r *RowCounter) operation(op OpCode, stack *Stack) {
+	switch op {
+	case SHA3:
+		if size, ok := stackUint64(stack, 1); ok {
+			r.keccak(size)
+			r.copy(size)
+		}
+	case SLOAD, SSTORE, BALANCE, EXTCODESIZE, EXTCODEHASH, SELFDESTRUCT:
+		r.stateAccess(1)
+	case CALL, CALLCODE:
+		r.stateAccess(1)
+		if size, ok := stackUint64(stack, 4); ok {
+			r.copy(size)
+		}
+	case DELEGATECALL, STATICCALL:
+		r.stateAccess(1)
+		if size, ok := stackUint64(stack, 3); ok {
+			r.copy(size)
+		}
+	case MLOAD, MSTORE, MSTORE8:
+		r.usage.Memory++
+	case CALLDATACOPY, CODECOPY, RETURNDATACOPY:
+		if size, ok := stackUint64(stack, 2); ok {
+			r.copy(size)
+		}
+	case EXTCODECOPY:
+		r.stateAccess(1)
+		if size, ok := stackUint64(stack, 3); ok {
+			r.copy(size)
+		}
+	case LOG0, LOG1, LOG2, LOG3, LOG4, RETURN, REVERT:
+		if size, ok := stackUint64(stack, 1); ok {
+			r.copy(size)
+		}
+	case CREATE, CREATE2:
+		r.stateAccess(1)
+		if size, ok := stackUint64(stack, 2); ok {
+			r.copy(size)
+			r.codeHash(size)
+			if op == CREATE2 {
+				r.keccak(size)
+			}
+		}
+	}
+}
+
+func (*RowCounter) CaptureStateAfter(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (*RowCounter) CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error) {
+}
+
+func (*RowCounter) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {}
+
+func (*RowCounter) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (*RowCounter) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (*RowCounter) CaptureGlobalVariable(index uint64, op OpCodeInfo, value uint64) {}
+
+func (*RowCounter) CaptureGlobalMemoryState(globalMemory map[uint32][]byte) {}
+
+// CaptureWasmState counts the rows of the WASM instruction about to be
+// executed, along with the memory it writes.
+func (r *RowCounter) CaptureWasmState(pc uint64, op OpCodeInfo, memory []MemoryChangeInfo, scope *ScopeContext, depth int, drop, keep uint32) {
+	r.usage.WasmSteps++
+	for _, change := range memory {
+		r.usage.Memory += toWordSize(uint64(len(change.Value)))
+	}
+}
+
+func (*RowCounter) CaptureGasState(gasCost uint64, scope *ScopeContext, depth int, err error) {}
+
+func (*RowCounter) CaptureWasmFunctionCall(fnIndex, maxStackHeight, numLocals uint32, fnName string) {
+}
+
+// keccak accounts for hashing size bytes.
+func (r *RowCounter) keccak(size uint64) {
+	r.usage.Keccak += (size/keccakBlockSize + 1) * keccakRowsPerBlock
+}
+
+// stateAccess accounts for n reads or writes of the state trie.
+func (r *RowCounter) stateAccess(n uint64) {
+	r.usage.Storage += n
+	r.usage.Poseidon += n * poseidonHashesPerTrie * poseidonRowsPerHash
+}
+
+// codeHash accounts for the poseidon code hash of size bytes of bytecode.
+func (r *RowCounter) codeHash(size uint64) {
+	r.usage.Poseidon += ((size+30)/31 + 1) * poseidonHashesPerChunk * poseidonRowsPerHash
+}
+
+// copy accounts for moving size bytes between memory regions.
+func (r *RowCounter) copy(size uint64) {
+	r.usage.Copy += size / copyBytesPerRow
+	r.usage.Memory += toWordSize(size)
+}
+
+// stackUint64 returns the n-th stack item from the top, if it exists and fits
+// into 64 bits. Larger values would run out of gas before executing anyway.
+func stackUint64(stack *Stack, n int) (uint64, bool) {
+	if stack.len() <= n {
+		return 0, false
+	}
+	item := stack.Back(n)
+	if !item.IsUint64() {
+		return 0, false
+	}
+	return item.Uint64(), true
+}
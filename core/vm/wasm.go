@@ -7,7 +7,6 @@ import (
 	"github.com/holiman/uint256"
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/common/math"
-	zkwasm_wasmi "github.com/wasm0/zkwasm-wasmi"
 	"log"
 	"strings"
 )
@@ -21,20 +20,24 @@ type WASMInterpreter struct {
 	// stateless params
 	readOnly   bool
 	returnData []byte
-	wasmEngine *zkwasm_wasmi.WasmEngine
+	wasmEngine WasmEngine
 }
 
 func NewWASMInterpreter(
 	evm *EVM,
 	config Config,
 ) VirtualInterpreter {
+	wasmEngine, err := NewWasmEngine(config.WasmEngine)
+	if err != nil {
+		log.Panicf("failed to create wasm engine: %v", err)
+	}
 	instance := &WASMInterpreter{
 		evm:        evm,
 		config:     config,
-		wasmEngine: zkwasm_wasmi.NewWasmEngine(),
+		wasmEngine: wasmEngine,
 	}
 	instance.registerNativeFunctions()
-	if config.Debug {
+	if _, ok := config.Tracer.(WASMLogger); config.Debug && ok {
 		instance.registerLogsCallback()
 	}
 	return instance
@@ -90,14 +93,23 @@ func (in *WASMInterpreter) writeMemory(offset, size uint64, value []byte) {
 	// we don't know does value's len matches size, to match EVM behavior we just create an empty array and copy data
 	value2 := make([]byte, size)
 	copy(value2, value)
-	_ = in.wasmEngine.TraceMemoryChange(uint32(offset), uint32(size), value2)
+	if offset+size > in.memorySize() {
+		panic(ErrBadInputParams)
+	}
+	if err := in.wasmEngine.WriteMemory(uint32(offset), value2); err != nil {
+		panic(err)
+	}
 }
 
 func (in *WASMInterpreter) readMemory(offset, size uint64) []byte {
-	// do honest read memory here
-	data, _ := in.wasmEngine.MemoryData()
-	dataChunk := data[offset : offset+size]
-	return dataChunk
+	if offset+size > in.memorySize() {
+		panic(ErrBadInputParams)
+	}
+	data, err := in.wasmEngine.ReadMemory(uint32(offset), uint32(size))
+	if err != nil {
+		panic(err)
+	}
+	return data
 }
 
 func (in *WASMInterpreter) rawData() []byte {
@@ -111,8 +123,8 @@ func (in *WASMInterpreter) resizeMemory(size uint64) {
 }
 
 func (in *WASMInterpreter) memorySize() uint64 {
-	data, _ := in.wasmEngine.MemoryData()
-	return uint64(len(data))
+	size, _ := in.wasmEngine.MemorySize()
+	return uint64(size)
 }
 
 func (in *WASMInterpreter) Run(
@@ -142,14 +154,13 @@ func (in *WASMInterpreter) Run(
 
 	in.readOnly = readOnly
 
+	// Tracers not implementing [WASMLogger] only observe the host calls
 	var wasmLogger WASMLogger
-	var ok bool
 	if in.config.Debug {
 		if in.config.Tracer == nil {
 			panic("tracer must be configured in debug mode")
-		} else if wasmLogger, ok = in.config.Tracer.(WASMLogger); !ok {
-			panic("tracer must implement [WASMLogger] in this mode")
 		}
+		wasmLogger, _ = in.config.Tracer.(WASMLogger)
 	}
 
 	//ctx := context.TODO()
@@ -173,15 +184,6 @@ func (in *WASMInterpreter) Run(
 	//	_ = runtime.Close(ctx)
 	//}()
 
-	// if contract deployment then check contract code is safe (do injection)
-	if input == nil || len(input) == 0 {
-		injectResult, err := injectGasComputationAndStackProtection(contract.Code)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check contract deployment code: %s", err)
-		}
-		contract.Code = injectResult
-	}
-
 	//hostModuleBuilder := runtime.NewHostModuleBuilder("env")
 	//hostModuleBuilder = in.registerNativeFunctions(hostModuleBuilder)
 	//_, err = hostModuleBuilder.Instantiate(ctx)
@@ -203,7 +205,20 @@ func (in *WASMInterpreter) Run(
 	//	scope.Memory = newMemoryFromSlice([]byte{})
 	//}
 
-	in.wasmEngine.SetWasmBinary(contract.Code)
+	// The memory and the table of the module are allocated before any of its
+	// instructions is executed, so they're charged upfront
+	module, err := compileWasm(contract)
+	if err != nil {
+		return nil, ErrBadWasmBinary
+	}
+	if !contract.UseGas(wasmAllocationGas(module)) {
+		return nil, ErrOutOfGas
+	}
+	if engine, ok := in.wasmEngine.(wasmModuleEngine); ok {
+		engine.SetWasmModule(module)
+	} else {
+		in.wasmEngine.SetWasmBinary(contract.Code)
+	}
 
 	if len(in.stateQueue) != in.evm.depth-1 {
 		panic("state queue len and evm depth mismatch, this is not possible")
@@ -258,13 +273,16 @@ func (in *WASMInterpreter) Run(
 		return res, nil
 	}()
 
-	if zkwasm_wasmi.ComputeTraceErrorCode(res) == zkwasm_wasmi.ComputeTraceErrorCodeOutOfGas {
+	if res == wasmExitOutOfGas {
 		err = ErrOutOfGas
-	} else if zkwasm_wasmi.ComputeTraceErrorCode(res) == zkwasm_wasmi.ComputeTraceErrorCodeExecutionReverted ||
-		zkwasm_wasmi.ComputeTraceErrorCode(res) == zkwasm_wasmi.ComputeTraceErrorCodeUnknown {
+	} else if res == wasmExitExecutionReverted || res == wasmExitUnknown {
 		err = ErrExecutionReverted
-	} else if zkwasm_wasmi.ComputeTraceErrorCode(res) == zkwasm_wasmi.ComputeTraceErrorCodeStopToken {
+	} else if res == wasmExitStopToken {
 		err = errStopToken
+	} else if res == wasmExitInvalidInput {
+		err = ErrBadInputParams
+	} else if res == wasmExitWriteProtection {
+		err = ErrWriteProtection
 	}
 
 	type traceMemory struct {
@@ -300,16 +318,16 @@ func (in *WASMInterpreter) Run(
 		FnMetas         []functionMeta   `json:"fn_metas"`
 	}
 
-	if in.config.Debug {
-		traceJsonBytes, err := in.wasmEngine.DumpTrace()
-		if err != nil {
-			fmt.Printf("can't calculate trace")
-			panic(err)
-		}
+	var traceJsonBytes []byte
+	if wasmLogger != nil {
+		// there is no trace if the binary couldn't be instantiated
+		traceJsonBytes, _ = in.wasmEngine.DumpTrace()
+	}
+	if traceJsonBytes != nil {
 		trace := &traceStruct{}
-		err = json.Unmarshal(traceJsonBytes, trace)
+		err := json.Unmarshal(traceJsonBytes, trace)
 		if err != nil {
-			fmt.Printf("received bad json from wasmi")
+			fmt.Printf("received bad json from wasm engine")
 			panic(err)
 		}
 		if trace.GlobalMemory != nil {
@@ -388,12 +406,16 @@ func (in *WASMInterpreter) execEvmOp(opcode OpCode, scope *ScopeContext) (err er
 		op = in.config.JumpTable[opcode]
 	}
 	cost := op.constantGas
+	hostLogger, _ := in.config.Tracer.(WASMHostLogger)
+	if in.config.Debug && hostLogger != nil {
+		hostLogger.CaptureHostCall(opcode, scope, in.evm.depth)
+	}
 	defer func() {
 		err2 := err
 		if err2 == errStopToken {
 			err2 = nil
 		}
-		if in.config.Debug {
+		if in.config.Debug && hostLogger == nil {
 			in.config.Tracer.CaptureState(math.MaxUint64, opcode, gasCopy, cost, scope, in.returnData, in.evm.depth, err2)
 		}
 	}()
@@ -591,7 +613,7 @@ func (in *WASMInterpreter) registerNativeFunction(
 	in.wasmEngine.RegisterHostFnI32(fnName, paramsCount, func(params []int32) int32 {
 		if len(params) != paramsCount {
 			log.Printf("host fn '%s' called with params count %d while expected %d\n", fnNameInner, len(params), paramsCount)
-			return int32(zkwasm_wasmi.ComputeTraceErrorCodeUnknown)
+			return wasmExitUnknown
 		}
 		input := make([]uint64, len(params))
 		for i, paramValue := range params {
@@ -599,11 +621,11 @@ func (in *WASMInterpreter) registerNativeFunction(
 		}
 		err := in.processOpcode(input, opcode, finalizer, inputPreprocessors...)
 		if err == errStopToken {
-			return int32(zkwasm_wasmi.ComputeTraceErrorCodeStopToken)
+			return wasmExitStopToken
 		} else if err != nil {
 			panic(err)
 		}
-		return int32(zkwasm_wasmi.ComputeTraceErrorCodeOk)
+		return wasmExitOk
 	})
 }
 
@@ -737,10 +759,58 @@ func (in *WASMInterpreter) registerNativeFunctions() {
 		replaceMemOffsetWithValueOnStack(1, AddressFieldType))
 	in.registerNativeFunction("_evm_revert", REVERT, nil)
 	in.registerNativeFunction("_evm_selfdestruct", SELFDESTRUCT, nil)
+	// precompiled hashes
+	in.registerPoseidonFunction()
+	in.registerFluentFunctions()
 
 	in.registerGasCheckFunction()
 }
 
+// registerPoseidonFunction registers the `_evm_poseidon` host function. It takes
+// (inputOffset, inputLen, width, capacity, destOffset), hashes the 32 byte
+// inputs the same way as the poseidon precompile and writes the 32 byte hash
+// to destOffset.
+func (in *WASMInterpreter) registerPoseidonFunction() {
+	paramsCount := 5
+	in.wasmEngine.RegisterHostFnI32("_evm_poseidon", paramsCount, func(params []int32) int32 {
+		if len(params) != paramsCount {
+			return wasmExitUnknown
+		}
+		offset, size := uint64(uint32(params[0])), uint64(uint32(params[1]))
+		width, nBytes := uint64(uint32(params[2])), uint64(uint32(params[3]))
+		dest := uint64(uint32(params[4]))
+		if offset+size > in.memorySize() || dest+HashDestLen > in.memorySize() {
+			panic(ErrBadInputParams)
+		}
+		scope := in.Scope()
+		gas := poseidonGas(width, size/32)
+		if wasmLogger, ok := in.config.Tracer.(WASMLogger); in.config.Debug && ok {
+			if scope.Contract.Gas < gas {
+				wasmLogger.CaptureGasState(gas, scope, in.evm.depth, ErrOutOfGas)
+				return wasmExitOutOfGas
+			}
+			wasmLogger.CaptureGasState(gas, scope, in.evm.depth, nil)
+		}
+		if !scope.Contract.UseGas(gas) {
+			panic(ErrOutOfGas)
+		}
+		in.tracePoseidon(width, size/32)
+		hash, err := runPoseidon(width, nBytes, in.readMemory(offset, size))
+		if err != nil {
+			panic(err)
+		}
+		in.writeMemory(dest, HashDestLen, hash)
+		return wasmExitOk
+	})
+}
+
+// tracePoseidon reports the poseidon hash of n elements to the tracers.
+func (in *WASMInterpreter) tracePoseidon(width, n uint64) {
+	if poseidonLogger, ok := in.config.Tracer.(WASMPoseidonLogger); in.config.Debug && ok {
+		poseidonLogger.CapturePoseidon(width, n, in.evm.depth)
+	}
+}
+
 func (in *WASMInterpreter) registerGasCheckFunction() {
 	paramsCount := 1
 	in.wasmEngine.RegisterHostFnI64(GasImportedFunction, paramsCount, func(params []int64) int32 {
@@ -754,14 +824,13 @@ func (in *WASMInterpreter) registerGasCheckFunction() {
 		}
 		val := int64(input[0])
 		gasSpend := uint64(val)
-		if in.config.Debug {
+		if wasmLogger, ok := in.config.Tracer.(WASMLogger); in.config.Debug && ok {
 			scope := &ScopeContext{
 				Contract: scope.Contract,
 			}
-			wasmLogger := in.config.Tracer.(WASMLogger)
 			if scope.Contract.Gas < gasSpend {
 				wasmLogger.CaptureGasState(gasSpend, scope, in.evm.depth, ErrOutOfGas)
-				return int32(zkwasm_wasmi.ComputeTraceErrorCodeOutOfGas)
+				return wasmExitOutOfGas
 			} else {
 				wasmLogger.CaptureGasState(gasSpend, scope, in.evm.depth, nil)
 			}
@@ -769,6 +838,6 @@ func (in *WASMInterpreter) registerGasCheckFunction() {
 		if !scope.Contract.UseGas(gasSpend) {
 			panic(ErrOutOfGas)
 		}
-		return int32(zkwasm_wasmi.ComputeTraceErrorCodeOk)
+		return wasmExitOk
 	})
 }
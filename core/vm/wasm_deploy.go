@@ -0,0 +1,47 @@
+package vm
+
+import (
+	"github.com/scroll-tech/go-ethereum/core/vm/gowasm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// prepareWasmInitCode injects the gas computation into the WASM initcode of a
+// contract creation, which pays for its execution like the deployed code. The
+// initcode is charged per word before it's decoded, like EIP-3860 does for the
+// EVM.
+func (evm *EVM) prepareWasmInitCode(contract *Contract) error {
+	if len(contract.Code) == 0 {
+		return nil
+	}
+	if !contract.UseGas(words(uint64(len(contract.Code))) * params.WasmInitCodeWordGas) {
+		return ErrOutOfGas
+	}
+	code, err := injectGasComputationAndStackProtection(contract.Code)
+	if err != nil {
+		return ErrBadWasmBinary
+	}
+	contract.Code = code
+	return nil
+}
+
+// prepareWasmCode injects the gas computation into the WASM code returned by
+// a contract creation, and checks the result against the code size limit. It
+// returns the code to store, and the price of its functions and code section
+// which is charged on top of the CreateDataGas of its bytes.
+func (evm *EVM) prepareWasmCode(code []byte) ([]byte, uint64, error) {
+	if len(code) == 0 {
+		return code, 0, nil
+	}
+	code, err := injectGasComputationAndStackProtection(code)
+	if err != nil {
+		return nil, 0, ErrBadWasmBinary
+	}
+	if len(code) > evm.chainConfig.Scroll.WasmCodeSizeLimit() {
+		return nil, 0, ErrMaxCodeSizeExceeded
+	}
+	funcs, codeSize, err := gowasm.Measure(code)
+	if err != nil {
+		return nil, 0, ErrBadWasmBinary
+	}
+	return code, funcs*params.WasmDeployFunctionGas + codeSize*params.WasmDeployCodeGas, nil
+}
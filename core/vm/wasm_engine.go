@@ -0,0 +1,127 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/vm/gowasm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// WasmEngine executes the WASM contracts for the WASMInterpreter. Host functions
+// are registered once per engine, and are linked to the imports of every
+// contract under the names given by gowasm.HostName.
+//
+// The contracts are executed by the pure-Go engine of the gowasm package unless
+// another engine is configured. The wasmi binding, available on cgo builds, only
+// records the results of host functions in its trace instead of writing them to
+// the memory of the contract, so it must not be used to validate blocks.
+type WasmEngine interface {
+	// SetWasmBinary instantiates the binary to execute.
+	SetWasmBinary(binary []byte)
+	// ComputeResult executes the main function of the binary. A nonzero result
+	// of a host function halts the execution and is returned.
+	ComputeResult() (int32, error)
+	// GetLastPc returns the offset of the last executed instruction.
+	GetLastPc() (int32, error)
+	// MemoryData returns a copy of the whole memory of the running binary.
+	MemoryData() ([]byte, error)
+	// MemorySize returns the size of the memory of the running binary.
+	MemorySize() (uint32, error)
+	// ReadMemory returns a copy of size bytes of the memory at offset.
+	ReadMemory(offset, size uint32) ([]byte, error)
+	// WriteMemory writes the result of a host function to the memory.
+	WriteMemory(offset uint32, data []byte) error
+	RegisterHostFnI32(name string, paramsCount int, cb func(params []int32) int32) bool
+	RegisterHostFnI64(name string, paramsCount int, cb func(params []int64) int32) bool
+	// RegisterCallbackOnAfterItemAddedToLogs enables the per instruction trace.
+	RegisterCallbackOnAfterItemAddedToLogs(cb func(jsonTrace string))
+	// DumpTrace returns the JSON encoded trace of the execution.
+	DumpTrace() ([]byte, error)
+}
+
+// wasmModuleEngine is implemented by the engines executing the modules compiled
+// by gowasm, which are cached by code hash across the executions.
+type wasmModuleEngine interface {
+	SetWasmModule(m *gowasm.Module)
+}
+
+const (
+	WasmEngineWasmi = "wasmi" // wasmi through cgo
+	WasmEngineGo    = "go"    // pure-Go interpreter
+)
+
+// Exit codes of the host functions.
+const (
+	wasmExitOk int32 = iota
+	wasmExitOutOfGas
+	wasmExitExecutionReverted
+	wasmExitStopToken
+	wasmExitUnknown
+	wasmExitInvalidInput
+	wasmExitWriteProtection
+)
+
+var (
+	wasmEngines = map[string]func() WasmEngine{
+		WasmEngineGo: func() WasmEngine { return gowasm.NewEngine() },
+	}
+	// defaultWasmEngine is the same on every build, as the engines may not
+	// agree on the result of a contract
+	defaultWasmEngine = WasmEngineGo
+
+	// wasmModules caches the compiled WASM contracts by code hash
+	wasmModules, _ = lru.New(wasmModuleCacheSize)
+)
+
+const wasmModuleCacheSize = 256
+
+// WasmEngines returns the names of the available WASM engines.
+func WasmEngines() []string {
+	names := make([]string, 0, len(wasmEngines))
+	for name := range wasmEngines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewWasmEngine creates the named WASM engine, or the default one if the name
+// is empty.
+func NewWasmEngine(name string) (WasmEngine, error) {
+	if name == "" {
+		name = defaultWasmEngine
+	}
+	newEngine, ok := wasmEngines[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown wasm engine %q, available: %v", name, WasmEngines())
+	}
+	return newEngine(), nil
+}
+
+// compileWasm returns the compiled code of the contract, cached by code hash
+// unless the hash is unknown.
+func compileWasm(contract *Contract) (*gowasm.Module, error) {
+	if contract.CodeHash == (common.Hash{}) {
+		return gowasm.Compile(contract.Code)
+	}
+	if m, ok := wasmModules.Get(contract.CodeHash); ok {
+		return m.(*gowasm.Module), nil
+	}
+	m, err := gowasm.Compile(contract.Code)
+	if err != nil {
+		return nil, err
+	}
+	wasmModules.Add(contract.CodeHash, m)
+	return m, nil
+}
+
+// wasmAllocationGas returns the price of the memory and of the table allocated
+// by every instantiation of the module, which the injected code doesn't charge.
+func wasmAllocationGas(m *gowasm.Module) uint64 {
+	pages, tableSize := m.Allocation()
+	return pages*params.WasmMemoryPageGas + tableSize*params.WasmTableElementGas
+}
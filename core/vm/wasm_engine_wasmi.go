@@ -0,0 +1,187 @@
+//go:build cgo
+
+package vm
+
+import (
+	zkwasm_wasmi "github.com/wasm0/zkwasm-wasmi"
+
+	"github.com/scroll-tech/go-ethereum/core/vm/gowasm"
+)
+
+func init() {
+	wasmEngines[WasmEngineWasmi] = func() WasmEngine {
+		return &wasmiEngine{zkwasm_wasmi.NewWasmEngine()}
+	}
+}
+
+// wasmiEngine adapts the wasmi binding, whose callbacks are untyped, to the
+// WasmEngine interface. Note that WriteMemory only records the change in the
+// trace of wasmi, and that the binding only exposes the memory as a whole.
+type wasmiEngine struct {
+	*zkwasm_wasmi.WasmEngine
+}
+
+func (e *wasmiEngine) RegisterHostFnI32(name string, paramsCount int, cb func(params []int32) int32) bool {
+	return e.WasmEngine.RegisterHostFnI32(name, paramsCount, cb)
+}
+
+func (e *wasmiEngine) RegisterHostFnI64(name string, paramsCount int, cb func(params []int64) int32) bool {
+	return e.WasmEngine.RegisterHostFnI64(name, paramsCount, cb)
+}
+
+func (e *wasmiEngine) MemorySize() (uint32, error) {
+	data, err := e.WasmEngine.MemoryData()
+	return uint32(len(data)), err
+}
+
+func (e *wasmiEngine) ReadMemory(offset, size uint32) ([]byte, error) {
+	data, err := e.WasmEngine.MemoryData()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(offset)+uint64(size) > uint64(len(data)) {
+		return nil, ErrBadInputParams
+	}
+	return data[offset : offset+size], nil
+}
+
+func (e *wasmiEngine) WriteMemory(offset uint32, data []byte) error {
+	return e.WasmEngine.TraceMemoryChange(offset, uint32(len(data)), data)
+}
+
+func (e *wasmiEngine) RegisterCallbackOnAfterItemAddedToLogs(cb func(jsonTrace string)) {
+	e.WasmEngine.RegisterCallbackOnAfterItemAddedToLogs(cb)
+}
+
+// SetWasmBinary links the imports of the modules other than "env", which the
+// binding doesn't support, by moving them to the "env" module under their
+// qualified names. This shifts the pcs of the trace by the size difference of
+// the import section.
+func (e *wasmiEngine) SetWasmBinary(binary []byte) {
+	e.WasmEngine.SetWasmBinary(qualifyImports(binary))
+}
+
+// qualifyImports rewrites the import section of the binary, leaving it as is
+// if it has no import outside of the "env" module or can't be decoded.
+func qualifyImports(binary []byte) []byte {
+	if len(binary) < 8 {
+		return binary
+	}
+	for pos := 8; pos < len(binary); {
+		id := binary[pos]
+		size, n := readULEB(binary[pos+1:])
+		start := pos + 1 + n
+		if n == 0 || start+int(size) > len(binary) {
+			return binary
+		}
+		if id != 2 {
+			pos = start + int(size)
+			continue
+		}
+		payload, ok := rewriteImports(binary[start : start+int(size)])
+		if !ok {
+			return binary
+		}
+		res := append([]byte{}, binary[:pos+1]...)
+		res = appendULEB(res, uint64(len(payload)))
+		res = append(res, payload...)
+		return append(res, binary[start+int(size):]...)
+	}
+	return binary
+}
+
+func rewriteImports(section []byte) ([]byte, bool) {
+	count, pos := readULEB(section)
+	if pos == 0 {
+		return nil, false
+	}
+	readName := func() (string, bool) {
+		size, n := readULEB(section[pos:])
+		if n == 0 || pos+n+int(size) > len(section) {
+			return "", false
+		}
+		name := string(section[pos+n : pos+n+int(size)])
+		pos += n + int(size)
+		return name, true
+	}
+	skipULEB := func() bool {
+		_, n := readULEB(section[pos:])
+		pos += n
+		return n != 0
+	}
+	skipLimits := func() bool {
+		if pos >= len(section) {
+			return false
+		}
+		hasMax := section[pos] == 1
+		pos++
+		return skipULEB() && (!hasMax || skipULEB())
+	}
+	res := appendULEB(nil, count)
+	qualified := false
+	for i := uint64(0); i < count; i++ {
+		module, ok := readName()
+		if !ok {
+			return nil, false
+		}
+		name, ok := readName()
+		if !ok || pos >= len(section) {
+			return nil, false
+		}
+		descStart := pos
+		kind := section[pos]
+		pos++
+		switch kind {
+		case 0: // function
+			ok = skipULEB()
+		case 1: // table
+			pos++
+			ok = skipLimits()
+		case 2: // memory
+			ok = skipLimits()
+		case 3: // global
+			pos += 2
+			ok = pos <= len(section)
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, false
+		}
+		if module != "env" {
+			module, name = "env", gowasm.HostName(module, name)
+			qualified = true
+		}
+		res = appendULEB(res, uint64(len(module)))
+		res = append(res, module...)
+		res = appendULEB(res, uint64(len(name)))
+		res = append(res, name...)
+		res = append(res, section[descStart:pos]...)
+	}
+	return res, qualified
+}
+
+func readULEB(data []byte) (uint64, int) {
+	var res uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		res |= uint64(data[i]&0x7f) << (7 * i)
+		if data[i]&0x80 == 0 {
+			return res, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func appendULEB(data []byte, n uint64) []byte {
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			b |= 0x80
+		}
+		data = append(data, b)
+		if n == 0 {
+			return data
+		}
+	}
+}
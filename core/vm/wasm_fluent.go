@@ -0,0 +1,267 @@
+package vm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/holiman/uint256"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/vm/gowasm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// FluentModule is the versioned host ABI module of the WASM contracts. Unlike
+// the "env" module, which mirrors the EVM opcodes, its functions work on byte
+// ranges of the memory and give direct access to the crypto primitives.
+const FluentModule = "fluent_v1"
+
+// registerFluentFunctions registers the host functions of the fluent_v1 module:
+//
+//	storage_read(keyOffset, destOffset, len)
+//	storage_write(keyOffset, srcOffset, len)
+//	input(destOffset, offset, len)
+//	input_size(destOffset)
+//	ecrecover(hashOffset, sigOffset, destOffset)
+//	sha256(offset, len, destOffset)
+//	poseidon(offset, len, destOffset)
+func (in *WASMInterpreter) registerFluentFunctions() {
+	in.registerFluentFunction("storage_read", 3, in.fluentStorageRead)
+	in.registerFluentFunction("storage_write", 3, in.fluentStorageWrite)
+	in.registerFluentFunction("input", 3, in.fluentInput)
+	in.registerFluentFunction("input_size", 1, in.fluentInputSize)
+	in.registerFluentFunction("ecrecover", 3, in.fluentEcrecover)
+	in.registerFluentFunction("sha256", 3, in.fluentSha256)
+	in.registerFluentFunction("poseidon", 3, in.fluentPoseidon)
+}
+
+func (in *WASMInterpreter) registerFluentFunction(name string, paramsCount int, fn func(scope *ScopeContext, args []uint64) int32) {
+	in.wasmEngine.RegisterHostFnI32(gowasm.HostName(FluentModule, name), paramsCount, func(params []int32) int32 {
+		if len(params) != paramsCount {
+			return wasmExitUnknown
+		}
+		input := make([]uint64, len(params))
+		for i, paramValue := range params {
+			input[i] = uint64(uint32(paramValue))
+		}
+		return fn(in.Scope(), input)
+	})
+}
+
+// useFluentGas charges the gas of a fluent_v1 host function, reporting it to
+// the tracers. It returns false if the contract can't pay for it.
+func (in *WASMInterpreter) useFluentGas(name string, gas uint64, scope *ScopeContext) bool {
+	if scope.Contract.Gas < gas {
+		in.traceFluentCall(name, gas, scope, ErrOutOfGas)
+		return false
+	}
+	in.traceFluentCall(name, gas, scope, nil)
+	return scope.Contract.UseGas(gas)
+}
+
+// traceFluentCall reports the gas of a fluent_v1 host function to the tracers.
+func (in *WASMInterpreter) traceFluentCall(name string, gas uint64, scope *ScopeContext, err error) {
+	if !in.config.Debug {
+		return
+	}
+	if wasmLogger, ok := in.config.Tracer.(WASMLogger); ok {
+		wasmLogger.CaptureGasState(gas, scope, in.evm.depth, err)
+	}
+	if fluentLogger, ok := in.config.Tracer.(WASMFluentLogger); ok {
+		fluentLogger.CaptureFluentCall(name, gas, scope, in.evm.depth, err)
+	}
+}
+
+// words returns the number of 32 byte words covering size bytes. Unlike
+// toWordSize, which counts the memory pages, it's used to price the data.
+func words(size uint64) uint64 {
+	return (size + 31) / 32
+}
+
+// inMemory reports whether a range fits in the memory.
+func (in *WASMInterpreter) inMemory(offset, size uint64) bool {
+	return offset+size <= in.memorySize()
+}
+
+// fluentExitCode returns the exit code halting the contract with the error.
+func fluentExitCode(err error) int32 {
+	switch err {
+	case ErrOutOfGas:
+		return wasmExitOutOfGas
+	case ErrWriteProtection:
+		return wasmExitWriteProtection
+	}
+	return wasmExitUnknown
+}
+
+// storageSlot executes SLOAD or SSTORE on the slot, charging the gas of the
+// EVM opcode, which depends on the access list and the previous value. The
+// slot is reported to WASMHostLogger tracers as the opcode, so they see the
+// same accesses as with _evm_sload and _evm_sstore. It returns the gas spent,
+// and the value of the slot for SLOAD.
+func (in *WASMInterpreter) storageSlot(opcode OpCode, scope *ScopeContext, key, value *uint256.Int) (*uint256.Int, uint64, error) {
+	stack := newstack()
+	defer returnStack(stack)
+	if opcode == SSTORE {
+		stack.push(value)
+	}
+	stack.push(key)
+	scope = in.ScopeWithStack(stack)
+	if hostLogger, ok := in.config.Tracer.(WASMHostLogger); in.config.Debug && ok {
+		hostLogger.CaptureHostCall(opcode, scope, in.evm.depth)
+	}
+	if in.config.JumpTable[opcode] == nil {
+		in.config.JumpTable = newLondonInstructionSet()
+	}
+	op := in.config.JumpTable[opcode]
+	cost, err := op.dynamicGas(in.evm, scope.Contract, stack, scope.Memory, 0)
+	cost += op.constantGas
+	if err != nil || !scope.Contract.UseGas(cost) {
+		return nil, cost, ErrOutOfGas
+	}
+	var pc uint64
+	if _, err := op.execute(&pc, NewEVMInterpreter(in.evm, in.config), scope); err != nil {
+		return nil, cost, err
+	}
+	if opcode == SLOAD {
+		return new(uint256.Int).Set(stack.peek()), cost, nil
+	}
+	return nil, cost, nil
+}
+
+// fluentStorageRead reads len bytes from the consecutive slots starting at the
+// 32 byte key.
+func (in *WASMInterpreter) fluentStorageRead(scope *ScopeContext, args []uint64) int32 {
+	keyOffset, dest, size := args[0], args[1], args[2]
+	if !in.inMemory(keyOffset, HashDestLen) || !in.inMemory(dest, size) {
+		return wasmExitInvalidInput
+	}
+	key := new(uint256.Int).SetBytes(in.readMemory(keyOffset, HashDestLen))
+	data := make([]byte, 0, words(size)*32)
+	var gas uint64
+	for i := uint64(0); i < words(size); i++ {
+		value, cost, err := in.storageSlot(SLOAD, scope, new(uint256.Int).AddUint64(key, i), nil)
+		gas += cost
+		if err != nil {
+			in.traceFluentCall("storage_read", gas, scope, err)
+			return fluentExitCode(err)
+		}
+		word := value.Bytes32()
+		data = append(data, word[:]...)
+	}
+	in.traceFluentCall("storage_read", gas, scope, nil)
+	in.writeMemory(dest, size, data)
+	return wasmExitOk
+}
+
+// fluentStorageWrite writes len bytes to the consecutive slots starting at the
+// 32 byte key, the last slot being padded with zeros.
+func (in *WASMInterpreter) fluentStorageWrite(scope *ScopeContext, args []uint64) int32 {
+	keyOffset, src, size := args[0], args[1], args[2]
+	if in.readOnly {
+		return wasmExitWriteProtection
+	}
+	if !in.inMemory(keyOffset, HashDestLen) || !in.inMemory(src, size) {
+		return wasmExitInvalidInput
+	}
+	key := new(uint256.Int).SetBytes(in.readMemory(keyOffset, HashDestLen))
+	data := common.RightPadBytes(in.readMemory(src, size), int(words(size)*32))
+	var gas uint64
+	for i := uint64(0); i < words(size); i++ {
+		value := new(uint256.Int).SetBytes(data[i*32 : (i+1)*32])
+		_, cost, err := in.storageSlot(SSTORE, scope, new(uint256.Int).AddUint64(key, i), value)
+		gas += cost
+		if err != nil {
+			in.traceFluentCall("storage_write", gas, scope, err)
+			return fluentExitCode(err)
+		}
+	}
+	in.traceFluentCall("storage_write", gas, scope, nil)
+	return wasmExitOk
+}
+
+// fluentInput copies len bytes of the call data from offset, padded with zeros.
+func (in *WASMInterpreter) fluentInput(scope *ScopeContext, args []uint64) int32 {
+	dest, offset, size := args[0], args[1], args[2]
+	if !in.inMemory(dest, size) {
+		return wasmExitInvalidInput
+	}
+	if !in.useFluentGas("input", GasFastestStep+words(size)*params.CopyGas, scope) {
+		return wasmExitOutOfGas
+	}
+	in.writeMemory(dest, size, getData(scope.Contract.Input, offset, size))
+	return wasmExitOk
+}
+
+// fluentInputSize writes the size of the call data as a little endian u32.
+func (in *WASMInterpreter) fluentInputSize(scope *ScopeContext, args []uint64) int32 {
+	dest := args[0]
+	if !in.inMemory(dest, 4) {
+		return wasmExitInvalidInput
+	}
+	if !in.useFluentGas("input_size", GasQuickStep, scope) {
+		return wasmExitOutOfGas
+	}
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(scope.Contract.Input)))
+	in.writeMemory(dest, 4, size)
+	return wasmExitOk
+}
+
+// fluentEcrecover recovers the 20 byte address which signed the 32 byte hash,
+// the 65 byte signature being r || s || v with v either 0/1 or 27/28. The zero
+// address is written if the signature is invalid.
+func (in *WASMInterpreter) fluentEcrecover(scope *ScopeContext, args []uint64) int32 {
+	hashOffset, sigOffset, dest := args[0], args[1], args[2]
+	if !in.inMemory(hashOffset, HashDestLen) || !in.inMemory(sigOffset, 65) || !in.inMemory(dest, AddressDestLen) {
+		return wasmExitInvalidInput
+	}
+	if !in.useFluentGas("ecrecover", params.EcrecoverGas, scope) {
+		return wasmExitOutOfGas
+	}
+	sig := in.readMemory(sigOffset, 65)
+	input := make([]byte, 128)
+	copy(input, in.readMemory(hashOffset, HashDestLen))
+	input[63] = sig[64]
+	if input[63] < 27 {
+		input[63] += 27
+	}
+	copy(input[64:], sig[:64])
+	addr, _ := (&ecrecover{}).Run(input)
+	in.writeMemory(dest, AddressDestLen, common.BytesToAddress(addr).Bytes())
+	return wasmExitOk
+}
+
+// fluentSha256 writes the 32 byte SHA256 hash of len bytes at offset.
+func (in *WASMInterpreter) fluentSha256(scope *ScopeContext, args []uint64) int32 {
+	offset, size, dest := args[0], args[1], args[2]
+	if !in.inMemory(offset, size) || !in.inMemory(dest, HashDestLen) {
+		return wasmExitInvalidInput
+	}
+	if !in.useFluentGas("sha256", params.Sha256BaseGas+words(size)*params.Sha256PerWordGas, scope) {
+		return wasmExitOutOfGas
+	}
+	hash := sha256.Sum256(in.readMemory(offset, size))
+	in.writeMemory(dest, HashDestLen, hash[:])
+	return wasmExitOk
+}
+
+// fluentPoseidon writes the 32 byte Poseidon hash of the 32 byte inputs at
+// offset, hashed as a single permutation like the poseidon precompile does
+// with a zero width.
+func (in *WASMInterpreter) fluentPoseidon(scope *ScopeContext, args []uint64) int32 {
+	offset, size, dest := args[0], args[1], args[2]
+	if !in.inMemory(offset, size) || !in.inMemory(dest, HashDestLen) {
+		return wasmExitInvalidInput
+	}
+	if !in.useFluentGas("poseidon", poseidonGas(0, words(size)), scope) {
+		return wasmExitOutOfGas
+	}
+	in.tracePoseidon(0, words(size))
+	hash, err := runPoseidon(0, 0, in.readMemory(offset, size))
+	if err != nil {
+		return wasmExitInvalidInput
+	}
+	in.writeMemory(dest, HashDestLen, hash)
+	return wasmExitOk
+}
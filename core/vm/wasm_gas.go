@@ -2,10 +2,31 @@ package vm
 
 import (
 	"fmt"
-	zkwasm_gas_injector "github.com/wasm0/zkwasm-gas-injector"
+
+	"github.com/scroll-tech/go-ethereum/core/vm/gowasm"
+	"github.com/scroll-tech/go-ethereum/params"
 )
 
-const disableGasInjection = true
+// WasmGasCosts prices the execution of the WASM contracts, charged by the
+// code injected on deployment.
+var WasmGasCosts = gowasm.GasCosts{
+	Instruction: params.WasmInstructionGas,
+	MemoryPage:  params.WasmMemoryPageGas,
+	MemoryWord:  params.WasmMemoryWordGas,
+}
+
+// injectGasComputationAndStackProtection injects the calls to the gas host
+// function into the code. The injection is done in Go on every build, so the
+// stored code doesn't depend on the node, while the call stack is bounded by
+// the engine.
+func injectGasComputationAndStackProtection(
+	destCode []byte,
+) (ret []byte, err error) {
+	if destCode == nil {
+		return nil, fmt.Errorf("no contract code to check")
+	}
+	return gowasm.InjectGas(destCode, "env", GasImportedFunction, WasmGasCosts)
+}
 
 var WasmOpCodeToName = map[uint16]string{
 	0x0000: "unreachable",
@@ -227,31 +248,3 @@ var WasmOpCodeToName = map[uint16]string{
 const (
 	GasImportedFunction = "gas"
 )
-
-func injectGasComputationAndStackProtection(
-	destCode []byte,
-) (ret []byte, err error) {
-	if disableGasInjection {
-		return destCode, nil
-	}
-	if destCode == nil {
-		return nil, fmt.Errorf("no contract code to check")
-	}
-	injectRes, err := zkwasm_gas_injector.Inject(
-		destCode,
-		zkwasm_gas_injector.InjectTypeBoth,
-		zkwasm_gas_injector.InjectGasTypeHost,
-		1,
-		10000,
-		0,
-		1024,
-		zkwasm_gas_injector.ReturnFormatWasm,
-	)
-	if err != nil {
-		return nil, err
-	}
-	if injectRes != nil {
-		ret = injectRes
-	}
-	return
-}
@@ -1,29 +1,54 @@
 package core
 
 import (
+	"crypto/sha256"
 	_ "embed"
 	"fmt"
 	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/core/vm/gowasm"
 	"github.com/scroll-tech/go-ethereum/eth/tracers/logger"
 	"github.com/stretchr/testify/assert"
-	zkwasm_wasmi "github.com/wasm0/zkwasm-wasmi"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/stretchr/testify/require"
 	"github.com/wasmerio/wasmer-go/wasmer"
 )
 
 func newWasmMachine() (*vm.EVM, *logger.WebAssemblyLogger) {
+	tracer := logger.NewWebAssemblyLogger(&logger.Config{
+		EnableMemory:     false,
+		DisableStack:     false,
+		DisableStorage:   false,
+		EnableReturnData: true,
+		Debug:            true,
+		Limit:            0,
+	})
+	return newWasmMachineWithTracer(tracer), tracer
+}
+
+func newWasmMachineWithTracer(tracer vm.EVMLogger) *vm.EVM {
+	return newWasmMachineWithConfig(vm.Config{
+		Tracer: tracer,
+		Debug:  true,
+	})
+}
+
+func newWasmMachineWithConfig(vmConfig vm.Config) *vm.EVM {
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
-	config := params.AllEthashProtocolChanges
+	config := *params.AllEthashProtocolChanges
 	config.WebAssemblyBlock = big.NewInt(0)
 	blockCtx := vm.BlockContext{
+		CanTransfer: func(vm.StateDB, common.Address, *big.Int) bool {
+			return true
+		},
 		Transfer: func(
 			vm.StateDB,
 			common.Address,
@@ -37,21 +62,9 @@ func newWasmMachine() (*vm.EVM, *logger.WebAssemblyLogger) {
 		BaseFee:     big.NewInt(4),
 	}
 	txCtx := vm.TxContext{}
-	tracer := logger.NewWebAssemblyLogger(&logger.Config{
-		EnableMemory:     false,
-		DisableStack:     false,
-		DisableStorage:   false,
-		EnableReturnData: true,
-		Debug:            true,
-		Limit:            0,
-	})
-	evm := vm.NewEVM(
-		blockCtx, txCtx, statedb, config, vm.Config{
-			Tracer: tracer,
-			Debug:  true,
-		},
+	return vm.NewEVM(
+		blockCtx, txCtx, statedb, &config, vmConfig,
 	)
-	return evm, tracer
 }
 
 func newWasmContract(evm *vm.EVM, addr common.Address, watCode string) {
@@ -102,13 +115,10 @@ func expectGasLeft(t *testing.T, interpreter vm.VirtualInterpreter, expectedGasL
 
 func TestWASMInterpreter_Hello(t *testing.T) {
 	{
-		evm, tracer := newWasmMachine()
+		evm, _ := newWasmMachine()
 		newWasmContract(evm, common.Address{100, 20, 3}, wasmTestHello)
 		_, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{100, 20, 3}, []byte{AddressFunctionFlag}, 10_000_000, big.NewInt(0))
 		require.EqualError(t, err, "exit return code: 123")
-		msg, _ := tracer.GetResult()
-		msg, _ = msg.MarshalJSON()
-		println(string(msg))
 	}
 
 	{
@@ -126,13 +136,10 @@ func TestWASMInterpreter_Hello(t *testing.T) {
 	}
 
 	{
-		evm, tracer := newWasmMachine()
+		evm, _ := newWasmMachine()
 		newWasmContract(evm, common.Address{}, wasmTestHello)
 		_, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{BalanceFunctionFlag}, 10_000_000, big.NewInt(0))
 		require.NoError(t, err)
-		msg, _ := tracer.GetResult()
-		msg, _ = msg.MarshalJSON()
-		println(string(msg))
 	}
 
 	{
@@ -183,14 +190,11 @@ func TestWASMInterpreter_Hello_injected(t *testing.T) {
 	}
 
 	{
-		evm, tracer := newWasmMachine()
+		evm, _ := newWasmMachine()
 		newWasmContract(evm, common.Address{}, watTestHelloInjected)
 		_, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{BalanceFunctionFlag}, 10_000_000, big.NewInt(0))
 		require.NoError(t, err)
-		msg, _ := tracer.GetResult()
-		msg, _ = msg.MarshalJSON()
 		expectGasLeft(t, evm.Interpreter(), 0x985251, "BalanceFunctionFlag")
-		println(string(msg))
 	}
 
 	{
@@ -227,33 +231,384 @@ func TestWASMInterpreter_Deploy(t *testing.T) {
 }
 
 func TestWASMInterpreter_Greeting(t *testing.T) {
-	evm, tracer := newWasmMachine()
+	evm, _ := newWasmMachine()
 	newWasmContract(evm, common.Address{}, watTestGreeting)
 	ret, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, 10_000_000, big.NewInt(0))
-	msg, _ := tracer.GetResult()
-	msg, _ = msg.MarshalJSON()
-	println(string(msg))
 	require.NoError(t, err)
 	require.Equal(t, ret, []byte("Hello, World"))
 	//expectGasLeft(t, evm.Interpreter(), 0x989623)
 }
 
+func TestWASMInterpreter_Engines(t *testing.T) {
+	for _, engine := range vm.WasmEngines() {
+		evm := newWasmMachineWithConfig(vm.Config{WasmEngine: engine})
+		newWasmContract(evm, common.Address{}, watTestGreeting)
+		ret, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, 10_000_000, big.NewInt(0))
+		require.NoError(t, err, engine)
+		require.Equal(t, []byte("Hello, World"), ret, engine)
+	}
+	_, err := vm.NewWasmEngine("unknown")
+	require.Error(t, err)
+}
+
 func TestWASMInterpreter_SimpleWasmFile(t *testing.T) {
-	evm, tracer := newWasmMachine()
+	evm, _ := newWasmMachine()
 	newWasmContract(evm, common.Address{}, watTestSimple)
 	_, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, 10_000_000, big.NewInt(0))
 	require.NoError(t, err)
-	msg, _ := tracer.GetResult()
-	msg, _ = msg.MarshalJSON()
-	println(string(msg))
 }
 
 func TestWASMInterpreter_SimpleWasmFile__out_of_gas(t *testing.T) {
-	evm, tracer := newWasmMachine()
-	newWasmContract(evm, common.Address{}, watTestSimple)
-	_, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, 10, big.NewInt(0))
-	require.Error(t, err, zkwasm_wasmi.ComputeTraceErrorCodeOutOfGas)
-	msg, _ := tracer.GetResult()
-	msg, _ = msg.MarshalJSON()
-	println(string(msg))
+	code, err := wasmer.Wat2Wasm(watTestSimple)
+	require.NoError(t, err)
+	// the code is stored with the gas computation injected when deployed
+	code, err = gowasm.InjectGas(code, "env", vm.GasImportedFunction, vm.WasmGasCosts)
+	require.NoError(t, err)
+	evm, _ := newWasmMachine()
+	evm.StateDB.SetCode(common.Address{}, code)
+	_, _, err = evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, 5, big.NewInt(0))
+	require.Equal(t, vm.ErrOutOfGas, err)
+}
+
+const watTestFluent = `(module
+  (import "fluent_v1" "storage_write" (func $storage_write (param i32 i32 i32)))
+  (import "fluent_v1" "storage_read" (func $storage_read (param i32 i32 i32)))
+  (import "fluent_v1" "sha256" (func $sha256 (param i32 i32 i32)))
+  (import "env" "_evm_return" (func $_evm_return (param i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 31) "\01")
+  (data (i32.const 32) "0123456789abcdefghijklmnopqrstuvwxyzABCD")
+  (func (export "main")
+    (call $storage_write (i32.const 0) (i32.const 32) (i32.const 40))
+    (call $storage_read (i32.const 0) (i32.const 128) (i32.const 40))
+    (call $sha256 (i32.const 128) (i32.const 40) (i32.const 168))
+    (call $_evm_return (i32.const 128) (i32.const 72))))`
+
+func TestWASMInterpreter_Fluent(t *testing.T) {
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyzABCD")
+	evm, _ := newWasmMachine()
+	newWasmContract(evm, common.Address{}, watTestFluent)
+	evm.StateDB.AddAddressToAccessList(common.Address{})
+	ret, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	require.Equal(t, common.BytesToHash(data[:32]), evm.StateDB.GetState(common.Address{}, common.BigToHash(big.NewInt(1))))
+	require.Equal(t, common.RightPadBytes(data[32:], 32), evm.StateDB.GetState(common.Address{}, common.BigToHash(big.NewInt(2))).Bytes())
+	hash := sha256.Sum256(data)
+	require.Equal(t, append(data, hash[:]...), ret)
+}
+
+// watTestFluentCall calls a fluent_v1 host function with the given arguments.
+const watTestFluentCall = `(module
+  (import "fluent_v1" "%s" (func $fn (param i32 i32 i32)))
+  (memory (export "memory") 1)
+  (func (export "main")
+    (call $fn (i32.const %d) (i32.const %d) (i32.const %d))))`
+
+func TestWASMInterpreter_FluentInvalidInput(t *testing.T) {
+	// a partial word of the poseidon input is charged as a whole word, on top
+	// of the memory page of the contract
+	poseidonTwoWordsGas := params.WasmMemoryPageGas + params.PoseidonBaseGas + 3*params.PoseidonPerElementGas
+	tests := []struct {
+		fn      string
+		args    [3]int32
+		gas     uint64
+		static  bool
+		wantErr error
+	}{
+		{"sha256", [3]int32{65500, 100, 0}, 100_000, false, vm.ErrBadInputParams},
+		{"sha256", [3]int32{0, 100, 65530}, 100_000, false, vm.ErrBadInputParams},
+		{"input", [3]int32{65535, 0, 2}, 100_000, false, vm.ErrBadInputParams},
+		{"storage_read", [3]int32{65530, 0, 32}, 100_000, false, vm.ErrBadInputParams},
+		{"storage_write", [3]int32{0, 0, 32}, 100_000, true, vm.ErrWriteProtection},
+		{"poseidon", [3]int32{0, 40, 64}, poseidonTwoWordsGas - 1, false, vm.ErrOutOfGas},
+		{"poseidon", [3]int32{0, 40, 64}, poseidonTwoWordsGas, false, vm.ErrBadInputParams},
+		{"poseidon", [3]int32{0, 64, 64}, poseidonTwoWordsGas, false, nil},
+	}
+	for i, tt := range tests {
+		evm, tracer := newWasmMachine()
+		newWasmContract(evm, common.Address{}, fmt.Sprintf(watTestFluentCall, tt.fn, tt.args[0], tt.args[1], tt.args[2]))
+		evm.StateDB.AddAddressToAccessList(common.Address{})
+		var err error
+		if tt.static {
+			// a static call doesn't start the trace
+			tracer.CaptureStart(evm, common.Address{}, common.Address{}, false, nil, tt.gas, nil)
+			_, _, err = evm.StaticCall(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, tt.gas)
+		} else {
+			_, _, err = evm.Call(vm.AccountRef(common.Address{}), common.Address{}, []byte{}, tt.gas, big.NewInt(0))
+		}
+		require.Equal(t, tt.wantErr, err, "test %d: %s", i, tt.fn)
+	}
+}
+
+func TestWASMInterpreter_CreateLimits(t *testing.T) {
+	code, err := wasmer.Wat2Wasm(watTestDeploy)
+	require.NoError(t, err)
+
+	evm, _ := newWasmMachine()
+	ret, addr, _, err := evm.Create(vm.AccountRef(common.Address{1}), code, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	require.Equal(t, ret, evm.StateDB.GetCode(addr))
+
+	// the limits are configured by the chain
+	evm, _ = newWasmMachine()
+	maxCodeSize := len(ret) - 1
+	evm.ChainConfig().Scroll.MaxWasmCodeSize = &maxCodeSize
+	_, _, _, err = evm.Create(vm.AccountRef(common.Address{1}), code, 10_000_000, big.NewInt(0))
+	require.Equal(t, vm.ErrMaxCodeSizeExceeded, err)
+
+	evm, _ = newWasmMachine()
+	maxInitCodeSize := len(code) - 1
+	evm.ChainConfig().Scroll.MaxWasmInitCodeSize = &maxInitCodeSize
+	_, _, _, err = evm.Create(vm.AccountRef(common.Address{1}), code, 10_000_000, big.NewInt(0))
+	require.Equal(t, vm.ErrMaxInitCodeSizeExceeded, err)
+}
+
+// newWasmInitCode returns the initcode deploying the code.
+func newWasmInitCode(t *testing.T, code []byte) []byte {
+	var data strings.Builder
+	for _, b := range code {
+		fmt.Fprintf(&data, "\\%02x", b)
+	}
+	initCode, err := wasmer.Wat2Wasm(fmt.Sprintf(`(module
+  (import "env" "_evm_return" (func $_evm_return (param i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "%s")
+  (func (export "main")
+    (call $_evm_return (i32.const 0) (i32.const %d))))`, data.String(), len(code)))
+	require.NoError(t, err)
+	return initCode
+}
+
+func TestWASMInterpreter_DeployGas(t *testing.T) {
+	code, err := wasmer.Wat2Wasm(watTestGreeting)
+	require.NoError(t, err)
+	initCode := newWasmInitCode(t, code)
+	injected, err := gowasm.InjectGas(code, "env", vm.GasImportedFunction, vm.WasmGasCosts)
+	require.NoError(t, err)
+
+	// the initcode and the deployed code are metered, the gas of their
+	// execution is measured by calling them
+	evm, _ := newWasmMachine()
+	evm.StateDB.SetCode(common.Address{2}, injected)
+	_, gasLeft, err := evm.Call(vm.AccountRef(common.Address{1}), common.Address{2}, nil, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	callGas := 10_000_000 - gasLeft
+
+	initInjected, err := gowasm.InjectGas(initCode, "env", vm.GasImportedFunction, vm.WasmGasCosts)
+	require.NoError(t, err)
+	evm.StateDB.SetCode(common.Address{3}, initInjected)
+	_, gasLeft, err = evm.Call(vm.AccountRef(common.Address{1}), common.Address{3}, nil, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	execGas := 10_000_000 - gasLeft
+	require.Greater(t, execGas, uint64(0))
+
+	// the code is stored with the gas computation injected, and priced by
+	// its functions and code section
+	funcs, codeSize, err := gowasm.Measure(injected)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), funcs)
+	deployGas := uint64(len(injected))*params.CreateDataGas + funcs*params.WasmDeployFunctionGas + codeSize*params.WasmDeployCodeGas
+	// the initcode is charged per word before its execution
+	initCodeGas := (uint64(len(initCode)) + 31) / 32 * params.WasmInitCodeWordGas
+
+	evm, _ = newWasmMachine()
+	ret, addr, gasLeft, err := evm.Create(vm.AccountRef(common.Address{1}), initCode, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	require.Equal(t, injected, ret)
+	require.Equal(t, injected, evm.StateDB.GetCode(addr))
+	require.Equal(t, initCodeGas+execGas+deployGas, 10_000_000-gasLeft)
+
+	// the deployed code is metered
+	ret, gasLeft, err = evm.Call(vm.AccountRef(common.Address{1}), addr, nil, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	require.Equal(t, []byte("Hello, World"), ret)
+	require.Equal(t, callGas, 10_000_000-gasLeft)
+	evm.StateDB.SetCode(common.Address{4}, code)
+	_, gasLeft, err = evm.Call(vm.AccountRef(common.Address{1}), common.Address{4}, nil, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	require.Less(t, 10_000_000-gasLeft, callGas)
+
+	// the code store must be paid for
+	evm, _ = newWasmMachine()
+	_, _, _, err = evm.Create(vm.AccountRef(common.Address{1}), initCode, initCodeGas+execGas+deployGas-1, big.NewInt(0))
+	require.Equal(t, vm.ErrCodeStoreOutOfGas, err)
+
+	// so is the initcode
+	evm, _ = newWasmMachine()
+	_, _, _, err = evm.Create(vm.AccountRef(common.Address{1}), initCode, initCodeGas-1, big.NewInt(0))
+	require.Equal(t, vm.ErrOutOfGas, err)
+
+	// the size limit applies to the code with the gas computation injected
+	evm, _ = newWasmMachine()
+	maxCodeSize := len(injected) - 1
+	require.GreaterOrEqual(t, maxCodeSize, len(code))
+	evm.ChainConfig().Scroll.MaxWasmCodeSize = &maxCodeSize
+	_, _, _, err = evm.Create(vm.AccountRef(common.Address{1}), initCode, 10_000_000, big.NewInt(0))
+	require.Equal(t, vm.ErrMaxCodeSizeExceeded, err)
+
+	// invalid code can't be deployed
+	evm, _ = newWasmMachine()
+	_, _, _, err = evm.Create(vm.AccountRef(common.Address{1}), newWasmInitCode(t, []byte{1, 2, 3}), 10_000_000, big.NewInt(0))
+	require.Equal(t, vm.ErrBadWasmBinary, err)
+	evm, _ = newWasmMachine()
+	_, _, _, err = evm.Create(vm.AccountRef(common.Address{1}), []byte{1, 2, 3}, 10_000_000, big.NewInt(0))
+	require.Equal(t, vm.ErrBadWasmBinary, err)
+}
+
+const watTestMemoryGas = `(module
+  (import "env" "_evm_return" (func $_evm_return (param i32 i32)))
+  (memory (export "memory") 1)
+  (func (export "main")
+    (drop (memory.grow (i32.const %d)))
+    (memory.fill (i32.const 0) (i32.const 1) (i32.const %d))
+    (call $_evm_return (i32.const 0) (i32.const 1))))`
+
+func TestWASMInterpreter_MemoryGas(t *testing.T) {
+	tests := []struct {
+		pages, fill int
+		gas         uint64
+		want        byte
+		wantErr     error
+	}{
+		{1, 65536, 100_000, 1, nil},
+		{1000, 0, 100_000, 0, vm.ErrOutOfGas},
+		{0, 65536, 5_000, 0, vm.ErrOutOfGas},
+		{1000, 0, 10_000_000, 0, nil},
+	}
+	for i, tt := range tests {
+		code, err := wasmer.Wat2Wasm(fmt.Sprintf(watTestMemoryGas, tt.pages, tt.fill))
+		require.NoError(t, err)
+		// the code is deployed with the gas computation injected
+		evm, _ := newWasmMachine()
+		_, addr, _, err := evm.Create(vm.AccountRef(common.Address{1}), newWasmInitCode(t, code), 10_000_000, big.NewInt(0))
+		require.NoError(t, err)
+		ret, gasLeft, err := evm.Call(vm.AccountRef(common.Address{1}), addr, nil, tt.gas, big.NewInt(0))
+		require.Equal(t, tt.wantErr, err, "test %d", i)
+		if err == nil {
+			require.Equal(t, []byte{tt.want}, ret, "test %d", i)
+			require.GreaterOrEqual(t, tt.gas-gasLeft, uint64(tt.pages)*params.WasmMemoryPageGas+uint64(tt.fill)/32*params.WasmMemoryWordGas, "test %d", i)
+		}
+	}
+}
+
+const watTestAllocationGas = `(module
+  (import "env" "_evm_return" (func $_evm_return (param i32 i32)))
+  (memory (export "memory") %d)
+  (table %d funcref)
+  (func (export "main")
+    (call $_evm_return (i32.const 0) (i32.const 1))))`
+
+func TestWASMInterpreter_AllocationGas(t *testing.T) {
+	tests := []struct {
+		pages, table int
+		gas          uint64
+		wantErr      error
+	}{
+		{1, 0, 100_000, nil},
+		{1024, 0, 1_000_000, vm.ErrOutOfGas},
+		{1024, 0, 10_000_000, nil},
+		{1, 1 << 20, 1_000_000, vm.ErrOutOfGas},
+		{1, 1 << 20, 2_000_000, nil},
+	}
+	for i, tt := range tests {
+		code, err := wasmer.Wat2Wasm(fmt.Sprintf(watTestAllocationGas, tt.pages, tt.table))
+		require.NoError(t, err)
+		evm, _ := newWasmMachine()
+		_, addr, _, err := evm.Create(vm.AccountRef(common.Address{1}), newWasmInitCode(t, code), 10_000_000, big.NewInt(0))
+		require.NoError(t, err)
+		// the memory and the table are charged before the execution
+		_, gasLeft, err := evm.Call(vm.AccountRef(common.Address{1}), addr, nil, tt.gas, big.NewInt(0))
+		require.Equal(t, tt.wantErr, err, "test %d", i)
+		if err == nil {
+			require.GreaterOrEqual(t, tt.gas-gasLeft, uint64(tt.pages)*params.WasmMemoryPageGas+uint64(tt.table)*params.WasmTableElementGas, "test %d", i)
+		}
+	}
+}
+
+const watTestAccessList = `(module
+  (import "env" "_evm_sload" (func $_evm_sload (param i32 i32)))
+  (import "env" "_evm_sstore" (func $_evm_sstore (param i32 i32)))
+  (import "env" "_evm_balance" (func $_evm_balance (param i32 i32)))
+  (import "fluent_v1" "storage_read" (func $storage_read (param i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 31) "\01")
+  (data (i32.const 63) "\02")
+  (data (i32.const 64) "\00\00\00\00\00\00\00\00\00\00\00\00\00\00\00\00\de\ad\be\ef")
+  (data (i32.const 127) "\03")
+  (func (export "main")
+    (call $_evm_sload (i32.const 0) (i32.const 128))
+    (call $_evm_sstore (i32.const 32) (i32.const 0))
+    (call $_evm_balance (i32.const 64) (i32.const 128))
+    (call $storage_read (i32.const 96) (i32.const 128) (i32.const 64))))`
+
+func TestWASMInterpreter_AccessList(t *testing.T) {
+	var (
+		from     = common.Address{1}
+		contract = common.Address{2}
+		other    = common.HexToAddress("0xdeadbeef")
+	)
+	tracer := vm.NewAccessListTracer(nil, from, contract, nil)
+	evm := newWasmMachineWithTracer(tracer)
+	newWasmContract(evm, contract, watTestAccessList)
+	_, _, err := evm.Call(vm.AccountRef(from), contract, nil, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+
+	// the slots of the host functions, the two words read by storage_read,
+	// and the address of the balance are collected
+	want := types.AccessList{
+		{Address: contract, StorageKeys: []common.Hash{
+			common.BigToHash(big.NewInt(1)),
+			common.BigToHash(big.NewInt(2)),
+			common.BigToHash(big.NewInt(3)),
+			common.BigToHash(big.NewInt(4)),
+		}},
+		{Address: other, StorageKeys: []common.Hash{}},
+	}
+	have := tracer.AccessList()
+	require.Len(t, have, len(want))
+	for _, tuple := range want {
+		found := false
+		for _, haveTuple := range have {
+			if haveTuple.Address == tuple.Address {
+				require.ElementsMatch(t, tuple.StorageKeys, haveTuple.StorageKeys, "slots of %x", tuple.Address)
+				found = true
+			}
+		}
+		require.True(t, found, "missing %x", tuple.Address)
+	}
+}
+
+const watTestRowUsage = `(module
+  (import "env" "_evm_sstore" (func $_evm_sstore (param i32 i32)))
+  (import "env" "_evm_keccak256" (func $_evm_keccak256 (param i32 i32 i32)))
+  (import "fluent_v1" "poseidon" (func $poseidon (param i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 31) "\01")
+  (data (i32.const 63) "\02")
+  (func (export "main")
+    (call $_evm_sstore (i32.const 0) (i32.const 32))
+    (call $_evm_keccak256 (i32.const 0) (i32.const 64) (i32.const 64))
+    (call $poseidon (i32.const 0) (i32.const 64) (i32.const 96))))`
+
+func TestWASMInterpreter_RowUsage(t *testing.T) {
+	counter := vm.NewRowCounter()
+	evm := newWasmMachineWithTracer(counter)
+	newWasmContract(evm, common.Address{}, watTestRowUsage)
+	evm.StateDB.AddAddressToAccessList(common.Address{})
+	_, _, err := evm.Call(vm.AccountRef(common.Address{}), common.Address{}, nil, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	rows := counter.RowUsage()
+
+	// the rows of the host calls are counted on top of the instructions
+	counter.Reset()
+	evm = newWasmMachineWithTracer(counter)
+	newWasmContract(evm, common.Address{}, fmt.Sprintf(watTestFluentCall, "input", 0, 0, 0))
+	_, _, err = evm.Call(vm.AccountRef(common.Address{}), common.Address{}, nil, 10_000_000, big.NewInt(0))
+	require.NoError(t, err)
+	base := counter.RowUsage()
+
+	require.Greater(t, rows.WasmSteps, uint64(0))
+	require.Equal(t, uint64(1), rows.Storage-base.Storage)
+	require.Greater(t, rows.Keccak, base.Keccak)
+	require.Greater(t, rows.Poseidon-base.Poseidon, uint64(32))
 }
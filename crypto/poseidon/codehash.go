@@ -1,7 +1,7 @@
 package poseidon
 
 import (
-	"math/big"
+	"github.com/iden3/go-iden3-crypto/ff"
 
 	"github.com/scroll-tech/go-ethereum/common"
 )
@@ -10,34 +10,31 @@ const defaultPoseidonChunk = 3
 const nBytesToFieldElement = 31
 
 func CodeHash(code []byte) (h common.Hash) {
-	nBytes := int64(len(code))
-
 	// step 1: pad code with 0x0 (STOP) so that len(code) % nBytesToFieldElement == 0
 	// step 2: for every nBytesToFieldElement bytes, convert to Fr, so that we get a Fr array
-	var length = (len(code) + nBytesToFieldElement - 1) / nBytesToFieldElement
-
-	Frs := make([]*big.Int, length)
-	ii := 0
-
-	for ii < length-1 {
-		Frs[ii] = big.NewInt(0)
-		Frs[ii].SetBytes(code[ii*nBytesToFieldElement : (ii+1)*nBytesToFieldElement])
-		ii++
-	}
-
-	if length > 0 {
-		Frs[ii] = big.NewInt(0)
-		bytes := make([]byte, nBytesToFieldElement)
-		copy(bytes, code[ii*nBytesToFieldElement:])
-		Frs[ii].SetBytes(bytes)
+	var (
+		length = (len(code) + nBytesToFieldElement - 1) / nBytesToFieldElement
+		frs    = make([]ff.Element, length)
+		chunk  [32]byte
+	)
+	for i := range frs {
+		chunk = [32]byte{}
+		end := (i + 1) * nBytesToFieldElement
+		if end > len(code) {
+			end = len(code)
+		}
+		copy(chunk[32-nBytesToFieldElement:], code[i*nBytesToFieldElement:end])
+		// 31 bytes always fit into the field
+		setBytes32(&frs[i], &chunk)
 	}
 
 	// step 3: apply the array onto a sponge process with the current poseidon scheme
 	// (3 Frs permutation and 1 Fr for output, so the throughout is 2 Frs)
 	// step 4: convert final root Fr to u256 (big-endian representation)
-	hash, err := HashWithCap(Frs, defaultPoseidonChunk, nBytes)
-	if err != nil {
-		return common.Hash{}
-	}
-	return common.BigToHash(hash)
+	pool := &hasherPools[defaultPoseidonChunk-2]
+	hasher := pool.Get().(*Hasher)
+	defer pool.Put(hasher)
+
+	res := hasher.HashWithCap(frs, uint64(len(code)))
+	return res.Bytes()
 }
@@ -2,7 +2,10 @@ package poseidon
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
 )
 
 func TestPoseidonCodeHash(t *testing.T) {
@@ -40,3 +43,14 @@ func TestPoseidonCodeHash(t *testing.T) {
 		t.Errorf("got %q, wanted %q", got, want)
 	}
 }
+
+func TestPoseidonCodeHashMatchesLegacy(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range []int{1, 30, 31, 32, 61, 62, 63, 1000} {
+		code := make([]byte, size)
+		rnd.Read(code)
+		if have, want := CodeHash(code), common.BigToHash(legacyCodeHash(code)); have != want {
+			t.Errorf("size %d: code hash mismatch: have %x, want %x", size, have, want)
+		}
+	}
+}
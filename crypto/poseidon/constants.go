@@ -16,86 +16,53 @@ type constantsStr struct {
 	P [][][]string
 }
 
-type constants struct {
-	c [][]*ff.Element
-	s [][]*ff.Element
-	m [][][]*ff.Element
-	p [][][]*ff.Element
+// roundConstants are the permutation constants of a single state width. They
+// are stored by value, so a permutation walks contiguous memory.
+type roundConstants struct {
+	width   int            // state width, rate + 1
+	partial int            // number of partial rounds
+	c       []ff.Element   // round constants
+	s       []ff.Element   // sparse matrices of the partial rounds
+	m       [][]ff.Element // MDS matrix
+	p       [][]ff.Element // pre-sparse matrix
 }
 
-var c *constants
+// rounds holds the precomputed constants of every supported width, indexed by
+// width - 2.
+var rounds []*roundConstants
 
 func init() {
-	c = &constants{
-		c: make([][]*ff.Element, len(cs.C)),
-		s: make([][]*ff.Element, len(cs.S)),
-		m: make([][][]*ff.Element, len(cs.M)),
-		p: make([][][]*ff.Element, len(cs.P)),
-	}
-
-	var (
-		cci, csi, cmij, cpij []*ff.Element
-		cmi, cpi             [][]*ff.Element
-
-		b  *big.Int
-		ok bool
-	)
-
-	for i := 0; i < len(cs.C); i++ {
-		cci = make([]*ff.Element, len(cs.C[i]))
-		for j := 0; j < len(cs.C[i]); j++ {
-			b, ok = new(big.Int).SetString(cs.C[i][j], 16)
-			if !ok {
-				panic(fmt.Errorf("error parsing constants"))
-			}
-			cci[j] = ff.NewElement().SetBigInt(b)
-		}
-		c.c[i] = cci
-	}
-
-	for i := 0; i < len(cs.S); i++ {
-		csi = make([]*ff.Element, len(cs.S[i]))
-		for j := 0; j < len(cs.S[i]); j++ {
-			b, ok = new(big.Int).SetString(cs.S[i][j], 16)
-			if !ok {
-				panic(fmt.Errorf("error parsing constants"))
-			}
-			csi[j] = ff.NewElement().SetBigInt(b)
+	rounds = make([]*roundConstants, len(cs.C))
+	for i := range rounds {
+		rounds[i] = &roundConstants{
+			width:   i + 2,
+			partial: NROUNDSP[i],
+			c:       parseElements(cs.C[i]),
+			s:       parseElements(cs.S[i]),
+			m:       parseMatrix(cs.M[i]),
+			p:       parseMatrix(cs.P[i]),
 		}
-		c.s[i] = csi
 	}
+}
 
-	for i := 0; i < len(cs.M); i++ {
-		cmi = make([][]*ff.Element, len(cs.M[i]))
-		for j := 0; j < len(cs.M[i]); j++ {
-			cmij = make([]*ff.Element, len(cs.M[i][j]))
-			for k := 0; k < len(cs.M[i][j]); k++ {
-				b, ok = new(big.Int).SetString(cs.M[i][j][k], 16)
-				if !ok {
-					panic(fmt.Errorf("error parsing constants"))
-				}
-				cmij[k] = ff.NewElement().SetBigInt(b)
-			}
-			cmi[j] = cmij
+func parseElements(strs []string) []ff.Element {
+	elems := make([]ff.Element, len(strs))
+	for i, str := range strs {
+		b, ok := new(big.Int).SetString(str, 16)
+		if !ok {
+			panic(fmt.Errorf("error parsing constants"))
 		}
-		c.m[i] = cmi
+		elems[i].SetBigInt(b)
 	}
+	return elems
+}
 
-	for i := 0; i < len(cs.P); i++ {
-		cpi = make([][]*ff.Element, len(cs.P[i]))
-		for j := 0; j < len(cs.P[i]); j++ {
-			cpij = make([]*ff.Element, len(cs.P[i][j]))
-			for k := 0; k < len(cs.P[i][j]); k++ {
-				b, ok = new(big.Int).SetString(cs.P[i][j][k], 16)
-				if !ok {
-					panic(fmt.Errorf("error parsing constants"))
-				}
-				cpij[k] = ff.NewElement().SetBigInt(b)
-			}
-			cpi[j] = cpij
-		}
-		c.p[i] = cpi
+func parseMatrix(strs [][]string) [][]ff.Element {
+	matrix := make([][]ff.Element, len(strs))
+	for i := range strs {
+		matrix[i] = parseElements(strs[i])
 	}
+	return matrix
 }
 
 //nolint:lll
@@ -0,0 +1,176 @@
+package poseidon
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/iden3/go-iden3-crypto/ff"
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// This file contains the previous, allocating implementation of the hash
+// functions, which the current one is validated and benchmarked against.
+
+type legacyRoundConstants struct {
+	c, s []*ff.Element
+	m, p [][]*ff.Element
+}
+
+var (
+	legacyRounds     []legacyRoundConstants
+	legacyRoundsOnce sync.Once
+)
+
+func legacyConstants(t int) (c, s []*ff.Element, m, p [][]*ff.Element) {
+	legacyRoundsOnce.Do(func() {
+		pointers := func(elems []ff.Element) []*ff.Element {
+			res := make([]*ff.Element, len(elems))
+			for i := range elems {
+				res[i] = new(ff.Element).Set(&elems[i])
+			}
+			return res
+		}
+		for _, r := range rounds {
+			var lr legacyRoundConstants
+			lr.c, lr.s = pointers(r.c), pointers(r.s)
+			for i := range r.m {
+				lr.m = append(lr.m, pointers(r.m[i]))
+			}
+			for i := range r.p {
+				lr.p = append(lr.p, pointers(r.p[i]))
+			}
+			legacyRounds = append(legacyRounds, lr)
+		}
+	})
+	r := legacyRounds[t-2]
+	return r.c, r.s, r.m, r.p
+}
+
+func legacyExp5(a *ff.Element) {
+	a.Exp(*a, big.NewInt(5)) //nolint:gomnd
+}
+
+func legacyExp5state(state []*ff.Element) {
+	for i := 0; i < len(state); i++ {
+		legacyExp5(state[i])
+	}
+}
+
+func legacyArk(state []*ff.Element, c []*ff.Element, it int) {
+	for i := 0; i < len(state); i++ {
+		state[i].Add(state[i], c[it+i])
+	}
+}
+
+func legacyMix(state []*ff.Element, t int, m [][]*ff.Element) []*ff.Element {
+	mul := zero()
+	newState := make([]*ff.Element, t)
+	for i := 0; i < t; i++ {
+		newState[i] = zero()
+	}
+	for i := 0; i < len(state); i++ {
+		newState[i].SetUint64(0)
+		for j := 0; j < len(state); j++ {
+			mul.Mul(m[j][i], state[j])
+			newState[i].Add(newState[i], mul)
+		}
+	}
+	return newState
+}
+
+func zero() *ff.Element {
+	return ff.NewElement()
+}
+
+func legacyPermute(state []*ff.Element, t int) []*ff.Element {
+	nRoundsF := NROUNDSF
+	nRoundsP := NROUNDSP[t-2]
+	C, S, M, P := legacyConstants(t)
+
+	legacyArk(state, C, 0)
+
+	for i := 0; i < nRoundsF/2-1; i++ {
+		legacyExp5state(state)
+		legacyArk(state, C, (i+1)*t)
+		state = legacyMix(state, t, M)
+	}
+	legacyExp5state(state)
+	legacyArk(state, C, (nRoundsF/2)*t)
+	state = legacyMix(state, t, P)
+
+	for i := 0; i < nRoundsP; i++ {
+		legacyExp5(state[0])
+		state[0].Add(state[0], C[(nRoundsF/2+1)*t+i])
+
+		mul := zero()
+		newState0 := zero()
+		for j := 0; j < len(state); j++ {
+			mul.Mul(S[(t*2-1)*i+j], state[j])
+			newState0.Add(newState0, mul)
+		}
+
+		for k := 1; k < t; k++ {
+			mul = zero()
+			state[k] = state[k].Add(state[k], mul.Mul(state[0], S[(t*2-1)*i+t+k-1]))
+		}
+		state[0] = newState0
+	}
+
+	for i := 0; i < nRoundsF/2-1; i++ {
+		legacyExp5state(state)
+		legacyArk(state, C, (nRoundsF/2+1)*t+nRoundsP+i*t)
+		state = legacyMix(state, t, M)
+	}
+	legacyExp5state(state)
+	return legacyMix(state, t, M)
+}
+
+func legacyHashWithCap(inpBI []*big.Int, width int, nBytes int64) *big.Int {
+	pow64 := big.NewInt(1)
+	pow64.Lsh(pow64, 64)
+	capflag := ff.NewElement().SetBigInt(big.NewInt(nBytes))
+	capflag.Mul(capflag, ff.NewElement().SetBigInt(pow64))
+
+	state := make([]*ff.Element, width)
+	state[0] = capflag
+	for i := 1; i < width; i++ {
+		state[i] = zero()
+	}
+	rate := width - 1
+	i := 0
+	for {
+		for j := 0; j < rate && i < len(inpBI); i, j = i+1, j+1 {
+			state[j+1].Add(state[j+1], ff.NewElement().SetBigInt(inpBI[i]))
+		}
+		state = legacyPermute(state, width)
+		if i == len(inpBI) {
+			break
+		}
+	}
+	return state[0].ToBigIntRegular(big.NewInt(0))
+}
+
+func legacyHashFixed(inpBI []*big.Int) *big.Int {
+	t := len(inpBI) + 1
+	inp := utils.BigIntArrayToElementArray(inpBI[:])
+
+	state := make([]*ff.Element, t)
+	state[0] = zero()
+	copy(state[1:], inp[:])
+
+	state = legacyPermute(state, t)
+	return state[0].ToBigIntRegular(big.NewInt(0))
+}
+
+func legacyCodeHash(code []byte) *big.Int {
+	var (
+		length = (len(code) + nBytesToFieldElement - 1) / nBytesToFieldElement
+		frs    = make([]*big.Int, length)
+	)
+	for i := range frs {
+		chunk := make([]byte, nBytesToFieldElement)
+		copy(chunk, code[i*nBytesToFieldElement:])
+		frs[i] = new(big.Int).SetBytes(chunk)
+	}
+	return legacyHashWithCap(frs, defaultPoseidonChunk, int64(len(code)))
+}
@@ -3,9 +3,11 @@
 package poseidon
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/iden3/go-iden3-crypto/ff"
 	"github.com/iden3/go-iden3-crypto/utils"
@@ -15,163 +17,336 @@ const NROUNDSF = 8 //nolint:golint
 
 var NROUNDSP = []int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68} //nolint:golint
 
-func zero() *ff.Element {
-	return ff.NewElement()
+var (
+	errInvalidWidth  = fmt.Errorf("width must be ranged from 2 to %d", len(NROUNDSP)+1)
+	errInvalidInputs = fmt.Errorf("invalid inputs length, must be ranged from 1 to %d", len(NROUNDSP))
+	errNotInField    = errors.New("inputs values not inside Finite Field")
+	errWidthMismatch = errors.New("inputs length doesn't match hasher width")
+	errBatchMismatch = errors.New("batch output length mismatch")
+)
+
+var (
+	// modulus is the field modulus in regular form, for range checks
+	modulus ff.Element
+
+	// hasherPools holds reusable hashers of every supported width, indexed by
+	// width - 2
+	hasherPools []sync.Pool
+)
+
+func init() {
+	var b [32]byte
+	ff.Modulus().FillBytes(b[:])
+	setLimbs(&modulus, &b)
+
+	hasherPools = make([]sync.Pool, len(NROUNDSP))
+	for i := range hasherPools {
+		index := i
+		hasherPools[i].New = func() interface{} {
+			return newHasher(rounds[index])
+		}
+	}
 }
 
-// exp5 performs x^5 mod p
+// exp5 performs x^5 mod p by square-and-multiply
 // https://eprint.iacr.org/2019/458.pdf page 8
 func exp5(a *ff.Element) {
-	a.Exp(*a, big.NewInt(5)) //nolint:gomnd
+	var sq ff.Element
+	sq.Square(a)
+	sq.Square(&sq)
+	a.Mul(a, &sq)
 }
 
 // exp5state perform exp5 for whole state
-func exp5state(state []*ff.Element) {
-	for i := 0; i < len(state); i++ {
-		exp5(state[i])
+func exp5state(state []ff.Element) {
+	for i := range state {
+		exp5(&state[i])
 	}
 }
 
 // ark computes Add-Round Key, from the paper https://eprint.iacr.org/2019/458.pdf
-func ark(state []*ff.Element, c []*ff.Element, it int) {
-	for i := 0; i < len(state); i++ {
-		state[i].Add(state[i], c[it+i])
+func ark(state []ff.Element, c []ff.Element) {
+	for i := range state {
+		state[i].Add(&state[i], &c[i])
 	}
 }
 
-// mix returns [[matrix]] * [vector]
-func mix(state []*ff.Element, t int, m [][]*ff.Element) []*ff.Element {
-	mul := zero()
-	newState := make([]*ff.Element, t)
-	for i := 0; i < t; i++ {
-		newState[i] = zero()
-	}
-	for i := 0; i < len(state); i++ {
-		newState[i].SetUint64(0)
-		for j := 0; j < len(state); j++ {
-			mul.Mul(m[j][i], state[j])
-			newState[i].Add(newState[i], mul)
+// mix sets the state to [[matrix]] * [vector], using scratch as temporary
+// storage.
+func mix(state, scratch []ff.Element, m [][]ff.Element) {
+	var mul ff.Element
+	for i := range scratch {
+		scratch[i].SetZero()
+		for j := range state {
+			mul.Mul(&m[j][i], &state[j])
+			scratch[i].Add(&scratch[i], &mul)
 		}
 	}
-	return newState
+	copy(state, scratch)
 }
 
-func permute(state []*ff.Element, t int) []*ff.Element {
-
-	nRoundsF := NROUNDSF
-	nRoundsP := NROUNDSP[t-2]
-	C := c.c[t-2]
-	S := c.s[t-2]
-	M := c.m[t-2]
-	P := c.p[t-2]
+// permute applies the poseidon permutation to the state in place.
+func (r *roundConstants) permute(state, scratch []ff.Element) {
+	var (
+		t  = r.width
+		rf = NROUNDSF / 2
+	)
+	ark(state, r.c[:t])
 
-	ark(state, C, 0)
-
-	for i := 0; i < nRoundsF/2-1; i++ {
+	for i := 0; i < rf-1; i++ {
 		exp5state(state)
-		ark(state, C, (i+1)*t)
-		state = mix(state, t, M)
+		ark(state, r.c[(i+1)*t:])
+		mix(state, scratch, r.m)
 	}
 	exp5state(state)
-	ark(state, C, (nRoundsF/2)*t)
-	state = mix(state, t, P)
-
-	for i := 0; i < nRoundsP; i++ {
-		exp5(state[0])
-		state[0].Add(state[0], C[(nRoundsF/2+1)*t+i])
-
-		mul := zero()
-		newState0 := zero()
-		for j := 0; j < len(state); j++ {
-			mul.Mul(S[(t*2-1)*i+j], state[j])
-			newState0.Add(newState0, mul)
-		}
+	ark(state, r.c[rf*t:])
+	mix(state, scratch, r.p)
+
+	var mul, state0 ff.Element
+	for i := 0; i < r.partial; i++ {
+		exp5(&state[0])
+		state[0].Add(&state[0], &r.c[(rf+1)*t+i])
 
+		s := r.s[(t*2-1)*i:]
+		state0.SetZero()
+		for j := range state {
+			mul.Mul(&s[j], &state[j])
+			state0.Add(&state0, &mul)
+		}
 		for k := 1; k < t; k++ {
-			mul = zero()
-			state[k] = state[k].Add(state[k], mul.Mul(state[0], S[(t*2-1)*i+t+k-1]))
+			mul.Mul(&state[0], &s[t+k-1])
+			state[k].Add(&state[k], &mul)
 		}
-		state[0] = newState0
+		state[0] = state0
 	}
 
-	for i := 0; i < nRoundsF/2-1; i++ {
+	for i := 0; i < rf-1; i++ {
 		exp5state(state)
-		ark(state, C, (nRoundsF/2+1)*t+nRoundsP+i*t)
-		state = mix(state, t, M)
+		ark(state, r.c[(rf+1)*t+r.partial+i*t:])
+		mix(state, scratch, r.m)
 	}
 	exp5state(state)
-	return mix(state, t, M)
+	mix(state, scratch, r.m)
 }
 
-// for short, use size of inpBI as cap
-func Hash(inpBI []*big.Int, width int) (*big.Int, error) {
-	return HashWithCap(inpBI, width, int64(len(inpBI)))
+// Hasher is a poseidon sponge of a fixed width, reusing its state buffers
+// between hashes. It is not safe for concurrent use.
+type Hasher struct {
+	rounds  *roundConstants
+	state   []ff.Element
+	scratch []ff.Element
 }
 
-// Hash using possible sponge specs specified by width (rate from 1 to 15), the size of input is applied as capacity
-// (notice we do not include width in the capacity )
-func HashWithCap(inpBI []*big.Int, width int, nBytes int64) (*big.Int, error) {
-	if width < 2 {
-		return nil, fmt.Errorf("width must be ranged from 2 to 16")
+// NewHasher creates a hasher for the given state width (rate + 1).
+func NewHasher(width int) (*Hasher, error) {
+	if width < 2 || width-2 >= len(rounds) {
+		return nil, errInvalidWidth
 	}
-	if width-2 > len(NROUNDSP) {
-		return nil, fmt.Errorf("invalid inputs width %d, max %d", width, len(NROUNDSP)+1) //nolint:gomnd,lll
+	return newHasher(rounds[width-2]), nil
+}
+
+func newHasher(r *roundConstants) *Hasher {
+	buf := make([]ff.Element, 2*r.width)
+	return &Hasher{
+		rounds:  r,
+		state:   buf[:r.width],
+		scratch: buf[r.width:],
 	}
+}
+
+// Width returns the state width of the hasher.
+func (h *Hasher) Width() int {
+	return h.rounds.width
+}
 
+// HashWithCap absorbs the inputs into the sponge, initialized with the given
+// capacity flag, and returns the first element of the final state. At least one
+// permutation is performed, even if there are no inputs.
+func (h *Hasher) HashWithCap(inputs []ff.Element, nBytes uint64) ff.Element {
 	// capflag = nBytes * 2^64
-	pow64 := big.NewInt(1)
-	pow64.Lsh(pow64, 64)
-	capflag := ff.NewElement().SetBigInt(big.NewInt(nBytes))
-	capflag.Mul(capflag, ff.NewElement().SetBigInt(pow64))
-
-	// initialize the state
-	state := make([]*ff.Element, width)
-	state[0] = capflag
-	for i := 1; i < width; i++ {
-		state[i] = zero()
-	}
-
-	rate := width - 1
-	i := 0
-	// always perform one round of permutation even when input is empty
-	for {
-		// each round absorb at most `rate` elements from `inpBI`
-		for j := 0; j < rate && i < len(inpBI); i, j = i+1, j+1 {
-			state[j+1].Add(state[j+1], ff.NewElement().SetBigInt(inpBI[i]))
+	for i := range h.state {
+		h.state[i].SetZero()
+	}
+	h.state[0][1] = nBytes
+	h.state[0].ToMont()
+
+	rate := h.rounds.width - 1
+	for i := 0; ; {
+		// each round absorb at most `rate` elements from the inputs
+		for j := 0; j < rate && i < len(inputs); i, j = i+1, j+1 {
+			h.state[j+1].Add(&h.state[j+1], &inputs[i])
 		}
-		state = permute(state, width)
-		if i == len(inpBI) {
+		h.rounds.permute(h.state, h.scratch)
+		if i == len(inputs) {
 			break
 		}
 	}
+	return h.state[0]
+}
 
-	// squeeze
-	rE := state[0]
-	r := big.NewInt(0)
-	rE.ToBigIntRegular(r)
-	return r, nil
+// HashFixed applies a single permutation to the inputs, which must fill the
+// rate of the hasher exactly. No capacity flag is applied.
+func (h *Hasher) HashFixed(inputs []ff.Element) (ff.Element, error) {
+	if len(inputs) != h.rounds.width-1 {
+		return ff.Element{}, errWidthMismatch
+	}
+	h.state[0].SetZero()
+	copy(h.state[1:], inputs)
+	h.rounds.permute(h.state, h.scratch)
+	return h.state[0], nil
+}
 
+// HashFixedElements computes the poseidon hash of the given fixed-size inputs,
+// selecting the width from the number of inputs.
+func HashFixedElements(inputs []ff.Element) (ff.Element, error) {
+	if len(inputs) == 0 || len(inputs) > len(NROUNDSP) {
+		return ff.Element{}, errInvalidInputs
+	}
+	pool := &hasherPools[len(inputs)-1]
+	h := pool.Get().(*Hasher)
+	defer pool.Put(h)
+
+	return h.HashFixed(inputs)
+}
+
+// HashFixed32 computes the poseidon hash of the given big-endian encoded
+// fixed-size inputs, which must all be inside the finite field.
+func HashFixed32(inputs [][32]byte) ([32]byte, error) {
+	if len(inputs) == 0 || len(inputs) > len(NROUNDSP) {
+		return [32]byte{}, errInvalidInputs
+	}
+	var elems [16]ff.Element
+	for i := range inputs {
+		if !setBytes32(&elems[i], &inputs[i]) {
+			return [32]byte{}, errNotInField
+		}
+	}
+	res, err := HashFixedElements(elems[:len(inputs)])
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return res.Bytes(), nil
+}
+
+// HashWithCap32 absorbs the big-endian encoded inputs, which must all be inside
+// the finite field, into a sponge of the given width initialized with the
+// capacity flag nBytes.
+func HashWithCap32(inputs [][32]byte, width int, nBytes uint64) ([32]byte, error) {
+	if width < 2 || width-2 >= len(NROUNDSP) {
+		return [32]byte{}, errInvalidWidth
+	}
+	elems := make([]ff.Element, len(inputs))
+	for i := range inputs {
+		if !setBytes32(&elems[i], &inputs[i]) {
+			return [32]byte{}, errNotInField
+		}
+	}
+	pool := &hasherPools[width-2]
+	h := pool.Get().(*Hasher)
+	defer pool.Put(h)
+
+	res := h.HashWithCap(elems, nBytes)
+	return res.Bytes(), nil
+}
+
+// HashBatch computes the fixed-size poseidon hash of every input set into out,
+// which must be at least as long as inputs. Consecutive input sets of equal
+// length share a hasher.
+func HashBatch(inputs [][]ff.Element, out []ff.Element) error {
+	if len(out) < len(inputs) {
+		return errBatchMismatch
+	}
+	var (
+		h    *Hasher
+		pool *sync.Pool
+	)
+	defer func() {
+		if h != nil {
+			pool.Put(h)
+		}
+	}()
+	for i, input := range inputs {
+		if len(input) == 0 || len(input) > len(NROUNDSP) {
+			return errInvalidInputs
+		}
+		if h == nil || h.Width() != len(input)+1 {
+			if h != nil {
+				pool.Put(h)
+			}
+			pool = &hasherPools[len(input)-1]
+			h = pool.Get().(*Hasher)
+		}
+		res, err := h.HashFixed(input)
+		if err != nil {
+			return err
+		}
+		out[i] = res
+	}
+	return nil
+}
+
+// setLimbs sets z to the big-endian encoded value b, without any reduction or
+// conversion to montgomery form.
+func setLimbs(z *ff.Element, b *[32]byte) {
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(b[24-8*i : 32-8*i])
+	}
+}
+
+// setBytes32 sets z to the big-endian encoded value b, returning false if it
+// isn't inside the finite field.
+func setBytes32(z *ff.Element, b *[32]byte) bool {
+	setLimbs(z, b)
+	for i := len(z) - 1; i >= 0; i-- {
+		if z[i] != modulus[i] {
+			if z[i] > modulus[i] {
+				return false
+			}
+			z.ToMont()
+			return true
+		}
+	}
+	return false // equal to the modulus
+}
+
+// for short, use size of inpBI as cap
+func Hash(inpBI []*big.Int, width int) (*big.Int, error) {
+	return HashWithCap(inpBI, width, int64(len(inpBI)))
+}
+
+// Hash using possible sponge specs specified by width (rate from 1 to 15), the size of input is applied as capacity
+// (notice we do not include width in the capacity )
+func HashWithCap(inpBI []*big.Int, width int, nBytes int64) (*big.Int, error) {
+	if width < 2 || width-2 >= len(NROUNDSP) {
+		return nil, errInvalidWidth
+	}
+	inp := make([]ff.Element, len(inpBI))
+	for i := range inpBI {
+		inp[i].SetBigInt(inpBI[i])
+	}
+	pool := &hasherPools[width-2]
+	h := pool.Get().(*Hasher)
+	defer pool.Put(h)
+
+	rE := h.HashWithCap(inp, uint64(nBytes))
+	return rE.ToBigIntRegular(new(big.Int)), nil
 }
 
 // Hash computes the Poseidon hash for the given fixed-size inputs, select specs automatically from the size, no capacity flag is applied
 func HashFixed(inpBI []*big.Int) (*big.Int, error) {
-	t := len(inpBI) + 1
 	if len(inpBI) == 0 || len(inpBI) > len(NROUNDSP) {
 		return nil, fmt.Errorf("invalid inputs length %d, max %d", len(inpBI), len(NROUNDSP)) //nolint:gomnd,lll
 	}
 	if !utils.CheckBigIntArrayInField(inpBI[:]) {
-		return nil, errors.New("inputs values not inside Finite Field")
+		return nil, errNotInField
 	}
-	inp := utils.BigIntArrayToElementArray(inpBI[:])
-
-	state := make([]*ff.Element, t)
-	state[0] = zero()
-	copy(state[1:], inp[:])
-
-	state = permute(state, t)
-
-	rE := state[0]
-	r := big.NewInt(0)
-	rE.ToBigIntRegular(r)
-	return r, nil
+	var inp [16]ff.Element
+	for i := range inpBI {
+		inp[i].SetBigInt(inpBI[i])
+	}
+	rE, err := HashFixedElements(inp[:len(inpBI)])
+	if err != nil {
+		return nil, err
+	}
+	return rE.ToBigIntRegular(new(big.Int)), nil
 }
@@ -3,8 +3,10 @@ package poseidon
 
 import (
 	"math/big"
+	"math/rand"
 	"testing"
 
+	"github.com/iden3/go-iden3-crypto/ff"
 	"github.com/iden3/go-iden3-crypto/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -168,3 +170,167 @@ func BenchmarkPoseidonHash(b *testing.B) {
 		HashFixed(bigArray4) //nolint:errcheck,gosec
 	}
 }
+
+// randomInputs returns n pseudo-random field elements as big integers.
+func randomInputs(rnd *rand.Rand, n int) []*big.Int {
+	inputs := make([]*big.Int, n)
+	for i := range inputs {
+		inputs[i] = new(big.Int).Rand(rnd, ff.Modulus())
+	}
+	return inputs
+}
+
+func toElements(inputs []*big.Int) []ff.Element {
+	elems := make([]ff.Element, len(inputs))
+	for i := range inputs {
+		elems[i].SetBigInt(inputs[i])
+	}
+	return elems
+}
+
+// Tests that the hashes match the previous implementation for every width.
+func TestHashMatchesLegacy(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for n := 1; n <= len(NROUNDSP); n++ {
+		inputs := randomInputs(rnd, n)
+
+		have, err := HashFixed(inputs)
+		require.NoError(t, err)
+		require.Equal(t, legacyHashFixed(inputs), have, "fixed hash of %d inputs", n)
+
+		for _, width := range []int{2, 3, n + 1} {
+			have, err := HashWithCap(inputs, width, int64(n*31))
+			require.NoError(t, err)
+			require.Equal(t, legacyHashWithCap(inputs, width, int64(n*31)), have, "sponge hash of %d inputs, width %d", n, width)
+		}
+	}
+}
+
+func TestHashFixed32(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	inputs := randomInputs(rnd, 4)
+
+	var encoded [][32]byte
+	for _, input := range inputs {
+		var b [32]byte
+		input.FillBytes(b[:])
+		encoded = append(encoded, b)
+	}
+	have, err := HashFixed32(encoded)
+	require.NoError(t, err)
+	want, _ := HashFixed(inputs)
+	require.Equal(t, want, new(big.Int).SetBytes(have[:]))
+
+	// The modulus itself must be rejected
+	ff.Modulus().FillBytes(encoded[0][:])
+	_, err = HashFixed32(encoded)
+	require.ErrorIs(t, err, errNotInField)
+
+	_, err = HashFixed32(nil)
+	require.ErrorIs(t, err, errInvalidInputs)
+}
+
+func TestHashWithCap32(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	inputs := randomInputs(rnd, 7)
+
+	var encoded [][32]byte
+	for _, input := range inputs {
+		var b [32]byte
+		input.FillBytes(b[:])
+		encoded = append(encoded, b)
+	}
+	have, err := HashWithCap32(encoded, 3, 42)
+	require.NoError(t, err)
+	want, _ := HashWithCap(inputs, 3, 42)
+	require.Equal(t, want, new(big.Int).SetBytes(have[:]))
+
+	ff.Modulus().FillBytes(encoded[0][:])
+	_, err = HashWithCap32(encoded, 3, 42)
+	require.ErrorIs(t, err, errNotInField)
+
+	_, err = HashWithCap32(encoded, 1, 42)
+	require.ErrorIs(t, err, errInvalidWidth)
+}
+
+func TestHashBatch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+
+	var (
+		inputs [][]ff.Element
+		want   []*big.Int
+	)
+	for _, n := range []int{2, 2, 3, 1, 2, 16} {
+		batch := randomInputs(rnd, n)
+		inputs = append(inputs, toElements(batch))
+		want = append(want, legacyHashFixed(batch))
+	}
+	out := make([]ff.Element, len(inputs))
+	require.NoError(t, HashBatch(inputs, out))
+	for i := range out {
+		require.Equal(t, want[i], out[i].ToBigIntRegular(new(big.Int)), "batch item %d", i)
+	}
+	require.ErrorIs(t, HashBatch(inputs, out[1:]), errBatchMismatch)
+	require.ErrorIs(t, HashBatch([][]ff.Element{{}}, out), errInvalidInputs)
+}
+
+func TestHasherAllocations(t *testing.T) {
+	h, err := NewHasher(3)
+	require.NoError(t, err)
+
+	inputs := toElements(randomInputs(rand.New(rand.NewSource(4)), 2))
+	allocs := testing.AllocsPerRun(100, func() {
+		h.HashFixed(inputs)
+		h.HashWithCap(inputs, 62)
+	})
+	require.Zero(t, allocs)
+
+	_, err = NewHasher(len(NROUNDSP) + 2)
+	require.ErrorIs(t, err, errInvalidWidth)
+	_, err = h.HashFixed(inputs[:1])
+	require.ErrorIs(t, err, errWidthMismatch)
+}
+
+func BenchmarkHashFixed(b *testing.B) {
+	inputs := randomInputs(rand.New(rand.NewSource(5)), 2)
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			legacyHashFixed(inputs)
+		}
+	})
+	b.Run("bigint", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			HashFixed(inputs)
+		}
+	})
+	b.Run("hasher", func(b *testing.B) {
+		h, _ := NewHasher(3)
+		elems := toElements(inputs)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.HashFixed(elems)
+		}
+	})
+}
+
+func BenchmarkCodeHash(b *testing.B) {
+	code := make([]byte, 24576)
+	rand.New(rand.NewSource(6)).Read(code)
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			legacyCodeHash(code)
+		}
+	})
+	b.Run("current", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CodeHash(code)
+		}
+	})
+}
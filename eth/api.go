@@ -159,6 +159,32 @@ func NewPrivateAdminAPI(eth *Ethereum) *PrivateAdminAPI {
 	return &PrivateAdminAPI{eth: eth}
 }
 
+// SetSafeBlock marks the canonical block with the given number as committed to
+// L1, making it resolvable through the "safe" block tag.
+func (api *PrivateAdminAPI) SetSafeBlock(number hexutil.Uint64) (bool, error) {
+	block := api.eth.blockchain.GetBlockByNumber(uint64(number))
+	if block == nil {
+		return false, fmt.Errorf("block #%d not found", number)
+	}
+	if err := api.eth.blockchain.SetSafe(block); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetFinalizedBlock marks the canonical block with the given number as finalized
+// on L1, making it resolvable through the "finalized" block tag.
+func (api *PrivateAdminAPI) SetFinalizedBlock(number hexutil.Uint64) (bool, error) {
+	block := api.eth.blockchain.GetBlockByNumber(uint64(number))
+	if block == nil {
+		return false, fmt.Errorf("block #%d not found", number)
+	}
+	if err := api.eth.blockchain.SetFinalized(block); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // ExportChain exports the current blockchain into a local file,
 // or a range of blocks if first and last are non-nil
 func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool, error) {
@@ -70,15 +70,43 @@ func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumb
 	// Pending block is only known by the miner
 	if number == rpc.PendingBlockNumber {
 		block := b.eth.miner.PendingBlock()
+		if block == nil {
+			return nil, nil
+		}
+		if b.eth.config.TxPool.Private {
+			block = redactBlock(block)
+		}
 		return block.Header(), nil
 	}
 	// Otherwise resolve and return the block
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock().Header(), nil
 	}
+	if number == rpc.SafeBlockNumber || number == rpc.FinalizedBlockNumber {
+		block, err := b.rollupBlock(number)
+		if err != nil {
+			return nil, err
+		}
+		return block.Header(), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumber(uint64(number)), nil
 }
 
+// rollupBlock resolves the safe and finalized block tags to the blocks tracked
+// by the rollup markers of the chain.
+func (b *EthAPIBackend) rollupBlock(number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.SafeBlockNumber {
+		if block := b.eth.blockchain.CurrentSafeBlock(); block != nil {
+			return block, nil
+		}
+		return nil, errors.New("safe block not found")
+	}
+	if block := b.eth.blockchain.CurrentFinalizedBlock(); block != nil {
+		return block, nil
+	}
+	return nil, errors.New("finalized block not found")
+}
+
 func (b *EthAPIBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.HeaderByNumber(ctx, blockNr)
@@ -104,15 +132,32 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 	// Pending block is only known by the miner
 	if number == rpc.PendingBlockNumber {
 		block := b.eth.miner.PendingBlock()
+		if block != nil && b.eth.config.TxPool.Private {
+			block = redactBlock(block)
+		}
 		return block, nil
 	}
 	// Otherwise resolve and return the block
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock(), nil
 	}
+	if number == rpc.SafeBlockNumber || number == rpc.FinalizedBlockNumber {
+		return b.rollupBlock(number)
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
 }
 
+// redactBlock strips a pending block of its transactions and of the header fields
+// derived from them, so it doesn't reveal the content of a private pool.
+func redactBlock(block *types.Block) *types.Block {
+	header := block.Header()
+	header.TxHash = types.EmptyRootHash
+	header.ReceiptHash = types.EmptyRootHash
+	header.Bloom = types.Bloom{}
+	header.GasUsed = 0
+	return types.NewBlockWithHeader(header)
+}
+
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	return b.eth.blockchain.GetBlockByHash(hash), nil
 }
@@ -143,11 +188,18 @@ func (b *EthAPIBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts)
 }
 
 func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
-	// Pending state is only known by the miner
-	if number == rpc.PendingBlockNumber {
+	// Pending state is only known by the miner. A private pool doesn't reveal
+	// it, the pending state is the one of the latest block.
+	if number == rpc.PendingBlockNumber && !b.eth.config.TxPool.Private {
 		block, state := b.eth.miner.Pending()
+		if block == nil {
+			return nil, nil, errors.New("pending block not found")
+		}
 		return state, block.Header(), nil
 	}
+	if number == rpc.PendingBlockNumber {
+		number = rpc.LatestBlockNumber
+	}
 	// Otherwise resolve the block number and return its state
 	header, err := b.HeaderByNumber(ctx, number)
 	if err != nil {
@@ -231,6 +283,14 @@ func (b *EthAPIBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) e
 	return b.eth.BlockChain().SubscribeChainHeadEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeSafeHeadEvent(ch chan<- core.SafeHeadEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeSafeHeadEvent(ch)
+}
+
+func (b *EthAPIBackend) SubscribeFinalizedHeadEvent(ch chan<- core.FinalizedHeadEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeFinalizedHeadEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainSideEvent(ch)
 }
@@ -318,6 +378,10 @@ func (b *EthAPIBackend) UnprotectedAllowed() bool {
 	return b.allowUnprotectedTxs
 }
 
+func (b *EthAPIBackend) PrivateTxPool() bool {
+	return b.eth.config.TxPool.Private
+}
+
 func (b *EthAPIBackend) RPCGasCap() uint64 {
 	return b.eth.config.RPCGasCap
 }
@@ -335,6 +399,14 @@ func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, sections
 }
 
+func (b *EthAPIBackend) LogIndexStatus() (uint64, uint64) {
+	if b.eth.logIndexer == nil {
+		return 0, 0
+	}
+	sections, _, _ := b.eth.logIndexer.Sections()
+	return params.BloomBitsBlocks, sections
+}
+
 func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	for i := 0; i < bloomFilterThreads; i++ {
 		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.eth.bloomRequests)
@@ -0,0 +1,139 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// newPendingTestBackend starts a node with a transaction of testAddr in the
+// pending block, keeping its pool private if requested.
+func newPendingTestBackend(t *testing.T, private bool) *EthAPIBackend {
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	t.Cleanup(func() { stack.Close() })
+
+	config := ethconfig.Defaults
+	config.Genesis = &core.Genesis{
+		Config:   params.AllEthashProtocolChanges,
+		GasLimit: 11500000,
+		Alloc:    core.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+	}
+	config.Ethash = ethash.Config{PowMode: ethash.ModeFake}
+	config.TxPool.Private = private
+
+	ethservice, err := New(stack, &config)
+	if err != nil {
+		t.Fatalf("could not create eth service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	backend := ethservice.APIBackend
+
+	// The pending block is created once the miner is initialized
+	waitPending(t, backend, 0)
+	signer := types.LatestSigner(config.Genesis.Config)
+	tx, _ := types.SignNewTx(testKey, signer, &types.LegacyTx{
+		To:       &common.Address{0xaa},
+		Value:    big.NewInt(1),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(2 * params.InitialBaseFee),
+	})
+	if err := backend.SendTx(context.Background(), tx); err != nil {
+		t.Fatalf("could not send transaction: %v", err)
+	}
+	waitPending(t, backend, 1)
+	return backend
+}
+
+// waitPending waits for the miner to build a pending block with the given number
+// of transactions.
+func waitPending(t *testing.T, backend *EthAPIBackend, txs int) {
+	for i := 0; i < 100; i++ {
+		if block := backend.eth.miner.PendingBlock(); block != nil && len(block.Transactions()) == txs {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no pending block with %d transactions", txs)
+}
+
+func TestPendingPublicPool(t *testing.T) {
+	backend := newPendingTestBackend(t, false)
+	ctx := context.Background()
+
+	block, err := backend.BlockByNumber(ctx, rpc.PendingBlockNumber)
+	if err != nil || len(block.Transactions()) != 1 {
+		t.Fatalf("pending block mismatch: %v, %v", block, err)
+	}
+	header, err := backend.HeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if err != nil || header.GasUsed != params.TxGas {
+		t.Fatalf("pending header mismatch: %v, %v", header, err)
+	}
+	state, header, err := backend.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if err != nil {
+		t.Fatalf("could not retrieve pending state: %v", err)
+	}
+	if header.Number.Uint64() != 1 || state.GetNonce(testAddr) != 1 {
+		t.Errorf("pending state mismatch: number %d, nonce %d", header.Number, state.GetNonce(testAddr))
+	}
+}
+
+func TestPendingPrivatePool(t *testing.T) {
+	backend := newPendingTestBackend(t, true)
+	ctx := context.Background()
+
+	block, err := backend.BlockByNumber(ctx, rpc.PendingBlockNumber)
+	if err != nil || len(block.Transactions()) != 0 || block.GasUsed() != 0 {
+		t.Fatalf("pending block not redacted: %v, %v", block, err)
+	}
+	header, err := backend.HeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if err != nil || header.GasUsed != 0 || header.TxHash != types.EmptyRootHash {
+		t.Fatalf("pending header not redacted: %v, %v", header, err)
+	}
+	// The pending state is the one of the latest block
+	state, header, err := backend.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if err != nil {
+		t.Fatalf("could not retrieve pending state: %v", err)
+	}
+	if header.Number.Uint64() != 0 || state.GetNonce(testAddr) != 0 {
+		t.Errorf("pending state not hidden: number %d, nonce %d", header.Number, state.GetNonce(testAddr))
+	}
+	pending := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	if state, _, err := backend.StateAndHeaderByNumberOrHash(ctx, pending); err != nil || state.GetBalance(common.Address{0xaa}).Sign() != 0 {
+		t.Errorf("pending balance not hidden: %v", err)
+	}
+	if !backend.PrivateTxPool() {
+		t.Errorf("pool not reported private")
+	}
+}
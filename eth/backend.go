@@ -54,6 +54,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/p2p/enode"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rollup/derivation"
+	"github.com/scroll-tech/go-ethereum/rollup/gas_oracle"
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
@@ -82,10 +85,14 @@ type Ethereum struct {
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
+	closeCodeIndexer  chan struct{}      // Channel to interrupt the poseidon code hash indexing
+	codeIndexerWg     sync.WaitGroup     // Wait group for the poseidon code hash indexing
+	logIndexer        *core.ChainIndexer // Log indexer operating during block imports, nil if disabled
 
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
+	deriver   *derivation.Deriver // L1 batch derivation, only set in verifier mode
 	gasPrice  *big.Int
 	etherbase common.Address
 
@@ -140,13 +147,22 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err := pruner.RecoverPruning(stack.ResolvePath(""), chainDb, stack.ResolvePath(config.TrieCleanCacheJournal)); err != nil {
 		log.Error("Failed to recover state", "error", err)
 	}
+	// Blocks derived from L1 are not sealed, verifier nodes use a dedicated engine
+	var engine consensus.Engine
+	if config.Derivation.Endpoint != "" {
+		log.Info("Deriving chain from L1 batches", "endpoint", config.Derivation.Endpoint)
+		engine = derivation.NewEngine()
+	} else {
+		engine = ethconfig.CreateConsensusEngine(stack, chainConfig, &ethashConfig, config.Miner.Notify, config.Miner.Noverify, chainDb)
+	}
 	eth := &Ethereum{
 		config:            config,
 		chainDb:           chainDb,
 		eventMux:          stack.EventMux(),
 		accountManager:    stack.AccountManager(),
-		engine:            ethconfig.CreateConsensusEngine(stack, chainConfig, &ethashConfig, config.Miner.Notify, config.Miner.Noverify, chainDb),
+		engine:            engine,
 		closeBloomHandler: make(chan struct{}),
+		closeCodeIndexer:  make(chan struct{}),
 		networkID:         config.NetworkId,
 		gasPrice:          config.Miner.GasPrice,
 		etherbase:         config.Miner.Etherbase,
@@ -175,6 +191,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	var (
 		vmConfig = vm.Config{
 			EnablePreimageRecording: config.EnablePreimageRecording,
+			WasmEngine:              config.WasmEngine,
 		}
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:      config.TrieCleanCache,
@@ -200,12 +217,50 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if config.LogIndex {
+		eth.logIndexer = core.NewLogIndexer(chainDb, chainConfig, params.BloomBitsBlocks, params.BloomConfirms)
+		eth.logIndexer.Start(eth.blockchain)
+	}
+
+	if config.Derivation.Endpoint != "" {
+		client, err := rpc.Dial(config.Derivation.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to L1 batch source: %w", err)
+		}
+		eth.deriver = derivation.New(&config.Derivation, chainDb, eth.blockchain, derivation.NewRPCSource(client))
+		stack.RegisterLifecycle(eth.deriver)
+	}
+	if config.L1Sync.Endpoint != "" {
+		client, err := rpc.Dial(config.L1Sync.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to L1 message source: %w", err)
+		}
+		stack.RegisterLifecycle(sync_service.New(&config.L1Sync, chainDb, sync_service.NewRPCSource(client)))
+	} else if chainConfig.Scroll.L1MessagesEnabled() {
+		log.Warn("L1 message sync disabled, blocks with L1 messages can't be produced or validated")
+	}
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
+	if config.L1GasOracle.Endpoint != "" {
+		client, err := rpc.Dial(config.L1GasOracle.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to L1 gas price source: %w", err)
+		}
+		signer := func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			account := accounts.Account{Address: addr}
+			wallet, err := stack.AccountManager().Find(account)
+			if err != nil {
+				return nil, err
+			}
+			return wallet.SignTx(account, tx, chainConfig.ChainID)
+		}
+		stack.RegisterLifecycle(gas_oracle.New(&config.L1GasOracle, eth.blockchain, eth.txPool, gas_oracle.NewRPCSource(client), signer))
+	}
+
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	checkpoint := config.Checkpoint
@@ -222,6 +277,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		EventMux:   eth.eventMux,
 		Checkpoint: checkpoint,
 		Whitelist:  config.Whitelist,
+		PrivateTxs: config.TxPool.Private,
+		L1Blocks:   config.Derivation.Endpoint != "",
 	}); err != nil {
 		return nil, err
 	}
@@ -298,8 +355,9 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
-	// Append all the local APIs and return
-	return append(apis, []rpc.API{
+	// Append all the local APIs
+	filterAPI := filters.NewPublicFilterAPI(s.APIBackend, false, 5*time.Minute)
+	apis = append(apis, []rpc.API{
 		{
 			Namespace: "eth",
 			Version:   "1.0",
@@ -323,12 +381,13 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.APIBackend, false, 5*time.Minute),
+			Service:   filterAPI,
 			Public:    true,
 		}, {
-			Namespace: "admin",
-			Version:   "1.0",
-			Service:   NewPrivateAdminAPI(s),
+			Namespace:     "admin",
+			Version:       "1.0",
+			Service:       NewPrivateAdminAPI(s),
+			Authenticated: true,
 		}, {
 			Namespace: "debug",
 			Version:   "1.0",
@@ -345,6 +404,25 @@ func (s *Ethereum) APIs() []rpc.API {
 			Public:    true,
 		},
 	}...)
+
+	// A private pool only reveals pending transactions over authenticated endpoints
+	if s.config.TxPool.Private {
+		for i := range apis {
+			if apis[i].Namespace == "txpool" {
+				apis[i].Authenticated = true
+			}
+			if apis[i].Service == filterAPI {
+				apis[i].Service = filters.NewRestrictedFilterAPI(filterAPI)
+			}
+		}
+		apis = append(apis, rpc.API{
+			Namespace:     "eth",
+			Version:       "1.0",
+			Service:       filterAPI,
+			Authenticated: true,
+		})
+	}
+	return apis
 }
 
 func (s *Ethereum) ResetWithGenesisBlock(gb *types.Block) {
@@ -530,6 +608,13 @@ func (s *Ethereum) Start() error {
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers(params.BloomBitsBlocks)
 
+	// Index the codes stored before the poseidon code hash index existed
+	s.codeIndexerWg.Add(1)
+	go func() {
+		defer s.codeIndexerWg.Done()
+		rawdb.IndexPoseidonCodeHashes(s.chainDb, s.closeCodeIndexer)
+	}()
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	//if s.config.LightServ > 0 {
@@ -554,6 +639,11 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	close(s.closeCodeIndexer)
+	s.codeIndexerWg.Wait()
+	if s.logIndexer != nil {
+		s.logIndexer.Close()
+	}
 	s.txPool.Stop()
 	s.miner.Close()
 	s.blockchain.Stop()
@@ -44,12 +44,20 @@ func Register(stack *node.Node, backend *eth.Ethereum) error {
 	}
 
 	log.Warn("Catalyst mode enabled")
+	api := newConsensusAPI(backend)
 	stack.RegisterAPIs([]rpc.API{
 		{
-			Namespace: "consensus",
-			Version:   "1.0",
-			Service:   newConsensusAPI(backend),
-			Public:    true,
+			Namespace:     "consensus",
+			Version:       "1.0",
+			Service:       api,
+			Public:        true,
+			Authenticated: true,
+		}, {
+			Namespace:     "engine",
+			Version:       "1.0",
+			Service:       api,
+			Public:        true,
+			Authenticated: true,
 		},
 	})
 	return nil
@@ -37,6 +37,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/miner"
 	"github.com/scroll-tech/go-ethereum/node"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/derivation"
+	"github.com/scroll-tech/go-ethereum/rollup/gas_oracle"
+	"github.com/scroll-tech/go-ethereum/rollup/sync_service"
 )
 
 // FullNodeGPO contains default gasprice oracle settings for full node.
@@ -92,6 +95,9 @@ var Defaults = Config{
 	RPCEVMTimeout: 5 * time.Second,
 	GPO:           FullNodeGPO,
 	RPCTxFeeCap:   1, // 1 ether
+	Derivation:    derivation.DefaultConfig,
+	L1Sync:        sync_service.DefaultConfig,
+	L1GasOracle:   gas_oracle.DefaultConfig,
 }
 
 func init() {
@@ -136,6 +142,7 @@ type Config struct {
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	LogIndex      bool   `toml:",omitempty"` // Whether to maintain the address and topic log index
 
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
@@ -183,6 +190,9 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// WASM engine executing the contracts, the default one if empty
+	WasmEngine string
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -207,6 +217,15 @@ type Config struct {
 
 	// Trace option
 	MPTWitness int
+
+	// L1 derivation options, the node runs in verifier mode if an endpoint is set
+	Derivation derivation.Config
+
+	// L1 message sync options
+	L1Sync sync_service.Config
+
+	// L1GasPriceOracle updater options
+	L1GasOracle gas_oracle.Config
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
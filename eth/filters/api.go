@@ -240,6 +240,49 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 	return rpcSub, nil
 }
 
+// NewSafeHeads send a notification each time the latest block committed to L1
+// advances.
+func (api *PublicFilterAPI) NewSafeHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.rollupHeads(ctx, api.events.SubscribeNewSafeHeads)
+}
+
+// NewFinalizedHeads send a notification each time the latest block finalized on
+// L1 advances.
+func (api *PublicFilterAPI) NewFinalizedHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.rollupHeads(ctx, api.events.SubscribeNewFinalizedHeads)
+}
+
+// rollupHeads forwards the headers delivered by the given event system
+// subscription to a new RPC subscription.
+func (api *PublicFilterAPI) rollupHeads(ctx context.Context, subscribe func(chan *types.Header) *Subscription) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := subscribe(headers)
+
+		for {
+			select {
+			case h := <-headers:
+				notifier.Notify(rpcSub.ID, h)
+			case <-rpcSub.Err():
+				headersSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				headersSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -581,3 +624,51 @@ func decodeTopic(s string) (common.Hash, error) {
 	}
 	return common.BytesToHash(b), err
 }
+
+// errPendingPrivate is returned for requests revealing pending transactions on
+// nodes keeping their transaction pool private.
+var errPendingPrivate = errors.New("pending transactions are private")
+
+// RestrictedFilterAPI is a PublicFilterAPI that doesn't reveal pending transactions
+// or logs. It's served publicly by nodes keeping their transaction pool private,
+// which expose the unrestricted API over authenticated endpoints only.
+type RestrictedFilterAPI struct {
+	*PublicFilterAPI
+}
+
+// NewRestrictedFilterAPI wraps a filter API to hide pending transactions.
+func NewRestrictedFilterAPI(api *PublicFilterAPI) *RestrictedFilterAPI {
+	return &RestrictedFilterAPI{api}
+}
+
+// NewPendingTransactionFilter refuses to create pending transaction filters.
+func (api *RestrictedFilterAPI) NewPendingTransactionFilter() (rpc.ID, error) {
+	return "", errPendingPrivate
+}
+
+// NewPendingTransactions refuses to create pending transaction subscriptions.
+func (api *RestrictedFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	return nil, errPendingPrivate
+}
+
+// Logs creates a subscription for new logs, refusing to include pending ones.
+func (api *RestrictedFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+	if crit.isPending() {
+		return nil, errPendingPrivate
+	}
+	return api.PublicFilterAPI.Logs(ctx, crit)
+}
+
+// NewFilter creates a new log filter, refusing to include pending logs.
+func (api *RestrictedFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
+	if crit.isPending() {
+		return "", errPendingPrivate
+	}
+	return api.PublicFilterAPI.NewFilter(crit)
+}
+
+// isPending reports whether the criteria cover the pending block.
+func (args *FilterCriteria) isPending() bool {
+	pending := rpc.PendingBlockNumber.Int64()
+	return (args.FromBlock != nil && args.FromBlock.Int64() == pending) || (args.ToBlock != nil && args.ToBlock.Int64() == pending)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/bloombits"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
@@ -42,8 +43,11 @@ type Backend interface {
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeSafeHeadEvent(ch chan<- core.SafeHeadEvent) event.Subscription
+	SubscribeFinalizedHeadEvent(ch chan<- core.FinalizedHeadEvent) event.Subscription
 
 	BloomStatus() (uint64, uint64)
+	LogIndexStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
@@ -142,18 +146,33 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
-	// Gather all indexed logs, and finish with non indexed ones
+	// Gather all logs available in the log index, then the ones covered by the
+	// bloom bits, and finish with non indexed ones
 	var (
 		logs []*types.Log
 		err  error
 	)
+	if size, sections := f.backend.LogIndexStatus(); f.selective() {
+		if indexed := sections * size; indexed > uint64(f.begin) {
+			if indexed > end {
+				logs, err = f.logIndexLogs(ctx, size, end)
+			} else {
+				logs, err = f.logIndexLogs(ctx, size, indexed-1)
+			}
+			if err != nil || uint64(f.begin) > end {
+				return logs, err
+			}
+		}
+	}
 	size, sections := f.backend.BloomStatus()
 	if indexed := sections * size; indexed > uint64(f.begin) {
+		var found []*types.Log
 		if indexed > end {
-			logs, err = f.indexedLogs(ctx, end)
+			found, err = f.indexedLogs(ctx, end)
 		} else {
-			logs, err = f.indexedLogs(ctx, indexed-1)
+			found, err = f.indexedLogs(ctx, indexed-1)
 		}
+		logs = append(logs, found...)
 		if err != nil {
 			return logs, err
 		}
@@ -210,6 +229,145 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 	}
 }
 
+// selective returns whether the filter restricts the addresses or any of the
+// topics, which is required to look logs up in the log index.
+func (f *Filter) selective() bool {
+	if len(f.addresses) > 0 {
+		return true
+	}
+	for _, sub := range f.topics {
+		if len(sub) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// logIndexLogs returns the logs matching the filter criteria based on the log
+// index sections available locally. It stops at the first section which isn't
+// indexed for the canonical chain, such as a section a reorg replaced before the
+// indexer rolled it back, leaving the rest of the range to the other lookups.
+func (f *Filter) logIndexLogs(ctx context.Context, size, end uint64) ([]*types.Log, error) {
+	var logs []*types.Log
+
+	for section := uint64(f.begin) / size; section*size <= end; section++ {
+		head := rawdb.ReadCanonicalHash(f.db, (section+1)*size-1)
+		if !rawdb.HasLogIndexSection(f.db, section, head) {
+			break
+		}
+		blocks, err := f.logIndexMatches(section, head)
+		if err != nil {
+			return logs, err
+		}
+		for _, offset := range blocks {
+			number := section*size + offset
+			if number < uint64(f.begin) {
+				continue
+			}
+			if number > end {
+				break
+			}
+			f.begin = int64(number) + 1
+
+			// Retrieve the suggested block and pull the matching logs
+			header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if header == nil || err != nil {
+				return logs, err
+			}
+			found, err := f.checkMatches(ctx, header)
+			if err != nil {
+				return logs, err
+			}
+			logs = append(logs, found...)
+		}
+		if next := (section + 1) * size; next <= end {
+			f.begin = int64(next)
+		} else {
+			f.begin = int64(end) + 1
+		}
+		if err := ctx.Err(); err != nil {
+			return logs, err
+		}
+	}
+	return logs, nil
+}
+
+// logIndexMatches returns the blocks of a log index section which contain logs
+// matching all the address and topic criteria of the filter.
+func (f *Filter) logIndexMatches(section uint64, head common.Hash) ([]uint64, error) {
+	var terms [][][]byte
+	if len(f.addresses) > 0 {
+		clause := make([][]byte, len(f.addresses))
+		for i, address := range f.addresses {
+			clause[i] = rawdb.LogIndexAddressTerm(address)
+		}
+		terms = append(terms, clause)
+	}
+	for i, sub := range f.topics {
+		if len(sub) == 0 {
+			continue // empty rule set == wildcard
+		}
+		clause := make([][]byte, len(sub))
+		for j, topic := range sub {
+			clause[j] = rawdb.LogIndexTopicTerm(i, topic)
+		}
+		terms = append(terms, clause)
+	}
+	var matches []uint64
+	for i, clause := range terms {
+		var union []uint64
+		for _, term := range clause {
+			blocks, err := rawdb.ReadLogIndex(f.db, section, head, term)
+			if err != nil {
+				return nil, err
+			}
+			union = mergeBlocks(union, blocks)
+		}
+		if i == 0 {
+			matches = union
+		} else {
+			matches = intersectBlocks(matches, union)
+		}
+		if len(matches) == 0 {
+			break
+		}
+	}
+	return matches, nil
+}
+
+// mergeBlocks returns the union of two ascending block lists.
+func mergeBlocks(a, b []uint64) []uint64 {
+	merged := make([]uint64, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case a[0] > b[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// intersectBlocks returns the intersection of two ascending block lists.
+func intersectBlocks(a, b []uint64) []uint64 {
+	var shared []uint64
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			shared, a, b = append(shared, a[0]), a[1:], b[1:]
+		}
+	}
+	return shared
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -52,6 +52,10 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// SafeBlocksSubscription queries headers of blocks committed to L1
+	SafeBlocksSubscription
+	// FinalizedBlocksSubscription queries headers of blocks finalized on L1
+	FinalizedBlocksSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -66,6 +70,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// rollupEvChanSize is the size of channel listening to SafeHeadEvent and FinalizedHeadEvent.
+	rollupEvChanSize = 10
 )
 
 type subscription struct {
@@ -93,15 +99,19 @@ type EventSystem struct {
 	rmLogsSub      event.Subscription // Subscription for removed log event
 	pendingLogsSub event.Subscription // Subscription for pending log event
 	chainSub       event.Subscription // Subscription for new chain event
+	safeSub        event.Subscription // Subscription for new safe head event
+	finalizedSub   event.Subscription // Subscription for new finalized head event
 
 	// Channels
-	install       chan *subscription         // install filter for event notification
-	uninstall     chan *subscription         // remove filter for event notification
-	txsCh         chan core.NewTxsEvent      // Channel to receive new transactions event
-	logsCh        chan []*types.Log          // Channel to receive new log event
-	pendingLogsCh chan []*types.Log          // Channel to receive new log event
-	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh       chan core.ChainEvent       // Channel to receive new chain event
+	install       chan *subscription           // install filter for event notification
+	uninstall     chan *subscription           // remove filter for event notification
+	txsCh         chan core.NewTxsEvent        // Channel to receive new transactions event
+	logsCh        chan []*types.Log            // Channel to receive new log event
+	pendingLogsCh chan []*types.Log            // Channel to receive new log event
+	rmLogsCh      chan core.RemovedLogsEvent   // Channel to receive removed log event
+	chainCh       chan core.ChainEvent         // Channel to receive new chain event
+	safeCh        chan core.SafeHeadEvent      // Channel to receive new safe head event
+	finalizedCh   chan core.FinalizedHeadEvent // Channel to receive new finalized head event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -121,6 +131,8 @@ func NewEventSystem(backend Backend, lightMode bool) *EventSystem {
 		rmLogsCh:      make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
 		chainCh:       make(chan core.ChainEvent, chainEvChanSize),
+		safeCh:        make(chan core.SafeHeadEvent, rollupEvChanSize),
+		finalizedCh:   make(chan core.FinalizedHeadEvent, rollupEvChanSize),
 	}
 
 	// Subscribe events
@@ -129,9 +141,12 @@ func NewEventSystem(backend Backend, lightMode bool) *EventSystem {
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
+	m.safeSub = m.backend.SubscribeSafeHeadEvent(m.safeCh)
+	m.finalizedSub = m.backend.SubscribeFinalizedHeadEvent(m.finalizedCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil ||
+		m.safeSub == nil || m.finalizedSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -290,6 +305,38 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 	return es.subscribe(sub)
 }
 
+// SubscribeNewSafeHeads creates a subscription that writes the header of a block
+// each time the latest block committed to L1 advances.
+func (es *EventSystem) SubscribeNewSafeHeads(headers chan *types.Header) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       SafeBlocksSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   headers,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeNewFinalizedHeads creates a subscription that writes the header of a
+// block each time the latest block finalized on L1 advances.
+func (es *EventSystem) SubscribeNewFinalizedHeads(headers chan *types.Header) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       FinalizedBlocksSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		headers:   headers,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 // SubscribePendingTxs creates a subscription that writes transaction hashes for
 // transactions that enter the transaction pool.
 func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscription {
@@ -366,6 +413,12 @@ func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent)
 	}
 }
 
+func (es *EventSystem) handleRollupHead(filters filterIndex, typ Type, header *types.Header) {
+	for _, f := range filters[typ] {
+		f.headers <- header
+	}
+}
+
 func (es *EventSystem) lightFilterNewHead(newHeader *types.Header, callBack func(*types.Header, bool)) {
 	oldh := es.lastHead
 	es.lastHead = newHeader
@@ -448,6 +501,8 @@ func (es *EventSystem) eventLoop() {
 		es.rmLogsSub.Unsubscribe()
 		es.pendingLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.safeSub.Unsubscribe()
+		es.finalizedSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -467,6 +522,10 @@ func (es *EventSystem) eventLoop() {
 			es.handlePendingLogs(index, ev)
 		case ev := <-es.chainCh:
 			es.handleChainEvent(index, ev)
+		case ev := <-es.safeCh:
+			es.handleRollupHead(index, SafeBlocksSubscription, ev.Block.Header())
+		case ev := <-es.finalizedCh:
+			es.handleRollupHead(index, FinalizedBlocksSubscription, ev.Block.Header())
 
 		case f := <-es.install:
 			if f.typ == MinedAndPendingLogsSubscription {
@@ -497,6 +556,10 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.safeSub.Err():
+			return
+		case <-es.finalizedSub.Err():
+			return
 		}
 	}
 }
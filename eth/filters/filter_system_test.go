@@ -47,11 +47,15 @@ type testBackend struct {
 	mux             *event.TypeMux
 	db              ethdb.Database
 	sections        uint64
+	logSize         uint64
+	logSections     uint64
 	txFeed          event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
 	chainFeed       event.Feed
+	safeFeed        event.Feed
+	finalizedFeed   event.Feed
 }
 
 func (b *testBackend) ChainDb() ethdb.Database {
@@ -126,10 +130,22 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeSafeHeadEvent(ch chan<- core.SafeHeadEvent) event.Subscription {
+	return b.safeFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeFinalizedHeadEvent(ch chan<- core.FinalizedHeadEvent) event.Subscription {
+	return b.finalizedFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
 
+func (b *testBackend) LogIndexStatus() (uint64, uint64) {
+	return b.logSize, b.logSections
+}
+
 func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	requests := make(chan chan *bloombits.Retrieval)
 
@@ -213,6 +229,57 @@ func TestBlockSubscription(t *testing.T) {
 	<-sub1.Err()
 }
 
+// TestRollupHeadSubscription tests if safe and finalized head subscriptions
+// only receive the headers of their own rollup marker.
+func TestRollupHeadSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		backend  = &testBackend{db: db}
+		api      = NewPublicFilterAPI(backend, false, deadline)
+		genesis  = (&core.Genesis{BaseFee: big.NewInt(params.InitialBaseFee)}).MustCommit(db)
+		chain, _ = core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 4, func(i int, gen *core.BlockGen) {})
+	)
+
+	safeCh := make(chan *types.Header)
+	safeSub := api.events.SubscribeNewSafeHeads(safeCh)
+	finalizedCh := make(chan *types.Header)
+	finalizedSub := api.events.SubscribeNewFinalizedHeads(finalizedCh)
+
+	done := make(chan struct{})
+	go func() { // simulate client
+		defer close(done)
+		var safe, finalized []*types.Header
+		for len(safe) < 2 || len(finalized) < 1 {
+			select {
+			case header := <-safeCh:
+				safe = append(safe, header)
+			case header := <-finalizedCh:
+				finalized = append(finalized, header)
+			}
+		}
+		for i, want := range []*types.Block{chain[1], chain[3]} {
+			if safe[i].Hash() != want.Hash() {
+				t.Errorf("safe subscription received invalid hash on index %d, want %x, got %x", i, want.Hash(), safe[i].Hash())
+			}
+		}
+		if finalized[0].Hash() != chain[1].Hash() {
+			t.Errorf("finalized subscription received invalid hash, want %x, got %x", chain[1].Hash(), finalized[0].Hash())
+		}
+		safeSub.Unsubscribe()
+		finalizedSub.Unsubscribe()
+	}()
+
+	backend.safeFeed.Send(core.SafeHeadEvent{Block: chain[1]})
+	backend.safeFeed.Send(core.SafeHeadEvent{Block: chain[3]})
+	backend.finalizedFeed.Send(core.FinalizedHeadEvent{Block: chain[1]})
+
+	<-done
+	<-safeSub.Err()
+	<-finalizedSub.Err()
+}
+
 // TestPendingTxFilter tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilter(t *testing.T) {
 	t.Parallel()
@@ -337,6 +404,34 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 	}
 }
 
+// TestRestrictedFilterAPI tests that the restricted API refuses to reveal pending
+// transactions and logs.
+func TestRestrictedFilterAPI(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		api     = NewRestrictedFilterAPI(NewPublicFilterAPI(backend, false, deadline))
+	)
+	if _, err := api.NewPendingTransactionFilter(); err != errPendingPrivate {
+		t.Errorf("pending transaction filter: expected %v, got %v", errPendingPrivate, err)
+	}
+	if _, err := api.NewPendingTransactions(context.Background()); err != errPendingPrivate {
+		t.Errorf("pending transaction subscription: expected %v, got %v", errPendingPrivate, err)
+	}
+	pending := FilterCriteria{FromBlock: big.NewInt(rpc.LatestBlockNumber.Int64()), ToBlock: big.NewInt(rpc.PendingBlockNumber.Int64())}
+	if _, err := api.NewFilter(pending); err != errPendingPrivate {
+		t.Errorf("pending log filter: expected %v, got %v", errPendingPrivate, err)
+	}
+	if _, err := api.Logs(context.Background(), pending); err != errPendingPrivate {
+		t.Errorf("pending log subscription: expected %v, got %v", errPendingPrivate, err)
+	}
+	if _, err := api.NewFilter(FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(2)}); err != nil {
+		t.Errorf("log filter creation failed: %v", err)
+	}
+}
+
 func TestInvalidGetLogsRequest(t *testing.T) {
 	var (
 		db        = rawdb.NewMemoryDatabase()
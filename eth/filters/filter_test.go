@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/scroll-tech/go-ethereum/common"
@@ -227,3 +228,100 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+func TestLogIndexFilters(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db, logSize: 100, logSections: 5}
+		addr1   = common.Address{0x01}
+		addr2   = common.Address{0x02}
+		hash1   = common.BytesToHash([]byte("topic1"))
+		hash2   = common.BytesToHash([]byte("topic2"))
+	)
+	emits := map[int][]*types.Log{
+		10:  {{Address: addr1, Topics: []common.Hash{hash1}}},
+		250: {{Address: addr2, Topics: []common.Hash{hash1, hash2}}},
+		251: {{Address: addr1, Topics: []common.Hash{hash2}}, {Address: addr2, Topics: []common.Hash{hash2}}},
+		650: {{Address: addr1, Topics: []common.Hash{hash1}}},
+	}
+	genesis := core.GenesisBlockForTesting(db, addr1, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 700, func(i int, gen *core.BlockGen) {
+		if logs, ok := emits[i]; ok {
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = logs
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 1, gen.BaseFee(), nil))
+		}
+	})
+	rawdb.WriteCanonicalHash(db, genesis.Hash(), 0)
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	indexer := core.NewLogIndexer(db, params.TestChainConfig, backend.logSize, 0)
+	defer indexer.Close()
+	if err := indexer.ProcessSections(backend.logSections); err != nil {
+		t.Fatalf("failed to build log index: %v", err)
+	}
+	// Blocks are one ahead of the generator indices, as the genesis is block 0
+	tests := []struct {
+		begin, end int64
+		addresses  []common.Address
+		topics     [][]common.Hash
+		blocks     []uint64
+	}{
+		{0, -1, []common.Address{addr1}, nil, []uint64{11, 252, 651}},
+		{0, -1, nil, [][]common.Hash{{hash1}}, []uint64{11, 251, 651}},
+		{0, -1, nil, [][]common.Hash{nil, {hash2}}, []uint64{251}},
+		{0, -1, []common.Address{addr2}, [][]common.Hash{{hash2}}, []uint64{252}},
+		{0, -1, []common.Address{addr1, addr2}, [][]common.Hash{{hash1, hash2}}, []uint64{11, 251, 252, 252, 651}},
+		{12, 251, []common.Address{addr1, addr2}, nil, []uint64{251}},
+		{252, 700, []common.Address{addr1}, nil, []uint64{252, 651}},
+		{0, -1, []common.Address{{0x03}}, nil, nil},
+	}
+	for i, test := range tests {
+		filter := NewRangeFilter(backend, test.begin, test.end, test.addresses, test.topics)
+		logs, err := filter.Logs(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to filter logs: %v", i, err)
+		}
+		var blocks []uint64
+		for _, log := range logs {
+			blocks = append(blocks, log.BlockNumber)
+		}
+		if !reflect.DeepEqual(blocks, test.blocks) {
+			t.Errorf("test %d: log blocks mismatch: have %v, want %v", i, blocks, test.blocks)
+		}
+	}
+	// Reorg the chain from block 200 on with the same logs, without updating the
+	// index: the replaced sections must be looked up in the chain instead
+	fork, forkReceipts := core.GenerateChain(params.TestChainConfig, chain[199], ethash.NewFaker(), db, 500, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{0xff})
+		if logs, ok := emits[i+200]; ok {
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = logs
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 1, gen.BaseFee(), nil))
+		}
+	})
+	for i, block := range fork {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), forkReceipts[i])
+	}
+	filter := NewRangeFilter(backend, 0, -1, []common.Address{addr1}, nil)
+	logs, err := filter.Logs(context.Background())
+	if err != nil {
+		t.Fatalf("failed to filter logs after reorg: %v", err)
+	}
+	var blocks []uint64
+	for _, log := range logs {
+		blocks = append(blocks, log.BlockNumber)
+	}
+	if want := []uint64{11, 252, 651}; !reflect.DeepEqual(blocks, want) {
+		t.Errorf("log blocks mismatch after reorg: have %v, want %v", blocks, want)
+	}
+}
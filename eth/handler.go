@@ -71,6 +71,34 @@ type txPool interface {
 	// SubscribeNewTxsEvent should return an event subscription of
 	// NewTxsEvent and send events to the given channel.
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+
+	// IsLocal should report whether a transaction was submitted locally.
+	IsLocal(tx *types.Transaction) bool
+}
+
+// privateTxPool hides the local transactions of a pool from the network.
+type privateTxPool struct {
+	txPool
+}
+
+// Get retrieves a transaction from the pool, unless it's local.
+func (p *privateTxPool) Get(hash common.Hash) *types.Transaction {
+	if tx := p.txPool.Get(hash); tx != nil && !p.IsLocal(tx) {
+		return tx
+	}
+	return nil
+}
+
+// Pending returns the pending transactions of all non-local accounts.
+func (p *privateTxPool) Pending(enforceTips bool) map[common.Address]types.Transactions {
+	pending := p.txPool.Pending(enforceTips)
+	for addr, txs := range pending {
+		// Locality is tracked per account, checking one transaction is enough
+		if len(txs) > 0 && p.IsLocal(txs[0]) {
+			delete(pending, addr)
+		}
+	}
+	return pending
 }
 
 // handlerConfig is the collection of initialization parameters to create a full
@@ -85,6 +113,8 @@ type handlerConfig struct {
 	EventMux   *event.TypeMux            // Legacy event mux, deprecate for `feed`
 	Checkpoint *params.TrustedCheckpoint // Hard coded checkpoint for sync challenges
 	Whitelist  map[uint64]common.Hash    // Hard coded whitelist for sync challenged
+	PrivateTxs bool                      // Whether to keep local transactions off the network
+	L1Blocks   bool                      // Whether blocks are only imported from L1, never from peers
 }
 
 type handler struct {
@@ -95,6 +125,9 @@ type handler struct {
 	snapSync  uint32 // Flag whether fast sync should operate on top of the snap protocol
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
+	privateTxs bool // Flag whether local transactions are kept off the network
+	l1Blocks   bool // Flag whether blocks are only imported from L1, never from peers
+
 	checkpointNumber uint64      // Block number for the sync progress validator to cross reference
 	checkpointHash   common.Hash // Block hash for the sync progress validator to cross reference
 
@@ -139,8 +172,17 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		chain:      config.Chain,
 		peers:      newPeerSet(),
 		whitelist:  config.Whitelist,
+		privateTxs: config.PrivateTxs,
+		l1Blocks:   config.L1Blocks,
 		quitSync:   make(chan struct{}),
 	}
+	if config.PrivateTxs {
+		h.txpool = &privateTxPool{config.TxPool}
+	}
+	if config.L1Blocks {
+		// Blocks are derived from L1 and there is no network sync to wait for
+		h.acceptTxs = 1
+	}
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the fast
 		// block is ahead, so fast sync was enabled for this node at a certain point.
@@ -192,6 +234,11 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		return h.chain.CurrentBlock().NumberU64()
 	}
 	inserter := func(blocks types.Blocks) (int, error) {
+		// Blocks derived from L1 are the only ones to import, whatever peers claim
+		if h.l1Blocks {
+			log.Debug("Deriving from L1, discarded propagated block", "number", blocks[0].Number(), "hash", blocks[0].Hash())
+			return 0, nil
+		}
 		// If sync hasn't reached the checkpoint yet, deny importing weird blocks.
 		//
 		// Ideally we would also compare the head block's timestamp and similarly reject
@@ -523,7 +570,17 @@ func (h *handler) txBroadcastLoop() {
 	for {
 		select {
 		case event := <-h.txsCh:
-			h.BroadcastTransactions(event.Txs)
+			// Conditional transactions are only valid with their conditions, which
+			// are not propagated, so they are never broadcast
+			txs := make(types.Transactions, 0, len(event.Txs))
+			for _, tx := range event.Txs {
+				if tx.Conditional() == nil && (!h.privateTxs || !h.txpool.IsLocal(tx)) {
+					txs = append(txs, tx)
+				}
+			}
+			if len(txs) > 0 {
+				h.BroadcastTransactions(txs)
+			}
 		case <-h.txsSub.Err():
 			return
 		}
@@ -31,6 +31,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/eth/downloader"
 	"github.com/scroll-tech/go-ethereum/eth/protocols/eth"
 	"github.com/scroll-tech/go-ethereum/event"
@@ -740,3 +741,73 @@ func testBroadcastMalformedBlock(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that a private pool hides local transactions from the network.
+func TestPrivateTxPool(t *testing.T) {
+	t.Parallel()
+
+	pool := newTestTxPool()
+	pool.locals[testAddr] = true
+	remoteKey, _ := crypto.GenerateKey()
+
+	local, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil), types.HomesteadSigner{}, testKey)
+	remote, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil), types.HomesteadSigner{}, remoteKey)
+	pool.AddRemotes([]*types.Transaction{local, remote})
+
+	private := &privateTxPool{pool}
+	if private.Get(local.Hash()) != nil {
+		t.Error("local transaction served to the network")
+	}
+	if private.Get(remote.Hash()) == nil {
+		t.Error("remote transaction not served to the network")
+	}
+	pending := private.Pending(false)
+	if len(pending) != 1 || len(pending[crypto.PubkeyToAddress(remoteKey.PublicKey)]) != 1 {
+		t.Errorf("pending transactions mismatch: have %v, want the remote one", pending)
+	}
+}
+
+// Tests that a node deriving its chain from L1 ignores blocks propagated by
+// its peers, which it couldn't verify the seal of.
+func TestL1BlocksIgnorePropagation(t *testing.T) {
+	t.Parallel()
+
+	for _, l1Blocks := range []bool{false, true} {
+		source := newTestHandler()
+		defer source.close()
+		source.handler.l1Blocks = l1Blocks
+		atomic.StoreUint32(&source.handler.fastSync, 0) // Fast sync would discard the block too
+
+		p2pSrc, p2pSink := p2p.MsgPipe()
+		defer p2pSrc.Close()
+		defer p2pSink.Close()
+
+		src := eth.NewPeer(eth.ETH66, p2p.NewPeerPipe(enode.ID{1}, "", nil, p2pSrc), p2pSrc, source.txpool)
+		sink := eth.NewPeer(eth.ETH66, p2p.NewPeerPipe(enode.ID{2}, "", nil, p2pSink), p2pSink, source.txpool)
+		defer src.Close()
+		defer sink.Close()
+
+		go source.handler.runEthPeer(src, func(peer *eth.Peer) error {
+			return eth.Handle((*ethHandler)(source.handler), peer)
+		})
+		var (
+			genesis = source.chain.Genesis()
+			td      = source.chain.GetTd(genesis.Hash(), genesis.NumberU64())
+		)
+		if err := sink.Handshake(1, td, genesis.Hash(), genesis.Hash(), forkid.NewIDWithChain(source.chain), forkid.NewFilter(source.chain)); err != nil {
+			t.Fatalf("l1Blocks %v: failed to run protocol handshake: %v", l1Blocks, err)
+		}
+		blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), source.db, 1, nil)
+		if err := sink.SendNewBlock(blocks[0], new(big.Int).Add(td, blocks[0].Difficulty())); err != nil {
+			t.Fatalf("l1Blocks %v: failed to broadcast block: %v", l1Blocks, err)
+		}
+		imported := false
+		for i := 0; i < 50 && !imported; i++ {
+			time.Sleep(10 * time.Millisecond)
+			imported = source.chain.CurrentBlock().NumberU64() == 1
+		}
+		if imported == l1Blocks {
+			t.Errorf("l1Blocks %v: block import mismatch: imported %v", l1Blocks, imported)
+		}
+	}
+}
@@ -46,7 +46,8 @@ var (
 // Its goal is to get around setting up a valid statedb for the balance and nonce
 // checks.
 type testTxPool struct {
-	pool map[common.Hash]*types.Transaction // Hash map of collected transactions
+	pool   map[common.Hash]*types.Transaction // Hash map of collected transactions
+	locals map[common.Address]bool            // Senders of local transactions
 
 	txFeed event.Feed   // Notification feed to allow waiting for inclusion
 	lock   sync.RWMutex // Protects the transaction pool
@@ -55,7 +56,8 @@ type testTxPool struct {
 // newTestTxPool creates a mock transaction pool.
 func newTestTxPool() *testTxPool {
 	return &testTxPool{
-		pool: make(map[common.Hash]*types.Transaction),
+		pool:   make(map[common.Hash]*types.Transaction),
+		locals: make(map[common.Address]bool),
 	}
 }
 
@@ -106,6 +108,15 @@ func (p *testTxPool) Pending(enforceTips bool) map[common.Address]types.Transact
 	return batches
 }
 
+// IsLocal reports whether the sender of a transaction is marked local.
+func (p *testTxPool) IsLocal(tx *types.Transaction) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	from, _ := types.Sender(types.HomesteadSigner{}, tx)
+	return p.locals[from]
+}
+
 // SubscribeNewTxsEvent should return an event subscription of NewTxsEvent and
 // send events to the given channel.
 func (p *testTxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
@@ -45,7 +45,11 @@ func (h *handler) syncTransactions(p *eth.Peer) {
 	var txs types.Transactions
 	pending := h.txpool.Pending(false)
 	for _, batch := range pending {
-		txs = append(txs, batch...)
+		for _, tx := range batch {
+			if tx.Conditional() == nil {
+				txs = append(txs, tx)
+			}
+		}
 	}
 	if len(txs) == 0 {
 		return
@@ -145,6 +149,9 @@ func (cs *chainSyncer) nextSyncOp() *chainSyncOp {
 	if cs.doneCh != nil {
 		return nil // Sync already running.
 	}
+	if cs.handler.l1Blocks {
+		return nil // Blocks are derived from L1 only.
+	}
 
 	// Ensure we're at minimum peer count.
 	minPeers := defaultMinSyncPeers
@@ -0,0 +1,60 @@
+package tracers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/rollup/fees"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// EstimateRowConsumption executes the given call on top of the given block and
+// returns the estimated number of rows it occupies in each of the zkEVM
+// sub-circuits.
+func (api *API) EstimateRowConsumption(ctx context.Context, args ethapi.TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash) (*types.RowUsage, error) {
+	var (
+		err   error
+		block *types.Block
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	} else {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.backend.StateAtBlock(ctx, block, defaultTraceReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := args.ToMessage(api.backend.RPCGasCap(), block.BaseFee())
+	if err != nil {
+		return nil, err
+	}
+	var (
+		counter = vm.NewRowCounter()
+		vmctx   = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		vmenv   = vm.NewEVM(vmctx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{Debug: true, Tracer: counter, NoBaseFee: true})
+	)
+	// If gasPrice is 0, make sure that the account has sufficient balance to cover `l1Fee`.
+	if api.backend.ChainConfig().Scroll.FeeVaultEnabled() && msg.GasPrice().Cmp(big.NewInt(0)) == 0 {
+		l1Fee, err := fees.CalculateL1MsgFee(msg, vmenv.StateDB)
+		if err != nil {
+			return nil, err
+		}
+		statedb.AddBalance(msg.From(), l1Fee)
+	}
+	if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+		return nil, fmt.Errorf("execution failed: %w", err)
+	}
+	return counter.RowUsage(), nil
+}
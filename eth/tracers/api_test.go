@@ -43,6 +43,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/internal/ethapi"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/wasmerio/wasmer-go/wasmer"
 )
 
 var (
@@ -59,13 +60,15 @@ type testBackend struct {
 }
 
 func newTestBackend(t *testing.T, n int, gspec *core.Genesis, generator func(i int, b *core.BlockGen)) *testBackend {
+	if gspec.Config == nil {
+		gspec.Config = params.TestChainConfig
+	}
 	backend := &testBackend{
-		chainConfig: params.TestChainConfig,
+		chainConfig: gspec.Config,
 		engine:      ethash.NewFaker(),
 		chaindb:     rawdb.NewMemoryDatabase(),
 	}
 	// Generate blocks for testing
-	gspec.Config = backend.chainConfig
 	var (
 		gendb   = rawdb.NewMemoryDatabase()
 		genesis = gspec.MustCommit(gendb)
@@ -582,3 +585,126 @@ func newStates(keys []common.Hash, vals []common.Hash) *map[common.Hash]common.H
 	}
 	return &m
 }
+
+func TestEstimateRowConsumption(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts, the contract hashes a word and stores the result
+	accounts := newAccounts(2)
+	contract := common.HexToAddress("0xc0ffee")
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+		contract: {
+			Balance: common.Big0,
+			Code: []byte{
+				byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.SHA3),
+				byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+				byte(vm.STOP),
+			},
+		},
+	}}
+	target := common.Hash{}
+	signer := types.HomesteadSigner{}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), contract, big.NewInt(0), 100000, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		target = tx.Hash()
+	}))
+	want := &types.RowUsage{
+		EVMSteps: 6,
+		Keccak:   600,
+		Poseidon: 96,
+		Storage:  3,
+		Memory:   1,
+		Copy:     32,
+	}
+	latest := rpc.LatestBlockNumber
+	usage, err := api.EstimateRowConsumption(context.Background(), ethapi.TransactionArgs{From: &accounts[0].addr, To: &contract}, rpc.BlockNumberOrHash{BlockNumber: &latest})
+	if err != nil {
+		t.Fatalf("failed to estimate row consumption: %v", err)
+	}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("row usage mismatch: have %+v, want %+v", usage, want)
+	}
+	if usage.Max() != 600 {
+		t.Errorf("max row usage mismatch: have %d, want 600", usage.Max())
+	}
+	// Tracing the same call must only report the row usage if requested
+	result, err := api.TraceTransaction(context.Background(), target, nil)
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	if rows := result.(*types.ExecutionResult).RowUsage; rows != nil {
+		t.Errorf("unrequested row usage reported: %+v", rows)
+	}
+	result, err = api.TraceTransaction(context.Background(), target, &TraceConfig{LogConfig: &vm.LogConfig{EnableRowUsage: true}})
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	if rows := result.(*types.ExecutionResult).RowUsage; !reflect.DeepEqual(rows, want) {
+		t.Errorf("traced row usage mismatch: have %+v, want %+v", rows, want)
+	}
+}
+
+const watTraceRowUsage = `(module
+  (import "env" "_evm_sstore" (func $_evm_sstore (param i32 i32)))
+  (import "env" "_evm_keccak256" (func $_evm_keccak256 (param i32 i32 i32)))
+  (import "fluent_v1" "poseidon" (func $poseidon (param i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 31) "\01")
+  (data (i32.const 63) "\02")
+  (func (export "main")
+    (call $_evm_sstore (i32.const 0) (i32.const 32))
+    (call $_evm_keccak256 (i32.const 0) (i32.const 64) (i32.const 64))
+    (call $poseidon (i32.const 0) (i32.const 64) (i32.const 96))))`
+
+func TestTraceTransactionWASMRowUsage(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts, the WASM contract stores a word, then hashes
+	// two words with keccak and poseidon
+	code, err := wasmer.Wat2Wasm(watTraceRowUsage)
+	if err != nil {
+		t.Fatalf("failed to compile contract: %v", err)
+	}
+	config := *params.TestChainConfig
+	config.WebAssemblyBlock = big.NewInt(0)
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0xc0ffee")
+	genesis := &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		contract:         {Balance: common.Big0, Code: code},
+	}}
+	target := common.Hash{}
+	signer := types.HomesteadSigner{}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), contract, big.NewInt(0), 1000000, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		target = tx.Hash()
+	}))
+	result, err := api.TraceTransaction(context.Background(), target, &TraceConfig{LogConfig: &vm.LogConfig{EnableRowUsage: true}})
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	res := result.(*types.ExecutionResult)
+	if res.Failed {
+		t.Fatalf("contract execution failed: %s", res.ReturnValue)
+	}
+	// The instructions are WASM steps, while the host calls are counted like
+	// the EVM opcodes without being steps
+	rows := res.RowUsage
+	if rows == nil || rows.WasmSteps == 0 || rows.EVMSteps != 0 {
+		t.Fatalf("unexpected steps in row usage: %+v", rows)
+	}
+	if rows.Storage < 3 || rows.Keccak < 2*300 {
+		t.Errorf("host calls missing from row usage: %+v", rows)
+	}
+	var ops []string
+	for _, log := range res.StructLogs {
+		ops = append(ops, log.Op)
+	}
+	if !reflect.DeepEqual(ops, []string{"SSTORE", "SHA3"}) {
+		t.Errorf("host calls logged as %v", ops)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth/tracers"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/tests"
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+type diffAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   hexutil.Uint64              `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+type stateDiff struct {
+	Pre  map[common.Address]*diffAccount `json:"pre"`
+	Post map[common.Address]*diffAccount `json:"post"`
+}
+
+// TestStateDiffTracer tests the state diff tracer on the following:
+// Tx sending 1 wei to A, which does not already exist.
+// Expected: the pre-state of the sender, A and the coinbase, and their modified
+// fields including the nonce and fees updated outside of the EVM execution.
+func TestStateDiffTracer(t *testing.T) {
+	var (
+		to       = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		coinbase = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	)
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+		Nonce:    3,
+		GasPrice: big.NewInt(10),
+		Gas:      50000,
+		To:       &to,
+		Value:    big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: tx.GasPrice(),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    coinbase,
+		BlockNumber: new(big.Int).SetUint64(8000000),
+		Time:        new(big.Int).SetUint64(5),
+		Difficulty:  big.NewInt(0x30000),
+		GasLimit:    uint64(6000000),
+	}
+	var alloc = core.GenesisAlloc{
+		origin: core.GenesisAccount{
+			Nonce:   3,
+			Balance: big.NewInt(500000000000000),
+		},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+	// Create the tracer, the EVM environment and run it
+	tracer, err := tracers.New("stateDiffTracer", nil)
+	if err != nil {
+		t.Fatalf("failed to create state diff tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, params.MainnetChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	result, err := st.TransitionDb()
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	have := new(stateDiff)
+	if err := json.Unmarshal(res, have); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if len(have.Pre) != 3 || len(have.Post) != 3 {
+		t.Fatalf("wrong number of accounts: have %d pre and %d post, want 3", len(have.Pre), len(have.Post))
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(result.UsedGas), tx.GasPrice())
+	balances := []struct {
+		addr      common.Address
+		pre, post *big.Int
+	}{
+		{origin, big.NewInt(500000000000000), new(big.Int).Sub(big.NewInt(500000000000000-1), fee)},
+		{to, big.NewInt(0), big.NewInt(1)},
+		{coinbase, big.NewInt(0), fee},
+	}
+	for _, b := range balances {
+		if pre := have.Pre[b.addr]; pre == nil || pre.Balance.ToInt().Cmp(b.pre) != 0 {
+			t.Errorf("%x: wrong pre balance, want %v", b.addr, b.pre)
+		}
+		if post := have.Post[b.addr]; post == nil || post.Balance.ToInt().Cmp(b.post) != 0 {
+			t.Errorf("%x: wrong post balance, want %v", b.addr, b.post)
+		}
+	}
+	// The sender nonce is updated before the EVM execution
+	if have.Pre[origin].Nonce != 3 || have.Post[origin].Nonce != 4 {
+		t.Errorf("wrong sender nonce: have %d -> %d, want 3 -> 4", have.Pre[origin].Nonce, have.Post[origin].Nonce)
+	}
+	if have.Post[to].Nonce != 0 || have.Post[to].Code != nil || have.Post[to].Storage != nil {
+		t.Errorf("wrong recipient post-state: %+v", have.Post[to])
+	}
+}
+
+// watStateDiff stores 2 at slot 1 and "abc" at slot 5 through the host
+// functions, reads slot 9, and sends 3 wei to 0xbeef.
+const watStateDiff = `(module
+  (import "env" "_evm_sload" (func $_evm_sload (param i32 i32)))
+  (import "env" "_evm_sstore" (func $_evm_sstore (param i32 i32)))
+  (import "env" "_evm_call" (func $_evm_call (param i32 i32 i32 i32 i32 i32 i32 i32)))
+  (import "fluent_v1" "storage_write" (func $storage_write (param i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 31) "\01")
+  (data (i32.const 63) "\02")
+  (data (i32.const 95) "\05")
+  (data (i32.const 96) "abc")
+  (data (i32.const 159) "\09")
+  (data (i32.const 178) "\be\ef")
+  (data (i32.const 211) "\03")
+  (func (export "main")
+    (call $_evm_sstore (i32.const 0) (i32.const 32))
+    (call $storage_write (i32.const 64) (i32.const 96) (i32.const 3))
+    (call $_evm_sload (i32.const 128) (i32.const 256))
+    (call $_evm_call (i32.const 0) (i32.const 160) (i32.const 180) (i32.const 0) (i32.const 0) (i32.const 0) (i32.const 0) (i32.const 256))))`
+
+// TestStateDiffTracerWASM tests the state diff tracer on a WASM contract, which
+// modifies the state through host functions instead of opcodes.
+// Tx sending 10 wei to the contract, which stores two slots, reads a third one
+// and sends 3 wei to another account.
+// Expected: the modified slots of the contract and the balances of the
+// contract and the other account, without the slot which was only read.
+func TestStateDiffTracerWASM(t *testing.T) {
+	var (
+		to       = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		other    = common.HexToAddress("0x000000000000000000000000000000000000beef")
+		coinbase = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	)
+	code, err := wasmer.Wat2Wasm(watStateDiff)
+	if err != nil {
+		t.Fatalf("failed to compile contract: %v", err)
+	}
+	config := *params.AllEthashProtocolChanges
+	config.WebAssemblyBlock = big.NewInt(0)
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	signer := types.LatestSigner(&config)
+	tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+		Nonce:    0,
+		GasPrice: big.NewInt(10),
+		Gas:      1000000,
+		To:       &to,
+		Value:    big.NewInt(10),
+	})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: tx.GasPrice(),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    coinbase,
+		BlockNumber: new(big.Int).SetUint64(1),
+		Time:        new(big.Int).SetUint64(5),
+		Difficulty:  big.NewInt(0x30000),
+		GasLimit:    uint64(6000000),
+		BaseFee:     big.NewInt(1),
+	}
+	var alloc = core.GenesisAlloc{
+		origin: core.GenesisAccount{
+			Balance: big.NewInt(500000000000000),
+		},
+		to: core.GenesisAccount{
+			Code:    code,
+			Storage: map[common.Hash]common.Hash{common.HexToHash("0x09"): common.HexToHash("0x01")},
+		},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+	tracer, err := tracers.New("stateDiffTracer", nil)
+	if err != nil {
+		t.Fatalf("failed to create state diff tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, &config, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(signer, context.BaseFee)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	result, err := st.TransitionDb()
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	if result.Err != nil {
+		t.Fatalf("contract execution failed: %v", result.Err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	have := new(stateDiff)
+	if err := json.Unmarshal(res, have); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if len(have.Pre) != 4 || len(have.Post) != 4 {
+		t.Fatalf("wrong number of accounts: have %d pre and %d post, want 4", len(have.Pre), len(have.Post))
+	}
+	balances := []struct {
+		addr      common.Address
+		pre, post *big.Int
+	}{
+		{to, big.NewInt(0), big.NewInt(7)},
+		{other, big.NewInt(0), big.NewInt(3)},
+	}
+	for _, b := range balances {
+		if pre := have.Pre[b.addr]; pre == nil || pre.Balance.ToInt().Cmp(b.pre) != 0 {
+			t.Errorf("%x: wrong pre balance, want %v", b.addr, b.pre)
+		}
+		if post := have.Post[b.addr]; post == nil || post.Balance.ToInt().Cmp(b.post) != 0 {
+			t.Errorf("%x: wrong post balance, want %v", b.addr, b.post)
+		}
+	}
+	var (
+		slot1, slot5 = common.HexToHash("0x01"), common.HexToHash("0x05")
+		abc          = common.BytesToHash(common.RightPadBytes([]byte("abc"), 32))
+	)
+	pre, post := have.Pre[to], have.Post[to]
+	if len(pre.Storage) != 2 || pre.Storage[slot1] != (common.Hash{}) || pre.Storage[slot5] != (common.Hash{}) {
+		t.Errorf("wrong contract pre storage: %v", pre.Storage)
+	}
+	if len(post.Storage) != 2 || post.Storage[slot1] != common.HexToHash("0x02") || post.Storage[slot5] != abc {
+		t.Errorf("wrong contract post storage: %v", post.Storage)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth/tracers"
+)
+
+func init() {
+	register("stateDiffTracer", newStateDiffTracer)
+}
+
+type diffAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   hexutil.Uint64              `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type stateDiff struct {
+	Pre  map[common.Address]*diffAccount `json:"pre"`
+	Post map[common.Address]*diffAccount `json:"post"`
+}
+
+// stateDiffTracer is a native go tracer which reports the accounts and storage
+// slots modified by a transaction, with their values before and after it. The
+// state is collected from the EVM opcodes as well as the host functions of WASM
+// contracts, and includes the nonce update, gas purchase and fee payment when
+// tracing a whole transaction.
+type stateDiffTracer struct {
+	env       *vm.EVM
+	pre       map[common.Address]*diffAccount
+	post      map[common.Address]*diffAccount
+	done      bool
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newStateDiffTracer returns a native go tracer which reports the state
+// modified by a transaction, and implements vm.EVMLogger.
+func newStateDiffTracer() tracers.Tracer {
+	return &stateDiffTracer{
+		pre:  make(map[common.Address]*diffAccount),
+		post: make(map[common.Address]*diffAccount),
+	}
+}
+
+// CaptureTxStart implements the EVMTxLogger interface to record the pre-state
+// of the accounts touched outside of the EVM execution.
+func (t *stateDiffTracer) CaptureTxStart(env *vm.EVM, from common.Address, to *common.Address) {
+	t.env = env
+	t.lookupAccount(from)
+	t.lookupAccount(env.FeeRecipient())
+	if to != nil {
+		t.lookupAccount(*to)
+	} else {
+		t.lookupAccount(crypto.CreateAddress(from, env.StateDB.GetNonce(from)))
+	}
+}
+
+// CaptureTxEnd implements the EVMTxLogger interface to compute the diff once
+// the fees have been paid.
+func (t *stateDiffTracer) CaptureTxEnd(restGas uint64) {
+	t.diff()
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *stateDiffTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	// The nonce and balance of the sender have already been updated if the
+	// transaction start was not captured
+	t.env = env
+	t.lookupAccount(from)
+	t.lookupAccount(to)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *stateDiffTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *stateDiffTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if err != nil {
+		return
+	}
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.env.Cancel()
+		return
+	}
+	t.captureOp(op, scope)
+}
+
+// CaptureHostCall implements the WASMHostLogger interface to trace the EVM
+// operations of WASM contracts.
+func (t *stateDiffTracer) CaptureHostCall(op vm.OpCode, scope *vm.ScopeContext, depth int) {
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.env.Cancel()
+		return
+	}
+	t.captureOp(op, scope)
+}
+
+// CaptureStateAfter for special needs, tracks SSTORE ops and records the storage change.
+func (t *stateDiffTracer) CaptureStateAfter(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *stateDiffTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *stateDiffTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *stateDiffTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+// GetResult returns the pre-state of the modified accounts and their modified
+// fields after the transaction.
+func (t *stateDiffTracer) GetResult() (json.RawMessage, error) {
+	if !t.done {
+		t.diff()
+	}
+	res, err := json.Marshal(&stateDiff{Pre: t.pre, Post: t.post})
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *stateDiffTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// captureOp records the pre-state of the accounts and storage slots the
+// operation is about to touch, with the operands on the stack.
+func (t *stateDiffTracer) captureOp(op vm.OpCode, scope *vm.ScopeContext) {
+	stack := scope.Stack.Data()
+	caller := scope.Contract.Address()
+	switch {
+	case (op == vm.SLOAD || op == vm.SSTORE) && len(stack) >= 1:
+		t.lookupStorage(caller, common.Hash(stack[len(stack)-1].Bytes32()))
+	case (op == vm.EXTCODECOPY || op == vm.EXTCODEHASH || op == vm.EXTCODESIZE || op == vm.BALANCE || op == vm.SELFDESTRUCT) && len(stack) >= 1:
+		t.lookupAccount(common.Address(stack[len(stack)-1].Bytes20()))
+	case (op == vm.DELEGATECALL || op == vm.CALL || op == vm.STATICCALL || op == vm.CALLCODE) && len(stack) >= 5:
+		t.lookupAccount(common.Address(stack[len(stack)-2].Bytes20()))
+	case op == vm.CREATE:
+		t.lookupAccount(crypto.CreateAddress(caller, t.env.StateDB.GetNonce(caller)))
+	case op == vm.CREATE2 && len(stack) >= 4:
+		offset, size := stack[len(stack)-2], stack[len(stack)-3]
+		// the init code may be out of bounds, which fails the creation
+		off, overflow := offset.Uint64WithOverflow()
+		if overflow || !size.IsUint64() || off+size.Uint64() < off || off+size.Uint64() > uint64(scope.Memory.Len()) {
+			return
+		}
+		code := scope.Memory.GetCopy(int64(off), int64(size.Uint64()))
+		t.lookupAccount(crypto.CreateAddress2(caller, stack[len(stack)-4].Bytes32(), crypto.Keccak256(code)))
+	}
+}
+
+// lookupAccount records the state of the account on its first touch.
+func (t *stateDiffTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.pre[addr]; ok {
+		return
+	}
+	t.pre[addr] = &diffAccount{
+		Balance: (*hexutil.Big)(new(big.Int).Set(t.env.StateDB.GetBalance(addr))),
+		Nonce:   hexutil.Uint64(t.env.StateDB.GetNonce(addr)),
+		Code:    common.CopyBytes(t.env.StateDB.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage records the value of the storage slot on its first touch.
+func (t *stateDiffTracer) lookupStorage(addr common.Address, slot common.Hash) {
+	t.lookupAccount(addr)
+	if _, ok := t.pre[addr].Storage[slot]; ok {
+		return
+	}
+	t.pre[addr].Storage[slot] = t.env.StateDB.GetState(addr, slot)
+}
+
+// diff compares the recorded pre-state with the current state. Unmodified
+// accounts and slots are dropped from the pre-state, and self-destructed
+// accounts are left out of the post-state.
+func (t *stateDiffTracer) diff() {
+	t.done = true
+	if t.env == nil {
+		return
+	}
+	state := t.env.StateDB
+	for addr, pre := range t.pre {
+		if state.HasSuicided(addr) {
+			continue
+		}
+		var (
+			post     = &diffAccount{Storage: make(map[common.Hash]common.Hash)}
+			modified bool
+		)
+		if balance := state.GetBalance(addr); balance.Cmp(pre.Balance.ToInt()) != 0 {
+			post.Balance = (*hexutil.Big)(new(big.Int).Set(balance))
+			modified = true
+		}
+		if nonce := state.GetNonce(addr); nonce != uint64(pre.Nonce) {
+			post.Nonce = hexutil.Uint64(nonce)
+			modified = true
+		}
+		if code := state.GetCode(addr); !bytes.Equal(code, pre.Code) {
+			post.Code = common.CopyBytes(code)
+			modified = true
+		}
+		for slot, value := range pre.Storage {
+			if current := state.GetState(addr, slot); current != value {
+				post.Storage[slot] = current
+				modified = true
+			} else {
+				delete(pre.Storage, slot)
+			}
+		}
+		if !modified {
+			delete(t.pre, addr)
+			continue
+		}
+		t.post[addr] = post
+	}
+}
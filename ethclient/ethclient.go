@@ -325,6 +325,18 @@ func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header)
 	return ec.c.EthSubscribe(ctx, ch, "newHeads")
 }
 
+// SubscribeNewSafeHead subscribes to notifications about the latest block
+// committed to L1 on the given channel.
+func (ec *Client) SubscribeNewSafeHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "newSafeHeads")
+}
+
+// SubscribeNewFinalizedHead subscribes to notifications about the latest block
+// finalized on L1 on the given channel.
+func (ec *Client) SubscribeNewFinalizedHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "newFinalizedHeads")
+}
+
 // GetBlockTraceByHash returns the BlockTrace given the block hash.
 func (ec *Client) GetBlockTraceByHash(ctx context.Context, blockHash common.Hash) (*types.BlockTrace, error) {
 	blockTrace := &types.BlockTrace{}
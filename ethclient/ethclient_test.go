@@ -247,6 +247,13 @@ func newTestBackend(t *testing.T) (*node.Node, []*types.Block) {
 	if _, err := ethservice.BlockChain().InsertChain(blocks[1:]); err != nil {
 		t.Fatalf("can't import test blocks: %v", err)
 	}
+	// Mark the imported blocks as committed and finalized on L1.
+	if err := ethservice.BlockChain().SetFinalized(blocks[1]); err != nil {
+		t.Fatalf("can't set finalized block: %v", err)
+	}
+	if err := ethservice.BlockChain().SetSafe(blocks[2]); err != nil {
+		t.Fatalf("can't set safe block: %v", err)
+	}
 	return n, blocks
 }
 
@@ -334,6 +341,14 @@ func testHeader(t *testing.T, chain []*types.Block, client *rpc.Client) {
 			want:    nil,
 			wantErr: ethereum.NotFound,
 		},
+		"safe": {
+			block: big.NewInt(int64(rpc.SafeBlockNumber)),
+			want:  chain[2].Header(),
+		},
+		"finalized": {
+			block: big.NewInt(int64(rpc.FinalizedBlockNumber)),
+			want:  chain[1].Header(),
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -19,6 +19,7 @@ package gethclient
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"runtime"
 	"runtime/debug"
@@ -29,6 +30,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/p2p"
 	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie/zkproof"
 )
 
 // Client is a wrapper around rpc.Client that implements geth-specific functionality.
@@ -124,6 +126,91 @@ func (ec *Client) GetProof(ctx context.Context, account common.Address, keys []s
 	return &result, err
 }
 
+// SMTAccountResult is the result of a GetSMTProof operation.
+type SMTAccountResult struct {
+	Address          common.Address
+	Exists           bool
+	AccountPath      *zkproof.SMTPath
+	Balance          *big.Int
+	Nonce            uint64
+	KeccakCodeHash   common.Hash
+	PoseidonCodeHash common.Hash
+	CodeSize         uint64
+	StorageHash      common.Hash
+	StorageProof     []SMTStorageResult
+}
+
+// SMTStorageResult provides a zktrie path for a storage slot.
+type SMTStorageResult struct {
+	Key    common.Hash      `json:"key"`
+	Value  common.Hash      `json:"value"`
+	Exists bool             `json:"exists"`
+	Path   *zkproof.SMTPath `json:"path"`
+}
+
+// GetSMTProof returns the account and storage values of the specified account
+// including their zktrie paths. The block number can be nil, in which case the
+// value is taken from the latest known block.
+func (ec *Client) GetSMTProof(ctx context.Context, account common.Address, keys []common.Hash, blockNumber *big.Int) (*SMTAccountResult, error) {
+	type accountResult struct {
+		Address          common.Address     `json:"address"`
+		Exists           bool               `json:"exists"`
+		AccountPath      *zkproof.SMTPath   `json:"accountPath"`
+		Balance          *hexutil.Big       `json:"balance"`
+		Nonce            hexutil.Uint64     `json:"nonce"`
+		KeccakCodeHash   common.Hash        `json:"keccakCodeHash"`
+		PoseidonCodeHash common.Hash        `json:"poseidonCodeHash"`
+		CodeSize         hexutil.Uint64     `json:"codeSize"`
+		StorageHash      common.Hash        `json:"storageHash"`
+		StorageProof     []SMTStorageResult `json:"storageProof"`
+	}
+
+	var res accountResult
+	if err := ec.c.CallContext(ctx, &res, "scroll_getProof", account, keys, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return &SMTAccountResult{
+		Address:          res.Address,
+		Exists:           res.Exists,
+		AccountPath:      res.AccountPath,
+		Balance:          res.Balance.ToInt(),
+		Nonce:            uint64(res.Nonce),
+		KeccakCodeHash:   res.KeccakCodeHash,
+		PoseidonCodeHash: res.PoseidonCodeHash,
+		CodeSize:         uint64(res.CodeSize),
+		StorageHash:      res.StorageHash,
+		StorageProof:     res.StorageProof,
+	}, nil
+}
+
+// VerifySMTProof checks the account and storage paths of a GetSMTProof result
+// against the state root of a header, including the existence markers.
+func VerifySMTProof(header *types.Header, result *SMTAccountResult) error {
+	var account *types.StateAccount
+	if result.Exists {
+		account = &types.StateAccount{
+			Nonce:            result.Nonce,
+			Balance:          result.Balance,
+			Root:             result.StorageHash,
+			KeccakCodeHash:   result.KeccakCodeHash.Bytes(),
+			PoseidonCodeHash: result.PoseidonCodeHash.Bytes(),
+			CodeSize:         result.CodeSize,
+		}
+	}
+	if err := zkproof.VerifyAccountPath(header.Root, result.Address, account, result.AccountPath); err != nil {
+		return fmt.Errorf("account %v: %w", result.Address, err)
+	}
+	for _, st := range result.StorageProof {
+		if st.Exists != (st.Value != common.Hash{}) {
+			return fmt.Errorf("storage %v: existence does not match value", st.Key)
+		}
+		if err := zkproof.VerifyStoragePath(result.StorageHash, st.Key, st.Value, st.Path); err != nil {
+			return fmt.Errorf("storage %v: %w", st.Key, err)
+		}
+	}
+	return nil
+}
+
 // OverrideAccount specifies the state of an account to be overridden.
 type OverrideAccount struct {
 	Nonce     uint64                      `json:"nonce"`
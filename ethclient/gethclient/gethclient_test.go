@@ -339,3 +339,95 @@ func testCallContractNoGas(t *testing.T, client *rpc.Client) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestGetSMTProof(t *testing.T) {
+	var (
+		config   = *params.AllEthashProtocolChanges
+		contract = common.HexToAddress("0x1234")
+		slot     = common.HexToHash("0x01")
+		value    = common.HexToHash("0x2a")
+	)
+	config.Scroll.UseZktrie = true
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			testAddr: {Balance: testBalance},
+			contract: {
+				Balance: big.NewInt(1),
+				Code:    []byte{0x60, 0x00},
+				Storage: map[common.Hash]common.Hash{slot: value},
+			},
+		},
+	}
+	n, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("can't create new node: %v", err)
+	}
+	ethConfig := &ethconfig.Config{Genesis: genesis}
+	ethConfig.Ethash.PowMode = ethash.ModeFake
+	if _, err := eth.New(n, ethConfig); err != nil {
+		t.Fatalf("can't create new ethereum service: %v", err)
+	}
+	if err := n.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	defer n.Close()
+	client, err := n.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ec := New(client)
+	header, err := ethclient.NewClient(client).HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := common.HexToHash("0x02")
+	tests := []struct {
+		account common.Address
+		exists  bool
+		keys    []common.Hash
+		slots   []bool
+	}{
+		{testAddr, true, []common.Hash{slot}, []bool{false}},
+		{contract, true, []common.Hash{slot, missing}, []bool{true, false}},
+		{emptyAddr, false, []common.Hash{slot}, []bool{false}},
+	}
+	for i, tt := range tests {
+		result, err := ec.GetSMTProof(context.Background(), tt.account, tt.keys, nil)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if result.Exists != tt.exists {
+			t.Fatalf("test %d: existence mismatch, have %v want %v", i, result.Exists, tt.exists)
+		}
+		for j, exists := range tt.slots {
+			if result.StorageProof[j].Exists != exists {
+				t.Fatalf("test %d: slot %d existence mismatch, have %v want %v", i, j, result.StorageProof[j].Exists, exists)
+			}
+		}
+		if err := VerifySMTProof(header, result); err != nil {
+			t.Fatalf("test %d: failed to verify proof: %v", i, err)
+		}
+
+		// Tampering with the proven values must be detected
+		result.Exists = !result.Exists
+		if err := VerifySMTProof(header, result); err == nil {
+			t.Fatalf("test %d: expected flipped account existence to fail", i)
+		}
+		result.Exists = !result.Exists
+		if tt.exists {
+			result.Balance = new(big.Int).Add(result.Balance, common.Big1)
+			if err := VerifySMTProof(header, result); err == nil {
+				t.Fatalf("test %d: expected modified balance to fail", i)
+			}
+			result.Balance.Sub(result.Balance, common.Big1)
+		}
+		result.StorageProof[0].Value = common.HexToHash("0x2b")
+		result.StorageProof[0].Exists = true
+		if err := VerifySMTProof(header, result); err == nil {
+			t.Fatalf("test %d: expected modified storage value to fail", i)
+		}
+	}
+}
@@ -86,6 +86,10 @@ type AncientReader interface {
 	// Ancients returns the ancient item numbers in the ancient store.
 	Ancients() (uint64, error)
 
+	// Tail returns the number of the first item still held by the ancient store,
+	// the items below it have been deleted.
+	Tail() (uint64, error)
+
 	// AncientSize returns the ancient size of the specified category.
 	AncientSize(kind string) (uint64, error)
 }
@@ -109,6 +113,10 @@ type AncientWriter interface {
 	// TruncateAncients discards all but the first n ancient data from the ancient store.
 	TruncateAncients(n uint64) error
 
+	// TruncateTail discards the ancient data below the nth item, retaining the
+	// recent history.
+	TruncateTail(n uint64) error
+
 	// Sync flushes all in-memory ancient store data to disk.
 	Sync() error
 }
@@ -28,15 +28,22 @@ import (
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/common/math"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/core/vm/gowasm"
 	"github.com/scroll-tech/go-ethereum/eth/filters"
 	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
 var (
 	errBlockInvariant = errors.New("block objects must be instantiated with at least one of num or hash")
+	errPendingPrivate = errors.New("pending transactions are private")
 )
 
 type Long int64
@@ -123,6 +130,22 @@ func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash })
 	return state.GetState(a.address, args.Slot), nil
 }
 
+func (a *Account) PoseidonCodeHash(ctx context.Context) (common.Hash, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetPoseidonCodeHash(a.address), nil
+}
+
+func (a *Account) IsWasm(ctx context.Context) (bool, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return false, err
+	}
+	return gowasm.HasMagic(state.GetCode(a.address)), nil
+}
+
 // Log represents an individual log message. All arguments are mandatory.
 type Log struct {
 	backend     ethapi.Backend
@@ -394,6 +417,14 @@ func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*Long, error) {
 	return &ret, nil
 }
 
+func (t *Transaction) L1Fee(ctx context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.L1Fee == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(receipt.L1Fee), nil
+}
+
 func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
 	receipt, err := t.getReceipt(ctx)
 	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
@@ -735,6 +766,18 @@ func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
 	return hexutil.Big(*td), nil
 }
 
+func (b *Block) WithdrawTrieRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	state, _, err := b.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(header.Hash(), false))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, state), nil
+}
+
 // BlockNumberArgs encapsulates arguments to accessors that specify a block number.
 type BlockNumberArgs struct {
 	// TODO: Ideally we could use input unions to allow the query to specify the
@@ -835,6 +878,165 @@ func (b *Block) OmmerAt(ctx context.Context, args struct{ Index int32 }) (*Block
 	}, nil
 }
 
+// maxTraceTransactions is the number of transactions above which Traces
+// refuses to replay a block.
+var maxTraceTransactions = 1000
+
+// Traces replays the transactions of the block on top of its parent state,
+// returning the circuit rows consumed by each of them. The replay is bounded
+// by the EVM timeout of the RPC calls.
+func (b *Block) Traces(ctx context.Context) (*[]*TransactionTrace, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	if n := len(block.Transactions()); n > maxTraceTransactions {
+		return nil, fmt.Errorf("block has %d transactions, tracing is limited to %d", n, maxTraceTransactions)
+	}
+	statedb, _, err := b.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(block.ParentHash(), false))
+	if err != nil {
+		return nil, err
+	}
+	// The whole replay shares a context, cancelled once it's done or when the
+	// timeout expires.
+	timeout := b.backend.RPCEVMTimeout()
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	var (
+		header = block.Header()
+		config = b.backend.ChainConfig()
+		signer = types.MakeSigner(config, header.Number)
+		gp     = new(core.GasPool).AddGas(header.GasLimit)
+		ret    = make([]*TransactionTrace, 0, len(block.Transactions()))
+	)
+	for i, tx := range block.Transactions() {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		msg, err := tx.AsMessage(signer, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("could not replay tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		statedb.Prepare(tx.Hash(), i)
+
+		counter := vm.NewRowCounter()
+		evm, vmError, err := b.backend.GetEVM(ctx, msg, statedb, header, &vm.Config{Debug: true, Tracer: counter})
+		if err != nil {
+			return nil, err
+		}
+		// Wait for the context to be done and cancel the evm. Even if the
+		// EVM has finished, cancelling may be done (repeatedly)
+		go func() {
+			<-ctx.Done()
+			evm.Cancel()
+		}()
+		var (
+			snap = statedb.Snapshot()
+			gas  = gp.Gas()
+		)
+		result, err := core.ApplyMessage(evm, msg, gp)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		trace := &TransactionTrace{
+			transaction: &Transaction{backend: b.backend, hash: tx.Hash(), tx: tx, block: b, index: uint64(i)},
+			rowUsage:    counter.RowUsage(),
+		}
+		if err != nil {
+			if !msg.IsL1MessageTx() {
+				return nil, fmt.Errorf("could not replay tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			// Skipped L1 messages are included without touching the state
+			statedb.RevertToSnapshot(snap)
+			*gp = core.GasPool(gas)
+			trace.err = err
+		} else {
+			trace.gasUsed, trace.l1Fee, trace.err = result.UsedGas, result.L1Fee, result.Err
+		}
+		statedb.Finalise(true)
+		ret = append(ret, trace)
+	}
+	return &ret, nil
+}
+
+// TransactionTrace is the outcome of replaying a transaction of a block.
+type TransactionTrace struct {
+	transaction *Transaction
+	rowUsage    *types.RowUsage
+	gasUsed     uint64
+	l1Fee       *big.Int
+	err         error
+}
+
+func (t *TransactionTrace) Transaction() *Transaction {
+	return t.transaction
+}
+
+func (t *TransactionTrace) RowUsage() *RowUsage {
+	return &RowUsage{t.rowUsage}
+}
+
+func (t *TransactionTrace) GasUsed() Long {
+	return Long(t.gasUsed)
+}
+
+func (t *TransactionTrace) L1Fee() *hexutil.Big {
+	return (*hexutil.Big)(t.l1Fee)
+}
+
+func (t *TransactionTrace) Error() *string {
+	if t.err == nil {
+		return nil
+	}
+	msg := t.err.Error()
+	return &msg
+}
+
+// RowUsage is the number of rows a transaction consumes in each sub-circuit.
+type RowUsage struct {
+	usage *types.RowUsage
+}
+
+func (r *RowUsage) EvmSteps() Long {
+	return Long(r.usage.EVMSteps)
+}
+
+func (r *RowUsage) Keccak() Long {
+	return Long(r.usage.Keccak)
+}
+
+func (r *RowUsage) Poseidon() Long {
+	return Long(r.usage.Poseidon)
+}
+
+func (r *RowUsage) Storage() Long {
+	return Long(r.usage.Storage)
+}
+
+func (r *RowUsage) Memory() Long {
+	return Long(r.usage.Memory)
+}
+
+func (r *RowUsage) Copy() Long {
+	return Long(r.usage.Copy)
+}
+
+func (r *RowUsage) WasmSteps() Long {
+	return Long(r.usage.WasmSteps)
+}
+
+func (r *RowUsage) Max() Long {
+	return Long(r.usage.Max())
+}
+
 // BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
 // a block.
 type BlockFilterCriteria struct {
@@ -987,11 +1189,17 @@ type Pending struct {
 }
 
 func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	if p.backend.PrivateTxPool() {
+		return 0, errPendingPrivate
+	}
 	txs, err := p.backend.GetPoolTransactions()
 	return int32(len(txs)), err
 }
 
 func (p *Pending) Transactions(ctx context.Context) (*[]*Transaction, error) {
+	if p.backend.PrivateTxPool() {
+		return nil, errPendingPrivate
+	}
 	txs, err := p.backend.GetPoolTransactions()
 	if err != nil {
 		return nil, err
@@ -1118,6 +1326,64 @@ func (r *Resolver) Blocks(ctx context.Context, args struct {
 	return ret, nil
 }
 
+func (r *Resolver) SafeBlock(ctx context.Context) (*Block, error) {
+	return r.rollupBlock(ctx, rpc.SafeBlockNumber)
+}
+
+func (r *Resolver) FinalizedBlock(ctx context.Context) (*Block, error) {
+	return r.rollupBlock(ctx, rpc.FinalizedBlockNumber)
+}
+
+// rollupBlock resolves one of the rollup block tags, returning nil if the
+// marker is not known yet.
+func (r *Resolver) rollupBlock(ctx context.Context, number rpc.BlockNumber) (*Block, error) {
+	header, err := r.backend.HeaderByNumber(ctx, number)
+	if header == nil || err != nil {
+		return nil, nil
+	}
+	numberOrHash := rpc.BlockNumberOrHashWithHash(header.Hash(), true)
+	return &Block{
+		backend:      r.backend,
+		numberOrHash: &numberOrHash,
+		hash:         header.Hash(),
+		header:       header,
+	}, nil
+}
+
+func (r *Resolver) Batch(ctx context.Context) *Batch {
+	return &Batch{r}
+}
+
+// Batch represents the progress of the rollup batches, as seen by this node.
+type Batch struct {
+	r *Resolver
+}
+
+func (b *Batch) LastDerivedIndex(ctx context.Context) *Long {
+	return readLong(rawdb.ReadLastDerivedBatchIndex(b.r.backend.ChainDb()))
+}
+
+func (b *Batch) LastL1MessageQueueIndex(ctx context.Context) *Long {
+	return readLong(rawdb.ReadLastL1MessageQueueIndex(b.r.backend.ChainDb()))
+}
+
+func (b *Batch) CommittedBlock(ctx context.Context) (*Block, error) {
+	return b.r.rollupBlock(ctx, rpc.SafeBlockNumber)
+}
+
+func (b *Batch) FinalizedBlock(ctx context.Context) (*Block, error) {
+	return b.r.rollupBlock(ctx, rpc.FinalizedBlockNumber)
+}
+
+// readLong converts an optional database counter into a GraphQL one.
+func readLong(value *uint64) *Long {
+	if value == nil {
+		return nil
+	}
+	ret := Long(*value)
+	return &ret
+}
+
 func (r *Resolver) Pending(ctx context.Context) *Pending {
 	return &Pending{r.backend}
 }
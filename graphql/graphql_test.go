@@ -17,6 +17,7 @@
 package graphql
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -355,3 +356,130 @@ func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
 		t.Fatalf("could not create graphql service: %v", err)
 	}
 }
+
+func TestGraphQLRollupFields(t *testing.T) {
+	stack := createNode(t, true, true)
+	defer stack.Close()
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	body := `{"query": "{block {withdrawTrieRoot account(address: \"0x0000000000000000000000000000000000000dad\") {isWasm poseidonCodeHash} transactions {gasUsed l1Fee} traces {transaction {hash} gasUsed error rowUsage {evmSteps storage max}}} batch {lastDerivedIndex lastL1MessageQueueIndex committedBlock {number}}}"}`
+	resp, err := http.Post(fmt.Sprintf("%s/graphql", stack.HTTPEndpoint()), "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("could not post: %v", err)
+	}
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("could not read from response body: %v", err)
+	}
+	var result struct {
+		Data struct {
+			Block struct {
+				WithdrawTrieRoot common.Hash
+				Account          struct{ IsWasm bool }
+				Transactions     []struct{ GasUsed int64 }
+				Traces           []struct {
+					Transaction struct{ Hash common.Hash }
+					GasUsed     int64
+					RowUsage    struct{ EvmSteps, Storage, Max int64 }
+				}
+			}
+			Batch struct {
+				LastDerivedIndex        *int64
+				LastL1MessageQueueIndex *int64
+				CommittedBlock          *struct{ Number int64 }
+			}
+		}
+	}
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		t.Fatalf("could not decode response %s: %v", bodyBytes, err)
+	}
+	block := result.Data.Block
+	if block.WithdrawTrieRoot != (common.Hash{}) || block.Account.IsWasm {
+		t.Errorf("rollup account fields mismatch: %s", bodyBytes)
+	}
+	// The traces must agree with the receipts of the block
+	if len(block.Traces) != 2 || len(block.Transactions) != 2 {
+		t.Fatalf("trace count mismatch: %s", bodyBytes)
+	}
+	hashes := []string{"0xd864c9d7d37fade6b70164740540c06dd58bb9c3f6b46101908d6339db6a6a7b", "0x19b35f8187b4e15fb59a9af469dca5dfa3cd363c11d372058c12f6482477b474"}
+	for i, trace := range block.Traces {
+		if trace.Transaction.Hash != common.HexToHash(hashes[i]) {
+			t.Errorf("trace %d: hash mismatch: have %x, want %s", i, trace.Transaction.Hash, hashes[i])
+		}
+		if trace.GasUsed != block.Transactions[i].GasUsed {
+			t.Errorf("trace %d: gas used mismatch: have %d, want %d", i, trace.GasUsed, block.Transactions[i].GasUsed)
+		}
+		// Both transactions call 0xdad, which executes 4 opcodes and loads 2 slots
+		if usage := trace.RowUsage; usage.EvmSteps == 0 || usage.Storage == 0 || usage.Max < usage.EvmSteps {
+			t.Errorf("trace %d: row usage mismatch: %+v", i, usage)
+		}
+	}
+	// The test node neither syncs L1 messages nor derives batches
+	if batch := result.Data.Batch; batch.LastDerivedIndex != nil || batch.LastL1MessageQueueIndex != nil || batch.CommittedBlock != nil {
+		t.Errorf("batch mismatch: %s", bodyBytes)
+	}
+}
+
+func TestGraphQLTracesLimit(t *testing.T) {
+	stack := createNode(t, true, true)
+	defer stack.Close()
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	defer func(limit int) { maxTraceTransactions = limit }(maxTraceTransactions)
+	maxTraceTransactions = 1
+
+	// The block has 2 transactions, which are not replayed
+	body := `{"query": "{block {traces {gasUsed}}}"}`
+	resp, err := http.Post(fmt.Sprintf("%s/graphql", stack.HTTPEndpoint()), "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("could not post: %v", err)
+	}
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("could not read from response body: %v", err)
+	}
+	if !strings.Contains(string(bodyBytes), "block has 2 transactions, tracing is limited to 1") {
+		t.Errorf("expected trace limit error, got %s", bodyBytes)
+	}
+}
+
+func TestGraphQLPendingPrivatePool(t *testing.T) {
+	stack := createNode(t, false, false)
+	defer stack.Close()
+
+	ethConf := ethconfig.Defaults
+	ethConf.Genesis = &core.Genesis{
+		Config:   params.AllEthashProtocolChanges,
+		GasLimit: 11500000,
+	}
+	ethConf.Ethash = ethash.Config{PowMode: ethash.ModeFake}
+	ethConf.TxPool.Private = true
+	ethBackend, err := eth.New(stack, &ethConf)
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	if err := New(stack, ethBackend.APIBackend, []string{}, []string{}); err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	for i, body := range []string{
+		`{"query": "{pending {transactionCount}}"}`,
+		`{"query": "{pending {transactions {hash}}}"}`,
+	} {
+		resp, err := http.Post(fmt.Sprintf("%s/graphql", stack.HTTPEndpoint()), "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("could not post: %v", err)
+		}
+		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("could not read from response body: %v", err)
+		}
+		if !strings.Contains(string(bodyBytes), errPendingPrivate.Error()) {
+			t.Errorf("testcase %d: pending transactions not hidden: %s", i, bodyBytes)
+		}
+	}
+}
@@ -52,6 +52,11 @@ const schema string = `
         # Storage provides access to the storage of a contract account, indexed
         # by its 32 byte slot identifier.
         storage(slot: Bytes32!): Bytes32!
+        # PoseidonCodeHash is the poseidon hash of the account's code, as used
+        # by the zkTrie.
+        poseidonCodeHash: Bytes32!
+        # IsWasm is true if the account's code is a WASM module.
+        isWasm: Boolean!
     }
 
     # Log is an Ethereum event log.
@@ -125,6 +130,10 @@ const schema string = `
         # coerced into the EIP-1559 format by setting both maxFeePerGas and
         # maxPriorityFeePerGas as the transaction's gas price.
         effectiveGasPrice: BigInt
+        # L1Fee is the fee charged for publishing this transaction's data on L1,
+        # in wei. If the transaction has not yet been mined, this field will be
+        # null.
+        l1Fee: BigInt
         # CreatedContract is the account that was created by a contract creation
         # transaction. If the transaction was not a contract creation transaction,
         # or it has not yet been mined, this field will be null.
@@ -231,6 +240,43 @@ const schema string = `
         # EstimateGas estimates the amount of gas that will be required for
         # successful execution of a transaction at the current block's state.
         estimateGas(data: CallData!): Long!
+        # WithdrawTrieRoot is the root of the withdraw trie of the L2 message
+        # queue at the current block's state.
+        withdrawTrieRoot: Bytes32!
+        # Traces replays the transactions of this block, returning the circuit
+        # rows consumed by each of them. This requires the state of the parent
+        # block. If transactions are unavailable for this block, this field will
+        # be null.
+        traces: [TransactionTrace!]
+    }
+
+    # TransactionTrace is the outcome of replaying a transaction of a block.
+    type TransactionTrace {
+        # Transaction is the replayed transaction.
+        transaction: Transaction!
+        # RowUsage is the number of circuit rows consumed by the transaction.
+        rowUsage: RowUsage!
+        # GasUsed is the amount of gas used by the transaction.
+        gasUsed: Long!
+        # L1Fee is the fee charged for publishing the transaction's data on L1,
+        # in wei. This is null for skipped L1 messages.
+        l1Fee: BigInt
+        # Error is the reason the transaction failed, or null if it succeeded.
+        # Skipped L1 messages report the reason they were skipped.
+        error: String
+    }
+
+    # RowUsage is the number of rows consumed in each of the sub-circuits.
+    type RowUsage {
+        evmSteps: Long!
+        keccak: Long!
+        poseidon: Long!
+        storage: Long!
+        memory: Long!
+        copy: Long!
+        wasmSteps: Long!
+        # Max is the row count of the most used sub-circuit.
+        max: Long!
     }
 
     # CallData represents the data associated with a local contract call.
@@ -320,6 +366,22 @@ const schema string = `
       estimateGas(data: CallData!): Long!
     }
 
+    # Batch is the progress of the rollup batches, as seen by this node.
+    type Batch {
+        # LastDerivedIndex is the index of the last batch whose blocks have
+        # been derived from L1, or null if the node doesn't derive the chain.
+        lastDerivedIndex: Long
+        # LastL1MessageQueueIndex is the queue index of the last synced L1
+        # message, or null if none have been synced.
+        lastL1MessageQueueIndex: Long
+        # CommittedBlock is the latest block whose batch has been committed to
+        # L1, or null if it is not known yet.
+        committedBlock: Block
+        # FinalizedBlock is the latest block whose batch has been finalized on
+        # L1, or null if it is not known yet.
+        finalizedBlock: Block
+    }
+
     type Query {
         # Block fetches an Ethereum block by number or by hash. If neither is
         # supplied, the most recent known block is returned.
@@ -327,6 +389,14 @@ const schema string = `
         # Blocks returns all the blocks between two numbers, inclusive. If
         # to is not supplied, it defaults to the most recent known block.
         blocks(from: Long, to: Long): [Block!]!
+        # SafeBlock returns the latest block whose batch has been committed to
+        # L1, or null if it is not known yet.
+        safeBlock: Block
+        # FinalizedBlock returns the latest block whose batch has been finalized
+        # on L1, or null if it is not known yet.
+        finalizedBlock: Block
+        # Batch returns the progress of the rollup batches.
+        batch: Batch!
         # Pending returns the current pending state.
         pending: Pending!
         # Transaction returns a transaction specified by its hash.
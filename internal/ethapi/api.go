@@ -38,6 +38,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/consensus/misc"
 	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
@@ -49,6 +50,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/rlp"
 	"github.com/scroll-tech/go-ethereum/rollup/fees"
 	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie/zkproof"
 )
 
 // PublicEthereumAPI provides an API to access Ethereum related information.
@@ -713,6 +715,129 @@ func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Addre
 	}, state.Error()
 }
 
+// PublicScrollAPI provides zktrie specific APIs for the state of the L2 chain.
+type PublicScrollAPI struct {
+	b Backend
+}
+
+// NewPublicScrollAPI creates a new zktrie specific API.
+func NewPublicScrollAPI(b Backend) *PublicScrollAPI {
+	return &PublicScrollAPI{b}
+}
+
+// SMTAccountResult is the result of scroll_getProof, with the account and its
+// storage slots proven by structured zktrie paths.
+type SMTAccountResult struct {
+	Address          common.Address     `json:"address"`
+	Exists           bool               `json:"exists"`
+	AccountPath      *zkproof.SMTPath   `json:"accountPath"`
+	Balance          *hexutil.Big       `json:"balance"`
+	Nonce            hexutil.Uint64     `json:"nonce"`
+	KeccakCodeHash   common.Hash        `json:"keccakCodeHash"`
+	PoseidonCodeHash common.Hash        `json:"poseidonCodeHash"`
+	CodeSize         hexutil.Uint64     `json:"codeSize"`
+	StorageHash      common.Hash        `json:"storageHash"`
+	StorageProof     []SMTStorageResult `json:"storageProof"`
+}
+
+// SMTStorageResult proves the value of a storage slot, where a slot that does
+// not exist has a zero value.
+type SMTStorageResult struct {
+	Key    common.Hash      `json:"key"`
+	Value  common.Hash      `json:"value"`
+	Exists bool             `json:"exists"`
+	Path   *zkproof.SMTPath `json:"path"`
+}
+
+// GetProof returns the zktrie paths of a given account and optionally some of
+// its storage keys, explicitly marking whether each of them exists.
+func (s *PublicScrollAPI) GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNrOrHash rpc.BlockNumberOrHash) (*SMTAccountResult, error) {
+	if !s.b.ChainConfig().Scroll.ZktrieEnabled() {
+		return nil, errors.New("zktrie is not enabled")
+	}
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	accountProof, err := state.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	accountPath, err := zkproof.DecodeSMTPath(accountProof)
+	if err != nil {
+		return nil, err
+	}
+	result := &SMTAccountResult{
+		Address:          address,
+		Exists:           zkproof.PathExists(accountPath, zkproof.AccountKey(address)),
+		AccountPath:      accountPath,
+		Balance:          (*hexutil.Big)(state.GetBalance(address)),
+		Nonce:            hexutil.Uint64(state.GetNonce(address)),
+		KeccakCodeHash:   codehash.EmptyKeccakCodeHash,
+		PoseidonCodeHash: codehash.EmptyPoseidonCodeHash,
+		StorageProof:     make([]SMTStorageResult, len(storageKeys)),
+	}
+	storageTrie := state.StorageTrie(address)
+	if storageTrie != nil {
+		result.KeccakCodeHash = state.GetKeccakCodeHash(address)
+		result.PoseidonCodeHash = state.GetPoseidonCodeHash(address)
+		result.CodeSize = hexutil.Uint64(state.GetCodeSize(address))
+		result.StorageHash = storageTrie.Hash()
+	}
+
+	for i, key := range storageKeys {
+		if storageTrie == nil {
+			result.StorageProof[i] = SMTStorageResult{Key: key, Path: zkproof.EmptySMTPath()}
+			continue
+		}
+		proof, err := state.GetStorageProof(address, key)
+		if err != nil {
+			return nil, err
+		}
+		path, err := zkproof.DecodeSMTPath(proof)
+		if err != nil {
+			return nil, err
+		}
+		storageKey, err := zkproof.StorageKey(key)
+		if err != nil {
+			return nil, err
+		}
+		result.StorageProof[i] = SMTStorageResult{
+			Key:    key,
+			Value:  state.GetState(address, key),
+			Exists: zkproof.PathExists(path, storageKey),
+			Path:   path,
+		}
+	}
+	return result, state.Error()
+}
+
+// GetCodeByHash returns the code with the given hash, of the given kind:
+// "keccak", the default, or "poseidon". Null is returned if the code is
+// unknown.
+func (s *PublicScrollAPI) GetCodeByHash(ctx context.Context, hash common.Hash, kind *string) (hexutil.Bytes, error) {
+	var code []byte
+	switch {
+	case kind == nil || *kind == "keccak":
+		if hash == codehash.EmptyKeccakCodeHash {
+			return hexutil.Bytes{}, nil
+		}
+		code = rawdb.ReadCode(s.b.ChainDb(), hash)
+	case *kind == "poseidon":
+		if hash == codehash.EmptyPoseidonCodeHash {
+			return hexutil.Bytes{}, nil
+		}
+		code = rawdb.ReadCodeByPoseidonHash(s.b.ChainDb(), hash)
+	default:
+		return nil, fmt.Errorf("unknown code hash kind %q, expected keccak or poseidon", *kind)
+	}
+	if len(code) == 0 {
+		return nil, nil
+	}
+	return code, nil
+}
+
 // GetHeaderByNumber returns the requested canonical block header.
 // * When blockNr is -1 the chain head is returned.
 // * When blockNr is -2 the pending chain head is returned.
@@ -1241,6 +1366,9 @@ func RPCMarshalHeader(head *types.Header, enableBaseFee bool) map[string]interfa
 	if enableBaseFee && head.BaseFee != nil {
 		result["baseFeePerGas"] = (*hexutil.Big)(head.BaseFee)
 	}
+	if head.L1BlockNumber != nil {
+		result["l1BlockNumber"] = hexutil.Uint64(*head.L1BlockNumber)
+	}
 
 	return result
 }
@@ -1320,6 +1448,7 @@ type RPCTransaction struct {
 	Type             hexutil.Uint64    `json:"type"`
 	Accesses         *types.AccessList `json:"accessList,omitempty"`
 	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	QueueIndex       *hexutil.Uint64   `json:"queueIndex,omitempty"`
 	V                *hexutil.Big      `json:"v"`
 	R                *hexutil.Big      `json:"r"`
 	S                *hexutil.Big      `json:"s"`
@@ -1369,6 +1498,9 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		} else {
 			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
 		}
+	case types.L1MessageTxType:
+		queueIndex := tx.Nonce()
+		result.QueueIndex = (*hexutil.Uint64)(&queueIndex)
 	}
 	return result
 }
@@ -1806,6 +1938,18 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, input
 	return SubmitTransaction(ctx, s.b, tx)
 }
 
+// SendRawTransactionConditional will add the signed transaction to the transaction
+// pool, to be included only in a block meeting the given preconditions. Conditional
+// transactions are not propagated to the network.
+func (s *PublicTransactionPoolAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	tx.SetConditional(&cond)
+	return SubmitTransaction(ctx, s.b, tx)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -2067,6 +2211,17 @@ func (api *PrivateDebugAPI) ChaindbCompact() error {
 	return nil
 }
 
+// ChaindbTruncateTail deletes the ancient chain data below the given block to
+// expire old history, returning the first block still held by the freezer.
+func (api *PrivateDebugAPI) ChaindbTruncateTail(tail hexutil.Uint64) (hexutil.Uint64, error) {
+	db := api.b.ChainDb()
+	if err := db.TruncateTail(uint64(tail)); err != nil {
+		return 0, err
+	}
+	first, err := db.Tail()
+	return hexutil.Uint64(first), err
+}
+
 // SetHead rewinds the head of the blockchain to a previous block.
 func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) {
 	api.b.SetHead(uint64(number))
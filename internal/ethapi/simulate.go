@@ -0,0 +1,511 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/common/math"
+	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rollup/fees"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks of a single simulation.
+	maxSimulateBlocks = 256
+
+	// simulateBlockTime is the timestamp increment of blocks without a time override.
+	simulateBlockTime = 3
+)
+
+var (
+	// transferAddress is the address emitting the logs of simulated ether transfers.
+	transferAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+	// transferTopic is the ERC-20 Transfer(address,address,uint256) event signature.
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// BlockOverrides is a set of header fields to override for a simulated block.
+// The L1 fee parameters are written into the L1 gas price oracle.
+type BlockOverrides struct {
+	Number        *hexutil.Big    `json:"number"`
+	Time          *hexutil.Uint64 `json:"time"`
+	GasLimit      *hexutil.Uint64 `json:"gasLimit"`
+	FeeRecipient  *common.Address `json:"feeRecipient"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas"`
+	L1BaseFee     *hexutil.Big    `json:"l1BaseFee"`
+	L1Overhead    *hexutil.Big    `json:"l1Overhead"`
+	L1Scalar      *hexutil.Big    `json:"l1Scalar"`
+}
+
+// apply overrides the fields of the given header.
+func (o *BlockOverrides) apply(header *types.Header) {
+	if o == nil {
+		return
+	}
+	if o.Number != nil {
+		header.Number = o.Number.ToInt()
+	}
+	if o.Time != nil {
+		header.Time = uint64(*o.Time)
+	}
+	if o.GasLimit != nil {
+		header.GasLimit = uint64(*o.GasLimit)
+	}
+	if o.FeeRecipient != nil {
+		header.Coinbase = *o.FeeRecipient
+	}
+	if o.BaseFeePerGas != nil {
+		header.BaseFee = o.BaseFeePerGas.ToInt()
+	}
+}
+
+// applyL1Fee writes the overridden L1 fee parameters into the oracle storage.
+func (o *BlockOverrides) applyL1Fee(state *state.StateDB) {
+	if o == nil {
+		return
+	}
+	if o.L1BaseFee != nil {
+		state.SetState(rcfg.L1GasPriceOracleAddress, rcfg.L1BaseFeeSlot, common.BigToHash(o.L1BaseFee.ToInt()))
+	}
+	if o.L1Overhead != nil {
+		state.SetState(rcfg.L1GasPriceOracleAddress, rcfg.OverheadSlot, common.BigToHash(o.L1Overhead.ToInt()))
+	}
+	if o.L1Scalar != nil {
+		state.SetState(rcfg.L1GasPriceOracleAddress, rcfg.ScalarSlot, common.BigToHash(o.L1Scalar.ToInt()))
+	}
+}
+
+// SimBlock is a block of calls to simulate, with the overrides applied before
+// executing them.
+type SimBlock struct {
+	BlockOverrides *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides *StateOverride    `json:"stateOverrides"`
+	Calls          []TransactionArgs `json:"calls"`
+}
+
+// SimOpts are the inputs of eth_simulateV1.
+type SimOpts struct {
+	BlockStateCalls []SimBlock `json:"blockStateCalls"`
+	TraceTransfers  bool       `json:"traceTransfers"`
+	Validation      bool       `json:"validation"`
+}
+
+// simCallResult is the outcome of a single simulated call.
+type simCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	L1Fee       *hexutil.Big   `json:"l1Fee"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *simCallError  `json:"error,omitempty"`
+}
+
+// simCallError is the error of a simulated call which failed in the EVM.
+type simCallError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    string `json:"data,omitempty"`
+}
+
+// SimulateV1 executes a series of calls in a sequence of blocks built on top of
+// the given one. Every block can override header fields and state, and the state
+// changes of every call are visible to the ones after it.
+//
+// Calls failing in the EVM are reported in the results. Calls which can't be
+// included at all, e.g. because of an invalid nonce in validation mode, fail
+// the whole simulation. The RPC gas cap and EVM timeout apply to the simulation
+// as a whole, not to every call separately.
+func (s *PublicBlockChainAPI) SimulateV1(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errors.New("empty input")
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, fmt.Errorf("too many blocks: %d > %d", len(opts.BlockStateCalls), maxSimulateBlocks)
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, base, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Setup context so it may be cancelled the simulation has completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	if timeout := s.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	gasCap := s.b.RPCGasCap()
+	if gasCap == 0 {
+		gasCap = math.MaxUint64
+	}
+	sim := &simulator{
+		b:            s.b,
+		state:        state,
+		base:         base,
+		opts:         &opts,
+		gasRemaining: gasCap,
+		hashes:       make(map[uint64]common.Hash),
+		results:      make([]map[string]interface{}, 0, len(opts.BlockStateCalls)),
+	}
+	parent := base
+	for i := range opts.BlockStateCalls {
+		if ctx.Err() != nil {
+			return nil, sim.timeoutError()
+		}
+		header, err := sim.makeHeader(&opts.BlockStateCalls[i], parent)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if err := sim.processBlock(ctx, &opts.BlockStateCalls[i], header); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		parent = header
+	}
+	return sim.results, nil
+}
+
+// simulator holds the state of an eth_simulateV1 run.
+type simulator struct {
+	b            Backend
+	state        *state.StateDB
+	base         *types.Header
+	opts         *SimOpts
+	gasRemaining uint64                 // gas left of the RPC gas cap for all calls
+	hashes       map[uint64]common.Hash // hashes of the simulated blocks
+	results      []map[string]interface{}
+}
+
+// timeoutError returns the error of a simulation aborted by the EVM timeout.
+func (sim *simulator) timeoutError() error {
+	return fmt.Errorf("execution aborted (timeout = %v)", sim.b.RPCEVMTimeout())
+}
+
+// makeHeader creates the header of a simulated block on top of the given parent.
+func (sim *simulator) makeHeader(block *SimBlock, parent *types.Header) (*types.Header, error) {
+	config := sim.b.ChainConfig()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   parent.Coinbase,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + simulateBlockTime,
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(config, parent)
+	}
+	block.BlockOverrides.apply(header)
+
+	if header.Number.Cmp(parent.Number) <= 0 {
+		return nil, fmt.Errorf("block number %d not above parent %d", header.Number, parent.Number)
+	}
+	if header.Time <= parent.Time {
+		return nil, fmt.Errorf("block timestamp %d not above parent %d", header.Time, parent.Time)
+	}
+	return header, nil
+}
+
+// processBlock executes the calls of a simulated block and records its result.
+func (sim *simulator) processBlock(ctx context.Context, block *SimBlock, header *types.Header) error {
+	if err := block.StateOverrides.Apply(sim.state); err != nil {
+		return err
+	}
+	block.BlockOverrides.applyL1Fee(sim.state)
+
+	var (
+		gp       = new(core.GasPool).AddGas(header.GasLimit)
+		calls    = make([]*simCallResult, 0, len(block.Calls))
+		receipts = make(types.Receipts, 0, len(block.Calls))
+		logs     []*types.Log
+	)
+	for i := range block.Calls {
+		result, txHash, err := sim.processCall(ctx, &block.Calls[i], header, gp, len(calls))
+		if err != nil {
+			return fmt.Errorf("call %d: %w", i, err)
+		}
+		header.GasUsed += uint64(result.GasUsed)
+		calls = append(calls, result)
+		receipts = append(receipts, &types.Receipt{TxHash: txHash, Logs: result.Logs})
+		logs = append(logs, result.Logs...)
+	}
+	header.Root = sim.state.IntermediateRoot(sim.b.ChainConfig().IsEIP158(header.Number))
+	header.Bloom = types.CreateBloom(receipts)
+
+	// The block hash is only known once all calls are done, fill it in.
+	hash := header.Hash()
+	for i, log := range logs {
+		log.BlockHash = hash
+		log.Index = uint(i)
+	}
+	sim.hashes[header.Number.Uint64()] = hash
+
+	fields := RPCMarshalHeader(header, sim.b.ChainConfig().Scroll.BaseFeeEnabled())
+	fields["calls"] = calls
+	sim.results = append(sim.results, fields)
+	return nil
+}
+
+// processCall executes a single call of a simulated block.
+func (sim *simulator) processCall(ctx context.Context, args *TransactionArgs, header *types.Header, gp *core.GasPool, index int) (*simCallResult, common.Hash, error) {
+	config := sim.b.ChainConfig()
+
+	if ctx.Err() != nil {
+		return nil, common.Hash{}, sim.timeoutError()
+	}
+	if sim.gasRemaining == 0 {
+		return nil, common.Hash{}, errors.New("simulation gas cap exhausted")
+	}
+	// Calls without a gas limit get what's left in the block and of the gas cap.
+	if args.Gas == nil {
+		gas := hexutil.Uint64(gp.Gas())
+		if uint64(gas) > sim.gasRemaining {
+			gas = hexutil.Uint64(sim.gasRemaining)
+		}
+		args.Gas = &gas
+	}
+	if args.Nonce == nil {
+		nonce := hexutil.Uint64(sim.state.GetNonce(args.from()))
+		args.Nonce = &nonce
+	}
+	msg, err := args.ToMessage(sim.gasRemaining, header.BaseFee)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	msg = types.NewMessage(msg.From(), msg.To(), uint64(*args.Nonce), msg.Value(), msg.Gas(), msg.GasPrice(), msg.GasFeeCap(), msg.GasTipCap(), msg.Data(), msg.AccessList(), !sim.opts.Validation)
+
+	// Outside of validation mode the L1 fee of gas-free calls is funded, like
+	// it is in eth_call.
+	if !sim.opts.Validation && msg.GasPrice().Sign() == 0 && config.Scroll.FeeVaultEnabled() {
+		l1Fee, err := fees.CalculateL1MsgFee(msg, sim.state)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		sim.state.AddBalance(msg.From(), l1Fee)
+	}
+	txHash := simulatedTxHash(msg)
+	sim.state.Prepare(txHash, index)
+
+	logIndex := len(sim.state.GetLogs(txHash, common.Hash{}))
+	vmConfig := &vm.Config{NoBaseFee: !sim.opts.Validation}
+	var tracer *transferTracer
+	if sim.opts.TraceTransfers {
+		tracer = newTransferTracer(func() int {
+			return len(sim.state.GetLogs(txHash, common.Hash{})) - logIndex
+		})
+		vmConfig.Debug, vmConfig.Tracer = true, tracer
+	}
+	evm, vmError, err := sim.b.GetEVM(ctx, msg, sim.state, header, vmConfig)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	evm.Context.Coinbase = header.Coinbase
+	evm.Context.GetHash = sim.getHash(ctx)
+	// Gas-free calls outside of validation mode don't pay the base fee, which
+	// would otherwise make the tip of the fee recipient negative.
+	if !sim.opts.Validation && msg.GasFeeCap().Sign() == 0 && msg.GasTipCap().Sign() == 0 {
+		evm.Context.BaseFee = new(big.Int)
+	}
+
+	// Wait for the context to be done and cancel the evm. Even if the
+	// EVM has finished, cancelling may be done (repeatedly)
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+
+	result, err := core.ApplyMessage(evm, msg, gp)
+	if err := vmError(); err != nil {
+		return nil, common.Hash{}, err
+	}
+	if evm.Cancelled() {
+		return nil, common.Hash{}, sim.timeoutError()
+	}
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	sim.gasRemaining -= result.UsedGas
+	sim.state.Finalise(config.IsEIP158(header.Number))
+
+	logs := sim.state.GetLogs(txHash, common.Hash{})[logIndex:]
+	if tracer != nil {
+		logs = tracer.withTransfers(logs)
+	}
+	for _, log := range logs {
+		log.BlockNumber = header.Number.Uint64()
+		log.TxHash = txHash
+		log.TxIndex = uint(index)
+	}
+	call := &simCallResult{
+		ReturnValue: result.Return(),
+		Logs:        logs,
+		GasUsed:     hexutil.Uint64(result.UsedGas),
+		L1Fee:       (*hexutil.Big)(result.L1Fee),
+		Status:      hexutil.Uint64(types.ReceiptStatusSuccessful),
+	}
+	if call.Logs == nil {
+		call.Logs = []*types.Log{}
+	}
+	if result.Failed() {
+		call.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+		call.Logs = []*types.Log{}
+		if errors.Is(result.Err, vm.ErrExecutionReverted) {
+			revert := newRevertError(result)
+			call.Error = &simCallError{Message: revert.Error(), Code: revert.ErrorCode(), Data: revert.reason}
+		} else {
+			call.Error = &simCallError{Message: result.Err.Error(), Code: -32015}
+		}
+	}
+	return call, txHash, nil
+}
+
+// getHash returns the block hash lookup of the simulated blocks, which resolves
+// the canonical chain below them.
+func (sim *simulator) getHash(ctx context.Context) vm.GetHashFunc {
+	return func(n uint64) common.Hash {
+		if n > sim.base.Number.Uint64() {
+			return sim.hashes[n]
+		}
+		if n == sim.base.Number.Uint64() {
+			return sim.base.Hash()
+		}
+		header, err := sim.b.HeaderByNumber(ctx, rpc.BlockNumber(n))
+		if header == nil || err != nil {
+			return common.Hash{}
+		}
+		return header.Hash()
+	}
+}
+
+// simulatedTxHash returns the hash identifying a simulated call, which is the hash
+// of the unsigned transaction.
+func simulatedTxHash(msg types.Message) common.Hash {
+	if msg.To() == nil {
+		return types.NewContractCreation(msg.Nonce(), msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data()).Hash()
+	}
+	return types.NewTransaction(msg.Nonce(), *msg.To(), msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data()).Hash()
+}
+
+// transferTracer collects ERC-20 style Transfer logs of every ether transfer
+// of a call, to be inserted among the logs of the call in execution order. The
+// transfers of reverted frames are dropped.
+type transferTracer struct {
+	logCount  func() int // number of logs the call emitted so far
+	transfers []simTransfer
+	frames    []int // number of transfers when each call frame was entered
+}
+
+// simTransfer is the log of an ether transfer, and its position among the logs
+// of the call.
+type simTransfer struct {
+	log   *types.Log
+	index int // number of logs the call emitted before the transfer
+}
+
+func newTransferTracer(logCount func() int) *transferTracer {
+	return &transferTracer{logCount: logCount}
+}
+
+func (t *transferTracer) addTransfer(from, to common.Address, value *big.Int) {
+	if value == nil || value.Sign() == 0 {
+		return
+	}
+	t.transfers = append(t.transfers, simTransfer{
+		log: &types.Log{
+			Address: transferAddress,
+			Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:    common.BigToHash(value).Bytes(),
+		},
+		index: t.logCount(),
+	})
+}
+
+// withTransfers returns the logs of the call with the transfer logs inserted
+// at their position.
+func (t *transferTracer) withTransfers(logs []*types.Log) []*types.Log {
+	merged := make([]*types.Log, 0, len(logs)+len(t.transfers))
+	next := 0
+	for _, transfer := range t.transfers {
+		for ; next < transfer.index && next < len(logs); next++ {
+			merged = append(merged, logs[next])
+		}
+		merged = append(merged, transfer.log)
+	}
+	return append(merged, logs[next:]...)
+}
+
+func (t *transferTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames[:0], 0)
+	t.addTransfer(from, to, value)
+}
+
+// CaptureState doesn't collect the logs, which are taken from the state as
+// WASM contracts emit them through host functions.
+func (t *transferTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *transferTracer) CaptureStateAfter(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *transferTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, len(t.transfers))
+	switch typ {
+	case vm.CALL, vm.CREATE, vm.CREATE2, vm.SELFDESTRUCT:
+		t.addTransfer(from, to, value)
+	}
+}
+
+func (t *transferTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.frames) == 0 {
+		return
+	}
+	start := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if err != nil {
+		t.transfers = t.transfers[:start]
+	}
+}
+
+func (t *transferTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *transferTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	if err != nil {
+		t.transfers = nil
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+var (
+	// simNumberCode returns the block number.
+	simNumberCode = common.FromHex("0x4360005260206000f3")
+	// simStoreCode stores 0x2a in slot 0.
+	simStoreCode = common.FromHex("0x602a60005500")
+	// simLoadCode returns the value of slot 0.
+	simLoadCode = common.FromHex("0x60005460005260206000f3")
+	// simBlockHashCode returns the hash of the block number given as calldata.
+	simBlockHashCode = common.FromHex("0x6000354060005260206000f3")
+	// simLoopCode loops forever.
+	simLoopCode = common.FromHex("0x5b600056")
+)
+
+// watSimLog emits a log with the topic 1 and the data 0x2a.
+const watSimLog = `(module
+  (import "env" "_evm_log1" (func $_evm_log1 (param i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "\2a")
+  (data (i32.const 63) "\01")
+  (func (export "main")
+    (call $_evm_log1 (i32.const 0) (i32.const 1) (i32.const 32))))`
+
+// simTestBackend is a Backend on top of a test chain, implementing only what
+// eth_simulateV1 needs.
+type simTestBackend struct {
+	Backend
+	chain   *core.BlockChain
+	gasCap  uint64
+	timeout time.Duration
+}
+
+func newSimTestBackend(t *testing.T, n int) *simTestBackend {
+	return newSimTestBackendWithConfig(t, n, params.TestChainConfig)
+}
+
+func newSimTestBackendWithConfig(t *testing.T, n int, config *params.ChainConfig) *simTestBackend {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: config}
+		genesis = gspec.MustCommit(db)
+		engine  = ethash.NewFaker()
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, rawdb.NewMemoryDatabase(), n, func(i int, b *core.BlockGen) {})
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	t.Cleanup(chain.Stop)
+	return &simTestBackend{chain: chain, gasCap: 25000000}
+}
+
+func (b *simTestBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+func (b *simTestBackend) RPCGasCap() uint64                { return b.gasCap }
+func (b *simTestBackend) RPCEVMTimeout() time.Duration     { return b.timeout }
+
+func (b *simTestBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.PendingBlockNumber || number == rpc.LatestBlockNumber {
+		return b.chain.CurrentHeader(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *simTestBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	number, ok := blockNrOrHash.Number()
+	if !ok {
+		return nil, nil, errors.New("block hashes not supported")
+	}
+	header, _ := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil, errors.New("header not found")
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+func (b *simTestBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmConfig *vm.Config) (*vm.EVM, func() error, error) {
+	txContext := core.NewEVMTxContext(msg)
+	context := core.NewEVMBlockContext(header, b.chain, nil)
+	return vm.NewEVM(context, txContext, state, b.chain.Config(), *vmConfig), func() error { return nil }, nil
+}
+
+// simCalls returns the call results of a simulated block.
+func simCalls(t *testing.T, block map[string]interface{}) []*simCallResult {
+	t.Helper()
+	calls, ok := block["calls"].([]*simCallResult)
+	if !ok {
+		t.Fatalf("block has no calls: %v", block)
+	}
+	return calls
+}
+
+func TestSimulateV1Overrides(t *testing.T) {
+	var (
+		api       = NewPublicBlockChainAPI(newSimTestBackend(t, 2))
+		contract  = common.HexToAddress("0xc0de")
+		recipient = common.HexToAddress("0xfee")
+		number    = (*hexutil.Big)(big.NewInt(100))
+		time      = hexutil.Uint64(1000000)
+		code      = hexutil.Bytes(simNumberCode)
+	)
+	results, err := api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{
+			BlockOverrides: &BlockOverrides{Number: number, Time: &time, FeeRecipient: &recipient},
+			StateOverrides: &StateOverride{contract: OverrideAccount{Code: &code}},
+			Calls:          []TransactionArgs{{To: &contract}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("have %d blocks, want 1", len(results))
+	}
+	if have := results[0]["number"].(*hexutil.Big).ToInt(); have.Cmp(number.ToInt()) != 0 {
+		t.Errorf("block number mismatch: have %v, want %v", have, number)
+	}
+	if have := results[0]["timestamp"].(hexutil.Uint64); have != time {
+		t.Errorf("block time mismatch: have %v, want %v", have, time)
+	}
+	if have := results[0]["miner"].(common.Address); have != recipient {
+		t.Errorf("fee recipient mismatch: have %v, want %v", have, recipient)
+	}
+	calls := simCalls(t, results[0])
+	if len(calls) != 1 || calls[0].Error != nil {
+		t.Fatalf("unexpected call results: %+v", calls)
+	}
+	if have := new(big.Int).SetBytes(calls[0].ReturnValue); have.Cmp(number.ToInt()) != 0 {
+		t.Errorf("overridden code saw block %v, want %v", have, number)
+	}
+}
+
+func TestSimulateV1Chaining(t *testing.T) {
+	var (
+		api    = NewPublicBlockChainAPI(newSimTestBackend(t, 2))
+		store  = common.HexToAddress("0x5707e")
+		hasher = common.HexToAddress("0xb10c")
+		code   = hexutil.Bytes(simStoreCode)
+		load   = hexutil.Bytes(simLoadCode)
+		hash   = hexutil.Bytes(simBlockHashCode)
+		input  = hexutil.Bytes(common.BigToHash(big.NewInt(3)).Bytes())
+	)
+	results, err := api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{
+			StateOverrides: &StateOverride{store: OverrideAccount{Code: &code}},
+			Calls:          []TransactionArgs{{To: &store}},
+		}, {
+			StateOverrides: &StateOverride{store: OverrideAccount{Code: &load}, hasher: OverrideAccount{Code: &hash}},
+			Calls:          []TransactionArgs{{To: &store}, {To: &hasher, Input: &input}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("have %d blocks, want 2", len(results))
+	}
+	// The blocks are built on top of the head and on top of each other.
+	for i, block := range results {
+		if have := block["number"].(*hexutil.Big).ToInt().Uint64(); have != uint64(3+i) {
+			t.Errorf("block %d: number mismatch: have %d, want %d", i, have, 3+i)
+		}
+	}
+	if have, want := results[1]["parentHash"].(common.Hash), results[0]["hash"].(common.Hash); have != want {
+		t.Errorf("parent hash mismatch: have %x, want %x", have, want)
+	}
+	if have, want := results[1]["timestamp"].(hexutil.Uint64), results[0]["timestamp"].(hexutil.Uint64)+simulateBlockTime; have != want {
+		t.Errorf("block time mismatch: have %d, want %d", have, want)
+	}
+	// The state changes of the first block are visible in the second one.
+	calls := simCalls(t, results[1])
+	if len(calls) != 2 {
+		t.Fatalf("have %d calls, want 2", len(calls))
+	}
+	if have := new(big.Int).SetBytes(calls[0].ReturnValue); have.Uint64() != 0x2a {
+		t.Errorf("stored value mismatch: have %v, want 42", have)
+	}
+	// The hashes of simulated blocks are available to the later ones.
+	if have, want := common.BytesToHash(calls[1].ReturnValue), results[0]["hash"].(common.Hash); have != want {
+		t.Errorf("block hash mismatch: have %x, want %x", have, want)
+	}
+}
+
+func TestSimulateV1Errors(t *testing.T) {
+	var (
+		api  = NewPublicBlockChainAPI(newSimTestBackend(t, 2))
+		low  = (*hexutil.Big)(big.NewInt(1))
+		zero = hexutil.Uint64(0)
+	)
+	tests := []struct {
+		name string
+		opts SimOpts
+		want string
+	}{
+		{"empty", SimOpts{}, "empty input"},
+		{"too many blocks", SimOpts{BlockStateCalls: make([]SimBlock, maxSimulateBlocks+1)}, "too many blocks"},
+		{"number not increasing", SimOpts{BlockStateCalls: []SimBlock{{BlockOverrides: &BlockOverrides{Number: low}}}}, "block number 1 not above parent 2"},
+		{"time not increasing", SimOpts{BlockStateCalls: []SimBlock{{BlockOverrides: &BlockOverrides{Time: &zero}}}}, "block timestamp 0 not above parent"},
+	}
+	for _, tt := range tests {
+		_, err := api.SimulateV1(context.Background(), tt.opts, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error mismatch: have %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSimulateV1GasCap(t *testing.T) {
+	var (
+		backend = newSimTestBackend(t, 2)
+		api     = NewPublicBlockChainAPI(backend)
+		loop    = common.HexToAddress("0x100b")
+		code    = hexutil.Bytes(simLoopCode)
+	)
+	backend.gasCap = 100000
+
+	// The first call burns the whole gas cap, leaving nothing for the second.
+	_, err := api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{
+			StateOverrides: &StateOverride{loop: OverrideAccount{Code: &code}},
+			Calls:          []TransactionArgs{{To: &loop}},
+		}, {
+			Calls: []TransactionArgs{{To: &loop}},
+		}},
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "gas cap exhausted") {
+		t.Fatalf("error mismatch: have %v, want gas cap exhausted", err)
+	}
+}
+
+func TestSimulateV1Timeout(t *testing.T) {
+	var (
+		backend  = newSimTestBackend(t, 2)
+		api      = NewPublicBlockChainAPI(backend)
+		loop     = common.HexToAddress("0x100b")
+		code     = hexutil.Bytes(simLoopCode)
+		gasLimit = hexutil.Uint64(1 << 62)
+	)
+	backend.gasCap = 0
+	backend.timeout = 50 * time.Millisecond
+
+	blocks := make([]SimBlock, maxSimulateBlocks)
+	for i := range blocks {
+		blocks[i] = SimBlock{
+			BlockOverrides: &BlockOverrides{GasLimit: &gasLimit},
+			Calls:          []TransactionArgs{{To: &loop}},
+		}
+	}
+	blocks[0].StateOverrides = &StateOverride{loop: OverrideAccount{Code: &code}}
+
+	_, err := api.SimulateV1(context.Background(), SimOpts{BlockStateCalls: blocks}, nil)
+	if err == nil || !strings.Contains(err.Error(), "execution aborted") {
+		t.Fatalf("error mismatch: have %v, want execution aborted", err)
+	}
+}
+
+func TestSimulateV1TraceTransfersWASM(t *testing.T) {
+	wasm, err := wasmer.Wat2Wasm(watSimLog)
+	if err != nil {
+		t.Fatalf("failed to compile contract: %v", err)
+	}
+	config := *params.TestChainConfig
+	config.WebAssemblyBlock = big.NewInt(0)
+	var (
+		api      = NewPublicBlockChainAPI(newSimTestBackendWithConfig(t, 2, &config))
+		sender   = common.HexToAddress("0x5e4d")
+		contract = common.HexToAddress("0xc0de")
+		code     = hexutil.Bytes(wasm)
+		balance  = (*hexutil.Big)(big.NewInt(params.Ether))
+		value    = (*hexutil.Big)(big.NewInt(5))
+	)
+	results, err := api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{
+			StateOverrides: &StateOverride{sender: OverrideAccount{Balance: &balance}, contract: OverrideAccount{Code: &code}},
+			Calls:          []TransactionArgs{{From: &sender, To: &contract, Value: value}},
+		}},
+		TraceTransfers: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	calls := simCalls(t, results[0])
+	if len(calls) != 1 || calls[0].Error != nil {
+		t.Fatalf("unexpected call results: %+v", calls)
+	}
+	// The log emitted through the host function follows the transfer of the call.
+	logs := calls[0].Logs
+	if len(logs) != 2 {
+		t.Fatalf("have %d logs, want 2", len(logs))
+	}
+	if logs[0].Address != transferAddress || logs[0].Topics[0] != transferTopic {
+		t.Errorf("first log is not the transfer: %+v", logs[0])
+	}
+	if logs[1].Address != contract || logs[1].Topics[0] != common.BigToHash(common.Big1) || !bytes.Equal(logs[1].Data, []byte{0x2a}) {
+		t.Errorf("second log is not the contract log: %+v", logs[1])
+	}
+	for i, log := range logs {
+		if log.Index != uint(i) {
+			t.Errorf("log %d: index mismatch: have %d", i, log.Index)
+		}
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
@@ -78,6 +79,13 @@ const (
 	staleThreshold = 7
 )
 
+var (
+	l1MsgQueueIndexGauge = metrics.NewRegisteredGauge("miner/l1msg/queueindex", nil)
+	l1MsgLagGauge        = metrics.NewRegisteredGauge("miner/l1msg/lag", nil)
+	l1MsgIncludedMeter   = metrics.NewRegisteredMeter("miner/l1msg/included", nil)
+	l1MsgSkippedMeter    = metrics.NewRegisteredMeter("miner/l1msg/skipped", nil)
+)
+
 // environment is the worker's current environment and holds all of the current state information.
 type environment struct {
 	signer types.Signer
@@ -668,6 +676,16 @@ func (w *worker) resultLoop() {
 			log.Info("Successfully sealed new block", "number", block.Number(), "sealhash", sealhash, "hash", hash,
 				"elapsed", common.PrettyDuration(time.Since(task.createdAt)))
 
+			for _, receipt := range receipts {
+				if receipt.Type != types.L1MessageTxType {
+					break // L1 messages precede all L2 transactions
+				}
+				l1MsgIncludedMeter.Mark(1)
+				if core.IsSkippedL1Message(receipt) {
+					l1MsgSkippedMeter.Mark(1)
+				}
+			}
+
 			// Broadcast the block and announce chain insertion event
 			w.mux.Post(core.NewMinedBlockEvent{Block: block})
 
@@ -786,6 +804,65 @@ func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Addres
 	return receipt.Logs, nil
 }
 
+// commitL1Messages includes the pending L1 messages in queue order, ahead of
+// any L2 transaction. It returns whether L2 transactions must be left out of
+// the block, as an overdue message is still pending afterwards.
+func (w *worker) commitL1Messages(coinbase common.Address) bool {
+	config := w.chainConfig.Scroll.L1Config
+	if config == nil || w.current == nil {
+		return false
+	}
+	if w.current.gasPool == nil {
+		w.current.gasPool = new(core.GasPool).AddGas(w.current.header.GasLimit)
+	}
+	next := w.chain.NextL1MessageIndex(w.current.header.ParentHash)
+	for count := uint64(0); config.NumL1MessagesPerBlock == 0 || count < config.NumL1MessagesPerBlock; count++ {
+		if !w.chainConfig.Scroll.IsValidTxCount(w.current.tcount + 1) {
+			break
+		}
+		msg, _ := w.chain.L1Message(next)
+		if msg == nil {
+			break
+		}
+		tx := types.NewTx(msg)
+		if !w.chainConfig.Scroll.IsValidBlockSize(w.current.blockSize + tx.Size()) {
+			break
+		}
+		w.current.state.Prepare(tx.Hash(), w.current.tcount)
+
+		if _, err := w.commitTransaction(tx, coinbase); err != nil {
+			// Messages which can never be executed are skipped during execution,
+			// so this one only doesn't fit into the block, retry in the next one
+			log.Trace("L1 message postponed", "index", next, "err", err)
+			break
+		}
+		if core.IsSkippedL1Message(w.current.receipts[len(w.current.receipts)-1]) {
+			log.Info("Skipping failing L1 message", "index", next, "hash", tx.Hash())
+		}
+		w.current.tcount++
+		w.current.blockSize += tx.Size()
+		next++
+	}
+	lag, _ := w.chain.L1MessageLag(next)
+	l1MsgQueueIndexGauge.Update(int64(next))
+	l1MsgLagGauge.Update(int64(lag))
+
+	if number := w.current.header.L1BlockNumber; number != nil {
+		return w.chain.L1MessageOverdue(next, *number)
+	}
+	return false
+}
+
+// l1BlockNumber returns the L1 block number to commit in a block on top of the
+// given parent: the synced L1 head, which may not fall below the parent's.
+func (w *worker) l1BlockNumber(parent *types.Header) uint64 {
+	number, _ := w.chain.SyncedL1BlockNumber()
+	if parent.L1BlockNumber != nil && *parent.L1BlockNumber > number {
+		number = *parent.L1BlockNumber
+	}
+	return number
+}
+
 func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coinbase common.Address, interrupt *int32) bool {
 	// Short circuit if current is nil
 	if w.current == nil {
@@ -853,6 +930,20 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			txs.Pop()
 			continue
 		}
+		// Re-check the preconditions of conditional transactions against the block
+		// being built, dropping the ones that can no longer be met.
+		if cond := tx.Conditional(); cond != nil {
+			if err := core.CheckConditional(cond, w.current.header, w.current.state); err != nil {
+				if errors.Is(err, core.ErrConditionalFailed) {
+					log.Trace("Dropping transaction with failed conditions", "hash", tx.Hash(), "err", err)
+					w.eth.TxPool().Drop(tx.Hash())
+				} else {
+					log.Trace("Skipping transaction with unmet conditions", "hash", tx.Hash(), "err", err)
+				}
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		w.current.state.Prepare(tx.Hash(), w.current.tcount)
 
@@ -950,6 +1041,15 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
 		}
 	}
+	// Commit to the L1 block the forced inclusion deadline is checked against.
+	// The base fee precedes it in the header encoding, so it can't be left out.
+	if w.chainConfig.IsForcedInclusion(header.Number) {
+		number := w.l1BlockNumber(parent.Header())
+		header.L1BlockNumber = &number
+		if header.BaseFee == nil {
+			header.BaseFee = new(big.Int)
+		}
+	}
 	// Only set the coinbase if our consensus engine is running (avoid spurious block rewards)
 	if w.isRunning() {
 		if w.coinbase == (common.Address{}) {
@@ -1017,12 +1117,20 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		w.commit(uncles, nil, false, tstart)
 	}
 
+	// Include the pending L1 messages first. If an overdue message is left in
+	// the queue, L2 transactions are held back until it's included.
+	overdue := w.commitL1Messages(w.coinbase)
+
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(true)
+	if overdue {
+		log.Debug("Holding back L2 transactions until overdue L1 messages are included")
+		pending = nil
+	}
 	// Short circuit if there is no available pending transactions.
 	// But if we disable empty precommit already, ignore it. Since
 	// empty block is necessary to keep the liveness of the network.
-	if len(pending) == 0 && atomic.LoadUint32(&w.noempty) == 0 {
+	if len(pending) == 0 && w.current.tcount == 0 && atomic.LoadUint32(&w.noempty) == 0 {
 		w.updateSnapshot()
 		return
 	}
@@ -34,9 +34,10 @@ import (
 func (n *Node) apis() []rpc.API {
 	return []rpc.API{
 		{
-			Namespace: "admin",
-			Version:   "1.0",
-			Service:   &privateAdminAPI{n},
+			Namespace:     "admin",
+			Version:       "1.0",
+			Service:       &privateAdminAPI{n},
+			Authenticated: true,
 		}, {
 			Namespace: "admin",
 			Version:   "1.0",
@@ -185,6 +186,7 @@ func (api *privateAdminAPI) StartHTTP(host *string, port *int, cors *string, api
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
+		rpcEndpointConfig:  api.node.endpointConfig(),
 	}
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
@@ -256,8 +258,9 @@ func (api *privateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 
 	// Determine config.
 	config := wsConfig{
-		Modules: api.node.config.WSModules,
-		Origins: api.node.config.WSOrigins,
+		Modules:           api.node.config.WSModules,
+		Origins:           api.node.config.WSOrigins,
+		rpcEndpointConfig: api.node.endpointConfig(),
 		// ExposeAll: api.node.config.WSExposeAll,
 	}
 	if apis != nil {
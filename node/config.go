@@ -40,6 +40,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirJWTKey          = "jwtsecret"          // Path within the datadir to the node's jwt secret
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -65,6 +66,10 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine is the backing database implementation to use ('leveldb' or 'pebble').
+	// If empty, the engine of an existing database is used, or leveldb for a new one.
+	DBEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -181,6 +186,47 @@ type Config struct {
 	// Requests using ip address directly are not affected
 	GraphQLVirtualHosts []string `toml:",omitempty"`
 
+	// AuthAddr is the host interface on which to start the JWT authenticated
+	// HTTP and WebSocket RPC server, which serves the authenticated APIs. If this
+	// field is empty, they are only available over IPC.
+	AuthAddr string `toml:",omitempty"`
+
+	// AuthPort is the TCP port number on which to start the authenticated server.
+	AuthPort int `toml:",omitempty"`
+
+	// AuthVirtualHosts is the list of virtual hostnames which are allowed on incoming
+	// requests to the authenticated server.
+	AuthVirtualHosts []string `toml:",omitempty"`
+
+	// AuthModules is a list of API modules to expose via the authenticated server,
+	// the authenticated APIs must be part of it.
+	AuthModules []string
+
+	// JWTSecret is the path to the hex encoded shared secret of the HS256 tokens
+	// of the authenticated server. If the file doesn't exist, a random secret is
+	// written to it. It defaults to the "jwtsecret" file of the data directory.
+	JWTSecret string `toml:",omitempty"`
+
+	// BatchRequestLimit is the maximum number of requests in a batch served over
+	// HTTP and WebSocket. Zero means unlimited.
+	BatchRequestLimit int `toml:",omitempty"`
+
+	// BatchResponseMaxSize is the maximum number of result bytes of a batch served
+	// over HTTP and WebSocket. Zero means unlimited.
+	BatchResponseMaxSize int `toml:",omitempty"`
+
+	// RPCMethodConcurrency limits the number of concurrent HTTP and WebSocket calls
+	// per method name. Calls exceeding the limit are rejected.
+	RPCMethodConcurrency map[string]int `toml:",omitempty"`
+
+	// RPCRateLimit is the number of requests per second a single HTTP or WebSocket
+	// connection may issue. Zero means unlimited.
+	RPCRateLimit float64 `toml:",omitempty"`
+
+	// RPCRateBurst is the number of requests a connection may issue at once. It
+	// defaults to the per second rate limit.
+	RPCRateBurst int `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
@@ -17,8 +17,11 @@
 package node
 
 import (
+	crand "crypto/rand"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -29,6 +32,8 @@ import (
 	"github.com/prometheus/tsdb/fileutil"
 
 	"github.com/scroll-tech/go-ethereum/accounts"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
@@ -56,6 +61,7 @@ type Node struct {
 	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
 	http          *httpServer //
 	ws            *httpServer //
+	httpAuth      *httpServer // JWT authenticated HTTP and WebSocket server
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
@@ -149,6 +155,7 @@ func New(conf *Config) (*Node, error) {
 	// Configure RPC servers.
 	node.http = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
+	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
 
 	return node, nil
@@ -343,6 +350,15 @@ func (n *Node) startRPC() error {
 	if err := n.startInProc(); err != nil {
 		return err
 	}
+	rpcConfig := n.endpointConfig()
+
+	// The authenticated APIs are only served over IPC and the authenticated server.
+	var openAPIs []rpc.API
+	for _, api := range n.rpcAPIs {
+		if !api.Authenticated {
+			openAPIs = append(openAPIs, api)
+		}
+	}
 
 	// Configure IPC.
 	if n.ipc.endpoint != "" {
@@ -358,11 +374,12 @@ func (n *Node) startRPC() error {
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
+			rpcEndpointConfig:  rpcConfig,
 		}
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
 			return err
 		}
-		if err := n.http.enableRPC(n.rpcAPIs, config); err != nil {
+		if err := n.http.enableRPC(openAPIs, config); err != nil {
 			return err
 		}
 	}
@@ -371,14 +388,22 @@ func (n *Node) startRPC() error {
 	if n.config.WSHost != "" {
 		server := n.wsServerForPort(n.config.WSPort)
 		config := wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:           n.config.WSModules,
+			Origins:           n.config.WSOrigins,
+			prefix:            n.config.WSPathPrefix,
+			rpcEndpointConfig: rpcConfig,
 		}
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
 		}
-		if err := server.enableWS(n.rpcAPIs, config); err != nil {
+		if err := server.enableWS(openAPIs, config); err != nil {
+			return err
+		}
+	}
+
+	// Configure the authenticated HTTP and WebSocket server.
+	if n.config.AuthAddr != "" {
+		if err := n.startAuth(rpcConfig); err != nil {
 			return err
 		}
 	}
@@ -389,6 +414,78 @@ func (n *Node) startRPC() error {
 	return n.ws.start()
 }
 
+// endpointConfig returns the request limits of the HTTP and WebSocket endpoints.
+func (n *Node) endpointConfig() rpcEndpointConfig {
+	return rpcEndpointConfig{
+		batchItemLimit:         n.config.BatchRequestLimit,
+		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		methodConcurrency:      n.config.RPCMethodConcurrency,
+		rateLimit:              n.config.RPCRateLimit,
+		rateBurst:              n.config.RPCRateBurst,
+	}
+}
+
+// startAuth starts the JWT authenticated server, serving both HTTP and WebSocket
+// on the same port.
+func (n *Node) startAuth(rpcConfig rpcEndpointConfig) error {
+	secret, err := n.obtainJWTSecret(n.config.JWTSecret)
+	if err != nil {
+		return err
+	}
+	if err := n.httpAuth.setListenAddr(n.config.AuthAddr, n.config.AuthPort); err != nil {
+		return err
+	}
+	if err := n.httpAuth.enableRPC(n.rpcAPIs, httpConfig{
+		CorsAllowedOrigins: DefaultAuthCors,
+		Vhosts:             n.config.AuthVirtualHosts,
+		Modules:            n.config.AuthModules,
+		jwtSecret:          secret,
+		rpcEndpointConfig:  rpcConfig,
+	}); err != nil {
+		return err
+	}
+	if err := n.httpAuth.enableWS(n.rpcAPIs, wsConfig{
+		Modules:           n.config.AuthModules,
+		Origins:           DefaultAuthOrigins,
+		jwtSecret:         secret,
+		rpcEndpointConfig: rpcConfig,
+	}); err != nil {
+		return err
+	}
+	return n.httpAuth.start()
+}
+
+// obtainJWTSecret loads the jwt secret from the provided path, or from the data
+// directory by default. If the file doesn't exist, a new secret is generated
+// and written to it.
+func (n *Node) obtainJWTSecret(path string) ([]byte, error) {
+	fileName := path
+	if len(fileName) == 0 {
+		fileName = n.ResolvePath(datadirJWTKey)
+	}
+	if data, err := ioutil.ReadFile(fileName); err == nil {
+		secret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(secret) == 32 {
+			n.log.Info("Loaded JWT secret file", "path", fileName, "crc32", fmt.Sprintf("%#x", crc32.ChecksumIEEE(secret)))
+			return secret, nil
+		}
+		n.log.Error("Invalid JWT secret", "path", fileName, "length", len(secret))
+		return nil, errors.New("invalid JWT secret")
+	}
+	secret := make([]byte, 32)
+	crand.Read(secret)
+	// Ephemeral nodes don't have anywhere to save it.
+	if fileName == "" {
+		n.log.Info("Generated ephemeral JWT secret", "secret", hexutil.Encode(secret))
+		return secret, nil
+	}
+	if err := ioutil.WriteFile(fileName, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		return nil, err
+	}
+	n.log.Info("Generated JWT secret", "path", fileName)
+	return secret, nil
+}
+
 func (n *Node) wsServerForPort(port int) *httpServer {
 	if n.config.HTTPHost == "" || n.http.port == port {
 		return n.http
@@ -399,6 +496,7 @@ func (n *Node) wsServerForPort(port int) *httpServer {
 func (n *Node) stopRPC() {
 	n.http.stop()
 	n.ws.stop()
+	n.httpAuth.stop()
 	n.ipc.stop()
 	n.stopInProc()
 }
@@ -538,6 +636,11 @@ func (n *Node) HTTPEndpoint() string {
 	return "http://" + n.http.listenAddr()
 }
 
+// HTTPAuthEndpoint returns the URL of the authenticated HTTP server.
+func (n *Node) HTTPAuthEndpoint() string {
+	return "http://" + n.httpAuth.listenAddr()
+}
+
 // WSEndpoint returns the current JSON-RPC over WebSocket endpoint.
 func (n *Node) WSEndpoint() string {
 	if n.http.wsAllowed() {
@@ -567,7 +670,14 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabase(n.ResolvePath(name), cache, handles, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:      n.config.DBEngine,
+			Directory: n.ResolvePath(name),
+			Namespace: namespace,
+			Cache:     cache,
+			Handles:   handles,
+			ReadOnly:  readonly,
+		})
 	}
 
 	if err == nil {
@@ -600,7 +710,15 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer,
 		case !filepath.IsAbs(freezer):
 			freezer = n.ResolvePath(freezer)
 		}
-		db, err = rawdb.NewLevelDBDatabaseWithFreezer(root, cache, handles, freezer, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:              n.config.DBEngine,
+			Directory:         root,
+			AncientsDirectory: freezer,
+			Namespace:         namespace,
+			Cache:             cache,
+			Handles:           handles,
+			ReadOnly:          readonly,
+		})
 	}
 
 	if err == nil {
@@ -24,10 +24,15 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/p2p"
@@ -591,6 +596,61 @@ func (test rpcPrefixTest) check(t *testing.T, node *Node) {
 	}
 }
 
+type authTestService struct{}
+
+func (authTestService) Echo(s string) string { return s }
+
+// Tests that the authenticated APIs are only served by the authenticated server.
+func TestNodeAuthenticatedAPIs(t *testing.T) {
+	secret := make([]byte, 32)
+	secret[0] = 1
+	secretFile := filepath.Join(t.TempDir(), "jwtsecret")
+	if err := ioutil.WriteFile(secretFile, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	node, err := New(&Config{
+		HTTPHost:    "127.0.0.1",
+		HTTPModules: []string{"engine", "web3"},
+		AuthAddr:    "127.0.0.1",
+		AuthModules: []string{"engine"},
+		JWTSecret:   secretFile,
+	})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	defer node.Close()
+	node.RegisterAPIs([]rpc.API{{Namespace: "engine", Service: authTestService{}, Authenticated: true}})
+	if err := node.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+
+	echo := func(endpoint string, key []byte) error {
+		client, err := rpc.DialHTTP(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.SetHeader("Authorization", "Bearer "+token)
+		var result string
+		return client.Call(&result, "engine_echo", "hello")
+	}
+	if err := echo(node.HTTPEndpoint(), secret); err == nil {
+		t.Error("authenticated API served by the HTTP server")
+	}
+	if err := echo(node.HTTPAuthEndpoint(), secret); err != nil {
+		t.Errorf("authenticated API not served by the authenticated server: %v", err)
+	}
+	if err := echo(node.HTTPAuthEndpoint(), []byte("other")); err == nil {
+		t.Error("authenticated server accepted a token signed with another secret")
+	}
+}
+
 func createNode(t *testing.T, httpPort, wsPort int) *Node {
 	conf := &Config{
 		HTTPHost: "127.0.0.1",
@@ -41,13 +41,38 @@ type httpConfig struct {
 	CorsAllowedOrigins []string
 	Vhosts             []string
 	prefix             string // path prefix on which to mount http handler
+	jwtSecret          []byte // optional JWT secret
+	rpcEndpointConfig
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins []string
-	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Origins   []string
+	Modules   []string
+	prefix    string // path prefix on which to mount ws handler
+	jwtSecret []byte // optional JWT secret
+	rpcEndpointConfig
+}
+
+// rpcEndpointConfig holds the request limits of an RPC endpoint.
+type rpcEndpointConfig struct {
+	batchItemLimit         int
+	batchResponseSizeLimit int
+	methodConcurrency      map[string]int
+	rateLimit              float64
+	rateBurst              int
+}
+
+// apply configures the request limits of the given server.
+func (c rpcEndpointConfig) apply(srv *rpc.Server) error {
+	srv.SetBatchLimits(c.batchItemLimit, c.batchResponseSizeLimit)
+	for method, limit := range c.methodConcurrency {
+		if err := srv.SetMethodConcurrencyLimit(method, limit); err != nil {
+			return err
+		}
+	}
+	srv.SetRateLimit(c.rateLimit, c.rateBurst)
+	return nil
 }
 
 type rpcHandler struct {
@@ -281,12 +306,19 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	if err := config.apply(srv); err != nil {
+		return err
+	}
 	if err := RegisterApis(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
 	h.httpConfig = config
+	handler := NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts)
+	if len(config.jwtSecret) != 0 {
+		handler = newJWTHandler(config.jwtSecret, handler)
+	}
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts),
+		Handler: handler,
 		server:  srv,
 	})
 	return nil
@@ -313,12 +345,19 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	if err := config.apply(srv); err != nil {
+		return err
+	}
 	if err := RegisterApis(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
 	h.wsConfig = config
+	handler := srv.WebsocketHandler(config.Origins)
+	if len(config.jwtSecret) != 0 {
+		handler = newJWTHandler(config.jwtSecret, handler)
+	}
 	h.wsHandler.Store(&rpcHandler{
-		Handler: srv.WebsocketHandler(config.Origins),
+		Handler: handler,
 		server:  srv,
 	})
 	return nil
@@ -24,7 +24,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 
@@ -59,6 +61,52 @@ func TestVhosts(t *testing.T) {
 	assert.Equal(t, resp2.StatusCode, http.StatusForbidden)
 }
 
+// TestJWT makes sure the tokens are checked on the authenticated http and ws server.
+func TestJWT(t *testing.T) {
+	secret := []byte("secret")
+	issue := func(method jwt.SigningMethod, key []byte, iat time.Time) string {
+		token, err := jwt.NewWithClaims(method, jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(iat)}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+	srv := createAndStartServer(t, &httpConfig{jwtSecret: secret}, true, &wsConfig{Origins: []string{"*"}, jwtSecret: secret})
+	defer srv.stop()
+	url := "http://" + srv.listenAddr()
+	wsURL := "ws://" + srv.listenAddr()
+
+	for i, tt := range []struct {
+		auth string
+		ok   bool
+	}{
+		{"", false},
+		{"Bearer ", false},
+		{issue(jwt.SigningMethodHS256, secret, time.Now()), true},
+		{issue(jwt.SigningMethodHS256, secret, time.Now().Add(jwtIssuedAtSkew/2)), true},
+		{issue(jwt.SigningMethodHS256, secret, time.Now().Add(-jwtIssuedAtSkew/2)), true},
+		{issue(jwt.SigningMethodHS256, secret, time.Now().Add(2*jwtIssuedAtSkew)), false},
+		{issue(jwt.SigningMethodHS256, secret, time.Now().Add(-2*jwtIssuedAtSkew)), false},
+		{issue(jwt.SigningMethodHS256, []byte("other"), time.Now()), false},
+		{issue(jwt.SigningMethodHS512, secret, time.Now()), false},
+		{"Bearer " + strings.TrimPrefix(issue(jwt.SigningMethodHS256, secret, time.Now()), "Bearer ") + "x", false},
+	} {
+		resp := rpcRequest(t, url, "Authorization", tt.auth)
+		if ok := resp.StatusCode == http.StatusOK; ok != tt.ok {
+			t.Errorf("test %d: http status %d, want ok %v", i, resp.StatusCode, tt.ok)
+		}
+		headers := make(http.Header)
+		headers.Set("Authorization", tt.auth)
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, headers)
+		if conn != nil {
+			conn.Close()
+		}
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("test %d: ws error %v, want ok %v", i, err, tt.ok)
+		}
+	}
+}
+
 type originTest struct {
 	spec    string
 	expOk   []string
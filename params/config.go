@@ -296,7 +296,7 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil,
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, new(EthashConfig), nil,
 		ScrollConfig{
 			UseZktrie:                 false,
 			FeeVaultAddress:           nil,
@@ -311,7 +311,7 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000},
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000},
 		ScrollConfig{
 			UseZktrie:                 false,
 			FeeVaultAddress:           nil,
@@ -321,7 +321,7 @@ var (
 			MaxTxPayloadBytesPerBlock: nil,
 		}}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil,
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, new(EthashConfig), nil,
 		ScrollConfig{
 			UseZktrie:                 false,
 			FeeVaultAddress:           &common.Address{123},
@@ -332,7 +332,7 @@ var (
 		}}
 	TestRules = TestChainConfig.Rules(new(big.Int))
 
-	TestNoL1feeChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil,
+	TestNoL1feeChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, new(EthashConfig), nil,
 		ScrollConfig{
 			UseZktrie:                 false,
 			FeeVaultAddress:           nil,
@@ -422,6 +422,9 @@ type ChainConfig struct {
 	ArchimedesBlock     *big.Int `json:"archimedesBlock,omitempty"`     // Archimedes switch block (nil = no fork, 0 = already on archimedes)
 	ShanghaiBlock       *big.Int `json:"shanghaiBlock,omitempty"`       // Shanghai switch block (nil = no fork, 0 = already on shanghai)
 	WebAssemblyBlock    *big.Int `json:"webAssemblyBlock,omitempty"`    // WebAssembly activation block (nil = no fork, 0 = already activated)
+	EOFBlock            *big.Int `json:"eofBlock,omitempty"`            // EVM Object Format activation block (nil = no fork, 0 = already activated)
+	PoseidonBlock       *big.Int `json:"poseidonBlock,omitempty"`       // Poseidon hash precompile activation block (nil = no fork, 0 = already activated)
+	ZktrieProofBlock    *big.Int `json:"zktrieProofBlock,omitempty"`    // Zktrie proof precompile activation block (nil = no fork, 0 = already activated)
 
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
@@ -453,6 +456,32 @@ type ScrollConfig struct {
 
 	// Enable EIP-1559 in tx pool, EnableEIP2718 should be true too [optional]
 	EnableEIP1559 bool `json:"enableEIP1559,omitempty"`
+
+	// L1 message queue configuration, L1 messages are rejected if nil [optional]
+	L1Config *L1Config `json:"l1Config,omitempty"`
+
+	// Maximum size of the deployed WASM code, after the gas injection [optional]
+	MaxWasmCodeSize *int `json:"maxWasmCodeSize,omitempty"`
+
+	// Maximum size of the WASM init code [optional]
+	MaxWasmInitCodeSize *int `json:"maxWasmInitCodeSize,omitempty"`
+}
+
+// L1Config contains the parameters of the L1 message queue.
+type L1Config struct {
+	// Maximum number of L1 messages consumed by a single block (0 = unlimited)
+	NumL1MessagesPerBlock uint64 `json:"numL1MessagesPerBlock,omitempty"`
+
+	// Number of L1 blocks after which an enqueued message must be included
+	// before any L2 transaction (0 = no forced inclusion)
+	ForcedInclusionDeadline uint64 `json:"forcedInclusionDeadline,omitempty"`
+}
+
+func (c *L1Config) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("{numL1MessagesPerBlock: %v, forcedInclusionDeadline: %v}", c.NumL1MessagesPerBlock, c.ForcedInclusionDeadline)
 }
 
 func (s ScrollConfig) BaseFeeEnabled() bool {
@@ -467,6 +496,10 @@ func (s ScrollConfig) ZktrieEnabled() bool {
 	return s.UseZktrie
 }
 
+func (s ScrollConfig) L1MessagesEnabled() bool {
+	return s.L1Config != nil
+}
+
 func (s ScrollConfig) String() string {
 	maxTxPerBlock := "<nil>"
 	if s.MaxTxPerBlock != nil {
@@ -478,8 +511,24 @@ func (s ScrollConfig) String() string {
 		maxTxPayloadBytesPerBlock = fmt.Sprintf("%v", *s.MaxTxPayloadBytesPerBlock)
 	}
 
-	return fmt.Sprintf("{useZktrie: %v, maxTxPerBlock: %v, MaxTxPayloadBytesPerBlock: %v, feeVaultAddress: %v, enableEIP2718:%v, enableEIP1559:%v}",
-		s.UseZktrie, maxTxPerBlock, maxTxPayloadBytesPerBlock, s.FeeVaultAddress, s.EnableEIP2718, s.EnableEIP1559)
+	return fmt.Sprintf("{useZktrie: %v, maxTxPerBlock: %v, MaxTxPayloadBytesPerBlock: %v, feeVaultAddress: %v, enableEIP2718:%v, enableEIP1559:%v, l1Config: %v, maxWasmCodeSize: %v, maxWasmInitCodeSize: %v}",
+		s.UseZktrie, maxTxPerBlock, maxTxPayloadBytesPerBlock, s.FeeVaultAddress, s.EnableEIP2718, s.EnableEIP1559, s.L1Config, s.WasmCodeSizeLimit(), s.WasmInitCodeSizeLimit())
+}
+
+// WasmCodeSizeLimit returns the maximum size of the deployed WASM code.
+func (s ScrollConfig) WasmCodeSizeLimit() int {
+	if s.MaxWasmCodeSize == nil {
+		return DefaultMaxWasmCodeSize
+	}
+	return *s.MaxWasmCodeSize
+}
+
+// WasmInitCodeSizeLimit returns the maximum size of the WASM init code.
+func (s ScrollConfig) WasmInitCodeSizeLimit() int {
+	if s.MaxWasmInitCodeSize == nil {
+		return DefaultMaxWasmInitCodeSize
+	}
+	return *s.MaxWasmInitCodeSize
 }
 
 // IsValidTxCount returns whether the given block's transaction count is below the limit.
@@ -634,6 +683,29 @@ func (c *ChainConfig) IsWebAssembly(num *big.Int) bool {
 	return isForked(c.WebAssemblyBlock, num)
 }
 
+// IsEOF returns whether num is either equal to the EOF fork block or greater.
+func (c *ChainConfig) IsEOF(num *big.Int) bool {
+	return isForked(c.EOFBlock, num)
+}
+
+// IsPoseidon returns whether num is either equal to the Poseidon fork block or greater.
+func (c *ChainConfig) IsPoseidon(num *big.Int) bool {
+	return isForked(c.PoseidonBlock, num)
+}
+
+// IsZktrieProof returns whether num is either equal to the zktrie proof fork block or greater.
+func (c *ChainConfig) IsZktrieProof(num *big.Int) bool {
+	return isForked(c.ZktrieProofBlock, num)
+}
+
+// IsForcedInclusion returns whether the block num is subject to the forced inclusion
+// deadline of L1 messages. Its headers then carry the L1 block number the deadline
+// is checked against, which requires the London header layout.
+func (c *ChainConfig) IsForcedInclusion(num *big.Int) bool {
+	l1 := c.Scroll.L1Config
+	return l1 != nil && l1.ForcedInclusionDeadline > 0 && c.IsLondon(num)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -677,7 +749,11 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		{name: "arrowGlacierBlock", block: c.ArrowGlacierBlock, optional: true},
 		{name: "archimedesBlock", block: c.ArchimedesBlock, optional: true},
 		{name: "shanghaiBlock", block: c.ShanghaiBlock, optional: true},
+		// EOF containers are run by the EVM interpreter, which WASM replaces
+		{name: "eofBlock", block: c.EOFBlock, optional: true},
 		{name: "webAssemblyBlock", block: c.WebAssemblyBlock, optional: true},
+		{name: "poseidonBlock", block: c.PoseidonBlock, optional: true},
+		{name: "zktrieProofBlock", block: c.ZktrieProofBlock, optional: true},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -697,6 +773,11 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	// The zktrie proof precompile set extends the poseidon one, so it cannot be
+	// enabled on its own even though both forks are optional.
+	if c.ZktrieProofBlock != nil && c.PoseidonBlock == nil {
+		return fmt.Errorf("unsupported fork ordering: poseidonBlock not enabled, but zktrieProofBlock enabled at %v", c.ZktrieProofBlock)
+	}
 	return nil
 }
 
@@ -756,6 +837,15 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ShanghaiBlock, newcfg.ShanghaiBlock, head) {
 		return newCompatError("Shanghai fork block", c.ShanghaiBlock, newcfg.ShanghaiBlock)
 	}
+	if isForkIncompatible(c.EOFBlock, newcfg.EOFBlock, head) {
+		return newCompatError("EOF fork block", c.EOFBlock, newcfg.EOFBlock)
+	}
+	if isForkIncompatible(c.PoseidonBlock, newcfg.PoseidonBlock, head) {
+		return newCompatError("Poseidon fork block", c.PoseidonBlock, newcfg.PoseidonBlock)
+	}
+	if isForkIncompatible(c.ZktrieProofBlock, newcfg.ZktrieProofBlock, head) {
+		return newCompatError("Zktrie proof fork block", c.ZktrieProofBlock, newcfg.ZktrieProofBlock)
+	}
 	return nil
 }
 
@@ -824,7 +914,7 @@ type Rules struct {
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon, IsArchimedes, IsShanghai            bool
-	IsWebAssembly                                           bool
+	IsWebAssembly, IsEOF, IsPoseidon, IsZktrieProof         bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -848,5 +938,8 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsArchimedes:     c.IsArchimedes(num),
 		IsShanghai:       c.IsShanghai(num),
 		IsWebAssembly:    c.IsWebAssembly(num),
+		IsEOF:            c.IsEOF(num),
+		IsPoseidon:       c.IsPoseidon(num),
+		IsZktrieProof:    c.IsZktrieProof(num),
 	}
 }
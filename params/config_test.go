@@ -96,3 +96,37 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckConfigForkOrderZktrieProof(t *testing.T) {
+	config := *TestChainConfig
+	config.ZktrieProofBlock = big.NewInt(10)
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for zktrie proof fork without poseidon fork")
+	}
+	config.PoseidonBlock = big.NewInt(20)
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for zktrie proof fork before poseidon fork")
+	}
+	config.PoseidonBlock = big.NewInt(10)
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckConfigForkOrderEOF(t *testing.T) {
+	config := *TestChainConfig
+	config.WebAssemblyBlock = big.NewInt(10)
+	config.EOFBlock = big.NewInt(20)
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for EOF fork after WebAssembly fork")
+	}
+	config.EOFBlock = big.NewInt(10)
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config.WebAssemblyBlock = nil
+	config.EOFBlock = big.NewInt(20)
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -124,6 +124,17 @@ const (
 
 	MaxCodeSize = 24576 // Maximum bytecode to permit for a contract
 
+	DefaultMaxWasmCodeSize     = 512 * 1024                 // Default maximum WASM bytecode to permit for a contract, after the gas injection
+	DefaultMaxWasmInitCodeSize = 2 * DefaultMaxWasmCodeSize // Default maximum WASM init code to permit for a contract creation
+
+	WasmDeployFunctionGas uint64 = 2000 // Per function price of deploying a WASM module
+	WasmDeployCodeGas     uint64 = 8    // Per byte price of the code section of a deployed WASM module
+	WasmInitCodeWordGas   uint64 = 2    // Per word price of the WASM initcode of a contract creation, charged before it's decoded
+	WasmInstructionGas    uint64 = 1    // Price of an executed WASM instruction, charged by the injected code
+	WasmMemoryPageGas     uint64 = 6144 // Price of a 64KiB WASM memory page requested by memory.grow, 2048 words of EVM memory
+	WasmMemoryWordGas     uint64 = 3    // Per word price of the WASM memory written by memory.copy and memory.fill
+	WasmTableElementGas   uint64 = 1    // Price of a WASM table element allocated when a module is instantiated
+
 	// Precompiled contract gas prices
 
 	EcrecoverGas        uint64 = 3000 // Elliptic curve sender recovery gas price
@@ -134,6 +145,11 @@ const (
 	IdentityBaseGas     uint64 = 15   // Base price for a data copy operation
 	IdentityPerWordGas  uint64 = 3    // Per-work price for a data copy operation
 
+	PoseidonBaseGas       uint64 = 200  // Base price for a poseidon hash operation
+	PoseidonPerElementGas uint64 = 600  // Per state element price of a poseidon permutation
+	ZktrieProofBaseGas    uint64 = 3000 // Base price for a zktrie proof verification
+	ZktrieProofPerNodeGas uint64 = 4000 // Per node price for a zktrie proof verification
+
 	Bn256AddGasByzantium             uint64 = 500    // Byzantium gas needed for an elliptic curve addition
 	Bn256AddGasIstanbul              uint64 = 150    // Gas needed for an elliptic curve addition
 	Bn256ScalarMulGasByzantium       uint64 = 40000  // Byzantium gas needed for an elliptic curve scalar multiplication
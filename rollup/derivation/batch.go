@@ -0,0 +1,175 @@
+package derivation
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+const (
+	// blockContextSize is the size of an encoded block context within a chunk:
+	// number (8) || timestamp (8) || baseFee (32) || gasLimit (8) || numTxs (2) || numL1Msgs (2)
+	blockContextSize = 60
+
+	// txLengthSize is the size of the length prefix of every L2 transaction
+	// within the chunk payload.
+	txLengthSize = 4
+)
+
+var (
+	errEmptyChunk     = errors.New("chunk contains no blocks")
+	errTruncatedChunk = errors.New("chunk data truncated")
+	errTrailingChunk  = errors.New("chunk has trailing data")
+	errInvalidL1Msgs  = errors.New("block context has more L1 messages than transactions")
+)
+
+// BlockContext is the per-block metadata committed to L1 alongside the
+// transaction payload of a chunk.
+type BlockContext struct {
+	Number          uint64
+	Timestamp       uint64
+	BaseFee         *big.Int
+	GasLimit        uint64
+	NumTransactions uint16
+	NumL1Messages   uint16
+}
+
+// Chunk is a sequence of consecutive L2 blocks committed to L1 as one unit.
+// The L1 messages of a block are not part of the payload, they are the next
+// NumL1Messages entries of the L1 message queue, included ahead of the L2
+// transactions.
+type Chunk struct {
+	Blocks       []*BlockContext
+	Transactions [][]*types.Transaction // L2 transactions of each block
+}
+
+// DecodeChunk parses the L1 calldata encoding of a chunk.
+func DecodeChunk(data []byte) (*Chunk, error) {
+	if len(data) < 1 {
+		return nil, errTruncatedChunk
+	}
+	numBlocks := int(data[0])
+	if numBlocks == 0 {
+		return nil, errEmptyChunk
+	}
+	data = data[1:]
+	if len(data) < numBlocks*blockContextSize {
+		return nil, errTruncatedChunk
+	}
+	chunk := &Chunk{
+		Blocks:       make([]*BlockContext, numBlocks),
+		Transactions: make([][]*types.Transaction, numBlocks),
+	}
+	for i := 0; i < numBlocks; i++ {
+		ctx := decodeBlockContext(data[i*blockContextSize : (i+1)*blockContextSize])
+		if ctx.NumL1Messages > ctx.NumTransactions {
+			return nil, fmt.Errorf("block #%d: %w", ctx.Number, errInvalidL1Msgs)
+		}
+		chunk.Blocks[i] = ctx
+	}
+	data = data[numBlocks*blockContextSize:]
+
+	for i, ctx := range chunk.Blocks {
+		numL2Txs := int(ctx.NumTransactions - ctx.NumL1Messages)
+		txs := make([]*types.Transaction, 0, numL2Txs)
+		for j := 0; j < numL2Txs; j++ {
+			if len(data) < txLengthSize {
+				return nil, errTruncatedChunk
+			}
+			size := binary.BigEndian.Uint32(data[:txLengthSize])
+			data = data[txLengthSize:]
+			if uint64(len(data)) < uint64(size) {
+				return nil, errTruncatedChunk
+			}
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(data[:size]); err != nil {
+				return nil, fmt.Errorf("block #%d, tx %d: %w", ctx.Number, j, err)
+			}
+			txs = append(txs, tx)
+			data = data[size:]
+		}
+		chunk.Transactions[i] = txs
+	}
+	if len(data) != 0 {
+		return nil, errTrailingChunk
+	}
+	return chunk, nil
+}
+
+// Encode serializes the chunk into its L1 calldata encoding.
+func (c *Chunk) Encode() ([]byte, error) {
+	if len(c.Blocks) == 0 || len(c.Blocks) > 255 {
+		return nil, fmt.Errorf("invalid number of blocks in chunk: %d", len(c.Blocks))
+	}
+	if len(c.Blocks) != len(c.Transactions) {
+		return nil, fmt.Errorf("block count mismatch: %d contexts, %d transaction lists", len(c.Blocks), len(c.Transactions))
+	}
+	enc := []byte{byte(len(c.Blocks))}
+	for _, ctx := range c.Blocks {
+		enc = append(enc, ctx.encode()...)
+	}
+	for _, txs := range c.Transactions {
+		for _, tx := range txs {
+			data, err := tx.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			var size [txLengthSize]byte
+			binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+			enc = append(enc, size[:]...)
+			enc = append(enc, data...)
+		}
+	}
+	return enc, nil
+}
+
+func decodeBlockContext(data []byte) *BlockContext {
+	return &BlockContext{
+		Number:          binary.BigEndian.Uint64(data[0:8]),
+		Timestamp:       binary.BigEndian.Uint64(data[8:16]),
+		BaseFee:         new(big.Int).SetBytes(data[16:48]),
+		GasLimit:        binary.BigEndian.Uint64(data[48:56]),
+		NumTransactions: binary.BigEndian.Uint16(data[56:58]),
+		NumL1Messages:   binary.BigEndian.Uint16(data[58:60]),
+	}
+}
+
+func (ctx *BlockContext) encode() []byte {
+	enc := make([]byte, blockContextSize)
+	binary.BigEndian.PutUint64(enc[0:8], ctx.Number)
+	binary.BigEndian.PutUint64(enc[8:16], ctx.Timestamp)
+	if ctx.BaseFee != nil {
+		ctx.BaseFee.FillBytes(enc[16:48])
+	}
+	binary.BigEndian.PutUint64(enc[48:56], ctx.GasLimit)
+	binary.BigEndian.PutUint16(enc[56:58], ctx.NumTransactions)
+	binary.BigEndian.PutUint16(enc[58:60], ctx.NumL1Messages)
+	return enc
+}
+
+// CommittedBatch is a batch of chunks as committed to L1, together with the
+// post-state it claims.
+type CommittedBatch struct {
+	Index        uint64          `json:"index"`
+	Chunks       []hexutil.Bytes `json:"chunks"`
+	StateRoot    common.Hash     `json:"stateRoot"`
+	WithdrawRoot common.Hash     `json:"withdrawRoot"`
+}
+
+// DecodeChunks parses all chunks of the batch.
+func (b *CommittedBatch) DecodeChunks() ([]*Chunk, error) {
+	chunks := make([]*Chunk, len(b.Chunks))
+	for i, data := range b.Chunks {
+		chunk, err := DecodeChunk(data)
+		if err != nil {
+			return nil, fmt.Errorf("batch %d, chunk %d: %w", b.Index, i, err)
+		}
+		chunks[i] = chunk
+	}
+	return chunks, nil
+}
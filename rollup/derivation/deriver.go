@@ -0,0 +1,390 @@
+package derivation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
+)
+
+var (
+	derivedBatchGauge = metrics.NewRegisteredGauge("rollup/derivation/batch", nil)
+	derivedBlockMeter = metrics.NewRegisteredMeter("rollup/derivation/blocks", nil)
+	derivationTimer   = metrics.NewRegisteredTimer("rollup/derivation/time", nil)
+
+	errBatchNotFound      = errors.New("batch not found")
+	errStopped            = errors.New("deriver stopped")
+	errL1MessageNotSynced = errors.New("L1 message not synced yet")
+)
+
+// Config contains the configuration of the L1 derivation pipeline.
+type Config struct {
+	Endpoint     string        `toml:",omitempty"` // RPC endpoint of the L1 batch source, derivation is disabled if empty
+	PollInterval time.Duration `toml:",omitempty"` // Interval between polls for newly committed batches
+}
+
+// DefaultConfig contains the default derivation settings.
+var DefaultConfig = Config{
+	PollInterval: 10 * time.Second,
+}
+
+// BlockSummary describes a single re-executed block of a batch.
+type BlockSummary struct {
+	Number    uint64
+	Hash      common.Hash
+	StateRoot common.Hash
+	GasUsed   uint64
+	Txs       int
+}
+
+// MismatchReport describes a batch whose re-executed post-state disagrees
+// with the state committed on L1.
+type MismatchReport struct {
+	BatchIndex            uint64
+	CommittedRoot         common.Hash
+	ComputedRoot          common.Hash
+	CommittedWithdrawRoot common.Hash
+	ComputedWithdrawRoot  common.Hash
+	Blocks                []BlockSummary
+}
+
+// Error implements error, returning a one line description of the mismatch.
+func (r *MismatchReport) Error() string {
+	if r.CommittedRoot != r.ComputedRoot {
+		return fmt.Sprintf("state root mismatch in batch %d: committed %x, computed %x", r.BatchIndex, r.CommittedRoot, r.ComputedRoot)
+	}
+	return fmt.Sprintf("withdraw root mismatch in batch %d: committed %x, computed %x", r.BatchIndex, r.CommittedWithdrawRoot, r.ComputedWithdrawRoot)
+}
+
+// String returns the detailed, multi-line report of the mismatch.
+func (r *MismatchReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n########## BATCH MISMATCH ##########\n")
+	fmt.Fprintf(&b, "Batch:                   %d\n", r.BatchIndex)
+	fmt.Fprintf(&b, "Committed state root:    %x\n", r.CommittedRoot)
+	fmt.Fprintf(&b, "Computed state root:     %x\n", r.ComputedRoot)
+	fmt.Fprintf(&b, "Committed withdraw root: %x\n", r.CommittedWithdrawRoot)
+	fmt.Fprintf(&b, "Computed withdraw root:  %x\n", r.ComputedWithdrawRoot)
+	fmt.Fprintf(&b, "Re-executed blocks:\n")
+	for _, block := range r.Blocks {
+		fmt.Fprintf(&b, "  #%d %x root=%x gasUsed=%d txs=%d\n", block.Number, block.Hash, block.StateRoot, block.GasUsed, block.Txs)
+	}
+	fmt.Fprintf(&b, "####################################\n")
+	return b.String()
+}
+
+// Deriver follows the batches committed to L1, re-executes their blocks and
+// imports them into the local chain. It halts on the first batch whose
+// re-executed state disagrees with the committed one.
+type Deriver struct {
+	config Config
+	db     ethdb.Database
+	chain  *core.BlockChain
+	source L1Source
+
+	lock sync.RWMutex
+	err  error // Error the deriver halted with, nil while running
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a deriver importing batches from the given L1 source into the
+// chain. The chain must have been created with the derivation Engine.
+func New(config *Config, db ethdb.Database, chain *core.BlockChain, source L1Source) *Deriver {
+	conf := *config
+	if conf.PollInterval <= 0 {
+		log.Warn("Sanitizing invalid derivation poll interval", "provided", conf.PollInterval, "updated", DefaultConfig.PollInterval)
+		conf.PollInterval = DefaultConfig.PollInterval
+	}
+	return &Deriver{
+		config: conf,
+		db:     db,
+		chain:  chain,
+		source: source,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting the derivation loop.
+func (d *Deriver) Start() error {
+	d.wg.Add(1)
+	go d.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the derivation loop.
+func (d *Deriver) Stop() error {
+	close(d.quit)
+	d.wg.Wait()
+	return nil
+}
+
+// Err returns the error the deriver halted with, or nil if it's still running.
+func (d *Deriver) Err() error {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return d.err
+}
+
+func (d *Deriver) loop() {
+	defer d.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-d.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := d.sync(ctx); err != nil {
+				if errors.Is(err, errStopped) {
+					return
+				}
+				d.lock.Lock()
+				d.err = err
+				d.lock.Unlock()
+
+				if report, ok := err.(*MismatchReport); ok {
+					log.Error(report.String())
+				}
+				log.Error("L1 derivation halted", "err", err)
+				return
+			}
+			timer.Reset(d.config.PollInterval)
+
+		case <-d.quit:
+			return
+		}
+	}
+}
+
+// sync derives all batches committed to L1 which haven't been imported yet.
+// Failures to reach the L1 source are retried on the next poll, any returned
+// error is fatal.
+func (d *Deriver) sync(ctx context.Context) error {
+	latest, err := d.source.LatestBatchIndex(ctx)
+	if err != nil {
+		log.Warn("Failed to retrieve latest L1 batch", "err", err)
+		return nil
+	}
+	for {
+		next := uint64(0)
+		if last := rawdb.ReadLastDerivedBatchIndex(d.db); last != nil {
+			next = *last + 1
+		}
+		if next > latest {
+			return nil
+		}
+		batch, err := d.source.BatchByIndex(ctx, next)
+		if err != nil {
+			log.Warn("Failed to retrieve L1 batch", "index", next, "err", err)
+			return nil
+		}
+		if batch.Index != next {
+			return fmt.Errorf("L1 source returned batch %d, requested %d", batch.Index, next)
+		}
+		if err := d.ProcessBatch(batch); err != nil {
+			if errors.Is(err, errL1MessageNotSynced) {
+				// The batch is retried once the L1 message queue caught up
+				log.Warn("Waiting for L1 messages to derive batch", "index", next, "err", err)
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-d.quit:
+			return errStopped
+		default:
+		}
+	}
+}
+
+// ProcessBatch re-executes the blocks of a committed batch on top of the
+// current head and imports them if the resulting state matches the committed
+// one. A *MismatchReport is returned if the states disagree.
+func (d *Deriver) ProcessBatch(batch *CommittedBatch) error {
+	start := time.Now()
+
+	chunks, err := batch.DecodeChunks()
+	if err != nil {
+		return err
+	}
+	blocks, statedb, err := d.buildBlocks(chunks)
+	if err != nil {
+		return fmt.Errorf("batch %d: %w", batch.Index, err)
+	}
+	if len(blocks) == 0 {
+		return fmt.Errorf("batch %d contains no blocks", batch.Index)
+	}
+	last := blocks[len(blocks)-1]
+
+	report := &MismatchReport{
+		BatchIndex:            batch.Index,
+		CommittedRoot:         batch.StateRoot,
+		ComputedRoot:          last.Root(),
+		CommittedWithdrawRoot: batch.WithdrawRoot,
+		ComputedWithdrawRoot:  withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb),
+	}
+	for _, block := range blocks {
+		report.Blocks = append(report.Blocks, BlockSummary{
+			Number:    block.NumberU64(),
+			Hash:      block.Hash(),
+			StateRoot: block.Root(),
+			GasUsed:   block.GasUsed(),
+			Txs:       len(block.Transactions()),
+		})
+	}
+	if report.CommittedRoot != report.ComputedRoot {
+		return report
+	}
+	if report.CommittedWithdrawRoot != (common.Hash{}) && report.CommittedWithdrawRoot != report.ComputedWithdrawRoot {
+		return report
+	}
+	// The batch is consistent with L1, import it through the regular chain
+	// insertion path which verifies and re-executes it once more.
+	if _, err := d.chain.InsertChain(blocks); err != nil {
+		return fmt.Errorf("batch %d: failed to import blocks: %w", batch.Index, err)
+	}
+	rawdb.WriteLastDerivedBatchIndex(d.db, batch.Index)
+	if err := d.chain.SetSafe(last); err != nil {
+		log.Warn("Failed to update safe block", "number", last.NumberU64(), "err", err)
+	}
+	derivedBatchGauge.Update(int64(batch.Index))
+	derivedBlockMeter.Mark(int64(len(blocks)))
+	derivationTimer.UpdateSince(start)
+
+	log.Info("Derived L1 batch", "index", batch.Index, "blocks", len(blocks), "number", last.NumberU64(), "hash", last.Hash(), "root", last.Root(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// pendingChain extends the local chain with the blocks already built from the
+// batch being processed, so that BLOCKHASH can resolve them.
+type pendingChain struct {
+	*core.BlockChain
+	headers map[common.Hash]*types.Header
+}
+
+func (c *pendingChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := c.headers[hash]; ok {
+		return header
+	}
+	return c.BlockChain.GetHeader(hash, number)
+}
+
+// buildBlocks executes the blocks of the given chunks on top of the current
+// head, returning the assembled blocks and the post-state of the last one.
+func (d *Deriver) buildBlocks(chunks []*Chunk) ([]*types.Block, *state.StateDB, error) {
+	var (
+		config = d.chain.Config()
+		engine = d.chain.Engine()
+		chain  = &pendingChain{BlockChain: d.chain, headers: make(map[common.Hash]*types.Header)}
+		parent = d.chain.CurrentBlock()
+		queue  = d.chain.NextL1MessageIndex(parent.Hash())
+		blocks []*types.Block
+	)
+	statedb, err := d.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, chunk := range chunks {
+		for i, ctx := range chunk.Blocks {
+			if ctx.Number != parent.NumberU64()+1 {
+				return nil, nil, fmt.Errorf("non-contiguous block: have #%d, want #%d", ctx.Number, parent.NumberU64()+1)
+			}
+			header := &types.Header{
+				ParentHash: parent.Hash(),
+				Number:     new(big.Int).SetUint64(ctx.Number),
+				GasLimit:   ctx.GasLimit,
+				Time:       ctx.Timestamp,
+			}
+			if config.IsLondon(header.Number) && config.Scroll.BaseFeeEnabled() {
+				header.BaseFee = new(big.Int).Set(ctx.BaseFee)
+			}
+			if err := engine.Prepare(chain, header); err != nil {
+				return nil, nil, err
+			}
+			// The L1 messages of the block are consumed from the queue in order,
+			// ahead of its L2 transactions
+			txs := make([]*types.Transaction, 0, ctx.NumTransactions)
+			l1BlockNumber := parent.Header().L1BlockNumber
+			for j := uint16(0); j < ctx.NumL1Messages; j++ {
+				msg, enqueued := rawdb.ReadL1Message(d.db, queue)
+				if msg == nil {
+					return nil, nil, fmt.Errorf("block #%d: %w: queue index %d", ctx.Number, errL1MessageNotSynced, queue)
+				}
+				if l1BlockNumber == nil || *l1BlockNumber < enqueued {
+					l1BlockNumber = &enqueued
+				}
+				txs = append(txs, types.NewTx(msg))
+				queue++
+			}
+			// Batches don't carry the L1 block number the sequencer committed to,
+			// the latest one the block provably observed is used in its place.
+			if config.IsForcedInclusion(header.Number) {
+				if l1BlockNumber == nil {
+					l1BlockNumber = new(uint64)
+				}
+				header.L1BlockNumber = l1BlockNumber
+				if header.BaseFee == nil {
+					header.BaseFee = new(big.Int)
+				}
+			}
+			txs = append(txs, chunk.Transactions[i]...)
+
+			var (
+				gp       = new(core.GasPool).AddGas(header.GasLimit)
+				receipts = make([]*types.Receipt, 0, len(txs))
+			)
+			for j, tx := range txs {
+				statedb.Prepare(tx.Hash(), j)
+				receipt, err := core.ApplyTransaction(config, chain, &header.Coinbase, gp, statedb, header, tx, &header.GasUsed, *d.chain.GetVMConfig())
+				if err != nil {
+					return nil, nil, fmt.Errorf("block #%d, tx %d [%v]: %w", ctx.Number, j, tx.Hash(), err)
+				}
+				receipts = append(receipts, receipt)
+			}
+			block, err := engine.FinalizeAndAssemble(chain, header, statedb, txs, nil, receipts)
+			if err != nil {
+				return nil, nil, err
+			}
+			// Flush the state into the trie database so the next block can be
+			// built on top of it
+			root, err := statedb.Commit(config.IsEIP158(header.Number))
+			if err != nil {
+				return nil, nil, err
+			}
+			if statedb, err = state.New(root, d.chain.StateCache(), nil); err != nil {
+				return nil, nil, err
+			}
+			chain.headers[block.Hash()] = block.Header()
+			blocks = append(blocks, block)
+			parent = block
+		}
+	}
+	return blocks, statedb, nil
+}
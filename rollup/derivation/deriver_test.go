@@ -0,0 +1,213 @@
+package derivation
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = big.NewInt(1_000_000_000_000_000_000)
+)
+
+func newTestDeriver(t *testing.T) *Deriver {
+	return newTestDeriverWithConfig(t, params.TestNoL1feeChainConfig)
+}
+
+func newTestDeriverWithConfig(t *testing.T, config *params.ChainConfig) *Deriver {
+	db := rawdb.NewMemoryDatabase()
+	gspec := &core.Genesis{
+		Config:   config,
+		GasLimit: 10_000_000,
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+		Alloc:    core.GenesisAlloc{testAddr: {Balance: testBalance}},
+	}
+	gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, NewEngine(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	return New(&DefaultConfig, db, chain, nil)
+}
+
+// makeTestBatch assembles a batch of n blocks on top of the deriver's current
+// head, each transferring some ether to a fresh address.
+func makeTestBatch(t *testing.T, d *Deriver, index uint64, n int) *CommittedBatch {
+	var (
+		config = d.chain.Config()
+		signer = types.LatestSigner(config)
+		parent = d.chain.CurrentBlock().Header()
+		chunk  = new(Chunk)
+		nonce  = d.chain.CurrentBlock().NumberU64()
+	)
+	for i := 0; i < n; i++ {
+		ctx := &BlockContext{
+			Number:          parent.Number.Uint64() + 1,
+			Timestamp:       parent.Time + 3,
+			BaseFee:         misc.CalcBaseFee(config, parent),
+			GasLimit:        parent.GasLimit,
+			NumTransactions: 1,
+		}
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{byte(nonce + 1)}, big.NewInt(1000), params.TxGas, ctx.BaseFee, nil), signer, testKey)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		chunk.Blocks = append(chunk.Blocks, ctx)
+		chunk.Transactions = append(chunk.Transactions, []*types.Transaction{tx})
+
+		// Only the fields the next context depends on are needed
+		parent = &types.Header{
+			Number:   new(big.Int).SetUint64(ctx.Number),
+			Time:     ctx.Timestamp,
+			GasLimit: ctx.GasLimit,
+			GasUsed:  params.TxGas,
+			BaseFee:  ctx.BaseFee,
+		}
+		nonce++
+	}
+	data, err := chunk.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode chunk: %v", err)
+	}
+	return &CommittedBatch{Index: index, Chunks: []hexutil.Bytes{data}}
+}
+
+func TestChunkEncoding(t *testing.T) {
+	d := newTestDeriver(t)
+	batch := makeTestBatch(t, d, 0, 3)
+
+	chunk, err := DecodeChunk(batch.Chunks[0])
+	if err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	if len(chunk.Blocks) != 3 || len(chunk.Transactions) != 3 {
+		t.Fatalf("block count mismatch: have %d/%d, want 3", len(chunk.Blocks), len(chunk.Transactions))
+	}
+	enc, err := chunk.Encode()
+	if err != nil {
+		t.Fatalf("failed to re-encode chunk: %v", err)
+	}
+	if common.Bytes2Hex(enc) != common.Bytes2Hex(batch.Chunks[0]) {
+		t.Fatalf("chunk encoding mismatch after round trip")
+	}
+	// Truncated and padded chunks must be rejected
+	if _, err := DecodeChunk(enc[:len(enc)-1]); err == nil {
+		t.Errorf("truncated chunk decoded")
+	}
+	if _, err := DecodeChunk(append(enc, 0x00)); !errors.Is(err, errTrailingChunk) {
+		t.Errorf("padded chunk: have %v, want %v", err, errTrailingChunk)
+	}
+	if _, err := DecodeChunk([]byte{0}); !errors.Is(err, errEmptyChunk) {
+		t.Errorf("empty chunk: have %v, want %v", err, errEmptyChunk)
+	}
+}
+
+func TestProcessBatch(t *testing.T) {
+	d := newTestDeriver(t)
+
+	// A batch committing to the wrong state must be rejected without touching
+	// the chain
+	batch := makeTestBatch(t, d, 0, 3)
+	batch.StateRoot = common.Hash{0xde, 0xad}
+
+	err := d.ProcessBatch(batch)
+	report, ok := err.(*MismatchReport)
+	if !ok {
+		t.Fatalf("bad batch error mismatch: have %v, want *MismatchReport", err)
+	}
+	if report.BatchIndex != 0 || report.CommittedRoot != batch.StateRoot || len(report.Blocks) != 3 {
+		t.Fatalf("unexpected mismatch report: %v", report)
+	}
+	if head := d.chain.CurrentBlock().NumberU64(); head != 0 {
+		t.Fatalf("chain extended by mismatched batch: head #%d", head)
+	}
+	if index := rawdb.ReadLastDerivedBatchIndex(d.db); index != nil {
+		t.Fatalf("mismatched batch recorded as derived: %d", *index)
+	}
+	// Committing to the computed state should import the batch
+	batch.StateRoot = report.ComputedRoot
+	if err := d.ProcessBatch(batch); err != nil {
+		t.Fatalf("failed to process batch: %v", err)
+	}
+	head := d.chain.CurrentBlock()
+	if head.NumberU64() != 3 || head.Root() != report.ComputedRoot {
+		t.Fatalf("head mismatch: have #%d [%x], want #3 [%x]", head.NumberU64(), head.Root(), report.ComputedRoot)
+	}
+	if safe := d.chain.CurrentSafeBlock(); safe == nil || safe.Hash() != head.Hash() {
+		t.Fatalf("safe block not advanced to derived head")
+	}
+	if index := rawdb.ReadLastDerivedBatchIndex(d.db); index == nil || *index != 0 {
+		t.Fatalf("derived batch index mismatch: have %v, want 0", index)
+	}
+	state, _ := d.chain.State()
+	if nonce := state.GetNonce(testAddr); nonce != 3 {
+		t.Fatalf("sender nonce mismatch: have %d, want 3", nonce)
+	}
+}
+
+func TestProcessBatchL1Messages(t *testing.T) {
+	config := *params.TestNoL1feeChainConfig
+	config.Scroll.L1Config = &params.L1Config{}
+	d := newTestDeriverWithConfig(t, &config)
+
+	// Prepend two L1 messages to the transfer of the last block, so that the
+	// base fee of the blocks doesn't change
+	batch := makeTestBatch(t, d, 0, 2)
+	chunk, err := DecodeChunk(batch.Chunks[0])
+	if err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	chunk.Blocks[1].NumTransactions += 2
+	chunk.Blocks[1].NumL1Messages = 2
+	data, err := chunk.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode chunk: %v", err)
+	}
+	batch.Chunks[0] = data
+
+	// The batch must wait for the messages to be synced from L1
+	if err := d.ProcessBatch(batch); !errors.Is(err, errL1MessageNotSynced) {
+		t.Fatalf("unsynced messages: error mismatch: have %v, want %v", err, errL1MessageNotSynced)
+	}
+	msgs := []*types.L1MessageTx{
+		{QueueIndex: 0, Gas: params.TxGas, To: &common.Address{1}, Value: new(big.Int), Sender: common.Address{0xaa}},
+		{QueueIndex: 1, Gas: params.TxGas, To: &common.Address{2}, Value: new(big.Int), Sender: common.Address{0xaa}},
+	}
+	for _, msg := range msgs {
+		rawdb.WriteL1Message(d.db, msg, 1)
+	}
+	err = d.ProcessBatch(batch)
+	report, ok := err.(*MismatchReport)
+	if !ok {
+		t.Fatalf("error mismatch: have %v, want *MismatchReport", err)
+	}
+	batch.StateRoot = report.ComputedRoot
+	if err := d.ProcessBatch(batch); err != nil {
+		t.Fatalf("failed to process batch: %v", err)
+	}
+	head := d.chain.CurrentBlock()
+	if head.NumberU64() != 2 {
+		t.Fatalf("head mismatch: have #%d, want #2", head.NumberU64())
+	}
+	txs := head.Transactions()
+	if len(txs) != 3 || txs[0].Hash() != types.NewTx(msgs[0]).Hash() || txs[1].Hash() != types.NewTx(msgs[1]).Hash() || txs[2].IsL1MessageTx() {
+		t.Fatalf("transactions mismatch: have %v", txs)
+	}
+	if next := d.chain.NextL1MessageIndex(head.Hash()); next != 2 {
+		t.Fatalf("next queue index mismatch: have %d, want 2", next)
+	}
+}
@@ -0,0 +1,157 @@
+package derivation
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus"
+	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+var (
+	// derivedDifficulty is the difficulty of every derived block. Derived blocks
+	// form a single canonical chain, so the total difficulty equals the height.
+	derivedDifficulty = big.NewInt(1)
+
+	errNoUncles         = errors.New("derived blocks cannot have uncles")
+	errInvalidTimestamp = errors.New("timestamp older than parent")
+	errSealUnsupported  = errors.New("derived blocks cannot be sealed")
+)
+
+// Engine is the consensus engine of a verifier node. Blocks derived from L1
+// carry no seal: their validity stems from the batch committed to L1, so the
+// engine only enforces the structural header rules.
+type Engine struct{}
+
+// NewEngine creates a consensus engine for L1-derived blocks.
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+// Author implements consensus.Engine, returning the header's coinbase.
+func (e *Engine) Author(header *types.Header) (common.Address, error) {
+	return header.Coinbase, nil
+}
+
+// VerifyHeader implements consensus.Engine.
+func (e *Engine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return e.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders implements consensus.Engine, verifying the headers sequentially.
+func (e *Engine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := e.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+func (e *Engine) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errors.New("missing block number")
+	}
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if header.Time < parent.Time {
+		return errInvalidTimestamp
+	}
+	if header.UncleHash != types.EmptyUncleHash {
+		return errNoUncles
+	}
+	if cap := uint64(0x7fffffffffffffff); header.GasLimit > cap {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, cap)
+	}
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if !chain.Config().IsLondon(header.Number) {
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
+		}
+		return misc.VerifyGaslimit(parent.GasLimit, header.GasLimit)
+	}
+	return misc.VerifyEip1559Header(chain.Config(), parent, header)
+}
+
+// VerifyUncles implements consensus.Engine, always returning an error for any
+// uncles as derived blocks never have them.
+func (e *Engine) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errNoUncles
+	}
+	return nil
+}
+
+// Prepare implements consensus.Engine, setting the difficulty of the header.
+func (e *Engine) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	header.Difficulty = new(big.Int).Set(derivedDifficulty)
+	return nil
+}
+
+// Finalize implements consensus.Engine. There are no block rewards on L2, so
+// only the state root is computed and uncles are dropped.
+func (e *Engine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+}
+
+// FinalizeAndAssemble implements consensus.Engine, returning the final block.
+func (e *Engine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	e.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Seal implements consensus.Engine. Verifier nodes never produce blocks.
+func (e *Engine) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	return errSealUnsupported
+}
+
+// SealHash implements consensus.Engine, returning the plain header hash as
+// there is no seal.
+func (e *Engine) SealHash(header *types.Header) common.Hash {
+	return header.Hash()
+}
+
+// CalcDifficulty implements consensus.Engine.
+func (e *Engine) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(derivedDifficulty)
+}
+
+// APIs implements consensus.Engine, returning no APIs.
+func (e *Engine) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return nil
+}
+
+// Close implements consensus.Engine. It's a noop as there are no background
+// threads.
+func (e *Engine) Close() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package derivation
+
+import (
+	"context"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// L1Source provides access to the batches committed to the rollup contract
+// on L1.
+type L1Source interface {
+	// LatestBatchIndex returns the index of the most recently committed batch.
+	LatestBatchIndex(ctx context.Context) (uint64, error)
+
+	// BatchByIndex returns the committed batch with the given index.
+	BatchByIndex(ctx context.Context, index uint64) (*CommittedBatch, error)
+}
+
+// rpcSource is an L1Source backed by a JSON-RPC endpoint serving the batches
+// committed to L1 (e.g. an L1 follower or a local stand-in).
+type rpcSource struct {
+	client *rpc.Client
+}
+
+// NewRPCSource creates an L1 source on top of an RPC client.
+func NewRPCSource(client *rpc.Client) L1Source {
+	return &rpcSource{client: client}
+}
+
+func (s *rpcSource) LatestBatchIndex(ctx context.Context) (uint64, error) {
+	var index hexutil.Uint64
+	if err := s.client.CallContext(ctx, &index, "l1_latestBatchIndex"); err != nil {
+		return 0, err
+	}
+	return uint64(index), nil
+}
+
+func (s *rpcSource) BatchByIndex(ctx context.Context, index uint64) (*CommittedBatch, error) {
+	var batch *CommittedBatch
+	if err := s.client.CallContext(ctx, &batch, "l1_getBatchByIndex", hexutil.Uint64(index)); err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, errBatchNotFound
+	}
+	return batch, nil
+}
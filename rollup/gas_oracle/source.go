@@ -0,0 +1,42 @@
+package gas_oracle
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// L1Source provides the current base fee of the L1 chain.
+type L1Source interface {
+	// BaseFee returns the base fee of the latest L1 block.
+	BaseFee(ctx context.Context) (*big.Int, error)
+}
+
+// rpcSource is an L1Source backed by the JSON-RPC endpoint of an L1 node.
+type rpcSource struct {
+	client *rpc.Client
+}
+
+// NewRPCSource creates an L1 source on top of an L1 node's RPC client.
+func NewRPCSource(client *rpc.Client) L1Source {
+	return &rpcSource{client: client}
+}
+
+func (s *rpcSource) BaseFee(ctx context.Context) (*big.Int, error) {
+	var header *struct {
+		BaseFee *hexutil.Big `json:"baseFeePerGas"`
+	}
+	if err := s.client.CallContext(ctx, &header, "eth_getBlockByNumber", "latest", false); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("L1 block not found")
+	}
+	if header.BaseFee == nil {
+		return nil, errors.New("L1 block has no base fee")
+	}
+	return (*big.Int)(header.BaseFee), nil
+}
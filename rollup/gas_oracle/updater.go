@@ -0,0 +1,216 @@
+package gas_oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// updateGasLimit is the gas limit of a single base fee update transaction.
+const updateGasLimit = 100_000
+
+// setL1BaseFeeSelector is the selector of L1GasPriceOracle.setL1BaseFee(uint256).
+var setL1BaseFeeSelector = crypto.Keccak256([]byte("setL1BaseFee(uint256)"))[:4]
+
+var (
+	l1BaseFeeGauge      = metrics.NewRegisteredGauge("rollup/gasoracle/l1basefee", nil)
+	onchainBaseFeeGauge = metrics.NewRegisteredGauge("rollup/gasoracle/onchain", nil)
+	updateMeter         = metrics.NewRegisteredMeter("rollup/gasoracle/updates", nil)
+	failureMeter        = metrics.NewRegisteredMeter("rollup/gasoracle/failures", nil)
+)
+
+// Config contains the configuration of the gas price oracle updater.
+type Config struct {
+	Endpoint     string         `toml:",omitempty"` // RPC endpoint of the L1 node, updating is disabled if empty
+	Account      common.Address `toml:",omitempty"` // Keystore account signing the update transactions
+	PollInterval time.Duration  `toml:",omitempty"` // Interval between L1 base fee polls
+	Threshold    uint64         `toml:",omitempty"` // Minimum deviation from the on-chain value to update, in percent
+	MaxChange    uint64         `toml:",omitempty"` // Maximum change of the on-chain value per update in percent, 0 for unlimited
+	DryRun       bool           `toml:",omitempty"` // Log the updates instead of submitting them
+}
+
+// DefaultConfig contains the default gas price oracle updater settings.
+var DefaultConfig = Config{
+	PollInterval: 30 * time.Second,
+	Threshold:    5,
+	MaxChange:    25,
+}
+
+// Chain is the part of the L2 chain the updater reads the oracle state from.
+type Chain interface {
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// TxPool is the part of the transaction pool the updates are submitted to.
+type TxPool interface {
+	Nonce(addr common.Address) uint64
+	AddLocal(tx *types.Transaction) error
+}
+
+// SignerFn signs an update transaction with the configured account.
+type SignerFn func(account common.Address, tx *types.Transaction) (*types.Transaction, error)
+
+// Updater keeps the L1 base fee stored in the L1GasPriceOracle predeploy in
+// line with the base fee observed on L1.
+type Updater struct {
+	config Config
+	chain  Chain
+	pool   TxPool
+	source L1Source
+	signer SignerFn
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a gas price oracle updater following the given L1 source.
+func New(config *Config, chain Chain, pool TxPool, source L1Source, signer SignerFn) *Updater {
+	conf := *config
+	if conf.PollInterval <= 0 {
+		log.Warn("Sanitizing invalid gas oracle poll interval", "provided", conf.PollInterval, "updated", DefaultConfig.PollInterval)
+		conf.PollInterval = DefaultConfig.PollInterval
+	}
+	return &Updater{
+		config: conf,
+		chain:  chain,
+		pool:   pool,
+		source: source,
+		signer: signer,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting the update loop.
+func (u *Updater) Start() error {
+	u.wg.Add(1)
+	go u.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the update loop.
+func (u *Updater) Stop() error {
+	close(u.quit)
+	u.wg.Wait()
+	return nil
+}
+
+func (u *Updater) loop() {
+	defer u.wg.Done()
+
+	ticker := time.NewTicker(u.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), u.config.PollInterval)
+			if err := u.Update(ctx); err != nil {
+				failureMeter.Mark(1)
+				log.Warn("Failed to update L1 base fee", "err", err)
+			}
+			cancel()
+
+		case <-u.quit:
+			return
+		}
+	}
+}
+
+// Update polls the L1 base fee and submits an update to the oracle if the
+// on-chain value deviates too much from it.
+func (u *Updater) Update(ctx context.Context) error {
+	observed, err := u.source.BaseFee(ctx)
+	if err != nil {
+		return err
+	}
+	l1BaseFeeGauge.Update(observed.Int64())
+
+	head := u.chain.CurrentBlock()
+	statedb, err := u.chain.StateAt(head.Root())
+	if err != nil {
+		return err
+	}
+	current := statedb.GetState(rcfg.L1GasPriceOracleAddress, rcfg.L1BaseFeeSlot).Big()
+	onchainBaseFeeGauge.Update(current.Int64())
+
+	target := nextBaseFee(current, observed, u.config.Threshold, u.config.MaxChange)
+	if target == nil {
+		return nil
+	}
+	// Don't stack updates on top of a previous one which is still pending
+	nonce := u.pool.Nonce(u.config.Account)
+	if nonce != statedb.GetNonce(u.config.Account) {
+		log.Debug("Previous L1 base fee update pending", "nonce", nonce)
+		return nil
+	}
+	if u.config.DryRun {
+		log.Info("Skipping L1 base fee update in dry-run mode", "current", current, "observed", observed, "target", target)
+		return nil
+	}
+	gasPrice := new(big.Int)
+	if head.BaseFee() != nil {
+		gasPrice.Mul(head.BaseFee(), common.Big2)
+	}
+	data := append(common.CopyBytes(setL1BaseFeeSelector), common.BigToHash(target).Bytes()...)
+	tx := types.NewTransaction(nonce, rcfg.L1GasPriceOracleAddress, new(big.Int), updateGasLimit, gasPrice, data)
+
+	if u.signer == nil {
+		return errors.New("no signer configured")
+	}
+	signed, err := u.signer(u.config.Account, tx)
+	if err != nil {
+		return fmt.Errorf("failed to sign update: %w", err)
+	}
+	if err := u.pool.AddLocal(signed); err != nil {
+		return fmt.Errorf("failed to submit update: %w", err)
+	}
+	updateMeter.Mark(1)
+	log.Info("Submitted L1 base fee update", "current", current, "observed", observed, "target", target, "hash", signed.Hash())
+	return nil
+}
+
+// nextBaseFee returns the base fee to store in the oracle given the current
+// on-chain and the observed L1 value, or nil if the deviation is within the
+// threshold. Each update changes the on-chain value by at most maxChange
+// percent, spreading sudden L1 spikes over multiple updates.
+func nextBaseFee(current, observed *big.Int, threshold, maxChange uint64) *big.Int {
+	// An unset oracle is initialized right away
+	if current.Sign() == 0 {
+		if observed.Sign() == 0 {
+			return nil
+		}
+		return new(big.Int).Set(observed)
+	}
+	diff := new(big.Int).Sub(observed, current)
+	deviation := new(big.Int).Abs(diff)
+	deviation.Mul(deviation, big.NewInt(100))
+	if deviation.Cmp(new(big.Int).Mul(current, new(big.Int).SetUint64(threshold))) <= 0 {
+		return nil
+	}
+	if maxChange > 0 {
+		limit := new(big.Int).Mul(current, new(big.Int).SetUint64(maxChange))
+		limit.Div(limit, big.NewInt(100))
+		if diff.CmpAbs(limit) > 0 {
+			if diff.Sign() > 0 {
+				diff.Set(limit)
+			} else {
+				diff.Neg(limit)
+			}
+		}
+	}
+	return diff.Add(diff, current)
+}
@@ -0,0 +1,109 @@
+package gas_oracle
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+func TestNextBaseFee(t *testing.T) {
+	tests := []struct {
+		current, observed int64
+		want              *big.Int
+	}{
+		{0, 0, nil},                 // nothing to initialize with
+		{0, 1000, big.NewInt(1000)}, // unset oracle is initialized right away
+		{1000, 1050, nil},           // within the threshold
+		{1000, 950, nil},            // within the threshold
+		{1000, 1100, big.NewInt(1100)},
+		{1000, 900, big.NewInt(900)},
+		{1000, 5000, big.NewInt(1250)}, // spike spread over multiple updates
+		{1000, 100, big.NewInt(750)},
+	}
+	for i, tt := range tests {
+		have := nextBaseFee(big.NewInt(tt.current), big.NewInt(tt.observed), 5, 25)
+		if (have == nil) != (tt.want == nil) || (have != nil && have.Cmp(tt.want) != 0) {
+			t.Errorf("test %d: base fee mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+type testSource struct{ baseFee *big.Int }
+
+func (s *testSource) BaseFee(ctx context.Context) (*big.Int, error) { return s.baseFee, nil }
+
+type testChain struct{ statedb *state.StateDB }
+
+func (c *testChain) Config() *params.ChainConfig { return params.TestChainConfig }
+func (c *testChain) CurrentBlock() *types.Block {
+	return types.NewBlockWithHeader(&types.Header{Number: common.Big1, BaseFee: big.NewInt(params.InitialBaseFee)})
+}
+func (c *testChain) StateAt(root common.Hash) (*state.StateDB, error) { return c.statedb, nil }
+
+type testPool struct{ txs []*types.Transaction }
+
+func (p *testPool) Nonce(addr common.Address) uint64 { return uint64(len(p.txs)) }
+func (p *testPool) AddLocal(tx *types.Transaction) error {
+	p.txs = append(p.txs, tx)
+	return nil
+}
+
+func TestUpdate(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.TestChainConfig)
+		sign   = func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return types.SignTx(tx, signer, key)
+		}
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		source     = &testSource{baseFee: big.NewInt(5000)}
+		pool       = new(testPool)
+		config     = DefaultConfig
+	)
+	config.Account = addr
+	statedb.SetState(rcfg.L1GasPriceOracleAddress, rcfg.L1BaseFeeSlot, common.BigToHash(big.NewInt(1000)))
+	updater := New(&config, &testChain{statedb}, pool, source, sign)
+
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if len(pool.txs) != 1 {
+		t.Fatalf("update count mismatch: have %d, want 1", len(pool.txs))
+	}
+	tx := pool.txs[0]
+	if *tx.To() != rcfg.L1GasPriceOracleAddress {
+		t.Errorf("update sent to wrong address: %x", tx.To())
+	}
+	want := append(common.CopyBytes(setL1BaseFeeSelector), common.BigToHash(big.NewInt(1250)).Bytes()...)
+	if common.Bytes2Hex(tx.Data()) != common.Bytes2Hex(want) {
+		t.Errorf("update calldata mismatch: have %x, want %x", tx.Data(), want)
+	}
+	if from, _ := types.Sender(signer, tx); from != addr {
+		t.Errorf("update signer mismatch: have %x, want %x", from, addr)
+	}
+	// The first update is still pending, another one must not be stacked on top
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if len(pool.txs) != 1 {
+		t.Fatalf("update submitted while another is pending")
+	}
+	// Dry-run mode must not submit anything
+	statedb.SetNonce(addr, 1)
+	updater.config.DryRun = true
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if len(pool.txs) != 1 {
+		t.Fatalf("update submitted in dry-run mode")
+	}
+}
@@ -0,0 +1,83 @@
+package sync_service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// EnqueuedMessage is an L1 message along with the L1 block it was enqueued in.
+type EnqueuedMessage struct {
+	L1BlockNumber uint64
+	Message       *types.L1MessageTx
+}
+
+// L1Source provides access to the messages enqueued in the L1 message queue.
+type L1Source interface {
+	// BlockNumber returns the number of the latest L1 block.
+	BlockNumber(ctx context.Context) (uint64, error)
+
+	// MessagesInRange returns the messages enqueued within the given range of
+	// L1 blocks (both inclusive), ordered by queue index.
+	MessagesInRange(ctx context.Context, from, to uint64) ([]*EnqueuedMessage, error)
+}
+
+// rpcMessage is the JSON-RPC representation of an enqueued L1 message.
+type rpcMessage struct {
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	QueueIndex  hexutil.Uint64  `json:"queueIndex"`
+	Sender      common.Address  `json:"sender"`
+	Target      *common.Address `json:"target"`
+	Value       *hexutil.Big    `json:"value"`
+	GasLimit    hexutil.Uint64  `json:"gasLimit"`
+	Data        hexutil.Bytes   `json:"data"`
+}
+
+// rpcSource is an L1Source backed by a JSON-RPC endpoint serving the messages
+// enqueued on L1 (e.g. an L1 follower or a local stand-in).
+type rpcSource struct {
+	client *rpc.Client
+}
+
+// NewRPCSource creates an L1 source on top of an RPC client.
+func NewRPCSource(client *rpc.Client) L1Source {
+	return &rpcSource{client: client}
+}
+
+func (s *rpcSource) BlockNumber(ctx context.Context) (uint64, error) {
+	var number hexutil.Uint64
+	if err := s.client.CallContext(ctx, &number, "l1_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(number), nil
+}
+
+func (s *rpcSource) MessagesInRange(ctx context.Context, from, to uint64) ([]*EnqueuedMessage, error) {
+	var res []*rpcMessage
+	if err := s.client.CallContext(ctx, &res, "l1_getMessagesInRange", hexutil.Uint64(from), hexutil.Uint64(to)); err != nil {
+		return nil, err
+	}
+	msgs := make([]*EnqueuedMessage, len(res))
+	for i, msg := range res {
+		value := new(big.Int)
+		if msg.Value != nil {
+			value = (*big.Int)(msg.Value)
+		}
+		msgs[i] = &EnqueuedMessage{
+			L1BlockNumber: uint64(msg.BlockNumber),
+			Message: &types.L1MessageTx{
+				QueueIndex: uint64(msg.QueueIndex),
+				Gas:        uint64(msg.GasLimit),
+				To:         msg.Target,
+				Value:      value,
+				Data:       msg.Data,
+				Sender:     msg.Sender,
+			},
+		}
+	}
+	return msgs, nil
+}
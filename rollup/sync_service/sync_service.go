@@ -0,0 +1,171 @@
+package sync_service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// fetchBlockRange is the number of L1 blocks whose messages are retrieved in
+// a single request.
+const fetchBlockRange = 1000
+
+var (
+	syncedL1BlockGauge = metrics.NewRegisteredGauge("rollup/l1sync/height", nil)
+	syncedQueueGauge   = metrics.NewRegisteredGauge("rollup/l1sync/queueindex", nil)
+)
+
+// Config contains the configuration of the L1 message sync.
+type Config struct {
+	Endpoint      string        `toml:",omitempty"` // RPC endpoint of the L1 message source, syncing is disabled if empty
+	PollInterval  time.Duration `toml:",omitempty"` // Interval between polls for newly enqueued messages
+	Confirmations uint64        `toml:",omitempty"` // Number of L1 blocks a message must be buried under before syncing
+}
+
+// DefaultConfig contains the default L1 message sync settings.
+var DefaultConfig = Config{
+	PollInterval:  10 * time.Second,
+	Confirmations: 6,
+}
+
+// SyncService follows the L1 message queue and stores the enqueued messages
+// locally, for the sequencer to include and for block validation to check.
+type SyncService struct {
+	config Config
+	db     ethdb.Database
+	source L1Source
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a sync service storing the messages of the given L1 source.
+func New(config *Config, db ethdb.Database, source L1Source) *SyncService {
+	conf := *config
+	if conf.PollInterval <= 0 {
+		log.Warn("Sanitizing invalid L1 sync poll interval", "provided", conf.PollInterval, "updated", DefaultConfig.PollInterval)
+		conf.PollInterval = DefaultConfig.PollInterval
+	}
+	return &SyncService{
+		config: conf,
+		db:     db,
+		source: source,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting the sync loop.
+func (s *SyncService) Start() error {
+	s.wg.Add(1)
+	go s.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the sync loop.
+func (s *SyncService) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+func (s *SyncService) loop() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := s.Sync(ctx); err != nil {
+				log.Warn("Failed to sync L1 messages", "err", err)
+			}
+			timer.Reset(s.config.PollInterval)
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// Sync stores all messages enqueued in confirmed L1 blocks which haven't been
+// synced yet.
+func (s *SyncService) Sync(ctx context.Context) error {
+	latest, err := s.source.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if latest < s.config.Confirmations {
+		return nil
+	}
+	target := latest - s.config.Confirmations
+
+	from := uint64(0)
+	if synced := rawdb.ReadSyncedL1BlockNumber(s.db); synced != nil {
+		from = *synced + 1
+	}
+	next := uint64(0)
+	if last := rawdb.ReadLastL1MessageQueueIndex(s.db); last != nil {
+		next = *last + 1
+	}
+	for from <= target {
+		to := from + fetchBlockRange - 1
+		if to > target {
+			to = target
+		}
+		msgs, err := s.source.MessagesInRange(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		// Store the messages along with the sync progress atomically, so a
+		// crash can't leave a gap in the local queue
+		batch := s.db.NewBatch()
+		for _, msg := range msgs {
+			if msg.Message.QueueIndex != next {
+				return fmt.Errorf("L1 message queue gap: have index %d, want %d", msg.Message.QueueIndex, next)
+			}
+			if msg.L1BlockNumber < from || msg.L1BlockNumber > to {
+				return fmt.Errorf("L1 message %d enqueued in block %d, outside of range [%d, %d]", next, msg.L1BlockNumber, from, to)
+			}
+			rawdb.WriteL1Message(batch, msg.Message, msg.L1BlockNumber)
+			next++
+		}
+		if len(msgs) > 0 {
+			rawdb.WriteLastL1MessageQueueIndex(batch, next-1)
+		}
+		rawdb.WriteSyncedL1BlockNumber(batch, to)
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		syncedL1BlockGauge.Update(int64(to))
+		syncedQueueGauge.Update(int64(next))
+
+		if len(msgs) > 0 {
+			log.Info("Synced L1 messages", "count", len(msgs), "next", next, "l1block", to)
+		}
+		from = to + 1
+
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+	}
+	return nil
+}
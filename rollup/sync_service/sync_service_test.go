@@ -0,0 +1,97 @@
+package sync_service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// testSource is an in-memory L1Source.
+type testSource struct {
+	head uint64
+	msgs []*EnqueuedMessage
+}
+
+func (s *testSource) BlockNumber(ctx context.Context) (uint64, error) {
+	return s.head, nil
+}
+
+func (s *testSource) MessagesInRange(ctx context.Context, from, to uint64) ([]*EnqueuedMessage, error) {
+	var msgs []*EnqueuedMessage
+	for _, msg := range s.msgs {
+		if msg.L1BlockNumber >= from && msg.L1BlockNumber <= to {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+func (s *testSource) enqueue(l1Block uint64) {
+	s.msgs = append(s.msgs, &EnqueuedMessage{
+		L1BlockNumber: l1Block,
+		Message: &types.L1MessageTx{
+			QueueIndex: uint64(len(s.msgs)),
+			Gas:        21000,
+			To:         &common.Address{byte(len(s.msgs))},
+			Value:      big.NewInt(1),
+			Sender:     common.Address{0xaa},
+		},
+	})
+}
+
+func TestSync(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		source = &testSource{head: 1500}
+		config = Config{PollInterval: DefaultConfig.PollInterval, Confirmations: 6}
+		s      = New(&config, db, source)
+	)
+	source.enqueue(10)
+	source.enqueue(10)
+	source.enqueue(1200) // spans multiple fetch ranges
+	source.enqueue(1497) // not confirmed yet
+
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if synced := rawdb.ReadSyncedL1BlockNumber(db); synced == nil || *synced != 1494 {
+		t.Fatalf("synced L1 block mismatch: have %v, want 1494", synced)
+	}
+	if last := rawdb.ReadLastL1MessageQueueIndex(db); last == nil || *last != 2 {
+		t.Fatalf("last queue index mismatch: have %v, want 2", last)
+	}
+	for _, want := range source.msgs[:3] {
+		msg, l1Block := rawdb.ReadL1Message(db, want.Message.QueueIndex)
+		if msg == nil {
+			t.Fatalf("message %d missing", want.Message.QueueIndex)
+		}
+		if types.NewTx(msg).Hash() != types.NewTx(want.Message).Hash() || l1Block != want.L1BlockNumber {
+			t.Fatalf("message %d mismatch", want.Message.QueueIndex)
+		}
+	}
+	if msg, _ := rawdb.ReadL1Message(db, 3); msg != nil {
+		t.Fatalf("unconfirmed message synced")
+	}
+	// Once confirmed, only the new message should be fetched
+	source.head = 1510
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if last := rawdb.ReadLastL1MessageQueueIndex(db); last == nil || *last != 3 {
+		t.Fatalf("last queue index mismatch: have %v, want 3", last)
+	}
+	// A gap in the queue must be rejected without recording progress
+	source.enqueue(1505)
+	source.msgs[4].Message.QueueIndex = 5
+	source.head = 1520
+	if err := s.Sync(context.Background()); err == nil {
+		t.Fatalf("queue gap accepted")
+	}
+	if synced := rawdb.ReadSyncedL1BlockNumber(db); synced == nil || *synced != 1504 {
+		t.Fatalf("synced L1 block advanced past gap: have %v, want 1504", synced)
+	}
+}
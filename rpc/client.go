@@ -82,6 +82,7 @@ type Client struct {
 	idgen    func() ID // for subscriptions
 	scheme   string    // connection type: http, ws or ipc
 	services *serviceRegistry
+	limits   *requestLimits // limits on requests served to the remote side
 
 	idCounter uint32
 
@@ -120,7 +121,7 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	if !c.isHTTP() && c.scheme != "" {
 		ctx = context.WithValue(ctx, "scheme", c.scheme)
 	}
-	handler := newHandler(ctx, conn, c.idgen, c.services)
+	handler := newHandler(ctx, conn, c.idgen, c.services, c.limits)
 	return &clientConn{conn, handler}
 }
 
@@ -206,12 +207,12 @@ func newClient(initctx context.Context, connect reconnectFunc) (*Client, error)
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), new(serviceRegistry))
+	c := initClient(conn, randomIDGenerator(), new(serviceRegistry), new(requestLimits))
 	c.reconnectFunc = connect
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *Client {
+func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, limits *requestLimits) *Client {
 	scheme := ""
 	switch conn.(type) {
 	case *httpConn:
@@ -225,6 +226,7 @@ func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *C
 		idgen:       idgen,
 		scheme:      scheme,
 		services:    services,
+		limits:      limits,
 		writeConn:   conn,
 		close:       make(chan struct{}),
 		closing:     make(chan struct{}),
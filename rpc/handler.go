@@ -19,12 +19,15 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"github.com/scroll-tech/go-ethereum/log"
 )
 
@@ -61,6 +64,8 @@ type handler struct {
 	conn           jsonWriter                     // where responses will be sent
 	log            log.Logger
 	allowSubscribe bool
+	limits         *requestLimits // limits on the served requests
+	limiter        *rate.Limiter  // rate limiter of the connection, nil if unlimited
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -71,7 +76,7 @@ type callProc struct {
 	notifiers []*Notifier
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, limits *requestLimits) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
 	h := &handler{
 		reg:            reg,
@@ -82,6 +87,8 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		rootCtx:        rootCtx,
 		cancelRoot:     cancelRoot,
 		allowSubscribe: true,
+		limits:         limits,
+		limiter:        limits.newLimiter(),
 		serverSubs:     make(map[ID]*Subscription),
 		log:            log.Root(),
 	}
@@ -101,6 +108,13 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		})
 		return
 	}
+	// Reject batches with too many items before processing any of them:
+	if limit := h.limits.batchItemLimit; limit != 0 && len(msgs) > limit {
+		h.startCallProc(func(cp *callProc) {
+			h.respondWithBatchTooLarge(cp, msgs)
+		})
+		return
+	}
 
 	// Handle non-call messages first:
 	calls := make([]*jsonrpcMessage, 0, len(msgs))
@@ -114,11 +128,30 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
-		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
-				answers = append(answers, answer)
+		var (
+			answers = make([]*jsonrpcMessage, 0, len(msgs))
+			size    int
+		)
+		for i, msg := range calls {
+			answer := h.handleCallMsg(cp, msg)
+			if answer == nil {
+				continue
+			}
+			// Drop the answer exceeding the response size limit and stop executing
+			// the remaining calls. Errors are counted like results, as their data
+			// may be just as large.
+			if limit := h.limits.batchResponseMaxSize; limit != 0 {
+				size += answer.encodedSize()
+				if size > limit {
+					for _, msg := range calls[i:] {
+						if msg.isCall() {
+							answers = append(answers, msg.errorResponse(&responseTooLargeError{}))
+						}
+					}
+					break
+				}
 			}
+			answers = append(answers, answer)
 		}
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
@@ -130,6 +163,19 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	})
 }
 
+// respondWithBatchTooLarge sends an error for a batch exceeding the item limit. The
+// error carries the ID of the first call in the batch.
+func (h *handler) respondWithBatchTooLarge(cp *callProc, batch []*jsonrpcMessage) {
+	resp := errorMessage(&invalidRequestError{"batch too large"})
+	for _, msg := range batch {
+		if msg.isCall() {
+			resp.ID = msg.ID
+			break
+		}
+	}
+	h.conn.writeJSON(cp.ctx, []*jsonrpcMessage{resp})
+}
+
 // handleMsg handles a single message.
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	if ok := h.handleImmediate(msg); ok {
@@ -317,6 +363,9 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if h.limiter != nil && !h.limiter.Allow() {
+		return msg.errorResponse(&limitExceededError{"rate limit exceeded"})
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -333,6 +382,12 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
+	release, ok := h.limits.acquire(msg.Method)
+	if !ok {
+		return msg.errorResponse(&limitExceededError{fmt.Sprintf("too many concurrent %s requests", msg.Method)})
+	}
+	defer release()
+
 	start := time.Now()
 	answer := h.runMethod(cp.ctx, msg, callb, args)
 
@@ -91,6 +91,12 @@ func (msg *jsonrpcMessage) String() string {
 	return string(b)
 }
 
+// encodedSize returns the size of the message as it's written out.
+func (msg *jsonrpcMessage) encodedSize() int {
+	b, _ := json.Marshal(msg)
+	return len(b)
+}
+
 func (msg *jsonrpcMessage) errorResponse(err error) *jsonrpcMessage {
 	resp := errorMessage(err)
 	resp.ID = msg.ID
@@ -0,0 +1,108 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"math"
+	"net"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"golang.org/x/time/rate"
+)
+
+// httpLimiterCacheSize is the number of HTTP clients whose rate limiters are
+// remembered by a server.
+const httpLimiterCacheSize = 4096
+
+// requestLimits holds the limits a Server enforces on incoming requests. The zero
+// value doesn't limit anything.
+type requestLimits struct {
+	batchItemLimit       int                      // maximum number of requests in a batch
+	batchResponseMaxSize int                      // maximum number of encoded response bytes of a batch
+	methods              map[string]chan struct{} // semaphores of the concurrency-limited methods
+	rate                 rate.Limit               // requests per second allowed per connection
+	burst                int                      // requests allowed at once per connection
+
+	httpLock     sync.Mutex
+	httpLimiters *simplelru.LRU // rate limiters of HTTP clients, by remote IP
+}
+
+// newLimiter creates the rate limiter of a connection, or nil if requests are
+// not rate limited.
+func (l *requestLimits) newLimiter() *rate.Limiter {
+	if l.rate == 0 {
+		return nil
+	}
+	return rate.NewLimiter(l.rate, l.burst)
+}
+
+// httpLimiter returns the rate limiter of the HTTP client with the given remote
+// address. HTTP requests are served by short-lived handlers, so the limiters are
+// kept across requests. They are keyed by IP, as a client can open any number of
+// connections from different ports.
+func (l *requestLimits) httpLimiter(remote string) *rate.Limiter {
+	if l.rate == 0 || remote == "" {
+		return l.newLimiter()
+	}
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	l.httpLock.Lock()
+	defer l.httpLock.Unlock()
+
+	if l.httpLimiters == nil {
+		l.httpLimiters, _ = simplelru.NewLRU(httpLimiterCacheSize, nil)
+	}
+	if limiter, ok := l.httpLimiters.Get(remote); ok {
+		return limiter.(*rate.Limiter)
+	}
+	limiter := l.newLimiter()
+	l.httpLimiters.Add(remote, limiter)
+	return limiter
+}
+
+// acquire reserves an execution slot for the given method. It returns false if the
+// method already runs at its concurrency limit, otherwise the returned function must
+// be called to release the slot once the call is done.
+func (l *requestLimits) acquire(method string) (func(), bool) {
+	sem := l.methods[method]
+	if sem == nil {
+		return func() {}, true
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, true
+	default:
+		return nil, false
+	}
+}
+
+// setRate configures the per-connection rate limit. A zero burst defaults to the
+// number of requests allowed in one second.
+func (l *requestLimits) setRate(limit float64, burst int) {
+	if limit <= 0 {
+		limit, burst = 0, 0
+	} else if burst <= 0 {
+		burst = int(math.Ceil(limit))
+	}
+	l.httpLock.Lock()
+	defer l.httpLock.Unlock()
+
+	l.rate, l.burst = rate.Limit(limit), burst
+	l.httpLimiters = nil
+}
@@ -20,6 +20,7 @@ import (
 	"compress/flate"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"sync/atomic"
 
@@ -51,6 +52,7 @@ type Server struct {
 	codecs   mapset.Set
 	// Add compressionLevel inorder to enable set it when open websocket server.
 	compressionLevel int
+	limits           requestLimits
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -88,7 +90,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(codec, s.idgen, &s.services, &s.limits)
 	<-codec.closed()
 	c.Close()
 }
@@ -102,6 +104,45 @@ func (s *Server) SetCompressionLevel(level int) error {
 	return nil
 }
 
+// SetBatchLimits sets limits applied to batch requests. The itemLimit is the maximum
+// number of requests in a batch, maxResponseSize the maximum number of encoded bytes
+// across all responses of a batch, errors included. Zero disables the respective limit.
+//
+// This method should be called before the server starts serving requests.
+func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
+	s.limits.batchItemLimit = itemLimit
+	s.limits.batchResponseMaxSize = maxResponseSize
+}
+
+// SetMethodConcurrencyLimit limits the number of concurrently executing calls of the
+// given method. Calls exceeding the limit are rejected instead of being queued. A zero
+// limit removes it.
+//
+// This method should be called before the server starts serving requests.
+func (s *Server) SetMethodConcurrencyLimit(method string, limit int) error {
+	if limit < 0 {
+		return fmt.Errorf("invalid concurrency limit %d for method %s", limit, method)
+	}
+	if limit == 0 {
+		delete(s.limits.methods, method)
+		return nil
+	}
+	if s.limits.methods == nil {
+		s.limits.methods = make(map[string]chan struct{})
+	}
+	s.limits.methods[method] = make(chan struct{}, limit)
+	return nil
+}
+
+// SetRateLimit limits the number of requests each connection may issue per second,
+// allowing bursts of up to burst requests. Every request of a batch is counted. HTTP
+// clients are told apart by their remote IP. A zero limit disables it.
+//
+// This method should be called before the server starts serving requests.
+func (s *Server) SetRateLimit(limit float64, burst int) {
+	s.limits.setRate(limit, burst)
+}
+
 // serveSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
 // this mode.
@@ -111,8 +152,9 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 		return
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services)
+	h := newHandler(ctx, codec, s.idgen, &s.services, &s.limits)
 	h.allowSubscribe = false
+	h.limiter = s.limits.httpLimiter(codec.remoteAddr())
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -19,6 +19,7 @@ package rpc
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
 	"net"
@@ -150,3 +151,168 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+// This test checks that batches exceeding the item limit are rejected as a whole.
+func TestServerBatchItemLimit(t *testing.T) {
+	server := newTestServer()
+	server.SetBatchLimits(2, 0)
+	defer server.Stop()
+
+	var (
+		request  = `[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",2]},{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["x",3]}]`
+		wantResp = `[{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"batch too large"}}]`
+	)
+	if resp := serveLine(t, server, request); resp != wantResp {
+		t.Fatalf("wrong response\ngot:  %s\nwant: %s", resp, wantResp)
+	}
+}
+
+// This test checks that the calls of a batch are answered with errors once the
+// encoded size limit of the responses would be exceeded, error responses included.
+func TestServerBatchResponseSizeLimit(t *testing.T) {
+	var (
+		echoes   = `[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","method":"test_echo","params":["x",2]},{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["x",3]}]`
+		failures = `[{"jsonrpc":"2.0","id":1,"method":"test_returnError"},{"jsonrpc":"2.0","id":2,"method":"test_returnError"}]`
+	)
+	tests := []struct {
+		request  string
+		limit    int
+		wantResp string
+	}{
+		{
+			request:  echoes,
+			limit:    70,
+			wantResp: `[{"jsonrpc":"2.0","id":1,"result":{"String":"x","Int":1,"Args":null}},{"jsonrpc":"2.0","id":3,"error":{"code":-32003,"message":"response too large"}}]`,
+		},
+		{
+			request:  echoes,
+			limit:    50,
+			wantResp: `[{"jsonrpc":"2.0","id":1,"error":{"code":-32003,"message":"response too large"}},{"jsonrpc":"2.0","id":3,"error":{"code":-32003,"message":"response too large"}}]`,
+		},
+		{
+			request:  failures,
+			limit:    100,
+			wantResp: `[{"jsonrpc":"2.0","id":1,"error":{"code":444,"message":"testError","data":"testError data"}},{"jsonrpc":"2.0","id":2,"error":{"code":-32003,"message":"response too large"}}]`,
+		},
+	}
+	for _, test := range tests {
+		server := newTestServer()
+		server.SetBatchLimits(0, test.limit)
+		if resp := serveLine(t, server, test.request); resp != test.wantResp {
+			t.Errorf("limit %d: wrong response\ngot:  %s\nwant: %s", test.limit, resp, test.wantResp)
+		}
+		server.Stop()
+	}
+}
+
+type blockingService struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingService) Wait() {
+	s.started <- struct{}{}
+	<-s.release
+}
+
+// This test checks that calls exceeding a method's concurrency limit are rejected.
+func TestServerMethodConcurrencyLimit(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	service := &blockingService{started: make(chan struct{}), release: make(chan struct{})}
+	if err := server.RegisterName("blocking", service); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetMethodConcurrencyLimit("blocking_wait", 1); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	done := make(chan error)
+	go func() { done <- client.Call(nil, "blocking_wait") }()
+	<-service.started
+
+	checkErrorCode(t, client.Call(nil, "blocking_wait"), -32005)
+	// Other methods are not affected by the limit.
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("unlimited method failed: %v", err)
+	}
+	close(service.release)
+	if err := <-done; err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	// The slot is free again.
+	go func() { <-service.started }()
+	if err := client.Call(nil, "blocking_wait"); err != nil {
+		t.Fatalf("call after release failed: %v", err)
+	}
+}
+
+// This test checks that connections exceeding the rate limit get errors.
+func TestServerRateLimit(t *testing.T) {
+	server := newTestServer()
+	server.SetRateLimit(0.001, 2)
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+	for i := 0; i < 2; i++ {
+		if err := client.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	checkErrorCode(t, client.Call(nil, "test_noArgsRets"), -32005)
+
+	// Other connections have their own allowance.
+	other := DialInProc(server)
+	defer other.Close()
+	if err := other.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("call on second connection failed: %v", err)
+	}
+}
+
+// This test checks that HTTP clients share their rate limit across connections
+// from different ports.
+func TestHTTPRateLimitByIP(t *testing.T) {
+	var limits requestLimits
+	limits.setRate(1, 1)
+
+	limiter := limits.httpLimiter("127.0.0.1:30303")
+	if limits.httpLimiter("127.0.0.1:30304") != limiter {
+		t.Errorf("connections of the same IP got different limiters")
+	}
+	if limits.httpLimiter("[::1]:30303") == limiter {
+		t.Errorf("connections of different IPs share a limiter")
+	}
+}
+
+// serveLine sends a single line to the server and returns the response line.
+func serveLine(t *testing.T, server *Server, request string) string {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewCodec(serverConn), 0)
+
+	clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(clientConn, request+"\n"); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	resp, err := bufio.NewReader(clientConn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	return strings.TrimRight(resp, "\r\n")
+}
+
+func checkErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected RPC error with code %d, got %v", code, err)
+	}
+	if rpcErr.ErrorCode() != code {
+		t.Fatalf("wrong error code: have %d, want %d (%v)", rpcErr.ErrorCode(), code, err)
+	}
+}
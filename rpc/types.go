@@ -34,6 +34,10 @@ type API struct {
 	Version   string      // api version for DApp's
 	Service   interface{} // receiver instance which holds the methods
 	Public    bool        // indication if the methods must be considered safe for public use
+
+	// Authenticated APIs are only served by the JWT authenticated endpoint, and
+	// over IPC.
+	Authenticated bool
 }
 
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
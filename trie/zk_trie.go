@@ -232,3 +232,48 @@ func VerifyProofSMT(rootHash common.Hash, key []byte, proofDb ethdb.KeyValueRead
 		return nil, fmt.Errorf("bad proof node %v", proof)
 	}
 }
+
+// VerifyProofSMTNodes checks a merkle proof given as the list of encoded nodes
+// written by ZkTrie.Prove. Every node is looked up by its own hash, so that a
+// proof of absence is checked against the root as well, in which case a nil
+// value is returned.
+func VerifyProofSMTNodes(rootHash common.Hash, key []byte, proof [][]byte) (value []byte, err error) {
+	nodes := make(map[zkt.Hash]*zktrie.Node, len(proof))
+	for _, buf := range proof {
+		n, err := zktrie.DecodeSMTProof(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			continue // magic bytes
+		}
+		nodeHash, err := n.NodeHash()
+		if err != nil {
+			return nil, err
+		}
+		nodes[*nodeHash] = n
+	}
+
+	h := zkt.NewHashFromBytes(rootHash.Bytes())
+	k, err := zkt.ToSecureKey(key)
+	if err != nil {
+		return nil, err
+	}
+	proofSMT, n, err := zktrie.BuildZkTrieProof(h, k, len(key)*8, func(key *zkt.Hash) (*zktrie.Node, error) {
+		if *key == zkt.HashZero {
+			return zktrie.NewEmptyNode(), nil
+		}
+		n, ok := nodes[*key]
+		if !ok {
+			return nil, zktrie.ErrKeyNotFound
+		}
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !proofSMT.Existence {
+		return nil, nil
+	}
+	return n.Data(), nil
+}
@@ -3,8 +3,6 @@ package trie
 import (
 	"math/big"
 
-	"github.com/syndtr/goleveldb/leveldb"
-
 	zktrie "github.com/scroll-tech/zktrie/trie"
 
 	"github.com/scroll-tech/go-ethereum/common"
@@ -54,8 +52,13 @@ func (l *ZktrieDatabase) Get(key []byte) ([]byte, error) {
 	}
 
 	v, err := l.db.diskdb.Get(concatKey)
-	if err == leveldb.ErrNotFound {
-		return nil, zktrie.ErrKeyNotFound
+	if err != nil {
+		// Each backend has its own not found error, check the key is missing
+		// instead of matching them.
+		if has, herr := l.db.diskdb.Has(concatKey); herr == nil && !has {
+			return nil, zktrie.ErrKeyNotFound
+		}
+		return nil, err
 	}
 	if l.db.cleans != nil {
 		l.db.cleans.Set(concatKey[:], v)
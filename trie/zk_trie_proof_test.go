@@ -167,6 +167,62 @@ func TestSMTMissingKeyProof(t *testing.T) {
 	}
 }
 
+// proofNodes returns the encoded nodes of a proof database as a list.
+func proofNodes(proof *memorydb.Database) [][]byte {
+	var nodes [][]byte
+	it := proof.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		nodes = append(nodes, common.CopyBytes(it.Value()))
+	}
+	return nodes
+}
+
+func TestSMTProofNodes(t *testing.T) {
+	mt, vals := randomZktrie(t, 100)
+	root := common.BytesToHash(mt.Tree().Root().Bytes())
+	prover := makeSMTProvers(mt)[0]
+	for _, kv := range vals {
+		nodes := proofNodes(prover(kv.k))
+		val, err := VerifyProofSMTNodes(root, kv.k, nodes)
+		if err != nil {
+			t.Fatalf("failed to verify proof for key %x: %v", kv.k, err)
+		}
+		if !verifyValue(val, zkt.NewByte32FromBytesPaddingZero(kv.v)[:]) {
+			t.Fatalf("verified value mismatch for key %x, want %x, get %x", kv.k, kv.v, val)
+		}
+
+		// A tampered node must either break the path to the root or be
+		// irrelevant to it (e.g. the magic bytes or a key preimage)
+		for i := range nodes {
+			tampered := make([][]byte, len(nodes))
+			copy(tampered, nodes)
+			tampered[i] = common.CopyBytes(nodes[i])
+			mutateByte(tampered[i])
+			val, err := VerifyProofSMTNodes(root, kv.k, tampered)
+			if err == nil && !verifyValue(val, zkt.NewByte32FromBytesPaddingZero(kv.v)[:]) {
+				t.Fatalf("tampered proof for key %x verified to %x", kv.k, val)
+			}
+		}
+	}
+
+	// Missing keys are proven absent, but only against the right root
+	for _, key := range []string{"a", "j", "l", "z"} {
+		keyBytes := bytes.Repeat([]byte(key), 32)
+		nodes := proofNodes(prover(keyBytes))
+		val, err := VerifyProofSMTNodes(root, keyBytes, nodes)
+		if err != nil {
+			t.Fatalf("failed to verify absence of key %x: %v", keyBytes, err)
+		}
+		if val != nil {
+			t.Fatalf("verified value mismatch: have %x, want nil", val)
+		}
+		if _, err := VerifyProofSMTNodes(common.Hash{1}, keyBytes, nodes); err == nil {
+			t.Fatalf("expected absence proof of key %x to fail against another root", keyBytes)
+		}
+	}
+}
+
 func randomZktrie(t *testing.T, n int) (*ZkTrie, map[string]*kv) {
 	tr, err := NewZkTrie(common.Hash{}, NewZktrieDatabase((memorydb.New())))
 	if err != nil {
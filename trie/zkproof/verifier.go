@@ -0,0 +1,166 @@
+package zkproof
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	zktrie "github.com/scroll-tech/zktrie/trie"
+	zkt "github.com/scroll-tech/zktrie/types"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+var (
+	errPathRootMismatch = errors.New("path root does not match")
+	errPathKeyMismatch  = errors.New("path does not follow the key")
+	errPathExistence    = errors.New("path existence does not match")
+	errPathValue        = errors.New("path leaf value does not match")
+)
+
+// DecodeSMTPath converts the nodes of a zktrie proof, ordered from the root down
+// as written by ZkTrie.Prove, into an SMTPath.
+func DecodeSMTPath(proof [][]byte) (path *SMTPath, err error) {
+	// decodeProofForMPTPath panics on malformed proofs, which are only
+	// expected from a corrupted trie
+	defer func() {
+		if r := recover(); r != nil {
+			path, err = nil, fmt.Errorf("invalid proof: %v", r)
+		}
+	}()
+	path = new(SMTPath)
+	decodeProofForMPTPath(proof, path)
+	return path, nil
+}
+
+// EmptySMTPath returns the path of any key in an empty trie.
+func EmptySMTPath() *SMTPath {
+	return &SMTPath{
+		KeyPathPart: new(hexutil.Big),
+		Root:        make(hexutil.Bytes, zkt.HashByteLen),
+	}
+}
+
+// AccountKey returns the zktrie key of an account.
+func AccountKey(addr common.Address) *zkt.Hash {
+	return addressToKey(addr)
+}
+
+// StorageKey returns the zktrie key of a storage slot.
+func StorageKey(slot common.Hash) (*zkt.Hash, error) {
+	h, err := zkt.NewByte32FromBytes(slot.Bytes()).Hash()
+	if err != nil {
+		return nil, err
+	}
+	return zkt.NewHashFromBigInt(h), nil
+}
+
+// PathExists reports whether the path ends at the leaf of the key, rather than
+// proving its absence with an empty node or the leaf of another key.
+func PathExists(path *SMTPath, key *zkt.Hash) bool {
+	return path.Leaf != nil && bytes.Equal(path.Leaf.Sibling, key[:])
+}
+
+// VerifyAccountPath checks that the path proves the account against the state
+// root, or its absence if account is nil.
+func VerifyAccountPath(root common.Hash, addr common.Address, account *types.StateAccount, path *SMTPath) error {
+	key := AccountKey(addr)
+	if err := verifySMTPath(root, key, path); err != nil {
+		return err
+	}
+	if PathExists(path, key) != (account != nil) {
+		return errPathExistence
+	}
+	if account == nil {
+		return nil
+	}
+	fields, flag := account.MarshalFields()
+	h, err := zkt.PreHandlingElems(flag, fields)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h[:], path.Leaf.Value) {
+		return errPathValue
+	}
+	return nil
+}
+
+// VerifyStoragePath checks that the path proves the value of the storage slot
+// against the storage root, or its absence if the value is zero.
+func VerifyStoragePath(root common.Hash, slot common.Hash, value common.Hash, path *SMTPath) error {
+	key, err := StorageKey(slot)
+	if err != nil {
+		return err
+	}
+	if err := verifySMTPath(root, key, path); err != nil {
+		return err
+	}
+	if PathExists(path, key) != (value != common.Hash{}) {
+		return errPathExistence
+	}
+	if value == (common.Hash{}) {
+		return nil
+	}
+	vh, err := zkt.NewByte32FromBytes(value.Bytes()).Hash()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(zkt.NewHashFromBigInt(vh)[:], path.Leaf.Value) {
+		return errPathValue
+	}
+	return nil
+}
+
+// verifySMTPath checks that the path follows the key from the root down and
+// hashes up to the root.
+func verifySMTPath(root common.Hash, key *zkt.Hash, path *SMTPath) error {
+	if path == nil || path.KeyPathPart == nil {
+		return errors.New("missing path")
+	}
+	depth := len(path.Path)
+	keyPath := path.KeyPathPart.ToInt()
+	if keyPath.BitLen() > depth {
+		return errPathKeyMismatch
+	}
+	for i := 0; i < depth; i++ {
+		if (keyPath.Bit(i) == 1) != zkt.TestBit(key[:], uint(i)) {
+			return errPathKeyMismatch
+		}
+	}
+
+	cur := &zkt.HashZero
+	if path.Leaf != nil {
+		var err error
+		if cur, err = zktrie.LeafHash(toHash(path.Leaf.Sibling), toHash(path.Leaf.Value)); err != nil {
+			return err
+		}
+	}
+	for i := depth - 1; i >= 0; i-- {
+		if !bytes.Equal(cur[:], path.Path[i].Value) {
+			return errPathRootMismatch
+		}
+		sibling := toHash(path.Path[i].Sibling)
+		var err error
+		if keyPath.Bit(i) == 1 {
+			cur, err = zkt.HashElems(sibling.BigInt(), cur.BigInt())
+		} else {
+			cur, err = zkt.HashElems(cur.BigInt(), sibling.BigInt())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(cur[:], path.Root) || !bytes.Equal(cur[:], zkt.NewHashFromBytes(root.Bytes())[:]) {
+		return errPathRootMismatch
+	}
+	return nil
+}
+
+// toHash converts the inner (little-endian) representation of a hash.
+func toHash(b []byte) *zkt.Hash {
+	var h zkt.Hash
+	copy(h[:], b)
+	return &h
+}